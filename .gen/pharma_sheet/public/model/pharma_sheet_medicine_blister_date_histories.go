@@ -19,4 +19,6 @@ type PharmaSheetMedicineBlisterDateHistories struct {
 	BrandID           *uuid.UUID
 	BlisterChangeDate time.Time
 	CreatedAt         time.Time
+	CreatedBy         *uuid.UUID
+	ConfirmedBy       *uuid.UUID
 }
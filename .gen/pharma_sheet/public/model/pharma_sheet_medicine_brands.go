@@ -20,6 +20,8 @@ type PharmaSheetMedicineBrands struct {
 	BlisterImageURL *string
 	TabletImageURL  *string
 	BoxImageURL     *string
+	Barcode         *string
 	CreatedAt       time.Time
 	UpdatedAt       time.Time
+	DeletedAt       *time.Time
 }
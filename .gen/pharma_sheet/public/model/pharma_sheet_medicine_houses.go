@@ -22,4 +22,5 @@ type PharmaSheetMedicineHouses struct {
 	Label        *string
 	CreatedAt    time.Time
 	UpdatedAt    time.Time
+	DeletedAt    *time.Time
 }
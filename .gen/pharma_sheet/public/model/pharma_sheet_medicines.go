@@ -12,8 +12,17 @@ import (
 )
 
 type PharmaSheetMedicines struct {
-	MedicationID string `sql:"primary_key"`
-	MedicalName  string
-	CreatedAt    time.Time
-	UpdatedAt    time.Time
+	MedicationID   string `sql:"primary_key"`
+	MedicalName    string
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+	Strength       *float64
+	StrengthUnit   *string
+	DosageForm     *PharmaSheetDosageForm
+	Route          *PharmaSheetMedicineRoute
+	PackageUnit    *string
+	IsHighAlert    bool
+	IsControlled   bool
+	IsRefrigerated bool
+	DeletedAt      *time.Time
 }
@@ -13,11 +13,12 @@ import (
 )
 
 type PharmaSheetUsers struct {
-	UserID      uuid.UUID `sql:"primary_key"`
-	FirebaseUID *string
-	Email       string
-	DisplayName *string
-	ImageURL    *string
-	CreatedAt   time.Time
-	UpdatedAt   time.Time
+	UserID        uuid.UUID `sql:"primary_key"`
+	FirebaseUID   *string
+	Email         string
+	DisplayName   *string
+	ImageURL      *string
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+	IsSystemAdmin bool
 }
@@ -16,4 +16,5 @@ type PharmaSheetWarehouses struct {
 	Name        string
 	CreatedAt   time.Time
 	UpdatedAt   time.Time
+	DeletedAt   *time.Time
 }
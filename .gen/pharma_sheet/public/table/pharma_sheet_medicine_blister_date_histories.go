@@ -23,6 +23,8 @@ type pharmaSheetMedicineBlisterDateHistoriesTable struct {
 	BrandID           postgres.ColumnString
 	BlisterChangeDate postgres.ColumnDate
 	CreatedAt         postgres.ColumnTimestampz
+	CreatedBy         postgres.ColumnString
+	ConfirmedBy       postgres.ColumnString
 
 	AllColumns     postgres.ColumnList
 	MutableColumns postgres.ColumnList
@@ -69,8 +71,10 @@ func newPharmaSheetMedicineBlisterDateHistoriesTableImpl(schemaName, tableName,
 		BrandIDColumn           = postgres.StringColumn("brand_id")
 		BlisterChangeDateColumn = postgres.DateColumn("blister_change_date")
 		CreatedAtColumn         = postgres.TimestampzColumn("created_at")
-		allColumns              = postgres.ColumnList{IDColumn, WarehouseIDColumn, MedicationIDColumn, BrandIDColumn, BlisterChangeDateColumn, CreatedAtColumn}
-		mutableColumns          = postgres.ColumnList{WarehouseIDColumn, MedicationIDColumn, BrandIDColumn, BlisterChangeDateColumn, CreatedAtColumn}
+		CreatedByColumn         = postgres.StringColumn("created_by")
+		ConfirmedByColumn       = postgres.StringColumn("confirmed_by")
+		allColumns              = postgres.ColumnList{IDColumn, WarehouseIDColumn, MedicationIDColumn, BrandIDColumn, BlisterChangeDateColumn, CreatedAtColumn, CreatedByColumn, ConfirmedByColumn}
+		mutableColumns          = postgres.ColumnList{WarehouseIDColumn, MedicationIDColumn, BrandIDColumn, BlisterChangeDateColumn, CreatedAtColumn, CreatedByColumn, ConfirmedByColumn}
 	)
 
 	return pharmaSheetMedicineBlisterDateHistoriesTable{
@@ -83,6 +87,8 @@ func newPharmaSheetMedicineBlisterDateHistoriesTableImpl(schemaName, tableName,
 		BrandID:           BrandIDColumn,
 		BlisterChangeDate: BlisterChangeDateColumn,
 		CreatedAt:         CreatedAtColumn,
+		CreatedBy:         CreatedByColumn,
+		ConfirmedBy:       ConfirmedByColumn,
 
 		AllColumns:     allColumns,
 		MutableColumns: mutableColumns,
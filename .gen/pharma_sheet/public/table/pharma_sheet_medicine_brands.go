@@ -24,8 +24,10 @@ type pharmaSheetMedicineBrandsTable struct {
 	BlisterImageURL postgres.ColumnString
 	TabletImageURL  postgres.ColumnString
 	BoxImageURL     postgres.ColumnString
+	Barcode         postgres.ColumnString
 	CreatedAt       postgres.ColumnTimestampz
 	UpdatedAt       postgres.ColumnTimestampz
+	DeletedAt       postgres.ColumnTimestampz
 
 	AllColumns     postgres.ColumnList
 	MutableColumns postgres.ColumnList
@@ -73,10 +75,12 @@ func newPharmaSheetMedicineBrandsTableImpl(schemaName, tableName, alias string)
 		BlisterImageURLColumn = postgres.StringColumn("blister_image_url")
 		TabletImageURLColumn  = postgres.StringColumn("tablet_image_url")
 		BoxImageURLColumn     = postgres.StringColumn("box_image_url")
+		BarcodeColumn         = postgres.StringColumn("barcode")
 		CreatedAtColumn       = postgres.TimestampzColumn("created_at")
 		UpdatedAtColumn       = postgres.TimestampzColumn("updated_at")
-		allColumns            = postgres.ColumnList{IDColumn, MedicationIDColumn, TradeIDColumn, TradeNameColumn, BlisterImageURLColumn, TabletImageURLColumn, BoxImageURLColumn, CreatedAtColumn, UpdatedAtColumn}
-		mutableColumns        = postgres.ColumnList{MedicationIDColumn, TradeIDColumn, TradeNameColumn, BlisterImageURLColumn, TabletImageURLColumn, BoxImageURLColumn, CreatedAtColumn, UpdatedAtColumn}
+		DeletedAtColumn       = postgres.TimestampzColumn("deleted_at")
+		allColumns            = postgres.ColumnList{IDColumn, MedicationIDColumn, TradeIDColumn, TradeNameColumn, BlisterImageURLColumn, TabletImageURLColumn, BoxImageURLColumn, BarcodeColumn, CreatedAtColumn, UpdatedAtColumn, DeletedAtColumn}
+		mutableColumns        = postgres.ColumnList{MedicationIDColumn, TradeIDColumn, TradeNameColumn, BlisterImageURLColumn, TabletImageURLColumn, BoxImageURLColumn, BarcodeColumn, CreatedAtColumn, UpdatedAtColumn, DeletedAtColumn}
 	)
 
 	return pharmaSheetMedicineBrandsTable{
@@ -90,8 +94,10 @@ func newPharmaSheetMedicineBrandsTableImpl(schemaName, tableName, alias string)
 		BlisterImageURL: BlisterImageURLColumn,
 		TabletImageURL:  TabletImageURLColumn,
 		BoxImageURL:     BoxImageURLColumn,
+		Barcode:         BarcodeColumn,
 		CreatedAt:       CreatedAtColumn,
 		UpdatedAt:       UpdatedAtColumn,
+		DeletedAt:       DeletedAtColumn,
 
 		AllColumns:     allColumns,
 		MutableColumns: mutableColumns,
@@ -26,6 +26,7 @@ type pharmaSheetMedicineHousesTable struct {
 	Label        postgres.ColumnString
 	CreatedAt    postgres.ColumnTimestampz
 	UpdatedAt    postgres.ColumnTimestampz
+	DeletedAt    postgres.ColumnTimestampz
 
 	AllColumns     postgres.ColumnList
 	MutableColumns postgres.ColumnList
@@ -75,8 +76,9 @@ func newPharmaSheetMedicineHousesTableImpl(schemaName, tableName, alias string)
 		LabelColumn        = postgres.StringColumn("label")
 		CreatedAtColumn    = postgres.TimestampzColumn("created_at")
 		UpdatedAtColumn    = postgres.TimestampzColumn("updated_at")
-		allColumns         = postgres.ColumnList{IDColumn, WarehouseIDColumn, MedicationIDColumn, LockerColumn, FloorColumn, NoColumn, LabelColumn, CreatedAtColumn, UpdatedAtColumn}
-		mutableColumns     = postgres.ColumnList{WarehouseIDColumn, MedicationIDColumn, LockerColumn, FloorColumn, NoColumn, LabelColumn, CreatedAtColumn, UpdatedAtColumn}
+		DeletedAtColumn    = postgres.TimestampzColumn("deleted_at")
+		allColumns         = postgres.ColumnList{IDColumn, WarehouseIDColumn, MedicationIDColumn, LockerColumn, FloorColumn, NoColumn, LabelColumn, CreatedAtColumn, UpdatedAtColumn, DeletedAtColumn}
+		mutableColumns     = postgres.ColumnList{WarehouseIDColumn, MedicationIDColumn, LockerColumn, FloorColumn, NoColumn, LabelColumn, CreatedAtColumn, UpdatedAtColumn, DeletedAtColumn}
 	)
 
 	return pharmaSheetMedicineHousesTable{
@@ -92,6 +94,7 @@ func newPharmaSheetMedicineHousesTableImpl(schemaName, tableName, alias string)
 		Label:        LabelColumn,
 		CreatedAt:    CreatedAtColumn,
 		UpdatedAt:    UpdatedAtColumn,
+		DeletedAt:    DeletedAtColumn,
 
 		AllColumns:     allColumns,
 		MutableColumns: mutableColumns,
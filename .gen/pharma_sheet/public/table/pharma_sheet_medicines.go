@@ -17,10 +17,19 @@ type pharmaSheetMedicinesTable struct {
 	postgres.Table
 
 	// Columns
-	MedicationID postgres.ColumnString
-	MedicalName  postgres.ColumnString
-	CreatedAt    postgres.ColumnTimestampz
-	UpdatedAt    postgres.ColumnTimestampz
+	MedicationID   postgres.ColumnString
+	MedicalName    postgres.ColumnString
+	CreatedAt      postgres.ColumnTimestampz
+	UpdatedAt      postgres.ColumnTimestampz
+	Strength       postgres.ColumnFloat
+	StrengthUnit   postgres.ColumnString
+	DosageForm     postgres.ColumnString
+	Route          postgres.ColumnString
+	PackageUnit    postgres.ColumnString
+	IsHighAlert    postgres.ColumnBool
+	IsControlled   postgres.ColumnBool
+	IsRefrigerated postgres.ColumnBool
+	DeletedAt      postgres.ColumnTimestampz
 
 	AllColumns     postgres.ColumnList
 	MutableColumns postgres.ColumnList
@@ -61,22 +70,40 @@ func newPharmaSheetMedicinesTable(schemaName, tableName, alias string) *PharmaSh
 
 func newPharmaSheetMedicinesTableImpl(schemaName, tableName, alias string) pharmaSheetMedicinesTable {
 	var (
-		MedicationIDColumn = postgres.StringColumn("medication_id")
-		MedicalNameColumn  = postgres.StringColumn("medical_name")
-		CreatedAtColumn    = postgres.TimestampzColumn("created_at")
-		UpdatedAtColumn    = postgres.TimestampzColumn("updated_at")
-		allColumns         = postgres.ColumnList{MedicationIDColumn, MedicalNameColumn, CreatedAtColumn, UpdatedAtColumn}
-		mutableColumns     = postgres.ColumnList{MedicalNameColumn, CreatedAtColumn, UpdatedAtColumn}
+		MedicationIDColumn   = postgres.StringColumn("medication_id")
+		MedicalNameColumn    = postgres.StringColumn("medical_name")
+		CreatedAtColumn      = postgres.TimestampzColumn("created_at")
+		UpdatedAtColumn      = postgres.TimestampzColumn("updated_at")
+		StrengthColumn       = postgres.FloatColumn("strength")
+		StrengthUnitColumn   = postgres.StringColumn("strength_unit")
+		DosageFormColumn     = postgres.StringColumn("dosage_form")
+		RouteColumn          = postgres.StringColumn("route")
+		PackageUnitColumn    = postgres.StringColumn("package_unit")
+		IsHighAlertColumn    = postgres.BoolColumn("is_high_alert")
+		IsControlledColumn   = postgres.BoolColumn("is_controlled")
+		IsRefrigeratedColumn = postgres.BoolColumn("is_refrigerated")
+		DeletedAtColumn      = postgres.TimestampzColumn("deleted_at")
+		allColumns           = postgres.ColumnList{MedicationIDColumn, MedicalNameColumn, CreatedAtColumn, UpdatedAtColumn, StrengthColumn, StrengthUnitColumn, DosageFormColumn, RouteColumn, PackageUnitColumn, IsHighAlertColumn, IsControlledColumn, IsRefrigeratedColumn, DeletedAtColumn}
+		mutableColumns       = postgres.ColumnList{MedicalNameColumn, CreatedAtColumn, UpdatedAtColumn, StrengthColumn, StrengthUnitColumn, DosageFormColumn, RouteColumn, PackageUnitColumn, IsHighAlertColumn, IsControlledColumn, IsRefrigeratedColumn, DeletedAtColumn}
 	)
 
 	return pharmaSheetMedicinesTable{
 		Table: postgres.NewTable(schemaName, tableName, alias, allColumns...),
 
 		//Columns
-		MedicationID: MedicationIDColumn,
-		MedicalName:  MedicalNameColumn,
-		CreatedAt:    CreatedAtColumn,
-		UpdatedAt:    UpdatedAtColumn,
+		MedicationID:   MedicationIDColumn,
+		MedicalName:    MedicalNameColumn,
+		CreatedAt:      CreatedAtColumn,
+		UpdatedAt:      UpdatedAtColumn,
+		Strength:       StrengthColumn,
+		StrengthUnit:   StrengthUnitColumn,
+		DosageForm:     DosageFormColumn,
+		Route:          RouteColumn,
+		PackageUnit:    PackageUnitColumn,
+		IsHighAlert:    IsHighAlertColumn,
+		IsControlled:   IsControlledColumn,
+		IsRefrigerated: IsRefrigeratedColumn,
+		DeletedAt:      DeletedAtColumn,
 
 		AllColumns:     allColumns,
 		MutableColumns: mutableColumns,
@@ -17,13 +17,14 @@ type pharmaSheetUsersTable struct {
 	postgres.Table
 
 	// Columns
-	UserID      postgres.ColumnString
-	FirebaseUID postgres.ColumnString
-	Email       postgres.ColumnString
-	DisplayName postgres.ColumnString
-	ImageURL    postgres.ColumnString
-	CreatedAt   postgres.ColumnTimestampz
-	UpdatedAt   postgres.ColumnTimestampz
+	UserID        postgres.ColumnString
+	FirebaseUID   postgres.ColumnString
+	Email         postgres.ColumnString
+	DisplayName   postgres.ColumnString
+	ImageURL      postgres.ColumnString
+	CreatedAt     postgres.ColumnTimestampz
+	UpdatedAt     postgres.ColumnTimestampz
+	IsSystemAdmin postgres.ColumnBool
 
 	AllColumns     postgres.ColumnList
 	MutableColumns postgres.ColumnList
@@ -64,28 +65,30 @@ func newPharmaSheetUsersTable(schemaName, tableName, alias string) *PharmaSheetU
 
 func newPharmaSheetUsersTableImpl(schemaName, tableName, alias string) pharmaSheetUsersTable {
 	var (
-		UserIDColumn      = postgres.StringColumn("user_id")
-		FirebaseUIDColumn = postgres.StringColumn("firebase_uid")
-		EmailColumn       = postgres.StringColumn("email")
-		DisplayNameColumn = postgres.StringColumn("display_name")
-		ImageURLColumn    = postgres.StringColumn("image_url")
-		CreatedAtColumn   = postgres.TimestampzColumn("created_at")
-		UpdatedAtColumn   = postgres.TimestampzColumn("updated_at")
-		allColumns        = postgres.ColumnList{UserIDColumn, FirebaseUIDColumn, EmailColumn, DisplayNameColumn, ImageURLColumn, CreatedAtColumn, UpdatedAtColumn}
-		mutableColumns    = postgres.ColumnList{FirebaseUIDColumn, EmailColumn, DisplayNameColumn, ImageURLColumn, CreatedAtColumn, UpdatedAtColumn}
+		UserIDColumn        = postgres.StringColumn("user_id")
+		FirebaseUIDColumn   = postgres.StringColumn("firebase_uid")
+		EmailColumn         = postgres.StringColumn("email")
+		DisplayNameColumn   = postgres.StringColumn("display_name")
+		ImageURLColumn      = postgres.StringColumn("image_url")
+		CreatedAtColumn     = postgres.TimestampzColumn("created_at")
+		UpdatedAtColumn     = postgres.TimestampzColumn("updated_at")
+		IsSystemAdminColumn = postgres.BoolColumn("is_system_admin")
+		allColumns          = postgres.ColumnList{UserIDColumn, FirebaseUIDColumn, EmailColumn, DisplayNameColumn, ImageURLColumn, CreatedAtColumn, UpdatedAtColumn, IsSystemAdminColumn}
+		mutableColumns      = postgres.ColumnList{FirebaseUIDColumn, EmailColumn, DisplayNameColumn, ImageURLColumn, CreatedAtColumn, UpdatedAtColumn, IsSystemAdminColumn}
 	)
 
 	return pharmaSheetUsersTable{
 		Table: postgres.NewTable(schemaName, tableName, alias, allColumns...),
 
 		//Columns
-		UserID:      UserIDColumn,
-		FirebaseUID: FirebaseUIDColumn,
-		Email:       EmailColumn,
-		DisplayName: DisplayNameColumn,
-		ImageURL:    ImageURLColumn,
-		CreatedAt:   CreatedAtColumn,
-		UpdatedAt:   UpdatedAtColumn,
+		UserID:        UserIDColumn,
+		FirebaseUID:   FirebaseUIDColumn,
+		Email:         EmailColumn,
+		DisplayName:   DisplayNameColumn,
+		ImageURL:      ImageURLColumn,
+		CreatedAt:     CreatedAtColumn,
+		UpdatedAt:     UpdatedAtColumn,
+		IsSystemAdmin: IsSystemAdminColumn,
 
 		AllColumns:     allColumns,
 		MutableColumns: mutableColumns,
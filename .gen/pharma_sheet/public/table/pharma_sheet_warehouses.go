@@ -21,6 +21,7 @@ type pharmaSheetWarehousesTable struct {
 	Name        postgres.ColumnString
 	CreatedAt   postgres.ColumnTimestampz
 	UpdatedAt   postgres.ColumnTimestampz
+	DeletedAt   postgres.ColumnTimestampz
 
 	AllColumns     postgres.ColumnList
 	MutableColumns postgres.ColumnList
@@ -65,8 +66,9 @@ func newPharmaSheetWarehousesTableImpl(schemaName, tableName, alias string) phar
 		NameColumn        = postgres.StringColumn("name")
 		CreatedAtColumn   = postgres.TimestampzColumn("created_at")
 		UpdatedAtColumn   = postgres.TimestampzColumn("updated_at")
-		allColumns        = postgres.ColumnList{WarehouseIDColumn, NameColumn, CreatedAtColumn, UpdatedAtColumn}
-		mutableColumns    = postgres.ColumnList{NameColumn, CreatedAtColumn, UpdatedAtColumn}
+		DeletedAtColumn   = postgres.TimestampzColumn("deleted_at")
+		allColumns        = postgres.ColumnList{WarehouseIDColumn, NameColumn, CreatedAtColumn, UpdatedAtColumn, DeletedAtColumn}
+		mutableColumns    = postgres.ColumnList{NameColumn, CreatedAtColumn, UpdatedAtColumn, DeletedAtColumn}
 	)
 
 	return pharmaSheetWarehousesTable{
@@ -77,6 +79,7 @@ func newPharmaSheetWarehousesTableImpl(schemaName, tableName, alias string) phar
 		Name:        NameColumn,
 		CreatedAt:   CreatedAtColumn,
 		UpdatedAt:   UpdatedAtColumn,
+		DeletedAt:   DeletedAtColumn,
 
 		AllColumns:     allColumns,
 		MutableColumns: mutableColumns,
@@ -10,11 +10,24 @@ package table
 // UseSchema sets a new schema name for all generated table SQL builder types. It is recommended to invoke
 // this method only once at the beginning of the program.
 func UseSchema(schema string) {
+	PharmaSheetAuditLogs = PharmaSheetAuditLogs.FromSchema(schema)
+	PharmaSheetBlisterChangeIntervals = PharmaSheetBlisterChangeIntervals.FromSchema(schema)
+	PharmaSheetCategories = PharmaSheetCategories.FromSchema(schema)
+	PharmaSheetLasaPairs = PharmaSheetLasaPairs.FromSchema(schema)
+	PharmaSheetMedicineAliases = PharmaSheetMedicineAliases.FromSchema(schema)
 	PharmaSheetMedicineBlisterDateHistories = PharmaSheetMedicineBlisterDateHistories.FromSchema(schema)
 	PharmaSheetMedicineBrands = PharmaSheetMedicineBrands.FromSchema(schema)
+	PharmaSheetMedicineCategories = PharmaSheetMedicineCategories.FromSchema(schema)
+	PharmaSheetMedicineHouseTransfers = PharmaSheetMedicineHouseTransfers.FromSchema(schema)
 	PharmaSheetMedicineHouses = PharmaSheetMedicineHouses.FromSchema(schema)
+	PharmaSheetMedicineLots = PharmaSheetMedicineLots.FromSchema(schema)
+	PharmaSheetMedicineTags = PharmaSheetMedicineTags.FromSchema(schema)
 	PharmaSheetMedicines = PharmaSheetMedicines.FromSchema(schema)
+	PharmaSheetPickVerifications = PharmaSheetPickVerifications.FromSchema(schema)
+	PharmaSheetStockLevels = PharmaSheetStockLevels.FromSchema(schema)
+	PharmaSheetStockMovements = PharmaSheetStockMovements.FromSchema(schema)
 	PharmaSheetUsers = PharmaSheetUsers.FromSchema(schema)
+	PharmaSheetWarehouseLockers = PharmaSheetWarehouseLockers.FromSchema(schema)
 	PharmaSheetWarehouseSheets = PharmaSheetWarehouseSheets.FromSchema(schema)
 	PharmaSheetWarehouseUsers = PharmaSheetWarehouseUsers.FromSchema(schema)
 	PharmaSheetWarehouses = PharmaSheetWarehouses.FromSchema(schema)
@@ -3,10 +3,16 @@ package config
 import "time"
 
 type AppConfig struct {
-	Environment         string        `env:"ENVIRONMENT"`
-	Port                int           `env:"PORT"`
-	JWTKey              string        `env:"JWT_KEY,required"`
-	APIKey              string        `env:"API_KEY,required"`
-	AccessTokenExpired  time.Duration `env:"ACCESS_TOKEN_EXPIRED,required"`
-	RefreshTokenExpired time.Duration `env:"REFRESH_TOKEN_EXPIRED,required"`
+	Environment               string        `env:"ENVIRONMENT"`
+	Port                      int           `env:"PORT"`
+	JWTKey                    string        `env:"JWT_KEY,required"`
+	APIKey                    string        `env:"API_KEY,required"`
+	AccessTokenExpired        time.Duration `env:"ACCESS_TOKEN_EXPIRED,required"`
+	RefreshTokenExpired       time.Duration `env:"REFRESH_TOKEN_EXPIRED,required"`
+	BlisterChangeIntervalDays int32         `env:"BLISTER_CHANGE_INTERVAL_DAYS" envDefault:"180"`
+	LabelFontPath             string        `env:"LABEL_FONT_PATH"`
+	SuggestionCacheTTL        time.Duration `env:"SUGGESTION_CACHE_TTL" envDefault:"1m"`
+	ConfirmationCodeTTL       time.Duration `env:"CONFIRMATION_CODE_TTL" envDefault:"5m"`
+	TrashRetentionDays        int32         `env:"TRASH_RETENTION_DAYS" envDefault:"30"`
+	TrashPurgeInterval        time.Duration `env:"TRASH_PURGE_INTERVAL" envDefault:"1h"`
 }
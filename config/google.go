@@ -6,9 +6,15 @@ type GoogleConfig struct {
 	FirebaseCredential string        `env:"FIREBASE_CREDENTIAL,required"`
 	Storage            StorageConfig `envPrefix:"STORAGE_"`
 	DriveRootFolderID  string        `env:"DRIVE_ROOT_FOLDER_ID,required"`
+	Retry              RetryConfig   `envPrefix:"RETRY_"`
 }
 
 type StorageConfig struct {
 	BucketName  string        `env:"BUCKET_NAME,required"`
 	ExpiredTime time.Duration `env:"EXPIRED_TIME"`
 }
+
+type RetryConfig struct {
+	MaxRetries     int           `env:"MAX_RETRIES" envDefault:"5"`
+	MaxElapsedTime time.Duration `env:"MAX_ELAPSED_TIME" envDefault:"2m"`
+}
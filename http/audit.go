@@ -0,0 +1,75 @@
+package http
+
+import (
+	"net/http"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/kinkando/pharma-sheet-service/model"
+	"github.com/kinkando/pharma-sheet-service/pkg/logger"
+	"github.com/kinkando/pharma-sheet-service/service"
+	"github.com/labstack/echo/v4"
+)
+
+type AuditHandler struct {
+	auditService service.Audit
+	validate     *validator.Validate
+}
+
+func NewAuditHandler(e *echo.Echo, validate *validator.Validate, auditService service.Audit) {
+	handler := &AuditHandler{
+		auditService: auditService,
+		validate:     validate,
+	}
+
+	route := e.Group("/audit")
+	route.GET("", handler.getAuditLogs)
+	route.GET("/entity/:entityType/:entityID", handler.getAuditLogs)
+	route.GET("/user/:userID", handler.getAuditLogs)
+	route.GET("/warehouse/:warehouseID", handler.getWarehouseAuditLogs)
+}
+
+func (h *AuditHandler) getAuditLogs(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	var req model.FilterAuditLog
+	if err := c.Bind(&req); err != nil {
+		logger.Context(ctx).Error(err)
+		return c.JSON(http.StatusBadRequest, echo.Map{"error": err.Error()})
+	}
+	req.Pagination.AssignDefault()
+
+	if err := h.validate.Struct(req); err != nil {
+		logger.Context(ctx).Error(err)
+		return c.JSON(http.StatusBadRequest, echo.Map{"error": err.Error()})
+	}
+
+	data, err := h.auditService.ListAuditLogs(ctx, req)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, data)
+}
+
+func (h *AuditHandler) getWarehouseAuditLogs(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	var req model.FilterAuditLog
+	if err := c.Bind(&req); err != nil {
+		logger.Context(ctx).Error(err)
+		return c.JSON(http.StatusBadRequest, echo.Map{"error": err.Error()})
+	}
+	req.Pagination.AssignDefault()
+
+	if err := h.validate.Struct(req); err != nil {
+		logger.Context(ctx).Error(err)
+		return c.JSON(http.StatusBadRequest, echo.Map{"error": err.Error()})
+	}
+
+	data, err := h.auditService.ListWarehouseAuditLogs(ctx, req)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, data)
+}
@@ -0,0 +1,121 @@
+package http
+
+import (
+	"net/http"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/kinkando/pharma-sheet-service/model"
+	"github.com/kinkando/pharma-sheet-service/pkg/logger"
+	"github.com/kinkando/pharma-sheet-service/service"
+	"github.com/labstack/echo/v4"
+)
+
+type CategoryHandler struct {
+	categoryService service.Category
+	validate        *validator.Validate
+}
+
+func NewCategoryHandler(e *echo.Echo, validate *validator.Validate, categoryService service.Category) {
+	handler := &CategoryHandler{
+		categoryService: categoryService,
+		validate:        validate,
+	}
+
+	route := e.Group("/category")
+	route.GET("", handler.getCategories)
+	route.POST("", handler.createCategory)
+	route.PUT("/:id", handler.updateCategory)
+	route.DELETE("/:id", handler.deleteCategory)
+
+	tagRoute := e.Group("/tag")
+	tagRoute.GET("", handler.getTags)
+}
+
+func (h *CategoryHandler) getCategories(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	data, err := h.categoryService.ListCategories(ctx)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, data)
+}
+
+func (h *CategoryHandler) createCategory(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	var req model.CreateCategoryRequest
+	if err := c.Bind(&req); err != nil {
+		logger.Context(ctx).Error(err)
+		return c.JSON(http.StatusBadRequest, echo.Map{"error": err.Error()})
+	}
+	if err := h.validate.Struct(req); err != nil {
+		logger.Context(ctx).Error(err)
+		return c.JSON(http.StatusBadRequest, echo.Map{"error": err.Error()})
+	}
+
+	id, err := h.categoryService.CreateCategory(ctx, req)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, echo.Map{"id": id})
+}
+
+func (h *CategoryHandler) updateCategory(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	var req model.UpdateCategoryRequest
+	if err := c.Bind(&req); err != nil {
+		logger.Context(ctx).Error(err)
+		return c.JSON(http.StatusBadRequest, echo.Map{"error": err.Error()})
+	}
+	if err := h.validate.Struct(req); err != nil {
+		logger.Context(ctx).Error(err)
+		return c.JSON(http.StatusBadRequest, echo.Map{"error": err.Error()})
+	}
+
+	if err := h.categoryService.UpdateCategory(ctx, req); err != nil {
+		return err
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+func (h *CategoryHandler) deleteCategory(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	var req model.DeleteCategoryRequest
+	if err := c.Bind(&req); err != nil {
+		logger.Context(ctx).Error(err)
+		return c.JSON(http.StatusBadRequest, echo.Map{"error": err.Error()})
+	}
+	if err := h.validate.Struct(req); err != nil {
+		logger.Context(ctx).Error(err)
+		return c.JSON(http.StatusBadRequest, echo.Map{"error": err.Error()})
+	}
+
+	if err := h.categoryService.DeleteCategory(ctx, req.ID); err != nil {
+		return err
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+func (h *CategoryHandler) getTags(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	var req model.FilterTag
+	if err := c.Bind(&req); err != nil {
+		logger.Context(ctx).Error(err)
+		return c.JSON(http.StatusBadRequest, echo.Map{"error": err.Error()})
+	}
+
+	data, err := h.categoryService.ListTags(ctx, req)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, data)
+}
@@ -0,0 +1,47 @@
+package http
+
+import (
+	"net/http"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/kinkando/pharma-sheet-service/model"
+	"github.com/kinkando/pharma-sheet-service/pkg/logger"
+	"github.com/kinkando/pharma-sheet-service/service"
+	"github.com/labstack/echo/v4"
+)
+
+type ConfirmationHandler struct {
+	confirmationService service.Confirmation
+	validate            *validator.Validate
+}
+
+func NewConfirmationHandler(e *echo.Echo, validate *validator.Validate, confirmationService service.Confirmation) {
+	handler := &ConfirmationHandler{
+		confirmationService: confirmationService,
+		validate:            validate,
+	}
+
+	route := e.Group("/confirmation")
+	route.POST("", handler.createConfirmation)
+}
+
+func (h *ConfirmationHandler) createConfirmation(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	var req model.CreateConfirmationRequest
+	if err := c.Bind(&req); err != nil {
+		logger.Context(ctx).Error(err)
+		return c.JSON(http.StatusBadRequest, echo.Map{"error": err.Error()})
+	}
+	if err := h.validate.Struct(req); err != nil {
+		logger.Context(ctx).Error(err)
+		return c.JSON(http.StatusBadRequest, echo.Map{"error": err.Error()})
+	}
+
+	data, err := h.confirmationService.CreateConfirmation(ctx, req)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, data)
+}
@@ -2,9 +2,11 @@ package http
 
 import (
 	"bytes"
+	"errors"
 	"net/http"
 
 	"github.com/go-playground/validator/v10"
+	"github.com/kinkando/pharma-sheet-service/pkg/circuitbreaker"
 	"github.com/kinkando/pharma-sheet-service/pkg/google"
 	"github.com/labstack/echo/v4"
 )
@@ -29,6 +31,9 @@ func (dh *driveHandler) getFile(c echo.Context) error {
 
 	fileID := c.Param("fileID")
 	result, err := dh.drive.Get(ctx, fileID)
+	if errors.Is(err, circuitbreaker.ErrOpenState) {
+		return c.JSON(http.StatusServiceUnavailable, echo.Map{"error": "file storage is temporarily unavailable"})
+	}
 	if err != nil {
 		return c.JSON(http.StatusBadRequest, echo.Map{"error": err.Error()})
 	}
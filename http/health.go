@@ -6,18 +6,20 @@ import (
 	"time"
 
 	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/kinkando/pharma-sheet-service/pkg/circuitbreaker"
 	"github.com/kinkando/pharma-sheet-service/pkg/logger"
 	"github.com/labstack/echo/v4"
 	"github.com/redis/go-redis/v9"
 )
 
 type HealthzHandler struct {
-	pgPool      *pgxpool.Pool
-	redisClient *redis.Client
+	pgPool          *pgxpool.Pool
+	redisClient     *redis.Client
+	circuitBreakers []circuitbreaker.CircuitBreaker
 }
 
-func NewHealthzHandler(e *echo.Echo, pgPool *pgxpool.Pool, redisClient *redis.Client) {
-	healthzHandler := HealthzHandler{pgPool: pgPool, redisClient: redisClient}
+func NewHealthzHandler(e *echo.Echo, pgPool *pgxpool.Pool, redisClient *redis.Client, circuitBreakers ...circuitbreaker.CircuitBreaker) {
+	healthzHandler := HealthzHandler{pgPool: pgPool, redisClient: redisClient, circuitBreakers: circuitBreakers}
 
 	e.GET("/livez", healthzHandler.Livez)
 	e.GET("/readyz", healthzHandler.Readyz)
@@ -42,5 +44,19 @@ func (hh *HealthzHandler) Readyz(c echo.Context) error {
 		return c.JSON(http.StatusServiceUnavailable, echo.Map{"error": err.Error()})
 	}
 
+	// google dependencies are optional, the service stays ready and reports itself as degraded
+	isDegraded := false
+	dependencies := make(map[string]circuitbreaker.State)
+	for _, cb := range hh.circuitBreakers {
+		state := cb.State()
+		dependencies[cb.Name()] = state
+		if state != circuitbreaker.StateClosed {
+			isDegraded = true
+		}
+	}
+	if isDegraded {
+		return c.JSON(http.StatusOK, echo.Map{"status": "degraded", "dependencies": dependencies})
+	}
+
 	return c.NoContent(http.StatusOK)
 }
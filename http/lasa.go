@@ -0,0 +1,114 @@
+package http
+
+import (
+	"net/http"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/kinkando/pharma-sheet-service/model"
+	"github.com/kinkando/pharma-sheet-service/pkg/logger"
+	"github.com/kinkando/pharma-sheet-service/service"
+	"github.com/labstack/echo/v4"
+)
+
+type LASAHandler struct {
+	lasaService service.LASA
+	validate    *validator.Validate
+}
+
+func NewLASAHandler(e *echo.Echo, validate *validator.Validate, lasaService service.LASA) {
+	handler := &LASAHandler{
+		lasaService: lasaService,
+		validate:    validate,
+	}
+
+	route := e.Group("/lasa")
+	route.GET("", handler.getLASAPairs)
+	route.POST("", handler.createLASAPair)
+	route.DELETE("/:id", handler.deleteLASAPair)
+	route.GET("/warehouse/:warehouseID", handler.getLASAProximityReport)
+}
+
+func (h *LASAHandler) getLASAPairs(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	var req model.FilterLASAPair
+	if err := c.Bind(&req); err != nil {
+		logger.Context(ctx).Error(err)
+		return c.JSON(http.StatusBadRequest, echo.Map{"error": err.Error()})
+	}
+	req.Pagination.AssignDefault()
+
+	if err := h.validate.Struct(req); err != nil {
+		logger.Context(ctx).Error(err)
+		return c.JSON(http.StatusBadRequest, echo.Map{"error": err.Error()})
+	}
+
+	data, err := h.lasaService.ListLASAPairs(ctx, req)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, data)
+}
+
+func (h *LASAHandler) createLASAPair(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	var req model.CreateLASAPairRequest
+	if err := c.Bind(&req); err != nil {
+		logger.Context(ctx).Error(err)
+		return c.JSON(http.StatusBadRequest, echo.Map{"error": err.Error()})
+	}
+	if err := h.validate.Struct(req); err != nil {
+		logger.Context(ctx).Error(err)
+		return c.JSON(http.StatusBadRequest, echo.Map{"error": err.Error()})
+	}
+
+	id, err := h.lasaService.CreateLASAPair(ctx, req)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, echo.Map{"id": id})
+}
+
+func (h *LASAHandler) deleteLASAPair(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	var req model.DeleteLASAPairRequest
+	if err := c.Bind(&req); err != nil {
+		logger.Context(ctx).Error(err)
+		return c.JSON(http.StatusBadRequest, echo.Map{"error": err.Error()})
+	}
+	if err := h.validate.Struct(req); err != nil {
+		logger.Context(ctx).Error(err)
+		return c.JSON(http.StatusBadRequest, echo.Map{"error": err.Error()})
+	}
+
+	if err := h.lasaService.DeleteLASAPair(ctx, req.ID); err != nil {
+		return err
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+func (h *LASAHandler) getLASAProximityReport(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	var req model.FilterLASAProximity
+	if err := c.Bind(&req); err != nil {
+		logger.Context(ctx).Error(err)
+		return c.JSON(http.StatusBadRequest, echo.Map{"error": err.Error()})
+	}
+	if err := h.validate.Struct(req); err != nil {
+		logger.Context(ctx).Error(err)
+		return c.JSON(http.StatusBadRequest, echo.Map{"error": err.Error()})
+	}
+
+	data, err := h.lasaService.GetLASAProximityReport(ctx, req)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, data)
+}
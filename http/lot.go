@@ -0,0 +1,203 @@
+package http
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/kinkando/pharma-sheet-service/model"
+	"github.com/kinkando/pharma-sheet-service/pkg/logger"
+	"github.com/kinkando/pharma-sheet-service/pkg/util"
+	"github.com/kinkando/pharma-sheet-service/service"
+	"github.com/labstack/echo/v4"
+)
+
+type LotHandler struct {
+	lotService service.Lot
+	validate   *validator.Validate
+}
+
+func NewLotHandler(e *echo.Echo, validate *validator.Validate, lotService service.Lot) {
+	handler := &LotHandler{
+		lotService: lotService,
+		validate:   validate,
+	}
+
+	route := e.Group("/lot")
+	route.GET("", handler.getMedicineLots)
+	route.GET("/expiring", handler.getExpiringMedicineLots)
+	route.GET("/:id", handler.getMedicineLot)
+	route.POST("", handler.createMedicineLot)
+	route.PUT("/:id", handler.updateMedicineLot)
+	route.DELETE("/:id", handler.deleteMedicineLot)
+}
+
+func (h *LotHandler) getMedicineLots(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	var req model.FilterMedicineLot
+	if err := c.Bind(&req); err != nil {
+		logger.Context(ctx).Error(err)
+		return c.JSON(http.StatusBadRequest, echo.Map{"error": err.Error()})
+	}
+	req.Pagination.AssignDefault()
+
+	if err := h.validate.Struct(req); err != nil {
+		logger.Context(ctx).Error(err)
+		return c.JSON(http.StatusBadRequest, echo.Map{"error": err.Error()})
+	}
+
+	data, err := h.lotService.ListMedicineLots(ctx, req)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, data)
+}
+
+func (h *LotHandler) getExpiringMedicineLots(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	var req model.FilterExpiringMedicineLot
+	err := c.Bind(&req)
+	if err != nil {
+		logger.Context(ctx).Error(err)
+		return c.JSON(http.StatusBadRequest, echo.Map{"error": err.Error()})
+	}
+	if err := h.validate.Struct(req); err != nil {
+		logger.Context(ctx).Error(err)
+		return c.JSON(http.StatusBadRequest, echo.Map{"error": err.Error()})
+	}
+
+	req.Duration = 30 * util.Day
+	if req.Within != "" {
+		req.Duration, err = util.ParseDuration(req.Within)
+		if err != nil {
+			logger.Context(ctx).Error(err)
+			return c.JSON(http.StatusBadRequest, echo.Map{"error": "within is invalid"})
+		}
+		if req.Duration < 0 {
+			return c.JSON(http.StatusBadRequest, echo.Map{"error": "within must not be negative"})
+		}
+	}
+
+	data, err := h.lotService.ListExpiringMedicineLots(ctx, req)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, data)
+}
+
+func (h *LotHandler) getMedicineLot(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	var req model.GetMedicineLotRequest
+	if err := c.Bind(&req); err != nil {
+		logger.Context(ctx).Error(err)
+		return c.JSON(http.StatusBadRequest, echo.Map{"error": err.Error()})
+	}
+	if err := h.validate.Struct(req); err != nil {
+		logger.Context(ctx).Error(err)
+		return c.JSON(http.StatusBadRequest, echo.Map{"error": err.Error()})
+	}
+
+	data, err := h.lotService.GetMedicineLot(ctx, req.ID)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, data)
+}
+
+func (h *LotHandler) createMedicineLot(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	var req model.CreateMedicineLotRequest
+	err := c.Bind(&req)
+	if err != nil {
+		logger.Context(ctx).Error(err)
+		return c.JSON(http.StatusBadRequest, echo.Map{"error": err.Error()})
+	}
+	if err := h.validate.Struct(req); err != nil {
+		logger.Context(ctx).Error(err)
+		return c.JSON(http.StatusBadRequest, echo.Map{"error": err.Error()})
+	}
+
+	req.ExpiryDate, req.ReceivedDate, err = parseLotDates(req.Expiry, req.Received)
+	if err != nil {
+		logger.Context(ctx).Error(err)
+		return c.JSON(http.StatusBadRequest, echo.Map{"error": err.Error()})
+	}
+
+	id, err := h.lotService.CreateMedicineLot(ctx, req)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, echo.Map{"id": id})
+}
+
+func (h *LotHandler) updateMedicineLot(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	var req model.UpdateMedicineLotRequest
+	err := c.Bind(&req)
+	if err != nil {
+		logger.Context(ctx).Error(err)
+		return c.JSON(http.StatusBadRequest, echo.Map{"error": err.Error()})
+	}
+	if err := h.validate.Struct(req); err != nil {
+		logger.Context(ctx).Error(err)
+		return c.JSON(http.StatusBadRequest, echo.Map{"error": err.Error()})
+	}
+
+	req.ExpiryDate, req.ReceivedDate, err = parseLotDates(req.Expiry, req.Received)
+	if err != nil {
+		logger.Context(ctx).Error(err)
+		return c.JSON(http.StatusBadRequest, echo.Map{"error": err.Error()})
+	}
+
+	err = h.lotService.UpdateMedicineLot(ctx, req)
+	if err != nil {
+		return err
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+func (h *LotHandler) deleteMedicineLot(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	var req model.DeleteMedicineLotRequest
+	if err := c.Bind(&req); err != nil {
+		logger.Context(ctx).Error(err)
+		return c.JSON(http.StatusBadRequest, echo.Map{"error": err.Error()})
+	}
+	if err := h.validate.Struct(req); err != nil {
+		logger.Context(ctx).Error(err)
+		return c.JSON(http.StatusBadRequest, echo.Map{"error": err.Error()})
+	}
+
+	err := h.lotService.DeleteMedicineLot(ctx, req)
+	if err != nil {
+		return err
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+func parseLotDates(expiry string, received *string) (expiryDate time.Time, receivedDate *time.Time, err error) {
+	expiryDate, err = time.Parse(time.DateOnly, expiry)
+	if err != nil {
+		return
+	}
+	if received != nil && *received != "" {
+		date, err := time.Parse(time.DateOnly, *received)
+		if err != nil {
+			return expiryDate, nil, err
+		}
+		receivedDate = &date
+	}
+	return expiryDate, receivedDate, nil
+}
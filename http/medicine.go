@@ -1,12 +1,15 @@
 package http
 
 import (
+	"fmt"
 	"net/http"
+	"strings"
 	"time"
 
 	"github.com/go-playground/validator/v10"
 	"github.com/kinkando/pharma-sheet-service/model"
 	"github.com/kinkando/pharma-sheet-service/pkg/logger"
+	"github.com/kinkando/pharma-sheet-service/pkg/util"
 	"github.com/kinkando/pharma-sheet-service/service"
 	"github.com/labstack/echo/v4"
 )
@@ -24,16 +27,22 @@ func NewMedicineHandler(e *echo.Echo, validate *validator.Validate, medicineServ
 
 	route := e.Group("/medicine")
 	route.GET("", handler.getMedicines)
+	route.GET("/search", handler.searchMedicines)
 	route.GET("/master/all", handler.getAllMedicines)
 	route.GET("/master/pagination", handler.getMedicineMasterPagination)
 	route.GET("/:medicationID", handler.getMedicine)
 	route.POST("/:medicationID", handler.createMedicine)
 	route.PATCH("/:medicationID", handler.updateMedicine)
 	route.DELETE("/:medicationID", handler.deleteMedicine)
+	route.PUT("/:medicationID/category", handler.updateMedicineCategories)
+	route.PUT("/:medicationID/tag", handler.updateMedicineTags)
+	route.PUT("/:medicationID/flag", handler.updateMedicineFlag)
+	route.POST("/:medicationID/merge", handler.mergeMedicine)
 
 	houseRoute := e.Group("/house")
 	houseRoute.GET("", handler.getMedicineHouses)
 	houseRoute.POST("", handler.createMedicineHouse)
+	houseRoute.GET("/label", handler.printMedicineHouseLabels)
 	houseRoute.PUT("/:id", handler.updateMedicineHouse)
 	houseRoute.DELETE("/:id", handler.deleteMedicineHouse)
 
@@ -44,12 +53,19 @@ func NewMedicineHandler(e *echo.Echo, validate *validator.Validate, medicineServ
 	brandRoute.PUT("/:id", handler.updateMedicineBrand)
 	brandRoute.DELETE("/:id", handler.deleteMedicineBrand)
 
+	scanRoute := e.Group("/scan")
+	scanRoute.GET("/:code", handler.scanMedicine)
+
 	historyRoute := e.Group("/history")
 	historyRoute.GET("", handler.listMedicineBlisterDateHistory)
 	historyRoute.POST("", handler.createMedicineBlisterDateHistory)
 	historyRoute.DELETE("/warehouse/:warehouseID/medicine/:medicationID", handler.deleteMedicineBlisterDateHistory)
 	historyRoute.DELETE("/warehouse/:warehouseID/medicine/:medicationID/brand/:brandID", handler.deleteMedicineBlisterDateHistory)
 	historyRoute.DELETE("/:historyID", handler.deleteMedicineBlisterDateHistory)
+	historyRoute.GET("/due", handler.listBlisterChangeDues)
+	historyRoute.GET("/interval", handler.listBlisterChangeIntervals)
+	historyRoute.PUT("/interval", handler.upsertBlisterChangeInterval)
+	historyRoute.DELETE("/interval/:id", handler.deleteBlisterChangeInterval)
 }
 
 func (h *MedicineHandler) getMedicines(c echo.Context) error {
@@ -75,6 +91,32 @@ func (h *MedicineHandler) getMedicines(c echo.Context) error {
 	return c.JSON(http.StatusOK, data)
 }
 
+func (h *MedicineHandler) searchMedicines(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	var req model.FilterMedicine
+	if err := c.Bind(&req); err != nil {
+		logger.Context(ctx).Error(err)
+		return c.JSON(http.StatusBadRequest, echo.Map{"error": err.Error()})
+	}
+	req.Pagination.AssignDefault()
+
+	if err := h.validate.Struct(req); err != nil {
+		logger.Context(ctx).Error(err)
+		return c.JSON(http.StatusBadRequest, echo.Map{"error": err.Error()})
+	}
+	if strings.TrimSpace(req.Search) == "" {
+		return c.JSON(http.StatusBadRequest, echo.Map{"error": "search is required"})
+	}
+
+	data, err := h.medicineService.SearchMedicines(ctx, req)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, data)
+}
+
 func (h *MedicineHandler) getAllMedicines(c echo.Context) error {
 	ctx := c.Request().Context()
 
@@ -89,12 +131,12 @@ func (h *MedicineHandler) getAllMedicines(c echo.Context) error {
 func (h *MedicineHandler) getMedicineMasterPagination(c echo.Context) error {
 	ctx := c.Request().Context()
 
-	var req model.Pagination
+	var req model.FilterMedicineMaster
 	if err := c.Bind(&req); err != nil {
 		logger.Context(ctx).Error(err)
 		return c.JSON(http.StatusBadRequest, echo.Map{"error": err.Error()})
 	}
-	req.AssignDefault()
+	req.Pagination.AssignDefault()
 
 	if err := h.validate.Struct(req); err != nil {
 		logger.Context(ctx).Error(err)
@@ -122,6 +164,27 @@ func (h *MedicineHandler) getMedicine(c echo.Context) error {
 	return c.JSON(http.StatusOK, medicine)
 }
 
+func (h *MedicineHandler) scanMedicine(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	var req model.ScanRequest
+	if err := c.Bind(&req); err != nil {
+		logger.Context(ctx).Error(err)
+		return c.JSON(http.StatusBadRequest, echo.Map{"error": err.Error()})
+	}
+	if err := h.validate.Struct(req); err != nil {
+		logger.Context(ctx).Error(err)
+		return c.JSON(http.StatusBadRequest, echo.Map{"error": err.Error()})
+	}
+
+	data, err := h.medicineService.ScanMedicine(ctx, req)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, data)
+}
+
 func (h *MedicineHandler) createMedicine(c echo.Context) error {
 	ctx := c.Request().Context()
 
@@ -137,10 +200,7 @@ func (h *MedicineHandler) createMedicine(c echo.Context) error {
 
 	medicationID, err := h.medicineService.CreateMedicine(ctx, req)
 	if err != nil {
-		if model.IsConflictError(err) {
-			return c.JSON(http.StatusConflict, echo.Map{"error": err.Error()})
-		}
-		return c.JSON(http.StatusInternalServerError, echo.Map{"error": err.Error()})
+		return err
 	}
 
 	return c.JSON(http.StatusOK, echo.Map{"medicationID": medicationID})
@@ -161,7 +221,7 @@ func (h *MedicineHandler) updateMedicine(c echo.Context) error {
 
 	err := h.medicineService.UpdateMedicine(ctx, req)
 	if err != nil {
-		return c.JSON(http.StatusInternalServerError, echo.Map{"error": err.Error()})
+		return err
 	}
 
 	return c.NoContent(http.StatusNoContent)
@@ -170,9 +230,94 @@ func (h *MedicineHandler) updateMedicine(c echo.Context) error {
 func (h *MedicineHandler) deleteMedicine(c echo.Context) error {
 	ctx := c.Request().Context()
 
-	medicationID := c.Param("medicationID")
+	var req model.DeleteMedicineRequest
+	if err := c.Bind(&req); err != nil {
+		logger.Context(ctx).Error(err)
+		return c.JSON(http.StatusBadRequest, echo.Map{"error": err.Error()})
+	}
+	if err := h.validate.Struct(req); err != nil {
+		logger.Context(ctx).Error(err)
+		return c.JSON(http.StatusBadRequest, echo.Map{"error": err.Error()})
+	}
+
+	if !req.Force {
+		if err := h.medicineService.DeleteMedicine(ctx, req.MedicationID); err != nil {
+			return err
+		}
+		return c.NoContent(http.StatusNoContent)
+	}
+
+	// a forced delete without a token only previews the impact, the token of the preview confirms the delete
+	if req.Token == "" {
+		preview, err := h.medicineService.PreviewForceDeleteMedicine(ctx, req.MedicationID)
+		if err != nil {
+			return err
+		}
+		return c.JSON(http.StatusOK, preview)
+	}
+
+	if err := h.medicineService.ForceDeleteMedicine(ctx, req); err != nil {
+		return err
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+func (h *MedicineHandler) updateMedicineCategories(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	var req model.UpdateMedicineCategoryRequest
+	if err := c.Bind(&req); err != nil {
+		logger.Context(ctx).Error(err)
+		return c.JSON(http.StatusBadRequest, echo.Map{"error": err.Error()})
+	}
+	if err := h.validate.Struct(req); err != nil {
+		logger.Context(ctx).Error(err)
+		return c.JSON(http.StatusBadRequest, echo.Map{"error": err.Error()})
+	}
+
+	err := h.medicineService.UpdateMedicineCategories(ctx, req)
+	if err != nil {
+		return err
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+func (h *MedicineHandler) updateMedicineTags(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	var req model.UpdateMedicineTagRequest
+	if err := c.Bind(&req); err != nil {
+		logger.Context(ctx).Error(err)
+		return c.JSON(http.StatusBadRequest, echo.Map{"error": err.Error()})
+	}
+	if err := h.validate.Struct(req); err != nil {
+		logger.Context(ctx).Error(err)
+		return c.JSON(http.StatusBadRequest, echo.Map{"error": err.Error()})
+	}
+
+	err := h.medicineService.UpdateMedicineTags(ctx, req)
+	if err != nil {
+		return err
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+func (h *MedicineHandler) updateMedicineFlag(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	var req model.UpdateMedicineFlagRequest
+	if err := c.Bind(&req); err != nil {
+		logger.Context(ctx).Error(err)
+		return c.JSON(http.StatusBadRequest, echo.Map{"error": err.Error()})
+	}
+	if err := h.validate.Struct(req); err != nil {
+		logger.Context(ctx).Error(err)
+		return c.JSON(http.StatusBadRequest, echo.Map{"error": err.Error()})
+	}
 
-	err := h.medicineService.DeleteMedicine(ctx, medicationID)
+	err := h.medicineService.UpdateMedicineFlag(ctx, req)
 	if err != nil {
 		return err
 	}
@@ -180,6 +325,27 @@ func (h *MedicineHandler) deleteMedicine(c echo.Context) error {
 	return c.NoContent(http.StatusNoContent)
 }
 
+func (h *MedicineHandler) mergeMedicine(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	var req model.MergeMedicineRequest
+	if err := c.Bind(&req); err != nil {
+		logger.Context(ctx).Error(err)
+		return c.JSON(http.StatusBadRequest, echo.Map{"error": err.Error()})
+	}
+	if err := h.validate.Struct(req); err != nil {
+		logger.Context(ctx).Error(err)
+		return c.JSON(http.StatusBadRequest, echo.Map{"error": err.Error()})
+	}
+
+	merged, err := h.medicineService.MergeMedicine(ctx, req)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, merged)
+}
+
 func (h *MedicineHandler) getMedicineHouses(c echo.Context) error {
 	ctx := c.Request().Context()
 
@@ -216,12 +382,12 @@ func (h *MedicineHandler) createMedicineHouse(c echo.Context) error {
 		return c.JSON(http.StatusBadRequest, echo.Map{"error": err.Error()})
 	}
 
-	id, err := h.medicineService.CreateMedicineHouse(ctx, req)
+	id, warnings, err := h.medicineService.CreateMedicineHouse(ctx, req)
 	if err != nil {
 		return err
 	}
 
-	return c.JSON(http.StatusOK, echo.Map{"id": id})
+	return c.JSON(http.StatusOK, echo.Map{"id": id, "warnings": warnings})
 }
 
 func (h *MedicineHandler) updateMedicineHouse(c echo.Context) error {
@@ -237,14 +403,40 @@ func (h *MedicineHandler) updateMedicineHouse(c echo.Context) error {
 		return c.JSON(http.StatusBadRequest, echo.Map{"error": err.Error()})
 	}
 
-	err := h.medicineService.UpdateMedicineHouse(ctx, req)
+	warnings, err := h.medicineService.UpdateMedicineHouse(ctx, req)
 	if err != nil {
 		return err
 	}
 
+	if len(warnings) > 0 {
+		return c.JSON(http.StatusOK, echo.Map{"warnings": warnings})
+	}
 	return c.NoContent(http.StatusNoContent)
 }
 
+func (h *MedicineHandler) printMedicineHouseLabels(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	var req model.PrintMedicineHouseLabelRequest
+	if err := c.Bind(&req); err != nil {
+		logger.Context(ctx).Error(err)
+		return c.JSON(http.StatusBadRequest, echo.Map{"error": err.Error()})
+	}
+
+	if err := h.validate.Struct(req); err != nil {
+		logger.Context(ctx).Error(err)
+		return c.JSON(http.StatusBadRequest, echo.Map{"error": err.Error()})
+	}
+
+	pdf, err := h.medicineService.PrintMedicineHouseLabels(ctx, req)
+	if err != nil {
+		return err
+	}
+
+	c.Response().Header().Set(echo.HeaderContentDisposition, fmt.Sprintf("inline; filename=%q", "label-"+req.WarehouseID+".pdf"))
+	return c.Blob(http.StatusOK, "application/pdf", pdf)
+}
+
 func (h *MedicineHandler) deleteMedicineHouse(c echo.Context) error {
 	ctx := c.Request().Context()
 
@@ -470,3 +662,100 @@ func (h *MedicineHandler) deleteMedicineBlisterDateHistory(c echo.Context) error
 
 	return c.NoContent(http.StatusNoContent)
 }
+
+func (h *MedicineHandler) listBlisterChangeDues(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	var req model.FilterBlisterChangeDue
+	err := c.Bind(&req)
+	if err != nil {
+		logger.Context(ctx).Error(err)
+		return c.JSON(http.StatusBadRequest, echo.Map{"error": err.Error()})
+	}
+	if err := h.validate.Struct(req); err != nil {
+		logger.Context(ctx).Error(err)
+		return c.JSON(http.StatusBadRequest, echo.Map{"error": err.Error()})
+	}
+
+	req.Duration = 7 * util.Day
+	if req.Within != "" {
+		req.Duration, err = util.ParseDuration(req.Within)
+		if err != nil {
+			logger.Context(ctx).Error(err)
+			return c.JSON(http.StatusBadRequest, echo.Map{"error": "within is invalid"})
+		}
+		if req.Duration < 0 {
+			return c.JSON(http.StatusBadRequest, echo.Map{"error": "within must not be negative"})
+		}
+	}
+
+	data, err := h.medicineService.ListBlisterChangeDues(ctx, req)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, data)
+}
+
+func (h *MedicineHandler) listBlisterChangeIntervals(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	var req model.FilterBlisterChangeInterval
+	if err := c.Bind(&req); err != nil {
+		logger.Context(ctx).Error(err)
+		return c.JSON(http.StatusBadRequest, echo.Map{"error": err.Error()})
+	}
+	if err := h.validate.Struct(req); err != nil {
+		logger.Context(ctx).Error(err)
+		return c.JSON(http.StatusBadRequest, echo.Map{"error": err.Error()})
+	}
+
+	data, err := h.medicineService.ListBlisterChangeIntervals(ctx, req)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, data)
+}
+
+func (h *MedicineHandler) upsertBlisterChangeInterval(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	var req model.UpsertBlisterChangeIntervalRequest
+	if err := c.Bind(&req); err != nil {
+		logger.Context(ctx).Error(err)
+		return c.JSON(http.StatusBadRequest, echo.Map{"error": err.Error()})
+	}
+	if err := h.validate.Struct(req); err != nil {
+		logger.Context(ctx).Error(err)
+		return c.JSON(http.StatusBadRequest, echo.Map{"error": err.Error()})
+	}
+
+	id, err := h.medicineService.UpsertBlisterChangeInterval(ctx, req)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, echo.Map{"id": id})
+}
+
+func (h *MedicineHandler) deleteBlisterChangeInterval(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	var req model.DeleteBlisterChangeIntervalRequest
+	if err := c.Bind(&req); err != nil {
+		logger.Context(ctx).Error(err)
+		return c.JSON(http.StatusBadRequest, echo.Map{"error": err.Error()})
+	}
+	if err := h.validate.Struct(req); err != nil {
+		logger.Context(ctx).Error(err)
+		return c.JSON(http.StatusBadRequest, echo.Map{"error": err.Error()})
+	}
+
+	err := h.medicineService.DeleteBlisterChangeInterval(ctx, req.ID)
+	if err != nil {
+		return err
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
@@ -0,0 +1,47 @@
+package http
+
+import (
+	"net/http"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/kinkando/pharma-sheet-service/model"
+	"github.com/kinkando/pharma-sheet-service/pkg/logger"
+	"github.com/kinkando/pharma-sheet-service/service"
+	"github.com/labstack/echo/v4"
+)
+
+type SearchHandler struct {
+	searchService service.Search
+	validate      *validator.Validate
+}
+
+func NewSearchHandler(e *echo.Echo, validate *validator.Validate, searchService service.Search) {
+	handler := &SearchHandler{
+		searchService: searchService,
+		validate:      validate,
+	}
+
+	route := e.Group("/search")
+	route.GET("/suggest", handler.suggest)
+}
+
+func (h *SearchHandler) suggest(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	var req model.FilterSuggestion
+	if err := c.Bind(&req); err != nil {
+		logger.Context(ctx).Error(err)
+		return c.JSON(http.StatusBadRequest, echo.Map{"error": err.Error()})
+	}
+	if err := h.validate.Struct(req); err != nil {
+		logger.Context(ctx).Error(err)
+		return c.JSON(http.StatusBadRequest, echo.Map{"error": err.Error()})
+	}
+
+	data, err := h.searchService.Suggest(ctx, req)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, data)
+}
@@ -24,6 +24,7 @@ func NewSheetHandler(e *echo.Echo, validate *validator.Validate, sheetService se
 	route := e.Group("/sheet")
 	route.GET("/warehouse/:warehouseID", handler.summarizeMedicineSyncData)
 	route.PUT("/warehouse/:warehouseID", handler.syncMedicine)
+	route.GET("/warehouse/:warehouseID/progress", handler.streamSyncProgress)
 }
 
 func (h *SheetHandler) summarizeMedicineSyncData(c echo.Context) error {
@@ -71,3 +72,26 @@ func (h *SheetHandler) syncMedicine(c echo.Context) error {
 
 	return c.NoContent(http.StatusNoContent)
 }
+
+func (h *SheetHandler) streamSyncProgress(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	var req model.GetSyncProgressRequest
+	if err := c.Bind(&req); err != nil {
+		logger.Context(ctx).Error(err)
+		return c.JSON(http.StatusBadRequest, echo.Map{"error": err.Error()})
+	}
+
+	if err := h.validate.Struct(req); err != nil {
+		logger.Context(ctx).Error(err)
+		return c.JSON(http.StatusBadRequest, echo.Map{"error": err.Error()})
+	}
+
+	progresses, err := h.sheetService.StreamSyncProgress(ctx, req)
+	if err != nil {
+		logger.Context(ctx).Error(err)
+		return err
+	}
+
+	return streamServerSentEvents(c, "progress", progresses, model.SyncProgress.IsFinished)
+}
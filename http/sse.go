@@ -0,0 +1,57 @@
+package http
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+const serverSentEventsHeartbeatInterval = 15 * time.Second
+
+// streamServerSentEvents writes every event as text/event-stream until the channel is closed,
+// the client disconnects or isLast reports the final event.
+func streamServerSentEvents[T any](c echo.Context, event string, events <-chan T, isLast func(T) bool) error {
+	ctx := c.Request().Context()
+	res := c.Response()
+	res.Header().Set(echo.HeaderContentType, "text/event-stream")
+	res.Header().Set(echo.HeaderCacheControl, "no-cache")
+	res.Header().Set(echo.HeaderConnection, "keep-alive")
+	res.Header().Set("X-Accel-Buffering", "no")
+	res.WriteHeader(http.StatusOK)
+	res.Flush()
+
+	heartbeat := time.NewTicker(serverSentEventsHeartbeatInterval)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+
+		case <-heartbeat.C:
+			if _, err := fmt.Fprint(res, ": heartbeat\n\n"); err != nil {
+				return nil
+			}
+			res.Flush()
+
+		case data, ok := <-events:
+			if !ok {
+				return nil
+			}
+			payload, err := json.Marshal(data)
+			if err != nil {
+				return err
+			}
+			if _, err = fmt.Fprintf(res, "event: %s\ndata: %s\n\n", event, payload); err != nil {
+				return nil
+			}
+			res.Flush()
+			if isLast != nil && isLast(data) {
+				return nil
+			}
+		}
+	}
+}
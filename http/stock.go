@@ -0,0 +1,228 @@
+package http
+
+import (
+	"net/http"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/kinkando/pharma-sheet-service/model"
+	"github.com/kinkando/pharma-sheet-service/pkg/logger"
+	"github.com/kinkando/pharma-sheet-service/service"
+	"github.com/labstack/echo/v4"
+)
+
+type StockHandler struct {
+	stockService service.Stock
+	validate     *validator.Validate
+}
+
+func NewStockHandler(e *echo.Echo, validate *validator.Validate, stockService service.Stock) {
+	handler := &StockHandler{
+		stockService: stockService,
+		validate:     validate,
+	}
+
+	route := e.Group("/stock")
+	route.GET("/balance", handler.getStockBalances)
+	route.GET("/movement", handler.getStockMovements)
+	route.POST("/movement", handler.createStockMovement)
+	route.GET("/level", handler.getStockLevels)
+	route.PUT("/level", handler.upsertStockLevel)
+	route.DELETE("/level/:id", handler.deleteStockLevel)
+
+	e.POST("/house/:id/transfer", handler.transferMedicineHouse)
+
+	warehouseRoute := e.Group("/warehouse/:warehouseID/low-stock")
+	warehouseRoute.GET("", handler.getLowStocks)
+	warehouseRoute.GET("/event", handler.streamStockLevelEvents)
+}
+
+func (h *StockHandler) getStockBalances(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	var req model.FilterStockBalance
+	if err := c.Bind(&req); err != nil {
+		logger.Context(ctx).Error(err)
+		return c.JSON(http.StatusBadRequest, echo.Map{"error": err.Error()})
+	}
+	if err := h.validate.Struct(req); err != nil {
+		logger.Context(ctx).Error(err)
+		return c.JSON(http.StatusBadRequest, echo.Map{"error": err.Error()})
+	}
+
+	data, err := h.stockService.ListStockBalances(ctx, req)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, data)
+}
+
+func (h *StockHandler) getStockMovements(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	var req model.FilterStockMovement
+	if err := c.Bind(&req); err != nil {
+		logger.Context(ctx).Error(err)
+		return c.JSON(http.StatusBadRequest, echo.Map{"error": err.Error()})
+	}
+	req.Pagination.AssignDefault()
+
+	if err := h.validate.Struct(req); err != nil {
+		logger.Context(ctx).Error(err)
+		return c.JSON(http.StatusBadRequest, echo.Map{"error": err.Error()})
+	}
+
+	data, err := h.stockService.ListStockMovements(ctx, req)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, data)
+}
+
+func (h *StockHandler) createStockMovement(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	var req model.CreateStockMovementRequest
+	if err := c.Bind(&req); err != nil {
+		logger.Context(ctx).Error(err)
+		return c.JSON(http.StatusBadRequest, echo.Map{"error": err.Error()})
+	}
+	if err := h.validate.Struct(req); err != nil {
+		logger.Context(ctx).Error(err)
+		return c.JSON(http.StatusBadRequest, echo.Map{"error": err.Error()})
+	}
+
+	ids, err := h.stockService.CreateStockMovement(ctx, req)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, echo.Map{"ids": ids})
+}
+
+func (h *StockHandler) transferMedicineHouse(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	var req model.TransferMedicineHouseRequest
+	if err := c.Bind(&req); err != nil {
+		logger.Context(ctx).Error(err)
+		return c.JSON(http.StatusBadRequest, echo.Map{"error": err.Error()})
+	}
+	if err := h.validate.Struct(req); err != nil {
+		logger.Context(ctx).Error(err)
+		return c.JSON(http.StatusBadRequest, echo.Map{"error": err.Error()})
+	}
+
+	data, err := h.stockService.TransferMedicineHouse(ctx, req)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, data)
+}
+
+func (h *StockHandler) getStockLevels(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	var req model.FilterStockLevel
+	if err := c.Bind(&req); err != nil {
+		logger.Context(ctx).Error(err)
+		return c.JSON(http.StatusBadRequest, echo.Map{"error": err.Error()})
+	}
+	if err := h.validate.Struct(req); err != nil {
+		logger.Context(ctx).Error(err)
+		return c.JSON(http.StatusBadRequest, echo.Map{"error": err.Error()})
+	}
+
+	data, err := h.stockService.ListStockLevels(ctx, req)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, data)
+}
+
+func (h *StockHandler) upsertStockLevel(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	var req model.UpsertStockLevelRequest
+	if err := c.Bind(&req); err != nil {
+		logger.Context(ctx).Error(err)
+		return c.JSON(http.StatusBadRequest, echo.Map{"error": err.Error()})
+	}
+	if err := h.validate.Struct(req); err != nil {
+		logger.Context(ctx).Error(err)
+		return c.JSON(http.StatusBadRequest, echo.Map{"error": err.Error()})
+	}
+
+	id, err := h.stockService.UpsertStockLevel(ctx, req)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, echo.Map{"id": id})
+}
+
+func (h *StockHandler) deleteStockLevel(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	var req model.DeleteStockLevelRequest
+	if err := c.Bind(&req); err != nil {
+		logger.Context(ctx).Error(err)
+		return c.JSON(http.StatusBadRequest, echo.Map{"error": err.Error()})
+	}
+	if err := h.validate.Struct(req); err != nil {
+		logger.Context(ctx).Error(err)
+		return c.JSON(http.StatusBadRequest, echo.Map{"error": err.Error()})
+	}
+
+	err := h.stockService.DeleteStockLevel(ctx, req.ID)
+	if err != nil {
+		return err
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+func (h *StockHandler) getLowStocks(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	var req model.FilterLowStock
+	if err := c.Bind(&req); err != nil {
+		logger.Context(ctx).Error(err)
+		return c.JSON(http.StatusBadRequest, echo.Map{"error": err.Error()})
+	}
+	if err := h.validate.Struct(req); err != nil {
+		logger.Context(ctx).Error(err)
+		return c.JSON(http.StatusBadRequest, echo.Map{"error": err.Error()})
+	}
+
+	data, err := h.stockService.ListLowStocks(ctx, req)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, data)
+}
+
+func (h *StockHandler) streamStockLevelEvents(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	var req model.StreamStockLevelEventRequest
+	if err := c.Bind(&req); err != nil {
+		logger.Context(ctx).Error(err)
+		return c.JSON(http.StatusBadRequest, echo.Map{"error": err.Error()})
+	}
+	if err := h.validate.Struct(req); err != nil {
+		logger.Context(ctx).Error(err)
+		return c.JSON(http.StatusBadRequest, echo.Map{"error": err.Error()})
+	}
+
+	events, err := h.stockService.StreamStockLevelEvents(ctx, req)
+	if err != nil {
+		return err
+	}
+
+	return streamServerSentEvents(c, "stock-level", events, nil)
+}
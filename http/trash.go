@@ -0,0 +1,143 @@
+package http
+
+import (
+	"net/http"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/kinkando/pharma-sheet-service/model"
+	"github.com/kinkando/pharma-sheet-service/pkg/logger"
+	"github.com/kinkando/pharma-sheet-service/service"
+	"github.com/labstack/echo/v4"
+)
+
+type TrashHandler struct {
+	trashService service.Trash
+	validate     *validator.Validate
+}
+
+func NewTrashHandler(e *echo.Echo, validate *validator.Validate, trashService service.Trash) {
+	handler := &TrashHandler{
+		trashService: trashService,
+		validate:     validate,
+	}
+
+	route := e.Group("/trash")
+	route.GET("/warehouse", handler.getDeletedWarehouses)
+	route.GET("/warehouse/:warehouseID", handler.getWarehouseTrash)
+	route.POST("/warehouse/:warehouseID/restore", handler.restoreWarehouse)
+	route.POST("/medicine/:medicationID/restore", handler.restoreMedicine)
+	route.POST("/brand/:id/restore", handler.restoreMedicineBrand)
+	route.POST("/house/:id/restore", handler.restoreMedicineHouse)
+}
+
+func (h *TrashHandler) getDeletedWarehouses(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	data, err := h.trashService.ListDeletedWarehouses(ctx)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, data)
+}
+
+func (h *TrashHandler) getWarehouseTrash(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	var req model.WarehouseTrashRequest
+	if err := c.Bind(&req); err != nil {
+		logger.Context(ctx).Error(err)
+		return c.JSON(http.StatusBadRequest, echo.Map{"error": err.Error()})
+	}
+	if err := h.validate.Struct(req); err != nil {
+		logger.Context(ctx).Error(err)
+		return c.JSON(http.StatusBadRequest, echo.Map{"error": err.Error()})
+	}
+
+	data, err := h.trashService.ListWarehouseTrash(ctx, req.WarehouseID)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, data)
+}
+
+func (h *TrashHandler) restoreWarehouse(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	var req model.WarehouseTrashRequest
+	if err := c.Bind(&req); err != nil {
+		logger.Context(ctx).Error(err)
+		return c.JSON(http.StatusBadRequest, echo.Map{"error": err.Error()})
+	}
+	if err := h.validate.Struct(req); err != nil {
+		logger.Context(ctx).Error(err)
+		return c.JSON(http.StatusBadRequest, echo.Map{"error": err.Error()})
+	}
+
+	if err := h.trashService.RestoreWarehouse(ctx, req.WarehouseID); err != nil {
+		return err
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+func (h *TrashHandler) restoreMedicine(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	var req model.RestoreMedicineRequest
+	if err := c.Bind(&req); err != nil {
+		logger.Context(ctx).Error(err)
+		return c.JSON(http.StatusBadRequest, echo.Map{"error": err.Error()})
+	}
+	if err := h.validate.Struct(req); err != nil {
+		logger.Context(ctx).Error(err)
+		return c.JSON(http.StatusBadRequest, echo.Map{"error": err.Error()})
+	}
+
+	if err := h.trashService.RestoreMedicine(ctx, req.MedicationID); err != nil {
+		return err
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+func (h *TrashHandler) restoreMedicineBrand(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	var req model.RestoreTrashItemRequest
+	if err := c.Bind(&req); err != nil {
+		logger.Context(ctx).Error(err)
+		return c.JSON(http.StatusBadRequest, echo.Map{"error": err.Error()})
+	}
+	if err := h.validate.Struct(req); err != nil {
+		logger.Context(ctx).Error(err)
+		return c.JSON(http.StatusBadRequest, echo.Map{"error": err.Error()})
+	}
+
+	if err := h.trashService.RestoreMedicineBrand(ctx, req.ID); err != nil {
+		return err
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+func (h *TrashHandler) restoreMedicineHouse(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	var req model.RestoreTrashItemRequest
+	if err := c.Bind(&req); err != nil {
+		logger.Context(ctx).Error(err)
+		return c.JSON(http.StatusBadRequest, echo.Map{"error": err.Error()})
+	}
+	if err := h.validate.Struct(req); err != nil {
+		logger.Context(ctx).Error(err)
+		return c.JSON(http.StatusBadRequest, echo.Map{"error": err.Error()})
+	}
+
+	if err := h.trashService.RestoreMedicineHouse(ctx, req.ID); err != nil {
+		return err
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
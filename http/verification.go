@@ -0,0 +1,71 @@
+package http
+
+import (
+	"net/http"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/kinkando/pharma-sheet-service/model"
+	"github.com/kinkando/pharma-sheet-service/pkg/logger"
+	"github.com/kinkando/pharma-sheet-service/service"
+	"github.com/labstack/echo/v4"
+)
+
+type VerificationHandler struct {
+	verificationService service.Verification
+	validate            *validator.Validate
+}
+
+func NewVerificationHandler(e *echo.Echo, validate *validator.Validate, verificationService service.Verification) {
+	handler := &VerificationHandler{
+		verificationService: verificationService,
+		validate:            validate,
+	}
+
+	route := e.Group("/verification")
+	route.GET("/pick", handler.getPickVerifications)
+	route.POST("/pick", handler.verifyPick)
+}
+
+func (h *VerificationHandler) getPickVerifications(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	var req model.FilterPickVerification
+	if err := c.Bind(&req); err != nil {
+		logger.Context(ctx).Error(err)
+		return c.JSON(http.StatusBadRequest, echo.Map{"error": err.Error()})
+	}
+	req.Pagination.AssignDefault()
+
+	if err := h.validate.Struct(req); err != nil {
+		logger.Context(ctx).Error(err)
+		return c.JSON(http.StatusBadRequest, echo.Map{"error": err.Error()})
+	}
+
+	data, err := h.verificationService.ListPickVerifications(ctx, req)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, data)
+}
+
+func (h *VerificationHandler) verifyPick(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	var req model.VerifyPickRequest
+	if err := c.Bind(&req); err != nil {
+		logger.Context(ctx).Error(err)
+		return c.JSON(http.StatusBadRequest, echo.Map{"error": err.Error()})
+	}
+	if err := h.validate.Struct(req); err != nil {
+		logger.Context(ctx).Error(err)
+		return c.JSON(http.StatusBadRequest, echo.Map{"error": err.Error()})
+	}
+
+	data, err := h.verificationService.VerifyPick(ctx, req)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, data)
+}
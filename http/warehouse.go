@@ -44,6 +44,13 @@ func NewWarehouseHandler(e *echo.Echo, validate *validator.Validate, warehouseSe
 	warehouseUserRoute.POST("/leave", handler.leaveWarehouse)
 	warehouseUserRoute.PUT("/:userID/:role", handler.updateWarehouseUser)
 	warehouseUserRoute.DELETE("/:userID", handler.deleteWarehouseUser)
+
+	warehouseLayoutRoute := route.Group("/:warehouseID/layout")
+	warehouseLayoutRoute.GET("", handler.getWarehouseLayout)
+	warehouseLayoutRoute.PUT("/locker", handler.upsertWarehouseLocker)
+	warehouseLayoutRoute.DELETE("/locker/:lockerID", handler.deleteWarehouseLocker)
+	warehouseLayoutRoute.POST("/relocate/preview", handler.previewRelocation)
+	warehouseLayoutRoute.POST("/relocate", handler.relocateMedicineHouses)
 }
 
 func (h *WarehouseHandler) getWarehouses(c echo.Context) error {
@@ -427,3 +434,113 @@ func (h *WarehouseHandler) deleteWarehouseUser(c echo.Context) error {
 
 	return c.NoContent(http.StatusNoContent)
 }
+
+func (h *WarehouseHandler) getWarehouseLayout(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	var req model.WarehouseRequest
+	if err := c.Bind(&req); err != nil {
+		logger.Context(ctx).Error(err)
+		return c.JSON(http.StatusBadRequest, echo.Map{"error": err.Error()})
+	}
+
+	if err := h.validate.Struct(req); err != nil {
+		logger.Context(ctx).Error(err)
+		return c.JSON(http.StatusBadRequest, echo.Map{"error": err.Error()})
+	}
+
+	layout, err := h.warehouseService.GetWarehouseLayout(ctx, req.WarehouseID)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, layout)
+}
+
+func (h *WarehouseHandler) upsertWarehouseLocker(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	var req model.UpsertWarehouseLockerRequest
+	if err := c.Bind(&req); err != nil {
+		logger.Context(ctx).Error(err)
+		return c.JSON(http.StatusBadRequest, echo.Map{"error": err.Error()})
+	}
+
+	if err := h.validate.Struct(req); err != nil {
+		logger.Context(ctx).Error(err)
+		return c.JSON(http.StatusBadRequest, echo.Map{"error": err.Error()})
+	}
+
+	id, err := h.warehouseService.UpsertWarehouseLocker(ctx, req)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, echo.Map{"id": id})
+}
+
+func (h *WarehouseHandler) deleteWarehouseLocker(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	var req model.DeleteWarehouseLockerRequest
+	if err := c.Bind(&req); err != nil {
+		logger.Context(ctx).Error(err)
+		return c.JSON(http.StatusBadRequest, echo.Map{"error": err.Error()})
+	}
+
+	if err := h.validate.Struct(req); err != nil {
+		logger.Context(ctx).Error(err)
+		return c.JSON(http.StatusBadRequest, echo.Map{"error": err.Error()})
+	}
+
+	err := h.warehouseService.DeleteWarehouseLocker(ctx, req)
+	if err != nil {
+		return err
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+func (h *WarehouseHandler) previewRelocation(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	var req model.RelocateMedicineHouseRequest
+	if err := c.Bind(&req); err != nil {
+		logger.Context(ctx).Error(err)
+		return c.JSON(http.StatusBadRequest, echo.Map{"error": err.Error()})
+	}
+
+	if err := h.validate.Struct(req); err != nil {
+		logger.Context(ctx).Error(err)
+		return c.JSON(http.StatusBadRequest, echo.Map{"error": err.Error()})
+	}
+
+	preview, err := h.warehouseService.PreviewRelocation(ctx, req)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, preview)
+}
+
+func (h *WarehouseHandler) relocateMedicineHouses(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	var req model.RelocateMedicineHouseRequest
+	if err := c.Bind(&req); err != nil {
+		logger.Context(ctx).Error(err)
+		return c.JSON(http.StatusBadRequest, echo.Map{"error": err.Error()})
+	}
+
+	if err := h.validate.Struct(req); err != nil {
+		logger.Context(ctx).Error(err)
+		return c.JSON(http.StatusBadRequest, echo.Map{"error": err.Error()})
+	}
+
+	preview, err := h.warehouseService.RelocateMedicineHouses(ctx, req)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, preview)
+}
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"log"
 	"time"
 	_ "time/tzdata"
@@ -8,12 +9,14 @@ import (
 	"github.com/go-playground/validator/v10"
 	"github.com/kinkando/pharma-sheet-service/config"
 	"github.com/kinkando/pharma-sheet-service/http"
+	"github.com/kinkando/pharma-sheet-service/pkg/circuitbreaker"
 	"github.com/kinkando/pharma-sheet-service/pkg/database/postgresql"
 	"github.com/kinkando/pharma-sheet-service/pkg/database/redis"
 	"github.com/kinkando/pharma-sheet-service/pkg/envconfig"
 	"github.com/kinkando/pharma-sheet-service/pkg/google"
 	httpmiddleware "github.com/kinkando/pharma-sheet-service/pkg/http/middleware"
 	httpserver "github.com/kinkando/pharma-sheet-service/pkg/http/server"
+	"github.com/kinkando/pharma-sheet-service/pkg/label"
 	"github.com/kinkando/pharma-sheet-service/pkg/logger"
 	"github.com/kinkando/pharma-sheet-service/pkg/option"
 	"github.com/kinkando/pharma-sheet-service/repository"
@@ -52,18 +55,36 @@ func main() {
 	)
 	defer postgresql.Shutdown(pgPool)
 
+	storageCircuitBreaker := circuitbreaker.New("storage", circuitbreaker.WithIsFailure(google.IsUnhealthyError))
+	driveCircuitBreaker := circuitbreaker.New("drive", circuitbreaker.WithIsFailure(google.IsUnhealthyError))
+	sheetCircuitBreaker := circuitbreaker.New("sheet", circuitbreaker.WithIsFailure(google.IsUnhealthyError), circuitbreaker.WithCallTimeout(cfg.Google.Retry.MaxElapsedTime))
+
 	cloudStorage := google.NewStorage([]byte(cfg.Google.FirebaseCredential), cfg.Google.Storage.BucketName, cfg.Google.Storage.ExpiredTime)
+	cloudStorage = google.NewCircuitBreakerStorage(cloudStorage, storageCircuitBreaker)
 	defer cloudStorage.Shutdown()
 
-	googleDrive := google.NewGoogleDrive([]byte(cfg.Google.FirebaseCredential), cfg.Google.DriveRootFolderID)
+	googleDrive := google.NewGoogleDrive(
+		[]byte(cfg.Google.FirebaseCredential),
+		cfg.Google.DriveRootFolderID,
+		option.WithHTTPInterceptorRetryMaxRetries(cfg.Google.Retry.MaxRetries),
+		option.WithHTTPInterceptorRetryMaxElapsedTime(cfg.Google.Retry.MaxElapsedTime),
+	)
+	googleDrive = google.NewCircuitBreakerDrive(googleDrive, driveCircuitBreaker)
 
 	sheet := google.NewSheet(
 		option.WithGoogleSheetClientCredentialJSON([]byte(cfg.Google.FirebaseCredential)),
 		option.WithGoogleSheetClientRateLimiter(ratelimit.New(60, ratelimit.Per(time.Minute))), // but 20 requests per minute is ensured
+		option.WithGoogleSheetClientRetry(
+			option.WithHTTPInterceptorRetryMaxRetries(cfg.Google.Retry.MaxRetries),
+			option.WithHTTPInterceptorRetryMaxElapsedTime(cfg.Google.Retry.MaxElapsedTime),
+		),
 	)
+	sheet = google.NewCircuitBreakerSheet(sheet, sheetCircuitBreaker)
 
 	firebaseAuthen := google.NewFirebaseAuthen([]byte(cfg.Google.FirebaseCredential))
 
+	labelPrinter := label.NewPrinter(cfg.App.LabelFontPath)
+
 	validate := validator.New()
 
 	httpServer := httpserver.New(
@@ -86,25 +107,56 @@ func main() {
 	)
 
 	userRepository := repository.NewUserRepository(pgPool)
-	cacheRepository := repository.NewCacheRepository(redisClient, cfg.App.AccessTokenExpired, cfg.App.RefreshTokenExpired)
+	cacheRepository := repository.NewCacheRepository(redisClient, cfg.App.AccessTokenExpired, cfg.App.RefreshTokenExpired, cfg.App.SuggestionCacheTTL, cfg.App.ConfirmationCodeTTL)
 	warehouseRepository := repository.NewWarehouseRepository(pgPool)
 	medicineRepository := repository.NewMedicineRepository(pgPool)
+	lotRepository := repository.NewLotRepository(pgPool)
+	stockRepository := repository.NewStockRepository(pgPool)
+	verificationRepository := repository.NewVerificationRepository(pgPool)
+	searchRepository := repository.NewSearchRepository(pgPool)
+	lasaRepository := repository.NewLASARepository(pgPool)
+	categoryRepository := repository.NewCategoryRepository(pgPool)
+	eventRepository := repository.NewEventRepository(redisClient)
+	trashRepository := repository.NewTrashRepository(pgPool)
+	auditRepository := repository.NewAuditRepository(pgPool)
 
 	jwtService := service.NewJWTService(cfg.App.JWTKey, cfg.App.AccessTokenExpired, cfg.App.RefreshTokenExpired)
 	authenService := service.NewAuthenService(userRepository, cacheRepository, jwtService, firebaseAuthen)
 	userService := service.NewUserService(userRepository, firebaseAuthen, cloudStorage)
-	warehouseService := service.NewWarehouseService(warehouseRepository, userRepository, medicineRepository, cloudStorage)
-	medicineService := service.NewMedicineService(medicineRepository, warehouseRepository, googleDrive)
-	sheetService := service.NewSheetService(warehouseRepository, medicineRepository, googleDrive, sheet)
+	warehouseService := service.NewWarehouseService(warehouseRepository, userRepository, medicineRepository, categoryRepository, auditRepository, cloudStorage)
+	medicineService := service.NewMedicineService(medicineRepository, warehouseRepository, lasaRepository, categoryRepository, cacheRepository, auditRepository, userRepository, trashRepository, googleDrive, labelPrinter, cfg.App.BlisterChangeIntervalDays)
+	lotService := service.NewLotService(lotRepository, medicineRepository, warehouseRepository, cacheRepository)
+	stockService := service.NewStockService(stockRepository, lotRepository, medicineRepository, warehouseRepository, eventRepository, cacheRepository)
+	verificationService := service.NewVerificationService(verificationRepository, medicineRepository, warehouseRepository)
+	searchService := service.NewSearchService(searchRepository, cacheRepository)
+	lasaService := service.NewLASAService(lasaRepository, medicineRepository, warehouseRepository, userRepository)
+	categoryService := service.NewCategoryService(categoryRepository, userRepository)
+	confirmationService := service.NewConfirmationService(cacheRepository, warehouseRepository)
+	trashService := service.NewTrashService(trashRepository, medicineRepository, warehouseRepository, googleDrive, cfg.App.TrashRetentionDays)
+	auditService := service.NewAuditService(auditRepository, warehouseRepository)
+	sheetService := service.NewSheetService(warehouseRepository, medicineRepository, categoryRepository, lotRepository, eventRepository, auditRepository, cacheRepository, googleDrive, sheet, cfg.App.BlisterChangeIntervalDays)
 
-	http.NewHealthzHandler(httpServer.Routers(), pgPool, redisClient)
+	http.NewHealthzHandler(httpServer.Routers(), pgPool, redisClient, storageCircuitBreaker, driveCircuitBreaker, sheetCircuitBreaker)
 	http.NewDriveHandler(httpServer.Routers(), validate, googleDrive)
 	http.NewAuthenHandler(httpServer.Routers(), validate, cfg.App.APIKey, authenService)
 	http.NewUserHandler(httpServer.Routers(), validate, userService)
 	http.NewWarehouseHandler(httpServer.Routers(), validate, warehouseService)
 	http.NewMedicineHandler(httpServer.Routers(), validate, medicineService)
+	http.NewLotHandler(httpServer.Routers(), validate, lotService)
+	http.NewStockHandler(httpServer.Routers(), validate, stockService)
+	http.NewVerificationHandler(httpServer.Routers(), validate, verificationService)
+	http.NewSearchHandler(httpServer.Routers(), validate, searchService)
+	http.NewLASAHandler(httpServer.Routers(), validate, lasaService)
+	http.NewCategoryHandler(httpServer.Routers(), validate, categoryService)
+	http.NewConfirmationHandler(httpServer.Routers(), validate, confirmationService)
+	http.NewTrashHandler(httpServer.Routers(), validate, trashService)
+	http.NewAuditHandler(httpServer.Routers(), validate, auditService)
 	http.NewSheetHandler(httpServer.Routers(), validate, sheetService)
 
+	purgeCtx, stopPurge := context.WithCancel(context.Background())
+	defer stopPurge()
+	go trashService.SchedulePurge(purgeCtx, cfg.App.TrashPurgeInterval)
+
 	httpServer.ListenAndServe()
 	httpServer.GracefulShutdown()
 }
@@ -0,0 +1,145 @@
+package model
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	genmodel "github.com/kinkando/pharma-sheet-service/.gen/pharma_sheet/public/model"
+)
+
+// MedicineStrengthUnits are the units a strength is written in, mL alone is the strength of a dose
+var MedicineStrengthUnits = []string{"mg", "g", "mcg", "ng", "IU", "mEq", "mmol", "mL", "%", "mg/mL", "mg/5mL", "mcg/mL", "IU/mL", "mg/g", "mcg/dose"}
+
+// MedicineAttribute is the structured master data telling apart medicines sharing the same name,
+// e.g. Paracetamol 500 mg tablet and Paracetamol 120 mg/5mL syrup.
+type MedicineAttribute struct {
+	Strength     *float64                           `json:"strength,omitempty"`
+	StrengthUnit *string                            `json:"strengthUnit,omitempty"`
+	DosageForm   *genmodel.PharmaSheetDosageForm    `json:"dosageForm,omitempty"`
+	Route        *genmodel.PharmaSheetMedicineRoute `json:"route,omitempty"`
+	PackageUnit  *string                            `json:"packageUnit,omitempty"`
+}
+
+// Equal compares the values of the attributes rather than their pointers
+func (m MedicineAttribute) Equal(attribute MedicineAttribute) bool {
+	return equalValue(m.Strength, attribute.Strength) &&
+		equalValue(m.StrengthUnit, attribute.StrengthUnit) &&
+		equalValue(m.DosageForm, attribute.DosageForm) &&
+		equalValue(m.Route, attribute.Route) &&
+		equalValue(m.PackageUnit, attribute.PackageUnit)
+}
+
+type FilterMedicineAttribute struct {
+	Strength     float64 `json:"-" query:"strength" validate:"omitempty,gt=0"`
+	StrengthUnit string  `json:"-" query:"strengthUnit"`
+	DosageForm   string  `json:"-" query:"dosageForm"`
+	Route        string  `json:"-" query:"route"`
+}
+
+// IsEmpty reports whether the filter has no attribute to filter by
+func (f FilterMedicineAttribute) IsEmpty() bool {
+	return f.Strength == 0 && f.StrengthUnit == "" && f.DosageForm == "" && f.Route == ""
+}
+
+// Normalize matches the case of the attributes to the known values so they can be compared with the stored ones
+func (f FilterMedicineAttribute) Normalize() (FilterMedicineAttribute, error) {
+	if f.StrengthUnit != "" {
+		strengthUnit, ok := normalizeStrengthUnit(f.StrengthUnit)
+		if !ok {
+			return f, fmt.Errorf("strengthUnit must be one of %s", strings.Join(MedicineStrengthUnits, ", "))
+		}
+		f.StrengthUnit = strengthUnit
+	}
+	if f.DosageForm != "" {
+		dosageForm, ok := normalizeDosageForm(f.DosageForm)
+		if !ok {
+			return f, fmt.Errorf("dosageForm %s is invalid", f.DosageForm)
+		}
+		f.DosageForm = dosageForm.String()
+	}
+	if f.Route != "" {
+		route, ok := normalizeRoute(f.Route)
+		if !ok {
+			return f, fmt.Errorf("route %s is invalid", f.Route)
+		}
+		f.Route = route.String()
+	}
+	return f, nil
+}
+
+// NormalizeMedicineAttribute trims the attributes and matches their case to the known values,
+// a strength always comes with its unit.
+func NormalizeMedicineAttribute(attribute MedicineAttribute) (MedicineAttribute, error) {
+	attribute.StrengthUnit = trimValue(attribute.StrengthUnit)
+	attribute.PackageUnit = trimValue(attribute.PackageUnit)
+
+	if attribute.Strength != nil && *attribute.Strength <= 0 {
+		return attribute, fmt.Errorf("strength must be greater than 0")
+	}
+	if (attribute.Strength == nil) != (attribute.StrengthUnit == nil) {
+		return attribute, fmt.Errorf("strength and strengthUnit must be given together")
+	}
+	if attribute.StrengthUnit != nil {
+		strengthUnit, ok := normalizeStrengthUnit(*attribute.StrengthUnit)
+		if !ok {
+			return attribute, fmt.Errorf("strengthUnit must be one of %s", strings.Join(MedicineStrengthUnits, ", "))
+		}
+		attribute.StrengthUnit = &strengthUnit
+	}
+
+	if attribute.DosageForm != nil {
+		dosageForm, ok := normalizeDosageForm(attribute.DosageForm.String())
+		if !ok {
+			return attribute, fmt.Errorf("dosageForm %s is invalid", attribute.DosageForm.String())
+		}
+		attribute.DosageForm = &dosageForm
+	}
+
+	if attribute.Route != nil {
+		route, ok := normalizeRoute(attribute.Route.String())
+		if !ok {
+			return attribute, fmt.Errorf("route %s is invalid", attribute.Route.String())
+		}
+		attribute.Route = &route
+	}
+
+	if attribute.PackageUnit != nil && len([]rune(*attribute.PackageUnit)) > 32 {
+		return attribute, fmt.Errorf("packageUnit must not be longer than 32 characters")
+	}
+
+	return attribute, nil
+}
+
+func normalizeStrengthUnit(strengthUnit string) (string, bool) {
+	index := slices.IndexFunc(MedicineStrengthUnits, func(unit string) bool { return strings.EqualFold(unit, strings.TrimSpace(strengthUnit)) })
+	if index < 0 {
+		return "", false
+	}
+	return MedicineStrengthUnits[index], true
+}
+
+func normalizeDosageForm(dosageForm string) (genmodel.PharmaSheetDosageForm, bool) {
+	value := genmodel.PharmaSheetDosageForm(strings.ToUpper(strings.TrimSpace(dosageForm)))
+	return value, slices.Contains(genmodel.PharmaSheetDosageFormAllValues, value)
+}
+
+func normalizeRoute(route string) (genmodel.PharmaSheetMedicineRoute, bool) {
+	value := genmodel.PharmaSheetMedicineRoute(strings.ToUpper(strings.TrimSpace(route)))
+	return value, slices.Contains(genmodel.PharmaSheetMedicineRouteAllValues, value)
+}
+
+func trimValue(value *string) *string {
+	if value == nil || strings.TrimSpace(*value) == "" {
+		return nil
+	}
+	trimmed := strings.TrimSpace(*value)
+	return &trimmed
+}
+
+func equalValue[T comparable](a, b *T) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
@@ -0,0 +1,62 @@
+package model
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+	genmodel "github.com/kinkando/pharma-sheet-service/.gen/pharma_sheet/public/model"
+)
+
+type AuditEntityType string
+
+const (
+	AuditEntityWarehouse             AuditEntityType = "WAREHOUSE"
+	AuditEntityWarehouseUser         AuditEntityType = "WAREHOUSE_USER"
+	AuditEntityWarehouseLocker       AuditEntityType = "WAREHOUSE_LOCKER"
+	AuditEntityMedicine              AuditEntityType = "MEDICINE"
+	AuditEntityMedicineBrand         AuditEntityType = "MEDICINE_BRAND"
+	AuditEntityMedicineHouse         AuditEntityType = "MEDICINE_HOUSE"
+	AuditEntityMedicineLot           AuditEntityType = "MEDICINE_LOT"
+	AuditEntityBlisterDateHistory    AuditEntityType = "BLISTER_DATE_HISTORY"
+	AuditEntityBlisterChangeInterval AuditEntityType = "BLISTER_CHANGE_INTERVAL"
+)
+
+// AuditLog records a mutation of an entity, Before is empty for a creation and After is empty for a deletion
+type AuditLog struct {
+	ID          uuid.UUID                       `json:"id"`
+	UserID      *uuid.UUID                      `json:"userID,omitempty"`
+	Action      genmodel.PharmaSheetAuditAction `json:"action"`
+	EntityType  AuditEntityType                 `json:"entityType"`
+	EntityID    string                          `json:"entityID"`
+	WarehouseID *string                         `json:"warehouseID,omitempty"`
+	Before      json.RawMessage                 `json:"before,omitempty"`
+	After       json.RawMessage                 `json:"after,omitempty"`
+	CreatedAt   time.Time                       `json:"createdAt"`
+
+	// JOIN ONLY
+	DisplayName *string `json:"displayName,omitempty"`
+	Email       *string `json:"email,omitempty"`
+}
+
+// CreateAuditLog is a mutation to record, Before and After are stored as JSON
+type CreateAuditLog struct {
+	Action      genmodel.PharmaSheetAuditAction
+	EntityType  AuditEntityType
+	EntityID    string
+	WarehouseID *string
+	Before      any
+	After       any
+}
+
+type FilterAuditLog struct {
+	Pagination
+	EntityType  AuditEntityType                 `json:"-" query:"entityType" param:"entityType"`
+	EntityID    string                          `json:"-" query:"entityID" param:"entityID"`
+	WarehouseID string                          `json:"-" query:"warehouseID" param:"warehouseID"`
+	UserID      string                          `json:"-" query:"userID" param:"userID" validate:"omitempty,uuid"`
+	Action      genmodel.PharmaSheetAuditAction `json:"-" query:"action" validate:"omitempty,oneof=CREATE UPDATE DELETE"`
+
+	// ViewerID limits the logs to the ones the viewer may see, it is never bound from the request
+	ViewerID string `json:"-"`
+}
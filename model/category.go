@@ -0,0 +1,176 @@
+package model
+
+import (
+	"cmp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type Category struct {
+	ID            uuid.UUID  `json:"id"`
+	Code          string     `json:"code"`
+	Name          string     `json:"name"`
+	ParentID      *uuid.UUID `json:"parentID,omitempty"`
+	CreatedAt     time.Time  `json:"createdAt"`
+	UpdatedAt     time.Time  `json:"updatedAt"`
+	TotalMedicine uint64     `json:"totalMedicine"`
+	Children      []Category `json:"children,omitempty"`
+}
+
+type MedicineCategory struct {
+	MedicationID string    `json:"-"`
+	ID           uuid.UUID `json:"id"`
+	Code         string    `json:"code"`
+	Name         string    `json:"name"`
+}
+
+// CategoryCount is the number of medicines of a category and its sub categories
+type CategoryCount struct {
+	ID            uuid.UUID `json:"id"`
+	Code          string    `json:"code"`
+	Name          string    `json:"name"`
+	TotalMedicine uint64    `json:"totalMedicine"`
+}
+
+type Tag struct {
+	Tag           string `json:"tag"`
+	TotalMedicine uint64 `json:"totalMedicine"`
+}
+
+type FilterMedicineCategory struct {
+	CategoryID  string      `json:"-" query:"categoryID" validate:"omitempty,uuid"`
+	Tag         string      `json:"-" query:"tag"`
+	CategoryIDs []uuid.UUID `json:"-"`
+}
+
+type FilterCategoryMedicine struct {
+	MedicationID string
+	WarehouseID  string
+}
+
+type FilterTag struct {
+	Search string `query:"search"`
+}
+
+type CreateCategoryRequest struct {
+	Code     string     `json:"code" validate:"required,max=16"`
+	Name     string     `json:"name" validate:"required,max=128"`
+	ParentID *uuid.UUID `json:"parentID" validate:"omitempty,uuid"`
+}
+
+type UpdateCategoryRequest struct {
+	ID       uuid.UUID  `param:"id" validate:"required,uuid"`
+	Code     string     `json:"code" validate:"required,max=16"`
+	Name     string     `json:"name" validate:"required,max=128"`
+	ParentID *uuid.UUID `json:"parentID" validate:"omitempty,uuid"`
+}
+
+type DeleteCategoryRequest struct {
+	ID uuid.UUID `param:"id" validate:"required,uuid"`
+}
+
+type UpdateMedicineCategoryRequest struct {
+	MedicationID string      `param:"medicationID" validate:"required"`
+	CategoryIDs  []uuid.UUID `json:"categoryIDs" validate:"dive,uuid"`
+}
+
+type UpdateMedicineTagRequest struct {
+	MedicationID string   `param:"medicationID" validate:"required"`
+	Tags         []string `json:"tags" validate:"dive,required,max=32"`
+}
+
+// NormalizeCategoryCode keeps a code in upper case like the ATC codes, e.g. J01CA04
+func NormalizeCategoryCode(code string) string {
+	return strings.ToUpper(strings.TrimSpace(code))
+}
+
+// NormalizeTags trims the tags in lower case and drops the duplicated ones
+func NormalizeTags(tags []string) []string {
+	normalized := []string{}
+	for _, tag := range tags {
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		if tag != "" && !slices.Contains(normalized, tag) {
+			normalized = append(normalized, tag)
+		}
+	}
+	return normalized
+}
+
+// CategoryTree nests every category under its parent, the categories of each level are sorted by their code
+func CategoryTree(categories []Category) []Category {
+	children := make(map[uuid.UUID][]Category)
+	var roots []Category
+	for _, category := range categories {
+		if category.ParentID == nil {
+			roots = append(roots, category)
+			continue
+		}
+		children[*category.ParentID] = append(children[*category.ParentID], category)
+	}
+
+	var nest func(categories []Category) []Category
+	nest = func(categories []Category) []Category {
+		slices.SortFunc(categories, func(a, b Category) int { return cmp.Compare(a.Code, b.Code) })
+		for index := range categories {
+			categories[index].Children = nest(children[categories[index].ID])
+		}
+		return categories
+	}
+
+	tree := nest(roots)
+	if tree == nil {
+		return []Category{}
+	}
+	return tree
+}
+
+// CategoryDescendants returns the category followed by every category below it
+func CategoryDescendants(categories []Category, id uuid.UUID) []uuid.UUID {
+	descendants := []uuid.UUID{id}
+	for index := 0; index < len(descendants); index++ {
+		for _, category := range categories {
+			if category.ParentID != nil && *category.ParentID == descendants[index] && !slices.Contains(descendants, category.ID) {
+				descendants = append(descendants, category.ID)
+			}
+		}
+	}
+	return descendants
+}
+
+// CountCategoryMedicines counts the distinct medicines of each category, a medicine of a sub category is counted by its ancestors too.
+// Categories without a medicine are left out.
+func CountCategoryMedicines(categories []Category, medicineCategories []MedicineCategory) []CategoryCount {
+	parents := make(map[uuid.UUID]*uuid.UUID, len(categories))
+	for _, category := range categories {
+		parents[category.ID] = category.ParentID
+	}
+
+	medicationIDs := make(map[uuid.UUID]map[string]bool)
+	for _, medicineCategory := range medicineCategories {
+		id := &medicineCategory.ID
+		for depth := 0; id != nil && depth < len(categories); depth++ {
+			if medicationIDs[*id] == nil {
+				medicationIDs[*id] = make(map[string]bool)
+			}
+			medicationIDs[*id][medicineCategory.MedicationID] = true
+			id = parents[*id]
+		}
+	}
+
+	counts := []CategoryCount{}
+	for _, category := range categories {
+		if total := len(medicationIDs[category.ID]); total > 0 {
+			counts = append(counts, CategoryCount{
+				ID:            category.ID,
+				Code:          category.Code,
+				Name:          category.Name,
+				TotalMedicine: uint64(total),
+			})
+		}
+	}
+	slices.SortFunc(counts, func(a, b CategoryCount) int { return cmp.Compare(a.Code, b.Code) })
+	return counts
+}
@@ -0,0 +1,63 @@
+package model
+
+import "time"
+
+// MedicineFlag marks a medicine needing extra care, only an admin can change the houses of a flagged medicine
+// and its movements and blister changes must be confirmed by a second user.
+type MedicineFlag struct {
+	IsHighAlert    bool `json:"isHighAlert,omitempty"`
+	IsControlled   bool `json:"isControlled,omitempty"`
+	IsRefrigerated bool `json:"isRefrigerated,omitempty"`
+}
+
+func (m MedicineFlag) IsFlagged() bool {
+	return m.IsHighAlert || m.IsControlled || m.IsRefrigerated
+}
+
+// Warnings are printed on the labels of the houses of a flagged medicine
+func (m MedicineFlag) Warnings() []string {
+	var warnings []string
+	if m.IsHighAlert {
+		warnings = append(warnings, "HIGH ALERT")
+	}
+	if m.IsControlled {
+		warnings = append(warnings, "CONTROLLED")
+	}
+	if m.IsRefrigerated {
+		warnings = append(warnings, "REFRIGERATE")
+	}
+	return warnings
+}
+
+type UpdateMedicineFlagRequest struct {
+	MedicationID string `param:"medicationID" validate:"required"`
+	MedicineFlag
+}
+
+type ConfirmationAction string
+
+const (
+	ConfirmationActionStockMovement ConfirmationAction = "STOCK_MOVEMENT"
+	ConfirmationActionHouseTransfer ConfirmationAction = "HOUSE_TRANSFER"
+	ConfirmationActionBlisterChange ConfirmationAction = "BLISTER_CHANGE"
+	ConfirmationActionLotChange     ConfirmationAction = "LOT_CHANGE"
+	ConfirmationActionSheetSync     ConfirmationAction = "SHEET_SYNC"
+)
+
+// ConfirmationScope binds a confirmation code to the single change it confirms, a sheet sync covers the whole warehouse
+type ConfirmationScope struct {
+	WarehouseID  string             `json:"warehouseID" validate:"required"`
+	MedicationID string             `json:"medicationID,omitempty" validate:"required_unless=Action SHEET_SYNC"`
+	Action       ConfirmationAction `json:"action" validate:"required,oneof=STOCK_MOVEMENT HOUSE_TRANSFER BLISTER_CHANGE LOT_CHANGE SHEET_SYNC"`
+}
+
+type CreateConfirmationRequest struct {
+	ConfirmationScope
+}
+
+// Confirmation is a one-time code a second user hands over to confirm a change of a flagged medicine
+type Confirmation struct {
+	ConfirmationScope
+	Code      string    `json:"code"`
+	ExpiredAt time.Time `json:"expiredAt"`
+}
@@ -0,0 +1,201 @@
+package model
+
+import (
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/google/uuid"
+)
+
+const (
+	lasaSpellingScore = 0.6
+	lasaSoundScore    = 0.4
+	lasaMinNameLength = 3
+)
+
+type LASAReason string
+
+const (
+	LASAReasonList     LASAReason = "LIST"
+	LASAReasonSpelling LASAReason = "SPELLING"
+	LASAReasonSound    LASAReason = "SOUND"
+)
+
+type LASAPair struct {
+	ID                  uuid.UUID  `json:"id"`
+	MedicationID        string     `json:"medicationID"`
+	SimilarMedicationID string     `json:"similarMedicationID"`
+	Note                *string    `json:"note,omitempty"`
+	CreatedBy           *uuid.UUID `json:"createdBy,omitempty"`
+	CreatedAt           time.Time  `json:"createdAt"`
+
+	// JOIN ONLY
+	MedicalName        *string `json:"medicalName,omitempty"`
+	SimilarMedicalName *string `json:"similarMedicalName,omitempty"`
+	CreatedName        *string `json:"createdName,omitempty"`
+}
+
+// LASAMatch is the most similar names of two medicines, a pair on the LASA list always matches
+type LASAMatch struct {
+	MedicationID        string     `json:"medicationID"`
+	SimilarMedicationID string     `json:"similarMedicationID"`
+	Name                string     `json:"name"`
+	SimilarName         string     `json:"similarName"`
+	Reason              LASAReason `json:"reason"`
+	Score               float64    `json:"score"`
+}
+
+// LASAWarning is a neighbour house keeping a medicine that looks or sounds alike the one being placed
+type LASAWarning struct {
+	LASAMatch
+	HouseID uuid.UUID `json:"houseID"`
+	Address string    `json:"address"`
+}
+
+type LASAProximity struct {
+	LASAMatch
+	HouseID        uuid.UUID `json:"houseID"`
+	Address        string    `json:"address"`
+	SimilarHouseID uuid.UUID `json:"similarHouseID"`
+	SimilarAddress string    `json:"similarAddress"`
+}
+
+type FilterLASAPair struct {
+	Pagination
+	MedicationID string `json:"-" query:"medicationID"`
+}
+
+type FilterLASAProximity struct {
+	WarehouseID string `param:"warehouseID" validate:"required"`
+}
+
+type CreateLASAPairRequest struct {
+	MedicationID        string  `json:"medicationID" validate:"required"`
+	SimilarMedicationID string  `json:"similarMedicationID" validate:"required,nefield=MedicationID"`
+	Note                *string `json:"note"`
+}
+
+type DeleteLASAPairRequest struct {
+	ID uuid.UUID `param:"id" validate:"required,uuid"`
+}
+
+// IsAdjacent reports whether two houses share a slot, or sit next to each other on a floor or between floors of the same locker
+func (m MedicineHouse) IsAdjacent(house MedicineHouse) bool {
+	if m.WarehouseID != house.WarehouseID || !strings.EqualFold(m.Locker, house.Locker) {
+		return false
+	}
+	floor, no := abs(m.Floor-house.Floor), abs(m.No-house.No)
+	return floor+no <= 1
+}
+
+// MatchLASANames compares every name of a medicine with every name of another one and keeps the most similar
+func MatchLASANames(names, similarNames []string) (LASAMatch, bool) {
+	var match LASAMatch
+	found := false
+	for _, name := range names {
+		for _, similarName := range similarNames {
+			reason, score, ok := CompareLASAName(name, similarName)
+			if ok && score > match.Score {
+				match = LASAMatch{Name: name, SimilarName: similarName, Reason: reason, Score: score}
+				found = true
+			}
+		}
+	}
+	return match, found
+}
+
+// CompareLASAName scores the spelling of two names by their edit distance, names spelt apart still match
+// when they share a phonetic key and are not too far apart.
+func CompareLASAName(name, similarName string) (LASAReason, float64, bool) {
+	a, b := normalizeLASAName(name), normalizeLASAName(similarName)
+	if len(a) < lasaMinNameLength || len(b) < lasaMinNameLength {
+		return "", 0, false
+	}
+
+	score := 1 - float64(levenshtein(a, b))/float64(max(len(a), len(b)))
+	if score >= lasaSpellingScore {
+		return LASAReasonSpelling, score, true
+	}
+	if key := soundex(a); key != "" && key == soundex(b) && score >= lasaSoundScore {
+		return LASAReasonSound, score, true
+	}
+	return "", 0, false
+}
+
+func normalizeLASAName(name string) []rune {
+	var runes []rune
+	for _, char := range strings.ToLower(name) {
+		if unicode.IsLetter(char) || unicode.IsDigit(char) {
+			runes = append(runes, char)
+		}
+	}
+	return runes
+}
+
+func levenshtein(a, b []rune) int {
+	previous := make([]int, len(b)+1)
+	current := make([]int, len(b)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		current[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			current[j] = min(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+		previous, current = current, previous
+	}
+	return previous[len(b)]
+}
+
+// soundex returns the american soundex key of the latin letters of a name, it is empty when there is none
+func soundex(name []rune) string {
+	codes := map[rune]byte{
+		'b': '1', 'f': '1', 'p': '1', 'v': '1',
+		'c': '2', 'g': '2', 'j': '2', 'k': '2', 'q': '2', 's': '2', 'x': '2', 'z': '2',
+		'd': '3', 't': '3',
+		'l': '4',
+		'm': '5', 'n': '5',
+		'r': '6',
+	}
+
+	key := make([]byte, 0, 4)
+	var previous byte
+	for _, char := range name {
+		if char < 'a' || char > 'z' {
+			previous = 0
+			continue
+		}
+		code := codes[char]
+		if len(key) == 0 {
+			key = append(key, byte(unicode.ToUpper(char)))
+		} else if code != 0 && code != previous {
+			key = append(key, code)
+		}
+		if char != 'h' && char != 'w' {
+			previous = code
+		}
+		if len(key) == 4 {
+			break
+		}
+	}
+	if len(key) == 0 {
+		return ""
+	}
+	for len(key) < 4 {
+		key = append(key, '0')
+	}
+	return string(key)
+}
+
+func abs(n int32) int32 {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
@@ -0,0 +1,132 @@
+package model
+
+import (
+	"fmt"
+
+	"github.com/google/uuid"
+)
+
+type WarehouseLocker struct {
+	ID            uuid.UUID `json:"id"`
+	WarehouseID   string    `json:"warehouseID"`
+	Name          string    `json:"name"`
+	Floors        int32     `json:"floors"`
+	SlotsPerFloor int32     `json:"slotsPerFloor"`
+}
+
+func (m WarehouseLocker) Contains(floor, no int32) bool {
+	return floor >= 1 && floor <= m.Floors && no >= 1 && no <= m.SlotsPerFloor
+}
+
+func (m WarehouseLocker) TotalSlots() int {
+	return int(m.Floors) * int(m.SlotsPerFloor)
+}
+
+// CheckHouseAddress validates an address against the layout of its warehouse, a warehouse without any locker has no layout to check
+func CheckHouseAddress(lockers []WarehouseLocker, locker string, floor, no int32) error {
+	if len(lockers) == 0 {
+		return nil
+	}
+	for _, warehouseLocker := range lockers {
+		if warehouseLocker.Name != locker {
+			continue
+		}
+		if !warehouseLocker.Contains(floor, no) {
+			return fmt.Errorf("locker %s has %d floors with %d slots per floor, floor %d no %d is out of range", locker, warehouseLocker.Floors, warehouseLocker.SlotsPerFloor, floor, no)
+		}
+		return nil
+	}
+	return fmt.Errorf("locker %s is not in the layout of the warehouse", locker)
+}
+
+type WarehouseLayout struct {
+	WarehouseID    string            `json:"warehouseID"`
+	TotalSlots     int               `json:"totalSlots"`
+	FreeSlots      int               `json:"freeSlots"`
+	Lockers        []LockerOccupancy `json:"lockers"`
+	UnplacedHouses []LayoutHouse     `json:"unplacedHouses"`
+}
+
+type LockerOccupancy struct {
+	WarehouseLocker
+	FreeSlots int              `json:"freeSlots"`
+	Floors    []FloorOccupancy `json:"floors"`
+}
+
+type FloorOccupancy struct {
+	Floor int32           `json:"floor"`
+	Slots []SlotOccupancy `json:"slots"`
+}
+
+type SlotOccupancy struct {
+	No     int32         `json:"no"`
+	IsFree bool          `json:"isFree"`
+	Houses []LayoutHouse `json:"houses"`
+}
+
+type LayoutHouse struct {
+	ID           uuid.UUID `json:"id"`
+	MedicationID string    `json:"medicationID"`
+	Locker       string    `json:"locker"`
+	Floor        int32     `json:"floor"`
+	No           int32     `json:"no"`
+	Label        *string   `json:"label,omitempty"`
+}
+
+type UpsertWarehouseLockerRequest struct {
+	WarehouseID   string `param:"warehouseID" validate:"required"`
+	Name          string `json:"name" validate:"required"`
+	Floors        int32  `json:"floors" validate:"required,min=1"`
+	SlotsPerFloor int32  `json:"slotsPerFloor" validate:"required,min=1"`
+}
+
+type DeleteWarehouseLockerRequest struct {
+	WarehouseID string    `param:"warehouseID" validate:"required"`
+	LockerID    uuid.UUID `param:"lockerID" validate:"required,uuid"`
+}
+
+// RelocationRule remaps the houses of a locker, or of a single floor of it, to another locker and/or shifts their floor and slot
+type RelocationRule struct {
+	Locker     string  `json:"locker" validate:"required"`
+	Floor      *int32  `json:"floor" validate:"omitempty,min=1"`
+	ToLocker   *string `json:"toLocker"`
+	FloorShift int32   `json:"floorShift"`
+	NoShift    int32   `json:"noShift"`
+}
+
+func (m RelocationRule) Match(house MedicineHouse) bool {
+	return house.Locker == m.Locker && (m.Floor == nil || *m.Floor == house.Floor)
+}
+
+func (m RelocationRule) Apply(house MedicineHouse) MedicineHouse {
+	if m.ToLocker != nil {
+		house.Locker = *m.ToLocker
+	}
+	house.Floor += m.FloorShift
+	house.No += m.NoShift
+	return house
+}
+
+type RelocateMedicineHouseRequest struct {
+	WarehouseID string           `param:"warehouseID" validate:"required"`
+	Rules       []RelocationRule `json:"rules" validate:"required,min=1,dive"`
+}
+
+type HouseRelocation struct {
+	HouseID      uuid.UUID `json:"houseID"`
+	MedicationID string    `json:"medicationID"`
+	FromLocker   string    `json:"fromLocker"`
+	FromFloor    int32     `json:"fromFloor"`
+	FromNo       int32     `json:"fromNo"`
+	ToLocker     string    `json:"toLocker"`
+	ToFloor      int32     `json:"toFloor"`
+	ToNo         int32     `json:"toNo"`
+	Conflict     *string   `json:"conflict,omitempty"`
+}
+
+type RelocationPreview struct {
+	WarehouseID    string            `json:"warehouseID"`
+	TotalHouses    int               `json:"totalHouses"`
+	TotalConflicts int               `json:"totalConflicts"`
+	Relocations    []HouseRelocation `json:"relocations"`
+}
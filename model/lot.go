@@ -0,0 +1,105 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type MedicineLotStatus string
+
+const (
+	MedicineLotStatusExpired  MedicineLotStatus = "EXPIRED"
+	MedicineLotStatusExpiring MedicineLotStatus = "EXPIRING"
+)
+
+type MedicineLot struct {
+	ID           uuid.UUID  `json:"id"`
+	HouseID      uuid.UUID  `json:"houseID"`
+	BrandID      *uuid.UUID `json:"brandID,omitempty"`
+	LotNumber    string     `json:"lotNumber"`
+	ExpiryDate   time.Time  `json:"expiryDate"`
+	Quantity     int32      `json:"quantity"`
+	ReceivedDate *time.Time `json:"receivedDate,omitempty"`
+
+	// JOIN ONLY
+	WarehouseID  string  `json:"warehouseID,omitempty"`
+	MedicationID string  `json:"medicationID,omitempty"`
+	MedicalName  *string `json:"medicalName,omitempty"`
+	Locker       string  `json:"locker,omitempty"`
+	Floor        int32   `json:"floor,omitempty"`
+	No           int32   `json:"no,omitempty"`
+	TradeID      *string `json:"tradeID,omitempty"`
+	TradeName    *string `json:"tradeName,omitempty"`
+}
+
+func (m MedicineLot) ExternalID() string {
+	id := m.HouseID.String()
+	if m.BrandID != nil {
+		id += "-" + m.BrandID.String()
+	}
+	return id + "-" + m.LotNumber
+}
+
+type MedicineLotExpiry struct {
+	MedicineLot
+	DaysUntilExpiry int               `json:"daysUntilExpiry"`
+	Status          MedicineLotStatus `json:"status"`
+}
+
+type FilterMedicineLot struct {
+	Pagination
+	WarehouseID  string     `json:"-" query:"warehouseID" validate:"required"`
+	HouseID      *uuid.UUID `json:"-" query:"houseID" validate:"omitempty,uuid"`
+	MedicationID string     `json:"-" query:"medicationID"`
+}
+
+type FilterExpiringMedicineLot struct {
+	WarehouseID string        `query:"warehouseID" validate:"required"`
+	Within      string        `query:"within"`
+	Duration    time.Duration `query:"-"`
+}
+
+type ListMedicineLot struct {
+	WarehouseID  string
+	HouseID      uuid.UUID
+	ExpiryBefore *time.Time
+	IsInStock    bool
+}
+
+type GetMedicineLotRequest struct {
+	ID uuid.UUID `param:"id" validate:"required,uuid"`
+}
+
+type CreateMedicineLotRequest struct {
+	HouseID      uuid.UUID  `json:"houseID" validate:"required,uuid"`
+	BrandID      *uuid.UUID `json:"brandID" validate:"omitempty,uuid"`
+	LotNumber    string     `json:"lotNumber" validate:"required"`
+	Expiry       string     `json:"expiryDate" validate:"required"`
+	Received     *string    `json:"receivedDate"`
+	Quantity     int32      `json:"quantity" validate:"min=0"`
+	ExpiryDate   time.Time  `json:"-"`
+	ReceivedDate *time.Time `json:"-"`
+	// ConfirmationCode is required for a flagged medicine
+	ConfirmationCode string `json:"confirmationCode"`
+}
+
+// UpdateMedicineLotRequest carries the counted quantity, its difference to the lot is posted to the ledger as an ADJUST movement
+type UpdateMedicineLotRequest struct {
+	ID           uuid.UUID  `param:"id" validate:"required,uuid"`
+	BrandID      *uuid.UUID `json:"brandID" validate:"omitempty,uuid"`
+	LotNumber    string     `json:"lotNumber" validate:"required"`
+	Expiry       string     `json:"expiryDate" validate:"required"`
+	Received     *string    `json:"receivedDate"`
+	Quantity     int32      `json:"quantity" validate:"min=0"`
+	ExpiryDate   time.Time  `json:"-"`
+	ReceivedDate *time.Time `json:"-"`
+	// ConfirmationCode is required for a flagged medicine
+	ConfirmationCode string `json:"confirmationCode"`
+}
+
+type DeleteMedicineLotRequest struct {
+	ID uuid.UUID `param:"id" validate:"required,uuid"`
+	// ConfirmationCode is required for a flagged medicine
+	ConfirmationCode string `query:"confirmationCode"`
+}
@@ -3,6 +3,7 @@ package model
 import (
 	"fmt"
 	"mime/multipart"
+	"slices"
 	"time"
 
 	"github.com/google/uuid"
@@ -14,6 +15,11 @@ type Medicine struct {
 	Brands               []MedicineBrand                  `json:"brands,omitempty"`
 	Houses               []MedicineHouseView              `json:"houses,omitempty"`
 	BlisterDateHistories []MedicineBlisterDateHistoryView `json:"blisterDateHistories,omitempty"`
+	Categories           []MedicineCategory               `json:"categories,omitempty"`
+	Tags                 []string                         `json:"tags,omitempty"`
+
+	MedicineAttribute
+	MedicineFlag
 }
 
 type MedicineBrand struct {
@@ -24,6 +30,7 @@ type MedicineBrand struct {
 	BlisterImageURL *string   `json:"blisterImageURL,omitempty"`
 	TabletImageURL  *string   `json:"tabletImageURL,omitempty"`
 	BoxImageURL     *string   `json:"boxImageURL,omitempty"`
+	Barcode         *string   `json:"barcode,omitempty"`
 
 	// JOIN ONLY
 	MedicalName            *string `json:"medicalName,omitempty"`
@@ -130,6 +137,7 @@ type MedicineBrandView struct {
 	BlisterImageURL *string                            `json:"blisterImageURL,omitempty"`
 	TabletImageURL  *string                            `json:"tabletImageURL,omitempty"`
 	BoxImageURL     *string                            `json:"boxImageURL,omitempty"`
+	Barcode         *string                            `json:"barcode,omitempty"`
 	BlisterDates    []MedicineBrandViewWithBlisterDate `json:"blisterDates,omitempty"`
 }
 
@@ -141,9 +149,29 @@ type MedicineBrandViewWithBlisterDate struct {
 
 type FilterMedicine struct {
 	Pagination
+	FilterMedicineAttribute
+	FilterMedicineCategory
 	WarehouseID string `json:"-" query:"warehouseID"`
 }
 
+type FilterMedicineMaster struct {
+	Pagination
+	FilterMedicineAttribute
+}
+
+// MedicineSearchResult is a medicine ranked by the best score of its matched fields
+type MedicineSearchResult struct {
+	Medicine
+	Score      float64                   `json:"score"`
+	Highlights []MedicineSearchHighlight `json:"highlights"`
+}
+
+type MedicineSearchHighlight struct {
+	Field string  `json:"field"`
+	Value string  `json:"value"`
+	Score float64 `json:"score"`
+}
+
 type FilterMedicineBlisterDateHistory struct {
 	Pagination
 	WarehouseID string `json:"-" query:"warehouseID"`
@@ -155,6 +183,7 @@ type ListMedicine struct {
 
 type ListMedicineHouse struct {
 	Pagination
+	FilterMedicineCategory
 	WarehouseID string `json:"-" query:"warehouseID" validate:"required"`
 }
 
@@ -168,20 +197,25 @@ type FilterMedicineBrand struct {
 	MedicationID string
 	WarehouseID  string
 	BrandID      uuid.UUID
+	TradeID      string
+	Barcode      string
 }
 
 type FilterMedicineWithBrand struct {
 	Pagination
+	FilterMedicineCategory
 }
 
 type CreateMedicineRequest struct {
 	MedicationID string  `param:"medicationID" validate:"required"`
 	MedicalName  *string `json:"medicalName,omitempty"`
+	MedicineAttribute
 }
 
 type UpdateMedicineRequest struct {
 	MedicationID string  `param:"medicationID" validate:"required"`
 	MedicalName  *string `json:"medicalName,omitempty"`
+	MedicineAttribute
 }
 
 type DeleteMedicineFilter struct {
@@ -189,6 +223,63 @@ type DeleteMedicineFilter struct {
 	WarehouseID  string
 }
 
+type DeleteMedicineRequest struct {
+	MedicationID string `param:"medicationID" validate:"required"`
+	Force        bool   `query:"force"`
+	Token        string `query:"token"`
+}
+
+// MedicineDeleteImpact counts what a forced delete of a medicine removes, the trashed rows included
+type MedicineDeleteImpact struct {
+	MedicationID   string                  `json:"medicationID"`
+	Brands         int64                   `json:"brands"`
+	Houses         int64                   `json:"houses"`
+	Histories      int64                   `json:"histories"`
+	Images         int64                   `json:"images"`
+	Lots           int64                   `json:"lots"`
+	StockMovements int64                   `json:"stockMovements"`
+	StockLevels    int64                   `json:"stockLevels"`
+	Transfers      int64                   `json:"transfers"`
+	Warehouses     []WarehouseDeleteImpact `json:"warehouses"`
+}
+
+// WarehouseDeleteImpact counts what a warehouse loses, a transfer between two warehouses is counted in both
+type WarehouseDeleteImpact struct {
+	WarehouseID    string `json:"warehouseID"`
+	Houses         int64  `json:"houses"`
+	Histories      int64  `json:"histories"`
+	Lots           int64  `json:"lots"`
+	StockMovements int64  `json:"stockMovements"`
+	StockLevels    int64  `json:"stockLevels"`
+	Transfers      int64  `json:"transfers"`
+}
+
+func (m MedicineDeleteImpact) Equal(impact MedicineDeleteImpact) bool {
+	return m.MedicationID == impact.MedicationID &&
+		m.Brands == impact.Brands &&
+		m.Houses == impact.Houses &&
+		m.Histories == impact.Histories &&
+		m.Images == impact.Images &&
+		m.Lots == impact.Lots &&
+		m.StockMovements == impact.StockMovements &&
+		m.StockLevels == impact.StockLevels &&
+		m.Transfers == impact.Transfers &&
+		slices.Equal(m.Warehouses, impact.Warehouses)
+}
+
+// ForceDeleteMedicinePreview is returned by a forced delete without a token, the token confirms the delete of this impact
+type ForceDeleteMedicinePreview struct {
+	MedicineDeleteImpact
+	Token     string    `json:"token"`
+	ExpiredAt time.Time `json:"expiredAt"`
+}
+
+// ForceDeleteToken is kept for the token of a preview until it expires
+type ForceDeleteToken struct {
+	UserID string               `json:"userID"`
+	Impact MedicineDeleteImpact `json:"impact"`
+}
+
 type CreateMedicineHouseRequest struct {
 	MedicationID string  `json:"medicationID" validate:"required"`
 	WarehouseID  string  `json:"warehouseID" validate:"required"`
@@ -207,6 +298,15 @@ type UpdateMedicineHouseRequest struct {
 	Label        *string   `json:"label"`
 }
 
+type PrintMedicineHouseLabelRequest struct {
+	WarehouseID string   `query:"warehouseID" validate:"required"`
+	HouseIDs    []string `query:"houseID" validate:"required_without=Locker,dive,uuid"`
+	Locker      string   `query:"locker"`
+	Size        string   `query:"size" validate:"omitempty,oneof=SMALL MEDIUM LARGE"`
+	Width       float64  `query:"width" validate:"required_with=Height,omitempty,min=20,max=190"`
+	Height      float64  `query:"height" validate:"required_with=Width,omitempty,min=10,max=280,ltefield=Width"`
+}
+
 type DeleteMedicineHouseRequest struct {
 	ID uuid.UUID `param:"id" validate:"required,uuid"`
 }
@@ -221,6 +321,7 @@ type CreateMedicineBrandRequest struct {
 	MedicationID     string                `form:"medicationID" validate:"required"`
 	TradeID          string                `form:"tradeID" validate:"required"`
 	TradeName        *string               `form:"tradeName"`
+	Barcode          *string               `form:"barcode"`
 	BlisterImageFile *multipart.FileHeader `form:"blisterImageFile"`
 	TabletImageFile  *multipart.FileHeader `form:"tabletImageFile"`
 	BoxImageFile     *multipart.FileHeader `form:"boxImageFile"`
@@ -231,7 +332,9 @@ type CreateMedicineBrandRequest struct {
 
 type UpdateMedicineBrandRequest struct {
 	BrandID            uuid.UUID             `param:"id" validate:"required,uuid"`
+	TradeID            *string               `form:"-"`
 	TradeName          *string               `form:"tradeName"`
+	Barcode            *string               `form:"barcode"`
 	DeleteBlisterImage bool                  `form:"deleteBlisterImage"`
 	DeleteTabletImage  bool                  `form:"deleteTabletImage"`
 	DeleteBoxImage     bool                  `form:"deleteBoxImage"`
@@ -275,7 +378,17 @@ type CreateMedicineBlisterChangeDateHistoryRequest struct {
 	WarehouseID       string     `json:"warehouseID" validate:"required"`
 	BrandID           *uuid.UUID `json:"brandID" validate:"omitempty,uuid"`
 	Date              string     `json:"date" validate:"required"`
+	ConfirmationCode  string     `json:"confirmationCode"`
 	BlisterChangeDate time.Time  `json:"-"`
+	CreatedBy         *uuid.UUID `json:"-"`
+	ConfirmedBy       *uuid.UUID `json:"-"`
+}
+
+type UpdateMedicineBlisterChangeDateHistoryRequest struct {
+	ID                uuid.UUID
+	MedicationID      string
+	BrandID           *uuid.UUID
+	BlisterChangeDate time.Time
 }
 
 type DeleteMedicineBlisterChangeDateHistoryRequest struct {
@@ -283,4 +396,64 @@ type DeleteMedicineBlisterChangeDateHistoryRequest struct {
 	MedicationID *string    `param:"medicationID"`
 	WarehouseID  *string    `param:"warehouseID"`
 	BrandID      *uuid.UUID `param:"brandID" validate:"omitempty,uuid"`
+	// ConfirmationCode is required for a flagged medicine
+	ConfirmationCode string `query:"confirmationCode"`
+}
+
+type BlisterChangeDueStatus string
+
+const (
+	BlisterChangeDueStatusOverdue  BlisterChangeDueStatus = "OVERDUE"
+	BlisterChangeDueStatusUpcoming BlisterChangeDueStatus = "UPCOMING"
+	// BlisterChangeDueStatusUnknown is a house whose blister was never changed, it has no last change or due date
+	BlisterChangeDueStatusUnknown BlisterChangeDueStatus = "UNKNOWN"
+)
+
+type BlisterChangeInterval struct {
+	ID           uuid.UUID  `json:"id"`
+	WarehouseID  string     `json:"warehouseID"`
+	MedicationID *string    `json:"medicationID,omitempty"`
+	BrandID      *uuid.UUID `json:"brandID,omitempty"`
+	IntervalDays int32      `json:"intervalDays"`
+
+	// JOIN ONLY
+	TradeID *string `json:"tradeID,omitempty"`
+}
+
+type FilterBlisterChangeInterval struct {
+	WarehouseID string `query:"warehouseID" validate:"required"`
+}
+
+type UpsertBlisterChangeIntervalRequest struct {
+	WarehouseID  string     `json:"warehouseID" validate:"required"`
+	MedicationID *string    `json:"medicationID"`
+	BrandID      *uuid.UUID `json:"brandID" validate:"omitempty,uuid"`
+	IntervalDays int32      `json:"intervalDays" validate:"required,min=1"`
+}
+
+type DeleteBlisterChangeIntervalRequest struct {
+	ID uuid.UUID `param:"id" validate:"required,uuid"`
+}
+
+type FilterBlisterChangeDue struct {
+	WarehouseID string        `query:"warehouseID" validate:"required"`
+	Within      string        `query:"within"`
+	Duration    time.Duration `query:"-"`
+}
+
+type BlisterChangeDue struct {
+	HouseID        uuid.UUID              `json:"houseID"`
+	WarehouseID    string                 `json:"warehouseID"`
+	MedicationID   string                 `json:"medicationID"`
+	Locker         string                 `json:"locker"`
+	Floor          int32                  `json:"floor"`
+	No             int32                  `json:"no"`
+	Label          *string                `json:"label,omitempty"`
+	BrandID        *uuid.UUID             `json:"brandID,omitempty"`
+	TradeID        *string                `json:"tradeID,omitempty"`
+	IntervalDays   int32                  `json:"intervalDays"`
+	LastChangeDate string                 `json:"lastChangeDate,omitempty"`
+	DueDate        string                 `json:"dueDate,omitempty"`
+	DaysUntilDue   int                    `json:"daysUntilDue"`
+	Status         BlisterChangeDueStatus `json:"status"`
 }
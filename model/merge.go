@@ -0,0 +1,53 @@
+package model
+
+import (
+	"errors"
+
+	"github.com/google/uuid"
+)
+
+var ErrMergeCollision = errors.New("source and target medicines have colliding rows")
+
+// MergeStrategy resolves a row of the source taking the unique key of a row of the target, the dropped row is deleted
+// along with what depends on it
+type MergeStrategy string
+
+const (
+	MergeStrategyAbort      MergeStrategy = "ABORT"
+	MergeStrategyKeepTarget MergeStrategy = "KEEP_TARGET"
+	MergeStrategyKeepSource MergeStrategy = "KEEP_SOURCE"
+)
+
+type MergeMedicineRequest struct {
+	MedicationID       string        `json:"-" param:"medicationID" validate:"required"`
+	SourceMedicationID string        `json:"sourceMedicationID" validate:"required,nefield=MedicationID"`
+	Strategy           MergeStrategy `json:"strategy" validate:"omitempty,oneof=ABORT KEEP_TARGET KEEP_SOURCE"`
+}
+
+// MergeCollision is a row of the source with the same unique key as a row of the target
+type MergeCollision struct {
+	EntityType AuditEntityType `json:"entityType"`
+	SourceID   uuid.UUID       `json:"sourceID"`
+	TargetID   uuid.UUID       `json:"targetID"`
+	Key        string          `json:"key"`
+}
+
+type MergedMedicine struct {
+	SourceMedicationID string           `json:"sourceMedicationID"`
+	MedicationID       string           `json:"medicationID"`
+	Strategy           MergeStrategy    `json:"strategy"`
+	Brands             int64            `json:"brands"`
+	Houses             int64            `json:"houses"`
+	Histories          int64            `json:"histories"`
+	Collisions         []MergeCollision `json:"collisions"`
+
+	// the rows of a dropped brand or house are moved to the one it collided with, a lot colliding as well is added up to it
+	Lots                   int64 `json:"lots"`
+	StockMovements         int64 `json:"stockMovements"`
+	StockLevels            int64 `json:"stockLevels"`
+	BlisterChangeIntervals int64 `json:"blisterChangeIntervals"`
+	PickVerifications      int64 `json:"pickVerifications"`
+
+	// ImageURLs are the images of the dropped brands, left for the caller to delete
+	ImageURLs []string `json:"-"`
+}
@@ -0,0 +1,55 @@
+package model
+
+import (
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+type ScanType string
+
+const (
+	ScanTypeHouse   ScanType = "HOUSE"
+	ScanTypeBarcode ScanType = "BARCODE"
+	ScanTypeTradeID ScanType = "TRADE_ID"
+)
+
+type ScanRequest struct {
+	Code        string `param:"code" validate:"required"`
+	WarehouseID string `query:"warehouseID"`
+}
+
+type ScanResult struct {
+	Code     string     `json:"code"`
+	Type     ScanType   `json:"type"`
+	HouseID  *uuid.UUID `json:"houseID,omitempty"`
+	BrandID  *uuid.UUID `json:"brandID,omitempty"`
+	Medicine Medicine   `json:"medicine"`
+}
+
+// NormalizeGTIN returns the code as a zero padded GTIN-14 when it is a valid GTIN-8, GTIN-12, GTIN-13 or GTIN-14.
+func NormalizeGTIN(code string) (string, bool) {
+	code = strings.TrimSpace(code)
+	switch len(code) {
+	case 8, 12, 13, 14:
+	default:
+		return "", false
+	}
+
+	sum := 0
+	for index, char := range code {
+		if char < '0' || char > '9' {
+			return "", false
+		}
+		digit := int(char - '0')
+		if (len(code)-index)%2 == 0 {
+			digit *= 3
+		}
+		sum += digit
+	}
+	if sum%10 != 0 {
+		return "", false
+	}
+
+	return strings.Repeat("0", 14-len(code)) + code, true
+}
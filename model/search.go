@@ -0,0 +1,25 @@
+package model
+
+type SuggestionType string
+
+const (
+	SuggestionTypeMedicine  SuggestionType = "MEDICINE"
+	SuggestionTypeBrand     SuggestionType = "BRAND"
+	SuggestionTypeHouse     SuggestionType = "HOUSE"
+	SuggestionTypeWarehouse SuggestionType = "WAREHOUSE"
+)
+
+type Suggestion struct {
+	Type         SuggestionType `json:"type"`
+	ID           string         `json:"id"`
+	Text         string         `json:"text"`
+	Description  *string        `json:"description,omitempty"`
+	MedicationID *string        `json:"medicationID,omitempty"`
+	WarehouseID  *string        `json:"warehouseID,omitempty"`
+}
+
+type FilterSuggestion struct {
+	Query       string `query:"q" validate:"required"`
+	WarehouseID string `query:"warehouseID"`
+	Limit       int64  `query:"limit" validate:"omitempty,min=1,max=50"`
+}
@@ -5,6 +5,8 @@ import (
 	"strings"
 	"time"
 
+	"github.com/google/uuid"
+	genmodel "github.com/kinkando/pharma-sheet-service/.gen/pharma_sheet/public/model"
 	"github.com/kinkando/pharma-sheet-service/pkg/google"
 	"github.com/kinkando/pharma-sheet-service/pkg/util"
 	"google.golang.org/api/sheets/v4"
@@ -23,14 +25,53 @@ type GetSyncMedicineMetadataRequest struct {
 type SyncMedicineRequest struct {
 	WarehouseID string `param:"warehouseID" validate:"required"`
 	URL         string `json:"url" validate:"required,url"`
+	// ConfirmationCode is required when the sync changes the houses, blister changes or lots of a flagged medicine
+	ConfirmationCode string `json:"confirmationCode"`
 }
 
 type SyncMedicineMetadata struct {
-	Title       string           `json:"title"`
-	Medication  MedicineMetadata `json:"medication"`
-	House       MedicineMetadata `json:"house"`
-	Brand       MedicineMetadata `json:"brand"`
-	BlisterDate MedicineMetadata `json:"blisterDate"`
+	Title       string            `json:"title"`
+	Medication  MedicineMetadata  `json:"medication"`
+	House       MedicineMetadata  `json:"house"`
+	Brand       MedicineMetadata  `json:"brand"`
+	BlisterDate MedicineMetadata  `json:"blisterDate"`
+	Lot         *MedicineMetadata `json:"lot,omitempty"`
+}
+
+type SyncProgressPhase string
+
+const (
+	SyncProgressPhaseReadingTabs  SyncProgressPhase = "READING_TABS"
+	SyncProgressPhaseMedicines    SyncProgressPhase = "MEDICINES"
+	SyncProgressPhaseBrands       SyncProgressPhase = "BRANDS"
+	SyncProgressPhaseHouses       SyncProgressPhase = "HOUSES"
+	SyncProgressPhaseBlisterDates SyncProgressPhase = "BLISTER_DATES"
+	SyncProgressPhaseLots         SyncProgressPhase = "LOTS"
+	SyncProgressPhaseWritingBack  SyncProgressPhase = "WRITING_BACK"
+	SyncProgressPhaseCompleted    SyncProgressPhase = "COMPLETED"
+	SyncProgressPhaseFailed       SyncProgressPhase = "FAILED"
+)
+
+type GetSyncProgressRequest struct {
+	WarehouseID string `param:"warehouseID" validate:"required"`
+}
+
+type SyncProgress struct {
+	WarehouseID string            `json:"warehouseID"`
+	Phase       SyncProgressPhase `json:"phase"`
+	Total       uint64            `json:"total"`
+	Processed   uint64            `json:"processed"`
+	Created     uint64            `json:"created"`
+	Updated     uint64            `json:"updated"`
+	Skipped     uint64            `json:"skipped"`
+	Warnings    []string          `json:"warnings,omitempty"`
+	Error       *string           `json:"error,omitempty"`
+	StartedAt   time.Time         `json:"startedAt"`
+	UpdatedAt   time.Time         `json:"updatedAt"`
+}
+
+func (p SyncProgress) IsFinished() bool {
+	return p.Phase == SyncProgressPhaseCompleted || p.Phase == SyncProgressPhaseFailed
 }
 
 type MedicineMetadata struct {
@@ -48,32 +89,88 @@ type GoogleSheetData struct {
 	Brand            MedicineBrandSheetMetadata
 	House            MedicineHouseSheetMetadata
 	BlisterDate      MedicineBlisterDateSheetMetadata
+	Lot              MedicineLotSheetMetadata
 }
 
 type MedicineSheetMetadata struct {
 	Sheet          *sheets.Sheet
 	MedicineSheets []MedicineSheet
 	MedicineData   map[string]Medicine
+	InvalidRows    []int
 }
 
 type MedicineBrandSheetMetadata struct {
-	Sheet          *sheets.Sheet
-	MedicineSheets []MedicineBrandSheet
-	MedicineData   map[string]MedicineBrand
+	Sheet            *sheets.Sheet
+	MedicineSheets   []MedicineBrandSheet
+	MedicineData     map[string]MedicineBrand
+	MedicineDataByID map[uuid.UUID]MedicineBrand
+	SystemIDs        map[int]string
+	RowCount         int
+	InvalidRows      []int
+}
+
+// Match finds the brand of the sheet row by its system ID first, then by its external ID
+func (m MedicineBrandSheetMetadata) Match(sheet MedicineBrandSheet) (MedicineBrand, bool) {
+	if id, err := uuid.Parse(sheet.SystemID); err == nil {
+		if medicine, ok := m.MedicineDataByID[id]; ok && medicine.MedicationID == sheet.MedicationID {
+			return medicine, true
+		}
+	}
+	medicine, ok := m.MedicineData[sheet.ExternalID()]
+	return medicine, ok
 }
 
 type MedicineSheet struct {
 	MedicationID string `csv:"Medication_ID" json:"medicationID"`
 	MedicalName  string `csv:"ชื่อสามัญทางยา" json:"medicalName,omitempty"`
+	Strength     string `csv:"ความแรง" json:"strength,omitempty"`
+	StrengthUnit string `csv:"หน่วยความแรง" json:"strengthUnit,omitempty"`
+	DosageForm   string `csv:"รูปแบบยา" json:"dosageForm,omitempty"`
+	Route        string `csv:"วิธีให้ยา" json:"route,omitempty"`
+	PackageUnit  string `csv:"หน่วยบรรจุ" json:"packageUnit,omitempty"`
 }
 
 func (m *MedicineSheet) IsDifferent(req Medicine) bool {
+	attribute, _ := m.Attribute(req.MedicineAttribute)
 	return m.MedicationID != req.MedicationID ||
-		m.MedicalName != req.MedicalName
+		m.MedicalName != req.MedicalName ||
+		!attribute.Equal(req.MedicineAttribute)
 }
 
 func (m *MedicineSheet) IsInvalid() bool {
-	return m.MedicationID == "" || m.MedicalName == ""
+	if m.MedicationID == "" || m.MedicalName == "" {
+		return true
+	}
+	_, err := m.Attribute(MedicineAttribute{})
+	return err != nil
+}
+
+// Attribute fills the attributes of a medicine with the ones of the sheet, an empty cell keeps the current value
+// so a sheet without the attribute columns never clears them.
+func (m *MedicineSheet) Attribute(current MedicineAttribute) (MedicineAttribute, error) {
+	attribute := current
+	if strength := strings.ReplaceAll(strings.TrimSpace(m.Strength), ",", ""); strength != "" {
+		value, err := strconv.ParseFloat(strength, 64)
+		if err != nil {
+			return current, err
+		}
+		attribute.Strength = &value
+	}
+	if m.StrengthUnit != "" {
+		attribute.StrengthUnit = &m.StrengthUnit
+	}
+	if m.DosageForm != "" {
+		dosageForm := genmodel.PharmaSheetDosageForm(m.DosageForm)
+		attribute.DosageForm = &dosageForm
+	}
+	if m.Route != "" {
+		route := genmodel.PharmaSheetMedicineRoute(m.Route)
+		attribute.Route = &route
+	}
+	if m.PackageUnit != "" {
+		attribute.PackageUnit = &m.PackageUnit
+	}
+	return NormalizeMedicineAttribute(attribute)
 }
 
 func (m *MedicineSheet) ExternalID() string {
@@ -88,6 +185,8 @@ type MedicineBrandSheet struct {
 	BlisterImageURL string `csv:"Link_แผงยา" json:"blisterImageURL,omitempty"`
 	TabletImageURL  string `csv:"Link_เม็ดยา" json:"tabletImageURL,omitempty"`
 	BoxImageURL     string `csv:"Link_กล่องยา" json:"boxImageURL,omitempty"`
+	SystemID        string `csv:"System_ID" json:"systemID,omitempty"`
+	RowNumber       int    `csv:"-" json:"-"`
 }
 
 func (m *MedicineBrandSheet) FileIDs() (blisterFileID, tabletFileID, boxFileID *string) {
@@ -137,9 +236,24 @@ func (m *MedicineBrandSheet) ExternalID() string {
 }
 
 type MedicineHouseSheetMetadata struct {
-	Sheet          *sheets.Sheet
-	MedicineSheets []MedicineHouseSheet
-	MedicineData   map[string]MedicineHouse
+	Sheet            *sheets.Sheet
+	MedicineSheets   []MedicineHouseSheet
+	MedicineData     map[string]MedicineHouse
+	MedicineDataByID map[uuid.UUID]MedicineHouse
+	SystemIDs        map[int]string
+	RowCount         int
+	InvalidRows      []int
+}
+
+// Match finds the house of the sheet row by its system ID first, then by its external ID
+func (m MedicineHouseSheetMetadata) Match(sheet MedicineHouseSheet) (MedicineHouse, bool) {
+	if id, err := uuid.Parse(sheet.SystemID); err == nil {
+		if medicine, ok := m.MedicineDataByID[id]; ok {
+			return medicine, true
+		}
+	}
+	medicine, ok := m.MedicineData[sheet.ExternalID()]
+	return medicine, ok
 }
 
 type MedicineHouseSheet struct {
@@ -152,6 +266,8 @@ type MedicineHouseSheet struct {
 	MedicationID string `csv:"Medication_ID" json:"medicationID"`
 	MedicalName  string `csv:"ชื่อสามัญทางยา" json:"medicalName,omitempty"`
 	Label        string `csv:"Label ตะกร้า" json:"label,omitempty"`
+	SystemID     string `csv:"System_ID" json:"systemID,omitempty"`
+	RowNumber    int    `csv:"-" json:"-"`
 }
 
 func (m *MedicineHouseSheet) Floor() int32 {
@@ -179,6 +295,7 @@ func (m *MedicineHouseSheet) IsDifferent(req MedicineHouse) bool {
 }
 
 func (m *MedicineHouseSheet) IsInvalid() bool {
+	m.Locker = strings.TrimSpace(m.Locker)
 	return m.WarehouseID == "" || m.HouseID == "" || m.MedicationID == "" || m.MedicalName == "" ||
 		m.Locker == "" || m.Floor() <= 0 || m.No() <= 0 || m.Address == ""
 }
@@ -188,9 +305,24 @@ func (m *MedicineHouseSheet) ExternalID() string {
 }
 
 type MedicineBlisterDateSheetMetadata struct {
-	Sheet          *sheets.Sheet
-	MedicineSheets []MedicineBlisterDateSheet
-	MedicineData   map[string]MedicineBlisterDateHistory
+	Sheet            *sheets.Sheet
+	MedicineSheets   []MedicineBlisterDateSheet
+	MedicineData     map[string]MedicineBlisterDateHistory
+	MedicineDataByID map[uuid.UUID]MedicineBlisterDateHistory
+	SystemIDs        map[int]string
+	RowCount         int
+	InvalidRows      []int
+}
+
+// Match finds the history of the sheet row by its system ID first, then by its external ID
+func (m MedicineBlisterDateSheetMetadata) Match(sheet MedicineBlisterDateSheet) (MedicineBlisterDateHistory, bool) {
+	if id, err := uuid.Parse(sheet.SystemID); err == nil {
+		if medicine, ok := m.MedicineDataByID[id]; ok {
+			return medicine, true
+		}
+	}
+	medicine, ok := m.MedicineData[sheet.ExternalID()]
+	return medicine, ok
 }
 
 type MedicineBlisterDateSheet struct {
@@ -201,6 +333,8 @@ type MedicineBlisterDateSheet struct {
 	TradeID      string `csv:"TRADENAME_ID" json:"tradeID,omitempty"`
 	TradeName    string `csv:"ชื่อการค้า" json:"tradeName,omitempty"`
 	BlisterDate  string `csv:"วันที่เปลี่ยนแผงยา" json:"date,omitempty"`
+	SystemID     string `csv:"System_ID" json:"systemID,omitempty"`
+	RowNumber    int    `csv:"-" json:"-"`
 }
 
 func (m *MedicineBlisterDateSheet) IsDifferent(req MedicineBlisterDateHistory) bool {
@@ -227,3 +361,89 @@ func (m *MedicineBlisterDateSheet) ExternalID() string {
 	id += "-" + date.Format(time.DateOnly)
 	return id
 }
+
+// MedicineLotSheetMetadata is read from an optional tab, Sheet is nil when the spreadsheet has no such tab
+type MedicineLotSheetMetadata struct {
+	Sheet            *sheets.Sheet
+	MedicineSheets   []MedicineLotSheet
+	MedicineData     map[string]MedicineLot
+	MedicineDataByID map[uuid.UUID]MedicineLot
+	SystemIDs        map[int]string
+	RowCount         int
+	InvalidRows      []int
+}
+
+// Match finds the lot of the sheet row by its system ID first, then by its house, brand and lot number
+func (m MedicineLotSheetMetadata) Match(sheet MedicineLotSheet, houseID uuid.UUID, brandID *uuid.UUID) (MedicineLot, bool) {
+	if id, err := uuid.Parse(sheet.SystemID); err == nil {
+		if medicine, ok := m.MedicineDataByID[id]; ok {
+			return medicine, true
+		}
+	}
+	medicine, ok := m.MedicineData[MedicineLot{HouseID: houseID, BrandID: brandID, LotNumber: sheet.LotNumber}.ExternalID()]
+	return medicine, ok
+}
+
+type MedicineLotSheet struct {
+	WarehouseID  string `csv:"ศูนย์" json:"warehouseID"`
+	MedicationID string `csv:"Medication_ID" json:"medicationID"`
+	TradeID      string `csv:"TRADENAME_ID" json:"tradeID,omitempty"`
+	Address      string `csv:"บ้านเลขที่ยา" json:"address"`
+	LotNumber    string `csv:"Lot_Number" json:"lotNumber"`
+	ExpiryDate   string `csv:"วันหมดอายุ" json:"expiryDate"`
+	QuantityText string `csv:"จำนวน" json:"quantity"`
+	ReceivedDate string `csv:"วันที่รับยา" json:"receivedDate,omitempty"`
+	SystemID     string `csv:"System_ID" json:"systemID,omitempty"`
+	RowNumber    int    `csv:"-" json:"-"`
+}
+
+func (m *MedicineLotSheet) Expiry() time.Time {
+	date, _ := time.Parse(DateLayout, m.ExpiryDate)
+	return date
+}
+
+func (m *MedicineLotSheet) Received() *time.Time {
+	date, err := time.Parse(DateLayout, m.ReceivedDate)
+	if err != nil {
+		return nil
+	}
+	return &date
+}
+
+func (m *MedicineLotSheet) Quantity() int32 {
+	if m.QuantityText == "" {
+		return 0
+	}
+	quantity, err := strconv.Atoi(strings.ReplaceAll(m.QuantityText, ",", ""))
+	if err != nil || quantity < 0 {
+		return -1
+	}
+	return int32(quantity)
+}
+
+// HouseExternalID matches the external ID of the house the lot is stored in
+func (m *MedicineLotSheet) HouseExternalID() string {
+	return m.WarehouseID + "-" + m.MedicationID + "-" + m.Address
+}
+
+func (m *MedicineLotSheet) IsDifferent(req MedicineLot, brandID *uuid.UUID) bool {
+	received := ""
+	if date := m.Received(); date != nil {
+		received = date.Format(time.DateOnly)
+	}
+	reqReceived := ""
+	if req.ReceivedDate != nil {
+		reqReceived = req.ReceivedDate.Format(time.DateOnly)
+	}
+	return m.LotNumber != req.LotNumber ||
+		util.Value(brandID) != util.Value(req.BrandID) ||
+		m.Expiry().Format(time.DateOnly) != req.ExpiryDate.Format(time.DateOnly) ||
+		m.Quantity() != req.Quantity ||
+		received != reqReceived
+}
+
+func (m *MedicineLotSheet) IsInvalid() bool {
+	m.LotNumber = strings.TrimSpace(m.LotNumber)
+	return m.WarehouseID == "" || m.MedicationID == "" || m.Address == "" || m.LotNumber == "" ||
+		m.Expiry().IsZero() || m.Quantity() < 0 || (m.ReceivedDate != "" && m.Received() == nil)
+}
@@ -0,0 +1,228 @@
+package model
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	genmodel "github.com/kinkando/pharma-sheet-service/.gen/pharma_sheet/public/model"
+)
+
+var (
+	ErrInsufficientStock = errors.New("insufficient stock")
+)
+
+type StockMovement struct {
+	ID           uuid.UUID                             `json:"id"`
+	HouseID      uuid.UUID                             `json:"houseID"`
+	BrandID      *uuid.UUID                            `json:"brandID,omitempty"`
+	LotID        *uuid.UUID                            `json:"lotID,omitempty"`
+	MovementType genmodel.PharmaSheetStockMovementType `json:"movementType"`
+	Quantity     int32                                 `json:"quantity"`
+	Reason       *string                               `json:"reason,omitempty"`
+	TransferID   *uuid.UUID                            `json:"transferID,omitempty"`
+	CreatedBy    *uuid.UUID                            `json:"createdBy,omitempty"`
+	CreatedAt    time.Time                             `json:"createdAt"`
+	ConfirmedBy  *uuid.UUID                            `json:"confirmedBy,omitempty"`
+
+	// JOIN ONLY
+	WarehouseID   string  `json:"warehouseID,omitempty"`
+	MedicationID  string  `json:"medicationID,omitempty"`
+	Locker        string  `json:"locker,omitempty"`
+	Floor         int32   `json:"floor,omitempty"`
+	No            int32   `json:"no,omitempty"`
+	TradeID       *string `json:"tradeID,omitempty"`
+	LotNumber     *string `json:"lotNumber,omitempty"`
+	CreatedName   *string `json:"createdName,omitempty"`
+	ConfirmedName *string `json:"confirmedName,omitempty"`
+}
+
+type StockBalance struct {
+	HouseID      uuid.UUID  `json:"houseID"`
+	WarehouseID  string     `json:"warehouseID"`
+	MedicationID string     `json:"medicationID"`
+	MedicalName  *string    `json:"medicalName,omitempty"`
+	Locker       string     `json:"locker"`
+	Floor        int32      `json:"floor"`
+	No           int32      `json:"no"`
+	BrandID      *uuid.UUID `json:"brandID,omitempty"`
+	TradeID      *string    `json:"tradeID,omitempty"`
+	TradeName    *string    `json:"tradeName,omitempty"`
+	OnHand       int64      `json:"onHand"`
+}
+
+func (m StockBalance) Key() string {
+	key := m.HouseID.String()
+	if m.BrandID != nil {
+		key += "-" + m.BrandID.String()
+	}
+	return key
+}
+
+type FilterStockBalance struct {
+	WarehouseID  string     `query:"warehouseID" validate:"required"`
+	MedicationID string     `query:"medicationID"`
+	HouseID      *uuid.UUID `query:"houseID" validate:"omitempty,uuid"`
+}
+
+type FilterStockMovement struct {
+	Pagination
+	WarehouseID  string     `json:"-" query:"warehouseID" validate:"required"`
+	MedicationID string     `json:"-" query:"medicationID"`
+	HouseID      *uuid.UUID `json:"-" query:"houseID" validate:"omitempty,uuid"`
+	MovementType string     `json:"-" query:"movementType" validate:"omitempty,oneof=RECEIVE DISPENSE TRANSFER ADJUST DISPOSE"`
+}
+
+type CreateStockMovementRequest struct {
+	HouseID          uuid.UUID                             `json:"houseID" validate:"required,uuid"`
+	BrandID          *uuid.UUID                            `json:"brandID" validate:"omitempty,uuid"`
+	LotID            *uuid.UUID                            `json:"lotID" validate:"omitempty,uuid"`
+	MovementType     genmodel.PharmaSheetStockMovementType `json:"movementType" validate:"required,oneof=RECEIVE DISPENSE TRANSFER ADJUST DISPOSE"`
+	Quantity         int32                                 `json:"quantity" validate:"required"`
+	Reason           *string                               `json:"reason"`
+	ToHouseID        *uuid.UUID                            `json:"toHouseID" validate:"required_if=MovementType TRANSFER,omitempty,uuid"`
+	ConfirmationCode string                                `json:"confirmationCode"`
+}
+
+// StockMovementEntry is a single signed row of the ledger, a transfer is posted as a pair of entries
+type StockMovementEntry struct {
+	HouseID      uuid.UUID
+	BrandID      *uuid.UUID
+	LotID        *uuid.UUID
+	MovementType genmodel.PharmaSheetStockMovementType
+	Quantity     int32
+	Reason       *string
+	TransferID   *uuid.UUID
+	ConfirmedBy  *uuid.UUID
+}
+
+// StockOnHandChange is the on-hand stock of a house right before and after the movements posted in one transaction
+type StockOnHandChange struct {
+	HouseID uuid.UUID
+	Before  int64
+	After   int64
+}
+
+type StockLevelScope string
+
+const (
+	StockLevelScopeHouse     StockLevelScope = "HOUSE"
+	StockLevelScopeWarehouse StockLevelScope = "WAREHOUSE"
+)
+
+type StockLevelEventType string
+
+const (
+	StockLevelEventTypeBelowMinimum StockLevelEventType = "BELOW_MINIMUM"
+	StockLevelEventTypeRestocked    StockLevelEventType = "RESTOCKED"
+)
+
+// StockLevel is the reorder point of a medicine, a level without a house applies to every house of the medicine in the warehouse
+type StockLevel struct {
+	ID           uuid.UUID  `json:"id"`
+	WarehouseID  string     `json:"warehouseID"`
+	MedicationID string     `json:"medicationID"`
+	HouseID      *uuid.UUID `json:"houseID,omitempty"`
+	MinQuantity  int32      `json:"minQuantity"`
+	MaxQuantity  *int32     `json:"maxQuantity,omitempty"`
+
+	// JOIN ONLY
+	Locker *string `json:"locker,omitempty"`
+	Floor  *int32  `json:"floor,omitempty"`
+	No     *int32  `json:"no,omitempty"`
+}
+
+func (m StockLevel) Scope() StockLevelScope {
+	if m.HouseID != nil {
+		return StockLevelScopeHouse
+	}
+	return StockLevelScopeWarehouse
+}
+
+type FilterStockLevel struct {
+	WarehouseID string `query:"warehouseID" validate:"required"`
+}
+
+type UpsertStockLevelRequest struct {
+	WarehouseID  string     `json:"warehouseID" validate:"required"`
+	MedicationID string     `json:"medicationID" validate:"required"`
+	HouseID      *uuid.UUID `json:"houseID" validate:"omitempty,uuid"`
+	MinQuantity  *int32     `json:"minQuantity" validate:"required,min=0"`
+	MaxQuantity  *int32     `json:"maxQuantity" validate:"omitempty,min=0"`
+}
+
+type DeleteStockLevelRequest struct {
+	ID uuid.UUID `param:"id" validate:"required,uuid"`
+}
+
+type FilterLowStock struct {
+	WarehouseID  string `param:"warehouseID" validate:"required"`
+	MedicationID string `query:"medicationID"`
+}
+
+type StreamStockLevelEventRequest struct {
+	WarehouseID string `param:"warehouseID" validate:"required"`
+}
+
+type LowStock struct {
+	HouseID         uuid.UUID       `json:"houseID"`
+	WarehouseID     string          `json:"warehouseID"`
+	MedicationID    string          `json:"medicationID"`
+	Locker          string          `json:"locker"`
+	Floor           int32           `json:"floor"`
+	No              int32           `json:"no"`
+	OnHand          int64           `json:"onHand"`
+	MinQuantity     int32           `json:"minQuantity"`
+	MaxQuantity     *int32          `json:"maxQuantity,omitempty"`
+	ReorderQuantity int64           `json:"reorderQuantity"`
+	Scope           StockLevelScope `json:"scope"`
+}
+
+type StockLevelEvent struct {
+	LowStock
+	Type       StockLevelEventType `json:"type"`
+	OccurredAt time.Time           `json:"occurredAt"`
+}
+
+type MedicineHouseTransfer struct {
+	ID                uuid.UUID                             `json:"id"`
+	Mode              genmodel.PharmaSheetHouseTransferMode `json:"mode"`
+	MedicationID      string                                `json:"medicationID"`
+	SourceHouseID     *uuid.UUID                            `json:"sourceHouseID,omitempty"`
+	SourceWarehouseID string                                `json:"sourceWarehouseID"`
+	SourceAddress     string                                `json:"sourceAddress"`
+	TargetHouseID     *uuid.UUID                            `json:"targetHouseID,omitempty"`
+	TargetWarehouseID string                                `json:"targetWarehouseID"`
+	TargetAddress     string                                `json:"targetAddress"`
+	CreatedBy         *uuid.UUID                            `json:"createdBy,omitempty"`
+	CreatedAt         time.Time                             `json:"createdAt"`
+	MovementIDs       []string                              `json:"movementIDs"`
+}
+
+type TransferMedicineHouseRequest struct {
+	ID               uuid.UUID                             `param:"id" validate:"required,uuid"`
+	Mode             genmodel.PharmaSheetHouseTransferMode `json:"mode" validate:"required,oneof=MOVE SPLIT"`
+	WarehouseID      string                                `json:"warehouseID" validate:"required"`
+	Locker           string                                `json:"locker" validate:"required"`
+	Floor            int32                                 `json:"floor" validate:"omitempty,min=1"`
+	No               int32                                 `json:"no" validate:"omitempty,min=1"`
+	Label            *string                               `json:"label"`
+	Reason           *string                               `json:"reason"`
+	Items            []TransferMedicineHouseItem           `json:"items" validate:"required_if=Mode SPLIT,dive"`
+	ConfirmationCode string                                `json:"confirmationCode"`
+}
+
+type TransferMedicineHouseItem struct {
+	BrandID  *uuid.UUID `json:"brandID" validate:"omitempty,uuid"`
+	LotID    *uuid.UUID `json:"lotID" validate:"omitempty,uuid"`
+	Quantity int32      `json:"quantity" validate:"required,min=1"`
+}
+
+// TransferMedicineHouse moves the source house to the target address, or splits the outgoing entries into a house at the target address.
+// Entries are the outgoing legs of the transfer, the incoming legs are mirrored onto the target house.
+type TransferMedicineHouse struct {
+	Mode    genmodel.PharmaSheetHouseTransferMode
+	Source  MedicineHouse
+	Target  MedicineHouse
+	Entries []StockMovementEntry
+}
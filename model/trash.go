@@ -0,0 +1,71 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type DeletedWarehouse struct {
+	WarehouseID string    `json:"warehouseID"`
+	Name        string    `json:"warehouseName"`
+	Role        string    `json:"role"`
+	DeletedAt   time.Time `json:"deletedAt"`
+	PurgeAt     time.Time `json:"purgeAt"`
+}
+
+type DeletedMedicine struct {
+	MedicationID string    `json:"medicationID"`
+	MedicalName  *string   `json:"medicalName,omitempty"`
+	DeletedAt    time.Time `json:"deletedAt"`
+	PurgeAt      time.Time `json:"purgeAt"`
+}
+
+type DeletedMedicineBrand struct {
+	ID           uuid.UUID `json:"id"`
+	MedicationID string    `json:"medicationID"`
+	TradeID      string    `json:"tradeID"`
+	TradeName    *string   `json:"tradeName,omitempty"`
+	DeletedAt    time.Time `json:"deletedAt"`
+	PurgeAt      time.Time `json:"purgeAt"`
+}
+
+type DeletedMedicineHouse struct {
+	ID           uuid.UUID `json:"id"`
+	WarehouseID  string    `json:"warehouseID"`
+	MedicationID string    `json:"medicationID"`
+	Locker       string    `json:"locker"`
+	Floor        int32     `json:"floor"`
+	No           int32     `json:"no"`
+	Label        *string   `json:"label,omitempty"`
+	DeletedAt    time.Time `json:"deletedAt"`
+	PurgeAt      time.Time `json:"purgeAt"`
+}
+
+// WarehouseTrash holds the deleted houses of a warehouse, with the deleted medicines and brands which were kept in it
+type WarehouseTrash struct {
+	Medicines []DeletedMedicine      `json:"medicines"`
+	Brands    []DeletedMedicineBrand `json:"brands"`
+	Houses    []DeletedMedicineHouse `json:"houses"`
+}
+
+type WarehouseTrashRequest struct {
+	WarehouseID string `param:"warehouseID" validate:"required"`
+}
+
+type RestoreMedicineRequest struct {
+	MedicationID string `param:"medicationID" validate:"required"`
+}
+
+type RestoreTrashItemRequest struct {
+	ID uuid.UUID `param:"id" validate:"required,uuid"`
+}
+
+// PurgedTrash reports what a purge deleted permanently, the image files of the brands are left for the caller to delete
+type PurgedTrash struct {
+	Warehouses int64
+	Medicines  int64
+	Brands     int64
+	Houses     int64
+	ImageURLs  []string
+}
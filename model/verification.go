@@ -0,0 +1,42 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	genmodel "github.com/kinkando/pharma-sheet-service/.gen/pharma_sheet/public/model"
+)
+
+// PickVerification is the audit record of a pharmacist scanning a house and then the product picked from it
+type PickVerification struct {
+	ID                  uuid.UUID                                  `json:"id"`
+	WarehouseID         string                                     `json:"warehouseID"`
+	HouseID             *uuid.UUID                                 `json:"houseID,omitempty"`
+	MedicationID        string                                     `json:"medicationID"`
+	Address             string                                     `json:"address"`
+	ProductCode         string                                     `json:"productCode"`
+	BrandID             *uuid.UUID                                 `json:"brandID,omitempty"`
+	ScannedMedicationID *string                                    `json:"scannedMedicationID,omitempty"`
+	Result              genmodel.PharmaSheetPickVerificationResult `json:"result"`
+	CreatedBy           *uuid.UUID                                 `json:"createdBy,omitempty"`
+	CreatedAt           time.Time                                  `json:"createdAt"`
+
+	// JOIN ONLY
+	MedicalName *string `json:"medicalName,omitempty"`
+	TradeID     *string `json:"tradeID,omitempty"`
+	TradeName   *string `json:"tradeName,omitempty"`
+	CreatedName *string `json:"createdName,omitempty"`
+}
+
+type VerifyPickRequest struct {
+	HouseID     uuid.UUID `json:"houseID" validate:"required,uuid"`
+	ProductCode string    `json:"productCode" validate:"required"`
+}
+
+type FilterPickVerification struct {
+	Pagination
+	WarehouseID  string     `json:"-" query:"warehouseID" validate:"required"`
+	MedicationID string     `json:"-" query:"medicationID"`
+	HouseID      *uuid.UUID `json:"-" query:"houseID" validate:"omitempty,uuid"`
+	Result       string     `json:"-" query:"result" validate:"omitempty,oneof=MATCH MISMATCH UNKNOWN"`
+}
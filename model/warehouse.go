@@ -42,6 +42,7 @@ type WarehouseDetail struct {
 	Role          *string                          `json:"role,omitempty"`
 	Status        *model.PharmaSheetApprovalStatus `json:"status,omitempty"`
 	TotalMedicine uint64                           `json:"totalMedicine"`
+	Categories    []CategoryCount                  `json:"categories,omitempty"`
 	Users         []WarehouseUser                  `json:"users,omitempty"`
 }
 
@@ -60,6 +61,7 @@ type FilterWarehouseUser struct {
 	WarehouseID string                          `param:"warehouseID" validate:"required"`
 	Status      model.PharmaSheetApprovalStatus `query:"status" validate:"omitempty,oneof=APPROVED PENDING"`
 	Role        model.PharmaSheetRole           `query:"role" validate:"omitempty,oneof=ADMIN EDITOR VIEWER"`
+	UserID      string                          `json:"-"`
 }
 
 type CreateWarehouseUserRequest struct {
@@ -0,0 +1,183 @@
+package circuitbreaker
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/kinkando/pharma-sheet-service/pkg/logger"
+)
+
+type State string
+
+const (
+	StateClosed   State = "CLOSED"
+	StateOpen     State = "OPEN"
+	StateHalfOpen State = "HALF_OPEN"
+)
+
+var ErrOpenState = errors.New("circuitbreaker: circuit is open")
+
+const (
+	defaultFailureThreshold = 5
+	defaultOpenTimeout      = 30 * time.Second
+	defaultCallTimeout      = 30 * time.Second
+)
+
+type Option interface {
+	apply(*circuitBreaker)
+}
+
+type optionFunc func(*circuitBreaker)
+
+func (o optionFunc) apply(cb *circuitBreaker) {
+	o(cb)
+}
+
+// WithFailureThreshold sets how many consecutive failures open the circuit.
+func WithFailureThreshold(n int) Option {
+	return optionFunc(func(cb *circuitBreaker) {
+		cb.failureThreshold = n
+	})
+}
+
+// WithOpenTimeout sets how long the circuit stays open before a probe call is let through.
+func WithOpenTimeout(timeout time.Duration) Option {
+	return optionFunc(func(cb *circuitBreaker) {
+		cb.openTimeout = timeout
+	})
+}
+
+// WithCallTimeout bounds every call executed through the breaker, a timed out call counts as a failure.
+func WithCallTimeout(timeout time.Duration) Option {
+	return optionFunc(func(cb *circuitBreaker) {
+		cb.callTimeout = timeout
+	})
+}
+
+// WithIsFailure decides which errors count against the circuit, e.g. a not found error is not a sign of an unhealthy dependency.
+func WithIsFailure(isFailure func(err error) bool) Option {
+	return optionFunc(func(cb *circuitBreaker) {
+		cb.isFailure = isFailure
+	})
+}
+
+type CircuitBreaker interface {
+	Name() string
+	State() State
+	Execute(ctx context.Context, fn func(ctx context.Context) error) error
+}
+
+type circuitBreaker struct {
+	name             string
+	failureThreshold int
+	openTimeout      time.Duration
+	callTimeout      time.Duration
+	isFailure        func(err error) bool
+
+	mutex    sync.Mutex
+	state    State
+	failures int
+	openedAt time.Time
+	probing  bool
+}
+
+func New(name string, options ...Option) CircuitBreaker {
+	cb := &circuitBreaker{
+		name:             name,
+		failureThreshold: defaultFailureThreshold,
+		openTimeout:      defaultOpenTimeout,
+		callTimeout:      defaultCallTimeout,
+		isFailure: func(err error) bool {
+			return err != nil
+		},
+		state: StateClosed,
+	}
+	for _, o := range options {
+		o.apply(cb)
+	}
+	return cb
+}
+
+func (cb *circuitBreaker) Name() string {
+	return cb.name
+}
+
+func (cb *circuitBreaker) State() State {
+	cb.mutex.Lock()
+	defer cb.mutex.Unlock()
+	if cb.state == StateOpen && time.Since(cb.openedAt) >= cb.openTimeout {
+		return StateHalfOpen
+	}
+	return cb.state
+}
+
+func (cb *circuitBreaker) Execute(ctx context.Context, fn func(ctx context.Context) error) error {
+	if err := cb.before(); err != nil {
+		return err
+	}
+
+	if cb.callTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, cb.callTimeout)
+		defer cancel()
+	}
+
+	err := fn(ctx)
+	cb.after(ctx, err)
+	return err
+}
+
+func (cb *circuitBreaker) before() error {
+	cb.mutex.Lock()
+	defer cb.mutex.Unlock()
+
+	switch cb.state {
+	case StateOpen:
+		if time.Since(cb.openedAt) < cb.openTimeout {
+			return ErrOpenState
+		}
+		cb.state = StateHalfOpen
+		cb.probing = true
+		return nil
+	case StateHalfOpen:
+		// only a single probe call is allowed while half-open
+		if cb.probing {
+			return ErrOpenState
+		}
+		cb.probing = true
+	}
+	return nil
+}
+
+func (cb *circuitBreaker) after(ctx context.Context, err error) {
+	cb.mutex.Lock()
+	defer cb.mutex.Unlock()
+
+	// a canceled call says nothing about the dependency, so it only releases the probe and keeps the state
+	if errors.Is(err, context.Canceled) {
+		cb.probing = false
+		return
+	}
+
+	if !cb.isFailure(err) {
+		if cb.state != StateClosed {
+			logger.Context(ctx).Infof("circuitbreaker: %s: closed", cb.name)
+		}
+		cb.state = StateClosed
+		cb.failures = 0
+		cb.probing = false
+		return
+	}
+
+	cb.failures++
+	if cb.state == StateHalfOpen || cb.failures >= cb.failureThreshold {
+		if cb.state != StateOpen {
+			logger.Context(ctx).Warnf("circuitbreaker: %s: opened after %d consecutive failures: %v", cb.name, cb.failures, err)
+		}
+		cb.state = StateOpen
+		cb.openedAt = time.Now()
+		cb.probing = false
+	}
+}
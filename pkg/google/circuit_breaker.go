@@ -0,0 +1,187 @@
+package google
+
+import (
+	"context"
+	"errors"
+	"mime/multipart"
+	"net/http"
+
+	"github.com/kinkando/pharma-sheet-service/pkg/circuitbreaker"
+	options "github.com/kinkando/pharma-sheet-service/pkg/option"
+	"google.golang.org/api/drive/v3"
+	"google.golang.org/api/googleapi"
+	"google.golang.org/api/sheets/v4"
+)
+
+// IsUnhealthyError reports whether err signals that a google dependency is unhealthy rather than a bad request.
+func IsUnhealthyError(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) {
+		return false
+	}
+	var apiErr *googleapi.Error
+	if errors.As(err, &apiErr) {
+		return apiErr.Code == http.StatusTooManyRequests || apiErr.Code >= http.StatusInternalServerError
+	}
+	return true
+}
+
+func execute[T any](ctx context.Context, cb circuitbreaker.CircuitBreaker, fn func(ctx context.Context) (T, error)) (result T, err error) {
+	err = cb.Execute(ctx, func(ctx context.Context) (err error) {
+		result, err = fn(ctx)
+		return err
+	})
+	return result, err
+}
+
+type circuitBreakerDrive struct {
+	drive Drive
+	cb    circuitbreaker.CircuitBreaker
+}
+
+func NewCircuitBreakerDrive(drive Drive, cb circuitbreaker.CircuitBreaker) Drive {
+	return &circuitBreakerDrive{drive: drive, cb: cb}
+}
+
+func (d *circuitBreakerDrive) Get(ctx context.Context, fileID string) (*File, error) {
+	return execute(ctx, d.cb, func(ctx context.Context) (*File, error) {
+		return d.drive.Get(ctx, fileID)
+	})
+}
+
+func (d *circuitBreakerDrive) List(ctx context.Context, req ListFile) ([]*drive.File, error) {
+	return execute(ctx, d.cb, func(ctx context.Context) ([]*drive.File, error) {
+		return d.drive.List(ctx, req)
+	})
+}
+
+func (d *circuitBreakerDrive) Upload(ctx context.Context, directory, fileName string, data []byte) (string, error) {
+	return execute(ctx, d.cb, func(ctx context.Context) (string, error) {
+		return d.drive.Upload(ctx, directory, fileName, data)
+	})
+}
+
+func (d *circuitBreakerDrive) UploadMultipart(ctx context.Context, directory string, file *multipart.FileHeader) (string, error) {
+	return execute(ctx, d.cb, func(ctx context.Context) (string, error) {
+		return d.drive.UploadMultipart(ctx, directory, file)
+	})
+}
+
+func (d *circuitBreakerDrive) Delete(ctx context.Context, fileID string) error {
+	return d.cb.Execute(ctx, func(ctx context.Context) error {
+		return d.drive.Delete(ctx, fileID)
+	})
+}
+
+// PublicURL returns an empty string while the circuit is open so callers can omit links that would not resolve.
+func (d *circuitBreakerDrive) PublicURL(ctx context.Context, fileID string) string {
+	if d.cb.State() == circuitbreaker.StateOpen {
+		return ""
+	}
+	return d.drive.PublicURL(ctx, fileID)
+}
+
+func (d *circuitBreakerDrive) MoveFromRoot(ctx context.Context, fileID, folderID string) error {
+	return d.cb.Execute(ctx, func(ctx context.Context) error {
+		return d.drive.MoveFromRoot(ctx, fileID, folderID)
+	})
+}
+
+func (d *circuitBreakerDrive) GetParentIDByDirectory(ctx context.Context, parentID, directory string) (string, error) {
+	return execute(ctx, d.cb, func(ctx context.Context) (string, error) {
+		return d.drive.GetParentIDByDirectory(ctx, parentID, directory)
+	})
+}
+
+type circuitBreakerSheet struct {
+	sheet Sheet
+	cb    circuitbreaker.CircuitBreaker
+}
+
+func NewCircuitBreakerSheet(sheet Sheet, cb circuitbreaker.CircuitBreaker) Sheet {
+	return &circuitBreakerSheet{sheet: sheet, cb: cb}
+}
+
+func (s *circuitBreakerSheet) Create(ctx context.Context, title string, opts ...options.GoogleSheetCreateOption) (*sheets.Spreadsheet, error) {
+	return execute(ctx, s.cb, func(ctx context.Context) (*sheets.Spreadsheet, error) {
+		return s.sheet.Create(ctx, title, opts...)
+	})
+}
+
+func (s *circuitBreakerSheet) List(ctx context.Context, folderID string, opts ...options.GoogleSheetListOption) ([]*drive.File, error) {
+	return execute(ctx, s.cb, func(ctx context.Context) ([]*drive.File, error) {
+		return s.sheet.List(ctx, folderID, opts...)
+	})
+}
+
+func (s *circuitBreakerSheet) Get(ctx context.Context, spreadsheetID string) (*sheets.Spreadsheet, error) {
+	return execute(ctx, s.cb, func(ctx context.Context) (*sheets.Spreadsheet, error) {
+		return s.sheet.Get(ctx, spreadsheetID)
+	})
+}
+
+func (s *circuitBreakerSheet) Update(ctx context.Context, spreadsheetID string, opts ...options.GoogleSheetUpdateOption) error {
+	return s.cb.Execute(ctx, func(ctx context.Context) error {
+		return s.sheet.Update(ctx, spreadsheetID, opts...)
+	})
+}
+
+func (s *circuitBreakerSheet) RenameSheet(ctx context.Context, spreadsheetID string, sheetId int64, title string) error {
+	return s.cb.Execute(ctx, func(ctx context.Context) error {
+		return s.sheet.RenameSheet(ctx, spreadsheetID, sheetId, title)
+	})
+}
+
+func (s *circuitBreakerSheet) ReadColumns(ctx context.Context, sheet *sheets.Sheet, opts ...options.GoogleSheetReadColumnOption) ([]options.GoogleSheetUpdateColumn, error) {
+	return s.sheet.ReadColumns(ctx, sheet, opts...)
+}
+
+func (s *circuitBreakerSheet) ReadData(ctx context.Context, sheet *sheets.Sheet, opts ...options.GoogleSheetReadDataOption) ([][]options.GoogleSheetUpdateData, error) {
+	return s.sheet.ReadData(ctx, sheet, opts...)
+}
+
+func (s *circuitBreakerSheet) Read(ctx context.Context, sheet *sheets.Sheet, data any, opts ...options.GoogleSheetReadOption) ([]byte, error) {
+	return s.sheet.Read(ctx, sheet, data, opts...)
+}
+
+func (s *circuitBreakerSheet) Write(ctx context.Context, data any, opts ...options.GoogleSheetWriteOption) ([][]options.GoogleSheetUpdateData, error) {
+	return s.sheet.Write(ctx, data, opts...)
+}
+
+type circuitBreakerStorage struct {
+	storage Storage
+	cb      circuitbreaker.CircuitBreaker
+}
+
+func NewCircuitBreakerStorage(storage Storage, cb circuitbreaker.CircuitBreaker) Storage {
+	return &circuitBreakerStorage{storage: storage, cb: cb}
+}
+
+func (s *circuitBreakerStorage) UploadFile(ctx context.Context, file *multipart.FileHeader, directory string) (string, error) {
+	return execute(ctx, s.cb, func(ctx context.Context) (string, error) {
+		return s.storage.UploadFile(ctx, file, directory)
+	})
+}
+
+func (s *circuitBreakerStorage) RemoveFile(ctx context.Context, path string) error {
+	return s.cb.Execute(ctx, func(ctx context.Context) error {
+		return s.storage.RemoveFile(ctx, path)
+	})
+}
+
+func (s *circuitBreakerStorage) SetPublic(ctx context.Context, fileName string) error {
+	return s.cb.Execute(ctx, func(ctx context.Context) error {
+		return s.storage.SetPublic(ctx, fileName)
+	})
+}
+
+func (s *circuitBreakerStorage) GetPublicUrl(fileName string) string {
+	return s.storage.GetPublicUrl(fileName)
+}
+
+func (s *circuitBreakerStorage) GetUrl(fileName string) (string, error) {
+	return s.storage.GetUrl(fileName)
+}
+
+func (s *circuitBreakerStorage) Shutdown() {
+	s.storage.Shutdown()
+}
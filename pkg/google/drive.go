@@ -11,7 +11,9 @@ import (
 	"sync"
 	"time"
 
+	httpinterceptor "github.com/kinkando/pharma-sheet-service/pkg/http/interceptor"
 	"github.com/kinkando/pharma-sheet-service/pkg/logger"
+	options "github.com/kinkando/pharma-sheet-service/pkg/option"
 	"golang.org/x/oauth2/google"
 	"golang.org/x/oauth2/jwt"
 	"google.golang.org/api/drive/v3"
@@ -64,7 +66,7 @@ type GoogleDrive struct {
 	mutex        sync.RWMutex
 }
 
-func NewGoogleDrive(serviceAccount []byte, rootFolderID string) Drive {
+func NewGoogleDrive(serviceAccount []byte, rootFolderID string, retryOptions ...options.HTTPInterceptorRetryOption) Drive {
 	credential := new(ServiceAccountCredential)
 	err := json.Unmarshal(serviceAccount, credential)
 	if err != nil {
@@ -82,6 +84,10 @@ func NewGoogleDrive(serviceAccount []byte, rootFolderID string) Drive {
 	defer cancel()
 
 	client := config.Client(ctx)
+	client.Transport = httpinterceptor.NewRetryTransport(
+		append([]options.HTTPInterceptorRetryOption{options.WithHTTPInterceptorRetryTransport(client.Transport)}, retryOptions...)...,
+	)
+
 	service, err := drive.NewService(ctx, option.WithHTTPClient(client))
 	if err != nil {
 		logger.Fatalf("googledrive.NewGoogleDrive: %w", err)
@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"math"
 	"reflect"
+	"slices"
 	"strings"
 	"time"
 
@@ -32,6 +33,8 @@ const (
 	doubleQuoteReplacer = "${DOUBLE_QUOTE}"
 )
 
+var relativeDates = []string{"PAST_YEAR", "PAST_MONTH", "PAST_WEEK", "YESTERDAY", "TODAY", "TOMORROW"}
+
 //go:generate mockgen -source=google_sheet.go -destination=google_sheet_mock.go -package=googlesheet
 type Sheet interface {
 	Create(ctx context.Context, title string, opts ...options.GoogleSheetCreateOption) (*sheets.Spreadsheet, error)
@@ -88,6 +91,13 @@ func NewSheet(opts ...options.GoogleSheetClientOption) Sheet {
 		)
 	}
 
+	// retries wrap the rate limiter so that every attempt is paced as well
+	if opt.IsRetry {
+		client.Transport = httpinterceptor.NewRetryTransport(
+			append([]options.HTTPInterceptorRetryOption{options.WithHTTPInterceptorRetryTransport(client.Transport)}, opt.RetryOptions...)...,
+		)
+	}
+
 	sheetSrv, err := sheets.NewService(ctx, option.WithHTTPClient(client))
 	if err != nil {
 		logger.Fatalf("google: sheet: unable to create sheet service: %v", err)
@@ -224,6 +234,20 @@ func (g *googleSheet) Update(ctx context.Context, spreadsheetID string, opts ...
 		}
 	}
 
+	if len(opt.DataValidations) > 0 {
+		err = g.setDataValidation(ctx, spreadsheetID, opt)
+		if err != nil {
+			return fmt.Errorf("google: sheet: Update: %v", err)
+		}
+	}
+
+	if len(opt.ConditionalFormats) > 0 {
+		err = g.setConditionalFormat(ctx, spreadsheetID, opt)
+		if err != nil {
+			return fmt.Errorf("google: sheet: Update: %v", err)
+		}
+	}
+
 	return nil
 }
 
@@ -574,6 +598,14 @@ func (g *googleSheet) getSheet(ctx context.Context, spreadsheetID, sheetTitle st
 }
 
 func (g *googleSheet) setHeader(ctx context.Context, spreadsheetID string, opt *options.GoogleSheetUpdate) (range_ *sheets.GridRange, err error) {
+	endColumnIndex := int64(len(opt.Columns)) + opt.ColumnStartIndex - 1
+	if opt.ColumnStartIndex > 1 {
+		err = g.expandColumns(ctx, spreadsheetID, opt, endColumnIndex)
+		if err != nil {
+			return
+		}
+	}
+
 	cellRange := fmt.Sprintf("%s1:%s1", ColumnNumberToLetter(int(opt.ColumnStartIndex)), ColumnNumberToLetter(len(opt.Columns)+int(opt.ColumnStartIndex)-1))
 	sheetRange := fmt.Sprintf("%s!%s", opt.SheetTitle, cellRange)
 	columns := []any{}
@@ -627,9 +659,78 @@ func (g *googleSheet) setHeader(ctx context.Context, spreadsheetID string, opt *
 		}
 	}
 
+	if opt.IsHiddenColumns {
+		err = g.hideColumns(ctx, spreadsheetID, range_)
+		if err != nil {
+			return
+		}
+	}
+
 	return range_, nil
 }
 
+// expandColumns appends columns to the grid when the header is written beyond the last column of the sheet
+func (g *googleSheet) expandColumns(ctx context.Context, spreadsheetID string, opt *options.GoogleSheetUpdate, endColumnIndex int64) error {
+	sheet, err := g.getSheet(ctx, spreadsheetID, opt.SheetTitle, opt.SheetID)
+	if err != nil {
+		return err
+	}
+
+	if sheet.Properties.GridProperties == nil {
+		return nil
+	}
+
+	columnCount := sheet.Properties.GridProperties.ColumnCount
+	if endColumnIndex <= columnCount {
+		return nil
+	}
+
+	request := &sheets.Request{
+		AppendDimension: &sheets.AppendDimensionRequest{
+			SheetId:   opt.SheetID,
+			Dimension: "COLUMNS",
+			Length:    endColumnIndex - columnCount,
+		},
+	}
+
+	updateRequest := &sheets.BatchUpdateSpreadsheetRequest{
+		Requests:                     []*sheets.Request{request},
+		IncludeSpreadsheetInResponse: false,
+	}
+	_, err = g.sheet.Spreadsheets.BatchUpdate(spreadsheetID, updateRequest).Context(ctx).Do()
+	if err != nil {
+		return fmt.Errorf("unable to expand columns: %v", err)
+	}
+	return nil
+}
+
+func (g *googleSheet) hideColumns(ctx context.Context, spreadsheetID string, range_ *sheets.GridRange) error {
+	request := &sheets.Request{
+		UpdateDimensionProperties: &sheets.UpdateDimensionPropertiesRequest{
+			Range: &sheets.DimensionRange{
+				SheetId:    range_.SheetId,
+				Dimension:  "COLUMNS",
+				StartIndex: range_.StartColumnIndex,
+				EndIndex:   range_.EndColumnIndex,
+			},
+			Properties: &sheets.DimensionProperties{
+				HiddenByUser: true,
+			},
+			Fields: "hiddenByUser",
+		},
+	}
+
+	updateRequest := &sheets.BatchUpdateSpreadsheetRequest{
+		Requests:                     []*sheets.Request{request},
+		IncludeSpreadsheetInResponse: false,
+	}
+	_, err := g.sheet.Spreadsheets.BatchUpdate(spreadsheetID, updateRequest).Context(ctx).Do()
+	if err != nil {
+		return fmt.Errorf("unable to hide columns: %v", err)
+	}
+	return nil
+}
+
 func (g *googleSheet) setData(ctx context.Context, spreadsheetID string, opt *options.GoogleSheetUpdate) (range_ *sheets.GridRange, err error) {
 	if opt.StartCellRange == "A1" {
 		opt.StartCellRange = "A2"
@@ -817,6 +918,111 @@ func (g *googleSheet) applyFilter(ctx context.Context, spreadsheetID string, ran
 	return nil
 }
 
+func (g *googleSheet) setDataValidation(ctx context.Context, spreadsheetID string, opt *options.GoogleSheetUpdate) error {
+	var requests []*sheets.Request
+	for _, validation := range opt.DataValidations {
+		isList := validation.Condition == options.GoogleSheetConditionTypeOneOfList || validation.Condition == options.GoogleSheetConditionTypeOneOfRange
+		requests = append(requests, &sheets.Request{
+			SetDataValidation: &sheets.SetDataValidationRequest{
+				Range: dataColumnRange(opt.SheetID, validation.ColumnIndex),
+				Rule: &sheets.DataValidationRule{
+					Condition:    booleanCondition(validation.Condition, validation.Values),
+					Strict:       validation.IsStrict,
+					ShowCustomUi: isList,
+					InputMessage: validation.InputMessage,
+				},
+			},
+		})
+	}
+
+	updateRequest := &sheets.BatchUpdateSpreadsheetRequest{
+		Requests:                     requests,
+		IncludeSpreadsheetInResponse: false,
+	}
+	_, err := g.sheet.Spreadsheets.BatchUpdate(spreadsheetID, updateRequest).Context(ctx).Do()
+	if err != nil {
+		return fmt.Errorf("unable to set data validation: %v", err)
+	}
+	return nil
+}
+
+func (g *googleSheet) setConditionalFormat(ctx context.Context, spreadsheetID string, opt *options.GoogleSheetUpdate) error {
+	sheet, err := g.getSheet(ctx, spreadsheetID, opt.SheetTitle, opt.SheetID)
+	if err != nil {
+		return err
+	}
+
+	columnIndexes := make(map[int64]bool)
+	for _, conditionalFormat := range opt.ConditionalFormats {
+		columnIndexes[conditionalFormat.ColumnIndex-1] = true
+	}
+
+	var requests []*sheets.Request
+	// delete from the last rule, so the index of the remaining rules does not shift
+	for index := len(sheet.ConditionalFormats) - 1; index >= 0; index-- {
+		rule := sheet.ConditionalFormats[index]
+		if len(rule.Ranges) != 1 {
+			continue
+		}
+		ruleRange := rule.Ranges[0]
+		if ruleRange.StartRowIndex == 1 && ruleRange.EndRowIndex == 0 && ruleRange.EndColumnIndex == ruleRange.StartColumnIndex+1 && columnIndexes[ruleRange.StartColumnIndex] {
+			requests = append(requests, &sheets.Request{
+				DeleteConditionalFormatRule: &sheets.DeleteConditionalFormatRuleRequest{
+					SheetId: opt.SheetID,
+					Index:   int64(index),
+				},
+			})
+		}
+	}
+
+	for index, conditionalFormat := range opt.ConditionalFormats {
+		requests = append(requests, &sheets.Request{
+			AddConditionalFormatRule: &sheets.AddConditionalFormatRuleRequest{
+				Index: int64(index),
+				Rule: &sheets.ConditionalFormatRule{
+					Ranges: []*sheets.GridRange{dataColumnRange(opt.SheetID, conditionalFormat.ColumnIndex)},
+					BooleanRule: &sheets.BooleanRule{
+						Condition: booleanCondition(conditionalFormat.Condition, conditionalFormat.Values),
+						Format:    conditionalFormat.Format,
+					},
+				},
+			},
+		})
+	}
+
+	updateRequest := &sheets.BatchUpdateSpreadsheetRequest{
+		Requests:                     requests,
+		IncludeSpreadsheetInResponse: false,
+	}
+	_, err = g.sheet.Spreadsheets.BatchUpdate(spreadsheetID, updateRequest).Context(ctx).Do()
+	if err != nil {
+		return fmt.Errorf("unable to set conditional format: %v", err)
+	}
+	return nil
+}
+
+// dataColumnRange is the whole column below the header, the end row index is left unbounded
+func dataColumnRange(sheetID, columnIndex int64) *sheets.GridRange {
+	return &sheets.GridRange{
+		SheetId:          sheetID,
+		StartRowIndex:    1,
+		StartColumnIndex: columnIndex - 1,
+		EndColumnIndex:   columnIndex,
+	}
+}
+
+func booleanCondition(condition options.GoogleSheetConditionType, values []string) *sheets.BooleanCondition {
+	booleanCondition := &sheets.BooleanCondition{Type: string(condition)}
+	for _, value := range values {
+		conditionValue := &sheets.ConditionValue{UserEnteredValue: value}
+		if condition == options.GoogleSheetConditionTypeDateBefore && slices.Contains(relativeDates, value) {
+			conditionValue = &sheets.ConditionValue{RelativeDate: value}
+		}
+		booleanCondition.Values = append(booleanCondition.Values, conditionValue)
+	}
+	return booleanCondition
+}
+
 func (g *googleSheet) lockedCell(ctx context.Context, spreadsheetID, sheetTitle string, range_ *sheets.GridRange) error {
 	isInvalidRange := range_.StartRowIndex > range_.EndRowIndex || range_.StartColumnIndex > range_.EndColumnIndex
 	if isInvalidRange {
@@ -0,0 +1,163 @@
+package httpinterceptor
+
+import (
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/kinkando/pharma-sheet-service/pkg/logger"
+	"github.com/kinkando/pharma-sheet-service/pkg/option"
+)
+
+type RetryTransport struct {
+	Transport       http.RoundTripper
+	MaxRetries      int
+	MaxElapsedTime  time.Duration
+	InitialInterval time.Duration
+	MaxInterval     time.Duration
+	StatusCodes     []int
+}
+
+func NewRetryTransport(opts ...option.HTTPInterceptorRetryOption) *RetryTransport {
+	hi := &option.HTTPInterceptorRetry{
+		Transport:       http.DefaultTransport,
+		MaxRetries:      5,
+		MaxElapsedTime:  2 * time.Minute,
+		InitialInterval: 500 * time.Millisecond,
+		MaxInterval:     30 * time.Second,
+		StatusCodes: []int{
+			http.StatusTooManyRequests,
+			http.StatusInternalServerError,
+			http.StatusBadGateway,
+			http.StatusServiceUnavailable,
+			http.StatusGatewayTimeout,
+		},
+	}
+	for _, opt := range opts {
+		opt.Apply(hi)
+	}
+
+	return &RetryTransport{
+		Transport:       hi.Transport,
+		MaxRetries:      hi.MaxRetries,
+		MaxElapsedTime:  hi.MaxElapsedTime,
+		InitialInterval: hi.InitialInterval,
+		MaxInterval:     hi.MaxInterval,
+		StatusCodes:     hi.StatusCodes,
+	}
+}
+
+func (rt *RetryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
+	start := time.Now()
+	attemptReq := req
+	for retry := 0; ; retry++ {
+		res, err := rt.Transport.RoundTrip(attemptReq)
+		if !rt.isRetryable(req, res, err) {
+			if retry > 0 {
+				logger.Context(ctx).Infof("httpinterceptor: retry: %s %s completed after %d retries", req.Method, req.URL.Path, retry)
+			}
+			return res, err
+		}
+
+		var reason string
+		if err != nil {
+			reason = err.Error()
+		} else {
+			reason = res.Status
+		}
+
+		if retry >= rt.MaxRetries {
+			logger.Context(ctx).Warnf("httpinterceptor: retry: %s %s gave up after %d retries: %s", req.Method, req.URL.Path, retry, reason)
+			return res, err
+		}
+		if req.Body != nil && req.Body != http.NoBody && req.GetBody == nil {
+			logger.Context(ctx).Warnf("httpinterceptor: retry: %s %s cannot be retried because its body is not replayable: %s", req.Method, req.URL.Path, reason)
+			return res, err
+		}
+
+		delay := rt.backoff(retry, res)
+		if rt.MaxElapsedTime > 0 && time.Since(start)+delay > rt.MaxElapsedTime {
+			logger.Context(ctx).Warnf("httpinterceptor: retry: %s %s exceeded its time budget of %s after %d retries: %s", req.Method, req.URL.Path, rt.MaxElapsedTime, retry, reason)
+			return res, err
+		}
+
+		nextReq := req.Clone(ctx)
+		if req.GetBody != nil {
+			body, bodyErr := req.GetBody()
+			if bodyErr != nil {
+				return res, err
+			}
+			nextReq.Body = body
+		}
+
+		logger.Context(ctx).Warnf("httpinterceptor: retry: %s %s failed with %s, retrying in %s (%d/%d)", req.Method, req.URL.Path, reason, delay.Round(time.Millisecond), retry+1, rt.MaxRetries)
+
+		if res != nil {
+			_, _ = io.Copy(io.Discard, res.Body)
+			res.Body.Close()
+		}
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		case <-timer.C:
+		}
+		attemptReq = nextReq
+	}
+}
+
+func (rt *RetryTransport) isRetryable(req *http.Request, res *http.Response, err error) bool {
+	if err != nil {
+		if req.Context().Err() != nil {
+			return false
+		}
+		// the request may have reached the server, so only replay methods that are safe to repeat
+		return isIdempotent(req.Method)
+	}
+	return slices.Contains(rt.StatusCodes, res.StatusCode)
+}
+
+// backoff returns the Retry-After delay of the response when present, otherwise an exponential delay with full jitter.
+func (rt *RetryTransport) backoff(retry int, res *http.Response) time.Duration {
+	if res != nil {
+		if delay, ok := parseRetryAfter(res.Header.Get("Retry-After")); ok {
+			return delay
+		}
+	}
+
+	ceiling := rt.InitialInterval << retry
+	if ceiling <= 0 || ceiling > rt.MaxInterval {
+		ceiling = rt.MaxInterval
+	}
+	if ceiling <= 0 {
+		return 0
+	}
+	return time.Duration(rand.Int64N(int64(ceiling))) + time.Millisecond
+}
+
+func parseRetryAfter(value string) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		return max(time.Duration(seconds)*time.Second, 0), true
+	}
+	if date, err := http.ParseTime(value); err == nil {
+		return max(time.Until(date), 0), true
+	}
+	return 0, false
+}
+
+func isIdempotent(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,205 @@
+package label
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/go-pdf/fpdf"
+	"github.com/kinkando/pharma-sheet-service/pkg/logger"
+	"github.com/skip2/go-qrcode"
+)
+
+const (
+	pageWidth  = 210.0
+	pageHeight = 297.0
+	pageMargin = 8.0
+	labelGap   = 3.0
+	padding    = 2.0
+	minFontPt  = 5.0
+	fontFamily = "label"
+)
+
+// ErrNoFont is returned by Print when the printer was set up without a usable font
+var ErrNoFont = errors.New("label: no unicode TrueType font is loaded to print thai names")
+
+// Size of a single label in millimeters
+type Size struct {
+	Width  float64
+	Height float64
+}
+
+var (
+	SizeSmall  = Size{Width: 50, Height: 25}
+	SizeMedium = Size{Width: 70, Height: 35}
+	SizeLarge  = Size{Width: 100, Height: 50}
+)
+
+type Label struct {
+	Title    string
+	Subtitle string
+	Address  string
+	Text     string
+	// Warning is printed on a red band, e.g. for a high alert medicine
+	Warning string
+	QRCode  string
+}
+
+type Printer interface {
+	Print(size Size, labels []Label) ([]byte, error)
+}
+
+type printer struct {
+	font []byte
+}
+
+// NewPrinter renders labels with the unicode TrueType font at fontPath. The built-in fonts of a pdf can only print
+// latin characters, so without a font the printer refuses with ErrNoFont instead of printing garbled thai names.
+func NewPrinter(fontPath string) Printer {
+	if fontPath == "" {
+		logger.Warn("label: no font is configured, labels cannot be printed")
+		return &printer{}
+	}
+
+	font, err := os.ReadFile(fontPath)
+	if err != nil {
+		logger.Errorf("label: unable to read font %s: %+v", fontPath, err)
+		return &printer{}
+	}
+
+	pdf := fpdf.New(fpdf.OrientationPortrait, fpdf.UnitMillimeter, fpdf.PageSizeA4, "")
+	pdf.AddUTF8FontFromBytes(fontFamily, "", font)
+	if err = pdf.Error(); err != nil {
+		logger.Errorf("label: unable to load font %s: %+v", fontPath, err)
+		return &printer{}
+	}
+	return &printer{font: font}
+}
+
+func (p *printer) Print(size Size, labels []Label) ([]byte, error) {
+	if p.font == nil {
+		return nil, ErrNoFont
+	}
+	columns := int((pageWidth - 2*pageMargin + labelGap) / (size.Width + labelGap))
+	rows := int((pageHeight - 2*pageMargin + labelGap) / (size.Height + labelGap))
+	if columns < 1 || rows < 1 {
+		return nil, fmt.Errorf("label size %.0fx%.0f mm does not fit on an A4 page", size.Width, size.Height)
+	}
+	// the square qr code takes the full height, it leaves no room for the text on a label taller than wide
+	if size.Height > size.Width {
+		return nil, fmt.Errorf("label height %.0f mm must not exceed its width %.0f mm", size.Height, size.Width)
+	}
+
+	pdf := fpdf.New(fpdf.OrientationPortrait, fpdf.UnitMillimeter, fpdf.PageSizeA4, "")
+	pdf.SetMargins(pageMargin, pageMargin, pageMargin)
+	pdf.SetAutoPageBreak(false, 0)
+	pdf.AddUTF8FontFromBytes(fontFamily, "", p.font)
+	pdf.SetFont(fontFamily, "", 10)
+
+	perPage := columns * rows
+	for index, label := range labels {
+		if index%perPage == 0 {
+			pdf.AddPage()
+		}
+		position := index % perPage
+		x := pageMargin + float64(position%columns)*(size.Width+labelGap)
+		y := pageMargin + float64(position/columns)*(size.Height+labelGap)
+		if err := p.drawLabel(pdf, x, y, size, label, index); err != nil {
+			return nil, err
+		}
+	}
+
+	var buffer bytes.Buffer
+	if err := pdf.Output(&buffer); err != nil {
+		return nil, err
+	}
+	return buffer.Bytes(), nil
+}
+
+func (p *printer) drawLabel(pdf *fpdf.Fpdf, x, y float64, size Size, label Label, index int) error {
+	// a light border is the cutting guide, a label with a warning gets a thick red one to stand out on the shelf
+	if label.Warning != "" {
+		pdf.SetDrawColor(200, 0, 0)
+		pdf.SetLineWidth(0.8)
+	} else {
+		pdf.SetDrawColor(200, 200, 200)
+	}
+	pdf.Rect(x, y, size.Width, size.Height, "D")
+	pdf.SetLineWidth(0.2)
+
+	qrSize := size.Height - 2*padding
+	png, err := qrcode.Encode(label.QRCode, qrcode.Medium, 256)
+	if err != nil {
+		return err
+	}
+	imageName := fmt.Sprintf("qr-%d", index)
+	pdf.RegisterImageOptionsReader(imageName, fpdf.ImageOptions{ImageType: "PNG"}, bytes.NewReader(png))
+	pdf.ImageOptions(imageName, x+padding, y+padding, qrSize, qrSize, false, fpdf.ImageOptions{ImageType: "PNG"}, 0, "")
+
+	textX := x + qrSize + 2*padding
+	textWidth := size.Width - qrSize - 3*padding
+	// the address is what staff look for first, so it gets the most room
+	lines := []struct {
+		text    string
+		ratio   float64
+		warning bool
+	}{
+		{label.Address, 0.3, false},
+		{label.Title, 0.25, false},
+		{label.Subtitle, 0.2, false},
+		{label.Text, 0.25, false},
+	}
+	if label.Warning != "" {
+		lines = []struct {
+			text    string
+			ratio   float64
+			warning bool
+		}{
+			{label.Address, 0.25, false},
+			{label.Warning, 0.2, true},
+			{label.Title, 0.2, false},
+			{label.Subtitle, 0.15, false},
+			{label.Text, 0.2, false},
+		}
+	}
+	textY := y + padding
+	textHeight := size.Height - 2*padding
+	for _, line := range lines {
+		lineHeight := textHeight * line.ratio
+		if text := strings.TrimSpace(line.text); text != "" {
+			text = fitText(pdf, text, textWidth, lineHeight)
+			pdf.SetXY(textX, textY)
+			if line.warning {
+				pdf.SetFillColor(200, 0, 0)
+				pdf.SetTextColor(255, 255, 255)
+			}
+			pdf.CellFormat(textWidth, lineHeight, text, "", 0, "LM", line.warning, 0, "")
+			pdf.SetTextColor(0, 0, 0)
+		}
+		textY += lineHeight
+	}
+	return nil
+}
+
+// fitText picks the largest font size at which the text fits into the line, down to minFontPt,
+// and cuts the text with an ellipsis when it is still too wide at that size
+func fitText(pdf *fpdf.Fpdf, text string, width, height float64) string {
+	// 1 pt is 0.3528 mm and a line is about 1.2 times the font size
+	fontSize := height / 0.3528 / 1.2
+	pdf.SetFontSize(fontSize)
+	for fontSize > minFontPt && pdf.GetStringWidth(text) > width {
+		fontSize = max(fontSize-0.5, minFontPt)
+		pdf.SetFontSize(fontSize)
+	}
+
+	if pdf.GetStringWidth(text) <= width {
+		return text
+	}
+	runes := []rune(text)
+	for len(runes) > 0 && pdf.GetStringWidth(string(runes)+"...") > width {
+		runes = runes[:len(runes)-1]
+	}
+	return string(runes) + "..."
+}
@@ -24,7 +24,16 @@ func WithGoogleSheetClientRateLimiter(rateLimiter ratelimit.Limiter) GoogleSheet
 	})
 }
 
+func WithGoogleSheetClientRetry(retryOptions ...HTTPInterceptorRetryOption) GoogleSheetClientOption {
+	return googleSheetClientOptionFunc(func(o *GoogleSheetClient) {
+		o.IsRetry = true
+		o.RetryOptions = retryOptions
+	})
+}
+
 type GoogleSheetClient struct {
 	CredentialJSON []byte
 	RateLimiter    ratelimit.Limiter
+	IsRetry        bool
+	RetryOptions   []HTTPInterceptorRetryOption
 }
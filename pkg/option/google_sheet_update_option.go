@@ -9,6 +9,16 @@ const (
 	ValueInputOptionUserEntered ValueInputOption = "USER_ENTERED"
 )
 
+type GoogleSheetConditionType string
+
+const (
+	GoogleSheetConditionTypeOneOfList     GoogleSheetConditionType = "ONE_OF_LIST"
+	GoogleSheetConditionTypeOneOfRange    GoogleSheetConditionType = "ONE_OF_RANGE"
+	GoogleSheetConditionTypeDateIsValid   GoogleSheetConditionType = "DATE_IS_VALID"
+	GoogleSheetConditionTypeDateBefore    GoogleSheetConditionType = "DATE_BEFORE"
+	GoogleSheetConditionTypeCustomFormula GoogleSheetConditionType = "CUSTOM_FORMULA"
+)
+
 type GoogleSheetUpdateOption interface {
 	Apply(*GoogleSheetUpdate)
 }
@@ -134,6 +144,26 @@ func WithGoogleSheetUpdateIsUnlockedCellColumn(isUnlockedCellColumn bool) Google
 	})
 }
 
+func WithGoogleSheetUpdateIsHiddenColumns(isHiddenColumns bool) GoogleSheetUpdateOption {
+	return googleSheetUpdateOptionFunc(func(o *GoogleSheetUpdate) {
+		o.IsHiddenColumns = isHiddenColumns
+	})
+}
+
+// DataValidations are applied to the data rows (below the header) of each column
+func WithGoogleSheetUpdateDataValidations(dataValidations []GoogleSheetDataValidation) GoogleSheetUpdateOption {
+	return googleSheetUpdateOptionFunc(func(o *GoogleSheetUpdate) {
+		o.DataValidations = dataValidations
+	})
+}
+
+// ConditionalFormats replace the rules previously set on the same columns, other rules of the sheet are kept
+func WithGoogleSheetUpdateConditionalFormats(conditionalFormats []GoogleSheetConditionalFormat) GoogleSheetUpdateOption {
+	return googleSheetUpdateOptionFunc(func(o *GoogleSheetUpdate) {
+		o.ConditionalFormats = conditionalFormats
+	})
+}
+
 type GoogleSheetUpdate struct {
 	SheetID          int64
 	SheetTitle       string
@@ -157,6 +187,10 @@ type GoogleSheetUpdate struct {
 	IsTextWraping        bool
 	IsLockedCellColumn   bool
 	IsUnlockedCellColumn bool
+	IsHiddenColumns      bool
+
+	DataValidations    []GoogleSheetDataValidation
+	ConditionalFormats []GoogleSheetConditionalFormat
 }
 
 type GoogleSheetUpdateData struct {
@@ -169,3 +203,20 @@ type GoogleSheetUpdateColumn struct {
 	Width      int64
 	CellFormat *sheets.CellFormat
 }
+
+type GoogleSheetDataValidation struct {
+	// ColumnIndex is 1-based index: A=1, B=2, C=3, ...
+	ColumnIndex  int64
+	Condition    GoogleSheetConditionType
+	Values       []string
+	IsStrict     bool
+	InputMessage string
+}
+
+type GoogleSheetConditionalFormat struct {
+	// ColumnIndex is 1-based index: A=1, B=2, C=3, ...
+	ColumnIndex int64
+	Condition   GoogleSheetConditionType
+	Values      []string
+	Format      *sheets.CellFormat
+}
@@ -0,0 +1,63 @@
+package option
+
+import (
+	"net/http"
+	"time"
+)
+
+type HTTPInterceptorRetryOption interface {
+	Apply(*HTTPInterceptorRetry)
+}
+
+type httpInterceptorRetryOptionFunc func(*HTTPInterceptorRetry)
+
+func (hirof httpInterceptorRetryOptionFunc) Apply(hiro *HTTPInterceptorRetry) {
+	hirof(hiro)
+}
+
+func WithHTTPInterceptorRetryTransport(transport http.RoundTripper) HTTPInterceptorRetryOption {
+	return httpInterceptorRetryOptionFunc(func(hiro *HTTPInterceptorRetry) {
+		hiro.Transport = transport
+	})
+}
+
+// WithHTTPInterceptorRetryMaxRetries sets how many times a single call may be retried.
+func WithHTTPInterceptorRetryMaxRetries(maxRetries int) HTTPInterceptorRetryOption {
+	return httpInterceptorRetryOptionFunc(func(hiro *HTTPInterceptorRetry) {
+		hiro.MaxRetries = maxRetries
+	})
+}
+
+// WithHTTPInterceptorRetryMaxElapsedTime sets the total time budget of a single call including every retry.
+func WithHTTPInterceptorRetryMaxElapsedTime(maxElapsedTime time.Duration) HTTPInterceptorRetryOption {
+	return httpInterceptorRetryOptionFunc(func(hiro *HTTPInterceptorRetry) {
+		hiro.MaxElapsedTime = maxElapsedTime
+	})
+}
+
+func WithHTTPInterceptorRetryInitialInterval(initialInterval time.Duration) HTTPInterceptorRetryOption {
+	return httpInterceptorRetryOptionFunc(func(hiro *HTTPInterceptorRetry) {
+		hiro.InitialInterval = initialInterval
+	})
+}
+
+func WithHTTPInterceptorRetryMaxInterval(maxInterval time.Duration) HTTPInterceptorRetryOption {
+	return httpInterceptorRetryOptionFunc(func(hiro *HTTPInterceptorRetry) {
+		hiro.MaxInterval = maxInterval
+	})
+}
+
+func WithHTTPInterceptorRetryStatusCodes(statusCodes ...int) HTTPInterceptorRetryOption {
+	return httpInterceptorRetryOptionFunc(func(hiro *HTTPInterceptorRetry) {
+		hiro.StatusCodes = statusCodes
+	})
+}
+
+type HTTPInterceptorRetry struct {
+	Transport       http.RoundTripper
+	MaxRetries      int
+	MaxElapsedTime  time.Duration
+	InitialInterval time.Duration
+	MaxInterval     time.Duration
+	StatusCodes     []int
+}
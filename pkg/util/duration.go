@@ -0,0 +1,58 @@
+package util
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	Day  = 24 * time.Hour
+	Week = 7 * Day
+)
+
+// ParseDuration extends time.ParseDuration with the day (d) and week (w) units, e.g. "7d" or "2w3d12h".
+// Weeks and days must precede the units understood by time.ParseDuration.
+func ParseDuration(s string) (time.Duration, error) {
+	text := strings.TrimSpace(s)
+	if text == "" {
+		return 0, fmt.Errorf("util: invalid duration %q", s)
+	}
+
+	sign := time.Duration(1)
+	if text[0] == '-' || text[0] == '+' {
+		if text[0] == '-' {
+			sign = -1
+		}
+		text = text[1:]
+	}
+
+	var duration time.Duration
+	for {
+		index := strings.IndexAny(text, "dw")
+		if index < 0 {
+			break
+		}
+		value, err := strconv.ParseFloat(text[:index], 64)
+		if err != nil || value < 0 {
+			return 0, fmt.Errorf("util: invalid duration %q", s)
+		}
+		unit := Day
+		if text[index] == 'w' {
+			unit = Week
+		}
+		duration += time.Duration(value * float64(unit))
+		text = text[index+1:]
+	}
+
+	if text != "" {
+		rest, err := time.ParseDuration(text)
+		if err != nil || rest < 0 {
+			return 0, fmt.Errorf("util: invalid duration %q", s)
+		}
+		duration += rest
+	}
+
+	return sign * duration, nil
+}
@@ -7,3 +7,7 @@ func Value[T any](v *T) T {
 	}
 	return *v
 }
+
+func Pointer[T any](v T) *T {
+	return &v
+}
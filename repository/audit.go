@@ -0,0 +1,197 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/go-jet/jet/v2/postgres"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/kinkando/pharma-sheet-service/.gen/pharma_sheet/public/enum"
+	genmodel "github.com/kinkando/pharma-sheet-service/.gen/pharma_sheet/public/model"
+	"github.com/kinkando/pharma-sheet-service/.gen/pharma_sheet/public/table"
+	"github.com/kinkando/pharma-sheet-service/model"
+	"github.com/kinkando/pharma-sheet-service/pkg/generator"
+	"github.com/kinkando/pharma-sheet-service/pkg/logger"
+)
+
+type Audit interface {
+	CreateAuditLogs(ctx context.Context, userID *uuid.UUID, logs []model.CreateAuditLog) error
+	ListAuditLogs(ctx context.Context, filter model.FilterAuditLog) ([]model.AuditLog, uint64, error)
+}
+
+type audit struct {
+	pgPool *pgxpool.Pool
+}
+
+func NewAuditRepository(pgPool *pgxpool.Pool) Audit {
+	return &audit{pgPool: pgPool}
+}
+
+func (r *audit) CreateAuditLogs(ctx context.Context, userID *uuid.UUID, logs []model.CreateAuditLog) error {
+	if len(logs) == 0 {
+		return nil
+	}
+
+	auditLogs := table.PharmaSheetAuditLogs
+	now := time.Now()
+	stmt := auditLogs.INSERT(auditLogs.ID, auditLogs.UserID, auditLogs.Action, auditLogs.EntityType, auditLogs.EntityID, auditLogs.WarehouseID, auditLogs.Before, auditLogs.After, auditLogs.CreatedAt)
+	for _, log := range logs {
+		before, err := auditSnapshot(log.Before)
+		if err != nil {
+			logger.Context(ctx).Error(err)
+			return err
+		}
+		after, err := auditSnapshot(log.After)
+		if err != nil {
+			logger.Context(ctx).Error(err)
+			return err
+		}
+		stmt = stmt.MODEL(genmodel.PharmaSheetAuditLogs{
+			ID:          uuid.MustParse(generator.UUID()),
+			UserID:      userID,
+			Action:      log.Action,
+			EntityType:  string(log.EntityType),
+			EntityID:    log.EntityID,
+			WarehouseID: log.WarehouseID,
+			Before:      before,
+			After:       after,
+			CreatedAt:   now,
+		})
+	}
+
+	sql, args := stmt.Sql()
+	if _, err := r.pgPool.Exec(ctx, sql, args...); err != nil {
+		logger.Context(ctx).Error(err)
+		return err
+	}
+	return nil
+}
+
+func (r *audit) ListAuditLogs(ctx context.Context, filter model.FilterAuditLog) (data []model.AuditLog, total uint64, err error) {
+	auditLogs := table.PharmaSheetAuditLogs
+
+	condition := postgres.Bool(true)
+	if filter.EntityType != "" {
+		condition = condition.AND(auditLogs.EntityType.EQ(postgres.String(string(filter.EntityType))))
+	}
+	if filter.EntityID != "" {
+		condition = condition.AND(auditLogs.EntityID.EQ(postgres.String(filter.EntityID)))
+	}
+	if filter.WarehouseID != "" {
+		condition = condition.AND(auditLogs.WarehouseID.EQ(postgres.String(filter.WarehouseID)))
+	}
+	if filter.UserID != "" {
+		condition = condition.AND(auditLogs.UserID.EQ(postgres.UUID(uuid.MustParse(filter.UserID))))
+	}
+	if filter.Action != "" {
+		condition = condition.AND(auditLogs.Action.EQ(postgres.NewEnumValue(filter.Action.String())))
+	}
+	if filter.ViewerID != "" {
+		// the master data is shared by everyone, a warehouse is only seen by the ones managing it
+		viewerID := postgres.UUID(uuid.MustParse(filter.ViewerID))
+		warehouseUsers := table.PharmaSheetWarehouseUsers
+		condition = condition.AND(postgres.OR(
+			auditLogs.WarehouseID.IS_NULL(),
+			auditLogs.UserID.EQ(viewerID),
+			auditLogs.WarehouseID.IN(
+				warehouseUsers.
+					SELECT(warehouseUsers.WarehouseID).
+					WHERE(
+						warehouseUsers.UserID.EQ(viewerID).
+							AND(warehouseUsers.Status.EQ(enum.PharmaSheetApprovalStatus.Approved)).
+							AND(warehouseUsers.Role.IN(enum.PharmaSheetRole.Admin, enum.PharmaSheetRole.Editor)),
+					),
+			),
+		))
+	}
+
+	from := auditLogs.
+		LEFT_JOIN(table.PharmaSheetUsers, table.PharmaSheetUsers.UserID.EQ(auditLogs.UserID))
+
+	query, args := from.
+		SELECT(postgres.COUNT(auditLogs.ID).AS("total")).
+		WHERE(condition).
+		Sql()
+	err = r.pgPool.QueryRow(ctx, query, args...).Scan(&total)
+	if err != nil {
+		logger.Context(ctx).Error(err)
+		return
+	}
+
+	if total == 0 {
+		return
+	}
+
+	query, args = from.
+		SELECT(
+			auditLogs.ID,
+			auditLogs.UserID,
+			auditLogs.Action,
+			auditLogs.EntityType,
+			auditLogs.EntityID,
+			auditLogs.WarehouseID,
+			auditLogs.Before,
+			auditLogs.After,
+			auditLogs.CreatedAt,
+			table.PharmaSheetUsers.DisplayName,
+			table.PharmaSheetUsers.Email,
+		).
+		WHERE(condition).
+		ORDER_BY(auditLogs.CreatedAt.DESC(), auditLogs.ID.ASC()).
+		LIMIT(int64(filter.Limit)).
+		OFFSET(int64(filter.Offset)).
+		Sql()
+
+	rows, err := r.pgPool.Query(ctx, query, args...)
+	if err != nil {
+		logger.Context(ctx).Error(err)
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var log model.AuditLog
+		var before, after *string
+		err = rows.Scan(
+			&log.ID,
+			&log.UserID,
+			&log.Action,
+			&log.EntityType,
+			&log.EntityID,
+			&log.WarehouseID,
+			&before,
+			&after,
+			&log.CreatedAt,
+			&log.DisplayName,
+			&log.Email,
+		)
+		if err != nil {
+			logger.Context(ctx).Error(err)
+			return nil, 0, err
+		}
+		if before != nil {
+			log.Before = json.RawMessage(*before)
+		}
+		if after != nil {
+			log.After = json.RawMessage(*after)
+		}
+		data = append(data, log)
+	}
+
+	return data, total, nil
+}
+
+// auditSnapshot turns the state of an entity into the JSON stored in the log, a missing state stays NULL
+func auditSnapshot(state any) (*string, error) {
+	if state == nil {
+		return nil, nil
+	}
+	data, err := json.Marshal(state)
+	if err != nil {
+		return nil, err
+	}
+	snapshot := string(data)
+	return &snapshot, nil
+}
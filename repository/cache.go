@@ -2,9 +2,11 @@ package repository
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"time"
 
+	"github.com/kinkando/pharma-sheet-service/model"
 	"github.com/kinkando/pharma-sheet-service/pkg/logger"
 	"github.com/kinkando/pharma-sheet-service/pkg/profile"
 	goredis "github.com/redis/go-redis/v9"
@@ -15,19 +17,39 @@ type Cache interface {
 	CreateAccessToken(ctx context.Context, accessToken profile.AccessToken) error
 	CreateRefreshToken(ctx context.Context, refreshToken profile.RefreshToken) error
 	DeleteToken(ctx context.Context, userID, sessionID string, tokenType profile.TokenType) error
+	GetSuggestions(ctx context.Context, userID string, filter model.FilterSuggestion) ([]model.Suggestion, bool, error)
+	SetSuggestions(ctx context.Context, userID string, filter model.FilterSuggestion, suggestions []model.Suggestion) error
+	CreateConfirmationCode(ctx context.Context, scope model.ConfirmationScope, code, userID string) (model.Confirmation, bool, error)
+	GetConfirmationCode(ctx context.Context, scope model.ConfirmationScope, code string) (string, bool, error)
+	DeleteConfirmationCode(ctx context.Context, scope model.ConfirmationScope, code string) (bool, error)
+	CountConfirmationFailures(ctx context.Context, userID string) (int64, error)
+	AddConfirmationFailure(ctx context.Context, userID string) error
+	CreateForceDeleteToken(ctx context.Context, token string, value model.ForceDeleteToken) (time.Time, error)
+	UseForceDeleteToken(ctx context.Context, token string) (model.ForceDeleteToken, bool, error)
 }
 
+const (
+	suggestionPrefix   = "SUGGESTION"
+	confirmationPrefix = "CONFIRMATION"
+	failurePrefix      = "CONFIRMATION_FAILURE"
+	forceDeletePrefix  = "FORCE_DELETE"
+)
+
 type cache struct {
 	db                     *goredis.Client
 	accessTokenExpireTime  time.Duration
 	refreshTokenExpireTime time.Duration
+	suggestionExpireTime   time.Duration
+	confirmationExpireTime time.Duration
 }
 
-func NewCacheRepository(client *goredis.Client, accessTokenExpireTime, refreshTokenExpireTime time.Duration) Cache {
+func NewCacheRepository(client *goredis.Client, accessTokenExpireTime, refreshTokenExpireTime, suggestionExpireTime, confirmationExpireTime time.Duration) Cache {
 	return &cache{
 		db:                     client,
 		accessTokenExpireTime:  accessTokenExpireTime,
 		refreshTokenExpireTime: refreshTokenExpireTime,
+		suggestionExpireTime:   suggestionExpireTime,
+		confirmationExpireTime: confirmationExpireTime,
 	}
 }
 
@@ -82,3 +104,145 @@ func (r *cache) DeleteToken(ctx context.Context, userID, sessionID string, token
 	}
 	return nil
 }
+
+// GetSuggestions returns false when the suggestions of the query are not cached yet
+func (r *cache) GetSuggestions(ctx context.Context, userID string, filter model.FilterSuggestion) ([]model.Suggestion, bool, error) {
+	payload, err := r.db.Get(ctx, suggestionKey(userID, filter)).Bytes()
+	if err == goredis.Nil {
+		return nil, false, nil
+	}
+	if err != nil {
+		logger.Context(ctx).Error(err)
+		return nil, false, err
+	}
+
+	var suggestions []model.Suggestion
+	if err = json.Unmarshal(payload, &suggestions); err != nil {
+		logger.Context(ctx).Error(err)
+		return nil, false, err
+	}
+	return suggestions, true, nil
+}
+
+func (r *cache) SetSuggestions(ctx context.Context, userID string, filter model.FilterSuggestion, suggestions []model.Suggestion) error {
+	payload, err := json.Marshal(suggestions)
+	if err != nil {
+		logger.Context(ctx).Error(err)
+		return err
+	}
+	err = r.db.Set(ctx, suggestionKey(userID, filter), payload, r.suggestionExpireTime).Err()
+	if err != nil {
+		logger.Context(ctx).Error(err)
+		return err
+	}
+	return nil
+}
+
+// suggestionKey is scoped by user because the suggestions only cover the warehouses of the user
+func suggestionKey(userID string, filter model.FilterSuggestion) string {
+	return fmt.Sprintf("%s:%s:%s:%s:%d:%s", profile.ApplicationPrefix, suggestionPrefix, userID, filter.WarehouseID, filter.Limit, filter.Query)
+}
+
+// CreateConfirmationCode keeps the code for the user and the change it confirms until it expires,
+// it returns false when the code is already taken
+func (r *cache) CreateConfirmationCode(ctx context.Context, scope model.ConfirmationScope, code, userID string) (model.Confirmation, bool, error) {
+	ok, err := r.db.SetNX(ctx, confirmationKey(scope, code), userID, r.confirmationExpireTime).Result()
+	if err != nil {
+		logger.Context(ctx).Error(err)
+		return model.Confirmation{}, false, err
+	}
+	return model.Confirmation{ConfirmationScope: scope, Code: code, ExpiredAt: time.Now().Add(r.confirmationExpireTime)}, ok, nil
+}
+
+// GetConfirmationCode returns the user of the code without using it up,
+// it returns false when the code is unknown, has expired or was issued for another change
+func (r *cache) GetConfirmationCode(ctx context.Context, scope model.ConfirmationScope, code string) (string, bool, error) {
+	userID, err := r.db.Get(ctx, confirmationKey(scope, code)).Result()
+	if err == goredis.Nil {
+		return "", false, nil
+	}
+	if err != nil {
+		logger.Context(ctx).Error(err)
+		return "", false, err
+	}
+	return userID, true, nil
+}
+
+// DeleteConfirmationCode uses up the code, it returns false when a concurrent change has already used it
+func (r *cache) DeleteConfirmationCode(ctx context.Context, scope model.ConfirmationScope, code string) (bool, error) {
+	deleted, err := r.db.Del(ctx, confirmationKey(scope, code)).Result()
+	if err != nil {
+		logger.Context(ctx).Error(err)
+		return false, err
+	}
+	return deleted > 0, nil
+}
+
+// CountConfirmationFailures returns how many codes the user has failed to look up since the first failure
+func (r *cache) CountConfirmationFailures(ctx context.Context, userID string) (int64, error) {
+	count, err := r.db.Get(ctx, confirmationFailureKey(userID)).Int64()
+	if err == goredis.Nil {
+		return 0, nil
+	}
+	if err != nil {
+		logger.Context(ctx).Error(err)
+		return 0, err
+	}
+	return count, nil
+}
+
+// AddConfirmationFailure counts a failed lookup of the user, the count expires as long as a code since the first failure
+func (r *cache) AddConfirmationFailure(ctx context.Context, userID string) error {
+	key := confirmationFailureKey(userID)
+	pipe := r.db.TxPipeline()
+	pipe.Incr(ctx, key)
+	pipe.ExpireNX(ctx, key, r.confirmationExpireTime)
+	if _, err := pipe.Exec(ctx); err != nil {
+		logger.Context(ctx).Error(err)
+		return err
+	}
+	return nil
+}
+
+func confirmationKey(scope model.ConfirmationScope, code string) string {
+	return fmt.Sprintf("%s:%s:%s:%s:%s:%s", profile.ApplicationPrefix, confirmationPrefix, scope.WarehouseID, scope.MedicationID, scope.Action, code)
+}
+
+func confirmationFailureKey(userID string) string {
+	return fmt.Sprintf("%s:%s:%s", profile.ApplicationPrefix, failurePrefix, userID)
+}
+
+// CreateForceDeleteToken keeps the token of a forced delete preview as long as a confirmation code
+func (r *cache) CreateForceDeleteToken(ctx context.Context, token string, value model.ForceDeleteToken) (time.Time, error) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		logger.Context(ctx).Error(err)
+		return time.Time{}, err
+	}
+	if err = r.db.Set(ctx, forceDeleteKey(token), data, r.confirmationExpireTime).Err(); err != nil {
+		logger.Context(ctx).Error(err)
+		return time.Time{}, err
+	}
+	return time.Now().Add(r.confirmationExpireTime), nil
+}
+
+// UseForceDeleteToken returns the preview of the token and removes it, it returns false when the token is unknown or has expired
+func (r *cache) UseForceDeleteToken(ctx context.Context, token string) (value model.ForceDeleteToken, ok bool, err error) {
+	data, err := r.db.GetDel(ctx, forceDeleteKey(token)).Bytes()
+	if err == goredis.Nil {
+		return value, false, nil
+	}
+	if err != nil {
+		logger.Context(ctx).Error(err)
+		return value, false, err
+	}
+	if err = json.Unmarshal(data, &value); err != nil {
+		logger.Context(ctx).Error(err)
+		return value, false, err
+	}
+	return value, true, nil
+}
+
+func forceDeleteKey(token string) string {
+	return fmt.Sprintf("%s:%s:%s", profile.ApplicationPrefix, forceDeletePrefix, token)
+}
@@ -0,0 +1,331 @@
+package repository
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/go-jet/jet/v2/postgres"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	genmodel "github.com/kinkando/pharma-sheet-service/.gen/pharma_sheet/public/model"
+	"github.com/kinkando/pharma-sheet-service/.gen/pharma_sheet/public/table"
+	"github.com/kinkando/pharma-sheet-service/model"
+	"github.com/kinkando/pharma-sheet-service/pkg/database/postgresql"
+	"github.com/kinkando/pharma-sheet-service/pkg/generator"
+	"github.com/kinkando/pharma-sheet-service/pkg/logger"
+)
+
+type Category interface {
+	ListCategories(ctx context.Context) ([]model.Category, error)
+	CreateCategory(ctx context.Context, req model.CreateCategoryRequest) (string, error)
+	UpdateCategory(ctx context.Context, req model.UpdateCategoryRequest) (int64, error)
+	DeleteCategory(ctx context.Context, id uuid.UUID) (int64, error)
+
+	ListMedicineCategories(ctx context.Context, filter model.FilterCategoryMedicine) ([]model.MedicineCategory, error)
+	UpdateMedicineCategories(ctx context.Context, medicationID string, categoryIDs []uuid.UUID) error
+	ListMedicineTags(ctx context.Context, medicationID string) ([]string, error)
+	UpdateMedicineTags(ctx context.Context, medicationID string, tags []string) error
+	ListTags(ctx context.Context, filter model.FilterTag) ([]model.Tag, error)
+}
+
+type category struct {
+	pgPool *pgxpool.Pool
+}
+
+func NewCategoryRepository(pgPool *pgxpool.Pool) Category {
+	return &category{pgPool: pgPool}
+}
+
+func (r *category) ListCategories(ctx context.Context) ([]model.Category, error) {
+	categories := table.PharmaSheetCategories
+	query, args := categories.
+		SELECT(categories.ID, categories.Code, categories.Name, categories.ParentID, categories.CreatedAt, categories.UpdatedAt).
+		ORDER_BY(categories.Code.ASC()).
+		Sql()
+
+	rows, err := r.pgPool.Query(ctx, query, args...)
+	if err != nil {
+		logger.Context(ctx).Error(err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	data := []model.Category{}
+	for rows.Next() {
+		var category model.Category
+		err = rows.Scan(&category.ID, &category.Code, &category.Name, &category.ParentID, &category.CreatedAt, &category.UpdatedAt)
+		if err != nil {
+			logger.Context(ctx).Error(err)
+			return nil, err
+		}
+		data = append(data, category)
+	}
+
+	return data, nil
+}
+
+func (r *category) CreateCategory(ctx context.Context, req model.CreateCategoryRequest) (string, error) {
+	id := generator.UUID()
+	now := time.Now()
+	categories := table.PharmaSheetCategories
+	sql, args := categories.
+		INSERT(categories.ID, categories.Code, categories.Name, categories.ParentID, categories.CreatedAt, categories.UpdatedAt).
+		MODEL(genmodel.PharmaSheetCategories{
+			ID:        uuid.MustParse(id),
+			Code:      req.Code,
+			Name:      req.Name,
+			ParentID:  req.ParentID,
+			CreatedAt: now,
+			UpdatedAt: now,
+		}).
+		Sql()
+	if _, err := r.pgPool.Exec(ctx, sql, args...); err != nil {
+		logger.Context(ctx).Error(err)
+		return "", err
+	}
+
+	return id, nil
+}
+
+func (r *category) UpdateCategory(ctx context.Context, req model.UpdateCategoryRequest) (int64, error) {
+	categories := table.PharmaSheetCategories
+	sql, args := categories.
+		UPDATE(categories.Code, categories.Name, categories.ParentID, categories.UpdatedAt).
+		MODEL(genmodel.PharmaSheetCategories{
+			Code:      req.Code,
+			Name:      req.Name,
+			ParentID:  req.ParentID,
+			UpdatedAt: time.Now(),
+		}).
+		WHERE(categories.ID.EQ(postgres.UUID(req.ID))).
+		Sql()
+	result, err := r.pgPool.Exec(ctx, sql, args...)
+	if err != nil {
+		logger.Context(ctx).Error(err)
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+func (r *category) DeleteCategory(ctx context.Context, id uuid.UUID) (int64, error) {
+	sql, args := table.PharmaSheetCategories.
+		DELETE().
+		WHERE(table.PharmaSheetCategories.ID.EQ(postgres.UUID(id))).
+		Sql()
+	result, err := r.pgPool.Exec(ctx, sql, args...)
+	if err != nil {
+		logger.Context(ctx).Error(err)
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+// ListMedicineCategories lists the categories of a medicine, or of every medicine kept in a warehouse
+func (r *category) ListMedicineCategories(ctx context.Context, filter model.FilterCategoryMedicine) ([]model.MedicineCategory, error) {
+	categories := table.PharmaSheetCategories
+	medicineCategories := table.PharmaSheetMedicineCategories
+	houses := table.PharmaSheetMedicineHouses
+
+	condition := postgres.Bool(true)
+	if filter.MedicationID != "" {
+		condition = condition.AND(medicineCategories.MedicationID.EQ(postgres.String(filter.MedicationID)))
+	}
+	if filter.WarehouseID != "" {
+		condition = condition.AND(medicineCategories.MedicationID.IN(
+			houses.SELECT(houses.MedicationID).WHERE(houses.WarehouseID.EQ(postgres.String(filter.WarehouseID)).AND(houses.DeletedAt.IS_NULL())),
+		))
+	}
+
+	query, args := medicineCategories.
+		INNER_JOIN(categories, categories.ID.EQ(medicineCategories.CategoryID)).
+		SELECT(medicineCategories.MedicationID, categories.ID, categories.Code, categories.Name).
+		WHERE(condition).
+		ORDER_BY(medicineCategories.MedicationID.ASC(), categories.Code.ASC()).
+		Sql()
+
+	rows, err := r.pgPool.Query(ctx, query, args...)
+	if err != nil {
+		logger.Context(ctx).Error(err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	data := []model.MedicineCategory{}
+	for rows.Next() {
+		var medicineCategory model.MedicineCategory
+		err = rows.Scan(&medicineCategory.MedicationID, &medicineCategory.ID, &medicineCategory.Code, &medicineCategory.Name)
+		if err != nil {
+			logger.Context(ctx).Error(err)
+			return nil, err
+		}
+		data = append(data, medicineCategory)
+	}
+
+	return data, nil
+}
+
+// UpdateMedicineCategories replaces the categories of a medicine with the given ones
+func (r *category) UpdateMedicineCategories(ctx context.Context, medicationID string, categoryIDs []uuid.UUID) error {
+	medicineCategories := table.PharmaSheetMedicineCategories
+	return postgresql.Commit(ctx, r.pgPool, func(ctx context.Context, tx pgx.Tx) error {
+		sql, args := medicineCategories.
+			DELETE().
+			WHERE(medicineCategories.MedicationID.EQ(postgres.String(medicationID))).
+			Sql()
+		if _, err := tx.Exec(ctx, sql, args...); err != nil {
+			logger.Context(ctx).Error(err)
+			return err
+		}
+
+		if len(categoryIDs) == 0 {
+			return nil
+		}
+
+		now := time.Now()
+		data := make([]genmodel.PharmaSheetMedicineCategories, 0, len(categoryIDs))
+		for _, categoryID := range categoryIDs {
+			data = append(data, genmodel.PharmaSheetMedicineCategories{
+				MedicationID: medicationID,
+				CategoryID:   categoryID,
+				CreatedAt:    now,
+			})
+		}
+		sql, args = medicineCategories.
+			INSERT(medicineCategories.MedicationID, medicineCategories.CategoryID, medicineCategories.CreatedAt).
+			MODELS(data).
+			Sql()
+		if _, err := tx.Exec(ctx, sql, args...); err != nil {
+			logger.Context(ctx).Error(err)
+			return err
+		}
+
+		return nil
+	})
+}
+
+func (r *category) ListMedicineTags(ctx context.Context, medicationID string) ([]string, error) {
+	medicineTags := table.PharmaSheetMedicineTags
+	query, args := medicineTags.
+		SELECT(medicineTags.Tag).
+		WHERE(medicineTags.MedicationID.EQ(postgres.String(medicationID))).
+		ORDER_BY(medicineTags.Tag.ASC()).
+		Sql()
+
+	rows, err := r.pgPool.Query(ctx, query, args...)
+	if err != nil {
+		logger.Context(ctx).Error(err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	tags := []string{}
+	for rows.Next() {
+		var tag string
+		if err = rows.Scan(&tag); err != nil {
+			logger.Context(ctx).Error(err)
+			return nil, err
+		}
+		tags = append(tags, tag)
+	}
+
+	return tags, nil
+}
+
+// UpdateMedicineTags replaces the tags of a medicine with the given ones
+func (r *category) UpdateMedicineTags(ctx context.Context, medicationID string, tags []string) error {
+	medicineTags := table.PharmaSheetMedicineTags
+	return postgresql.Commit(ctx, r.pgPool, func(ctx context.Context, tx pgx.Tx) error {
+		sql, args := medicineTags.
+			DELETE().
+			WHERE(medicineTags.MedicationID.EQ(postgres.String(medicationID))).
+			Sql()
+		if _, err := tx.Exec(ctx, sql, args...); err != nil {
+			logger.Context(ctx).Error(err)
+			return err
+		}
+
+		if len(tags) == 0 {
+			return nil
+		}
+
+		now := time.Now()
+		data := make([]genmodel.PharmaSheetMedicineTags, 0, len(tags))
+		for _, tag := range tags {
+			data = append(data, genmodel.PharmaSheetMedicineTags{
+				MedicationID: medicationID,
+				Tag:          tag,
+				CreatedAt:    now,
+			})
+		}
+		sql, args = medicineTags.
+			INSERT(medicineTags.MedicationID, medicineTags.Tag, medicineTags.CreatedAt).
+			MODELS(data).
+			Sql()
+		if _, err := tx.Exec(ctx, sql, args...); err != nil {
+			logger.Context(ctx).Error(err)
+			return err
+		}
+
+		return nil
+	})
+}
+
+// ListTags lists every tag in use with the number of medicines having it
+func (r *category) ListTags(ctx context.Context, filter model.FilterTag) ([]model.Tag, error) {
+	medicineTags := table.PharmaSheetMedicineTags
+
+	condition := postgres.Bool(true)
+	if search := strings.ToLower(strings.TrimSpace(filter.Search)); search != "" {
+		condition = medicineTags.Tag.LIKE(postgres.String("%" + search + "%"))
+	}
+
+	query, args := medicineTags.
+		SELECT(medicineTags.Tag, postgres.COUNT(medicineTags.MedicationID).AS("total")).
+		WHERE(condition).
+		GROUP_BY(medicineTags.Tag).
+		ORDER_BY(medicineTags.Tag.ASC()).
+		Sql()
+
+	rows, err := r.pgPool.Query(ctx, query, args...)
+	if err != nil {
+		logger.Context(ctx).Error(err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	data := []model.Tag{}
+	for rows.Next() {
+		var tag model.Tag
+		if err = rows.Scan(&tag.Tag, &tag.TotalMedicine); err != nil {
+			logger.Context(ctx).Error(err)
+			return nil, err
+		}
+		data = append(data, tag)
+	}
+
+	return data, nil
+}
+
+// medicineCategoryCondition filters the medicines by their categories and tag, it matches every medicine when the filter is empty
+func medicineCategoryCondition(medicationID postgres.ColumnString, filter model.FilterMedicineCategory) postgres.BoolExpression {
+	condition := postgres.Bool(true)
+	if len(filter.CategoryIDs) > 0 {
+		medicineCategories := table.PharmaSheetMedicineCategories
+		categoryIDs := make([]postgres.Expression, 0, len(filter.CategoryIDs))
+		for _, categoryID := range filter.CategoryIDs {
+			categoryIDs = append(categoryIDs, postgres.UUID(categoryID))
+		}
+		condition = condition.AND(medicationID.IN(
+			medicineCategories.SELECT(medicineCategories.MedicationID).WHERE(medicineCategories.CategoryID.IN(categoryIDs...)),
+		))
+	}
+	if tag := strings.ToLower(strings.TrimSpace(filter.Tag)); tag != "" {
+		medicineTags := table.PharmaSheetMedicineTags
+		condition = condition.AND(medicationID.IN(
+			medicineTags.SELECT(medicineTags.MedicationID).WHERE(medicineTags.Tag.EQ(postgres.String(tag))),
+		))
+	}
+	return condition
+}
@@ -0,0 +1,86 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/kinkando/pharma-sheet-service/pkg/logger"
+	"github.com/kinkando/pharma-sheet-service/pkg/profile"
+	goredis "github.com/redis/go-redis/v9"
+)
+
+const (
+	eventPrefix      = "EVENT"
+	latestEventTTL   = 30 * time.Minute
+	eventChannelSize = 64
+)
+
+type Event interface {
+	Publish(ctx context.Context, topic string, event any) error
+	Latest(ctx context.Context, topic string) ([]byte, error)
+	Subscribe(ctx context.Context, topic string) (<-chan []byte, func() error)
+}
+
+type event struct {
+	db *goredis.Client
+}
+
+func NewEventRepository(client *goredis.Client) Event {
+	return &event{db: client}
+}
+
+func (r *event) Publish(ctx context.Context, topic string, event any) error {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		logger.Context(ctx).Error(err)
+		return err
+	}
+
+	key := fmt.Sprintf("%s:%s:%s", profile.ApplicationPrefix, eventPrefix, topic)
+
+	// the latest event is kept so that a subscriber joining mid-way starts from the current state
+	_, err = r.db.TxPipelined(ctx, func(pipe goredis.Pipeliner) error {
+		pipe.Set(ctx, key, payload, latestEventTTL)
+		pipe.Publish(ctx, key, payload)
+		return nil
+	})
+	if err != nil {
+		logger.Context(ctx).Error(err)
+		return err
+	}
+	return nil
+}
+
+func (r *event) Latest(ctx context.Context, topic string) ([]byte, error) {
+	key := fmt.Sprintf("%s:%s:%s", profile.ApplicationPrefix, eventPrefix, topic)
+	payload, err := r.db.Get(ctx, key).Bytes()
+	if err == goredis.Nil {
+		return nil, nil
+	}
+	if err != nil {
+		logger.Context(ctx).Error(err)
+		return nil, err
+	}
+	return payload, nil
+}
+
+func (r *event) Subscribe(ctx context.Context, topic string) (<-chan []byte, func() error) {
+	key := fmt.Sprintf("%s:%s:%s", profile.ApplicationPrefix, eventPrefix, topic)
+	pubsub := r.db.Subscribe(ctx, key)
+
+	events := make(chan []byte, eventChannelSize)
+	go func() {
+		defer close(events)
+		for message := range pubsub.Channel() {
+			select {
+			case events <- []byte(message.Payload):
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return events, pubsub.Close
+}
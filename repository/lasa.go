@@ -0,0 +1,255 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-jet/jet/v2/postgres"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+	genmodel "github.com/kinkando/pharma-sheet-service/.gen/pharma_sheet/public/model"
+	"github.com/kinkando/pharma-sheet-service/.gen/pharma_sheet/public/table"
+	"github.com/kinkando/pharma-sheet-service/model"
+	"github.com/kinkando/pharma-sheet-service/pkg/generator"
+	"github.com/kinkando/pharma-sheet-service/pkg/logger"
+)
+
+type LASA interface {
+	ListLASAPairs(ctx context.Context, filter model.FilterLASAPair) (data []model.LASAPair, total uint64, err error)
+	GetLASAPairs(ctx context.Context, medicationIDs []string) ([]model.LASAPair, error)
+	CreateLASAPair(ctx context.Context, req model.CreateLASAPairRequest) (string, error)
+	DeleteLASAPair(ctx context.Context, id uuid.UUID) (int64, error)
+	ListMedicineNames(ctx context.Context, medicationIDs []string) (map[string][]string, error)
+}
+
+type lasa struct {
+	pgPool *pgxpool.Pool
+}
+
+func NewLASARepository(pgPool *pgxpool.Pool) LASA {
+	return &lasa{pgPool: pgPool}
+}
+
+func (r *lasa) ListLASAPairs(ctx context.Context, filter model.FilterLASAPair) (data []model.LASAPair, total uint64, err error) {
+	lasaPairs := table.PharmaSheetLasaPairs
+	medicines := table.PharmaSheetMedicines
+	similarMedicines := table.PharmaSheetMedicines.AS("similar_medicines")
+
+	condition := postgres.Bool(true)
+	if filter.MedicationID != "" {
+		medicationID := postgres.String(filter.MedicationID)
+		condition = lasaPairs.MedicationID.EQ(medicationID).OR(lasaPairs.SimilarMedicationID.EQ(medicationID))
+	}
+	if filter.Search != "" {
+		search := postgres.String("%" + filter.Search + "%")
+		condition = condition.AND(postgres.OR(
+			lasaPairs.MedicationID.LIKE(search),
+			lasaPairs.SimilarMedicationID.LIKE(search),
+			medicines.MedicalName.LIKE(search),
+			similarMedicines.MedicalName.LIKE(search),
+		))
+	}
+
+	from := lasaPairs.
+		INNER_JOIN(medicines, medicines.MedicationID.EQ(lasaPairs.MedicationID).AND(medicines.DeletedAt.IS_NULL())).
+		INNER_JOIN(similarMedicines, similarMedicines.MedicationID.EQ(lasaPairs.SimilarMedicationID).AND(similarMedicines.DeletedAt.IS_NULL())).
+		LEFT_JOIN(table.PharmaSheetUsers, table.PharmaSheetUsers.UserID.EQ(lasaPairs.CreatedBy))
+
+	query, args := from.
+		SELECT(postgres.COUNT(lasaPairs.ID).AS("total")).
+		WHERE(condition).
+		Sql()
+	err = r.pgPool.QueryRow(ctx, query, args...).Scan(&total)
+	if err != nil {
+		logger.Context(ctx).Error(err)
+		return
+	}
+
+	if total == 0 {
+		return
+	}
+
+	query, args = from.
+		SELECT(
+			lasaPairs.ID,
+			lasaPairs.MedicationID,
+			lasaPairs.SimilarMedicationID,
+			lasaPairs.Note,
+			lasaPairs.CreatedBy,
+			lasaPairs.CreatedAt,
+			medicines.MedicalName,
+			similarMedicines.MedicalName,
+			table.PharmaSheetUsers.DisplayName,
+		).
+		WHERE(condition).
+		ORDER_BY(lasaPairs.MedicationID.ASC(), lasaPairs.SimilarMedicationID.ASC()).
+		LIMIT(int64(filter.Limit)).
+		OFFSET(int64(filter.Offset)).
+		Sql()
+
+	rows, err := r.pgPool.Query(ctx, query, args...)
+	if err != nil {
+		logger.Context(ctx).Error(err)
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var lasaPair model.LASAPair
+		err = rows.Scan(
+			&lasaPair.ID,
+			&lasaPair.MedicationID,
+			&lasaPair.SimilarMedicationID,
+			&lasaPair.Note,
+			&lasaPair.CreatedBy,
+			&lasaPair.CreatedAt,
+			&lasaPair.MedicalName,
+			&lasaPair.SimilarMedicalName,
+			&lasaPair.CreatedName,
+		)
+		if err != nil {
+			logger.Context(ctx).Error(err)
+			return nil, 0, err
+		}
+		data = append(data, lasaPair)
+	}
+
+	return data, total, nil
+}
+
+// GetLASAPairs lists the pairs having both of their medicines in the given ones
+func (r *lasa) GetLASAPairs(ctx context.Context, medicationIDs []string) ([]model.LASAPair, error) {
+	if len(medicationIDs) == 0 {
+		return nil, nil
+	}
+
+	ids := make([]postgres.Expression, 0, len(medicationIDs))
+	for _, medicationID := range medicationIDs {
+		ids = append(ids, postgres.String(medicationID))
+	}
+
+	lasaPairs := table.PharmaSheetLasaPairs
+	query, args := lasaPairs.
+		SELECT(lasaPairs.ID, lasaPairs.MedicationID, lasaPairs.SimilarMedicationID, lasaPairs.Note).
+		WHERE(lasaPairs.MedicationID.IN(ids...).AND(lasaPairs.SimilarMedicationID.IN(ids...))).
+		Sql()
+
+	rows, err := r.pgPool.Query(ctx, query, args...)
+	if err != nil {
+		logger.Context(ctx).Error(err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	var data []model.LASAPair
+	for rows.Next() {
+		var lasaPair model.LASAPair
+		if err = rows.Scan(&lasaPair.ID, &lasaPair.MedicationID, &lasaPair.SimilarMedicationID, &lasaPair.Note); err != nil {
+			logger.Context(ctx).Error(err)
+			return nil, err
+		}
+		data = append(data, lasaPair)
+	}
+
+	return data, nil
+}
+
+// collateC compares the text byte by byte as Go does, the medication ids of a pair are ordered the same way in Go and in the database
+func collateC(expression postgres.StringExpression) postgres.StringExpression {
+	return postgres.StringExp(postgres.CustomExpression(expression, postgres.Token(`COLLATE "C"`)))
+}
+
+// CreateLASAPair stores a pair in byte order of its medication ids so the same pair is never stored twice
+func (r *lasa) CreateLASAPair(ctx context.Context, req model.CreateLASAPairRequest) (string, error) {
+	userID, err := useProfileUserID(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	medicationID, similarMedicationID := req.MedicationID, req.SimilarMedicationID
+	if medicationID > similarMedicationID {
+		medicationID, similarMedicationID = similarMedicationID, medicationID
+	}
+
+	id := generator.UUID()
+	lasaPairs := table.PharmaSheetLasaPairs
+	sql, args := lasaPairs.
+		INSERT(lasaPairs.ID, lasaPairs.MedicationID, lasaPairs.SimilarMedicationID, lasaPairs.Note, lasaPairs.CreatedBy, lasaPairs.CreatedAt).
+		MODEL(genmodel.PharmaSheetLasaPairs{
+			ID:                  uuid.MustParse(id),
+			MedicationID:        medicationID,
+			SimilarMedicationID: similarMedicationID,
+			Note:                req.Note,
+			CreatedBy:           &userID,
+			CreatedAt:           time.Now(),
+		}).
+		Sql()
+	if _, err = r.pgPool.Exec(ctx, sql, args...); err != nil {
+		logger.Context(ctx).Error(err)
+		return "", err
+	}
+
+	return id, nil
+}
+
+func (r *lasa) DeleteLASAPair(ctx context.Context, id uuid.UUID) (int64, error) {
+	sql, args := table.PharmaSheetLasaPairs.
+		DELETE().
+		WHERE(table.PharmaSheetLasaPairs.ID.EQ(postgres.UUID(id))).
+		Sql()
+	result, err := r.pgPool.Exec(ctx, sql, args...)
+	if err != nil {
+		logger.Context(ctx).Error(err)
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+// ListMedicineNames maps each medicine to its medical name followed by the trade names of its brands
+func (r *lasa) ListMedicineNames(ctx context.Context, medicationIDs []string) (map[string][]string, error) {
+	names := make(map[string][]string)
+	if len(medicationIDs) == 0 {
+		return names, nil
+	}
+
+	ids := make([]postgres.Expression, 0, len(medicationIDs))
+	for _, medicationID := range medicationIDs {
+		ids = append(ids, postgres.String(medicationID))
+	}
+
+	medicines := table.PharmaSheetMedicines
+	brands := table.PharmaSheetMedicineBrands
+	query, args := medicines.
+		LEFT_JOIN(brands, brands.MedicationID.EQ(medicines.MedicationID).AND(brands.DeletedAt.IS_NULL())).
+		SELECT(medicines.MedicationID, medicines.MedicalName, brands.TradeName).
+		WHERE(medicines.MedicationID.IN(ids...)).
+		ORDER_BY(medicines.MedicationID.ASC(), brands.TradeID.ASC()).
+		Sql()
+
+	rows, err := r.pgPool.Query(ctx, query, args...)
+	if err != nil {
+		logger.Context(ctx).Error(err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var medicationID string
+		var medicalName, tradeName *string
+		if err = rows.Scan(&medicationID, &medicalName, &tradeName); err != nil {
+			logger.Context(ctx).Error(err)
+			return nil, err
+		}
+		if _, ok := names[medicationID]; !ok {
+			names[medicationID] = []string{}
+			if medicalName != nil && *medicalName != "" {
+				names[medicationID] = append(names[medicationID], *medicalName)
+			}
+		}
+		if tradeName != nil && *tradeName != "" {
+			names[medicationID] = append(names[medicationID], *tradeName)
+		}
+	}
+
+	return names, nil
+}
@@ -0,0 +1,422 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/go-jet/jet/v2/postgres"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	genmodel "github.com/kinkando/pharma-sheet-service/.gen/pharma_sheet/public/model"
+	"github.com/kinkando/pharma-sheet-service/.gen/pharma_sheet/public/table"
+	"github.com/kinkando/pharma-sheet-service/model"
+	"github.com/kinkando/pharma-sheet-service/pkg/database/postgresql"
+	"github.com/kinkando/pharma-sheet-service/pkg/generator"
+	"github.com/kinkando/pharma-sheet-service/pkg/logger"
+	"github.com/kinkando/pharma-sheet-service/pkg/util"
+)
+
+type Lot interface {
+	GetMedicineLot(ctx context.Context, id uuid.UUID) (model.MedicineLot, error)
+	ListMedicineLots(ctx context.Context, filter model.ListMedicineLot) ([]model.MedicineLot, error)
+	ListMedicineLotsPagination(ctx context.Context, filter model.FilterMedicineLot) (data []model.MedicineLot, total uint64, err error)
+	CreateMedicineLot(ctx context.Context, req model.CreateMedicineLotRequest, confirmedBy *uuid.UUID) (string, error)
+	UpdateMedicineLot(ctx context.Context, req model.UpdateMedicineLotRequest, confirmedBy *uuid.UUID) error
+	DeleteMedicineLot(ctx context.Context, id uuid.UUID, confirmedBy *uuid.UUID) (int64, error)
+}
+
+type lot struct {
+	pgPool *pgxpool.Pool
+	// the quantity of a lot is only written by the movements of the stock ledger, posted in the transaction changing the lot
+	stock *stock
+}
+
+func NewLotRepository(pgPool *pgxpool.Pool) Lot {
+	return &lot{pgPool: pgPool, stock: &stock{pgPool: pgPool}}
+}
+
+var medicineLotColumns = postgres.ProjectionList{
+	table.PharmaSheetMedicineLots.ID,
+	table.PharmaSheetMedicineLots.HouseID,
+	table.PharmaSheetMedicineLots.BrandID,
+	table.PharmaSheetMedicineLots.LotNumber,
+	table.PharmaSheetMedicineLots.ExpiryDate,
+	table.PharmaSheetMedicineLots.Quantity,
+	table.PharmaSheetMedicineLots.ReceivedDate,
+	table.PharmaSheetMedicineHouses.WarehouseID,
+	table.PharmaSheetMedicineHouses.MedicationID,
+	table.PharmaSheetMedicines.MedicalName,
+	table.PharmaSheetMedicineHouses.Locker,
+	table.PharmaSheetMedicineHouses.Floor,
+	table.PharmaSheetMedicineHouses.No,
+	table.PharmaSheetMedicineBrands.TradeID,
+	table.PharmaSheetMedicineBrands.TradeName,
+}
+
+func medicineLotTable() postgres.ReadableTable {
+	return table.PharmaSheetMedicineLots.
+		INNER_JOIN(table.PharmaSheetMedicineHouses, table.PharmaSheetMedicineHouses.ID.EQ(table.PharmaSheetMedicineLots.HouseID).AND(table.PharmaSheetMedicineHouses.DeletedAt.IS_NULL())).
+		INNER_JOIN(table.PharmaSheetMedicines, table.PharmaSheetMedicines.MedicationID.EQ(table.PharmaSheetMedicineHouses.MedicationID)).
+		LEFT_JOIN(table.PharmaSheetMedicineBrands, table.PharmaSheetMedicineBrands.ID.EQ(table.PharmaSheetMedicineLots.BrandID))
+}
+
+func scanMedicineLot(row pgx.Row) (medicineLot model.MedicineLot, err error) {
+	err = row.Scan(
+		&medicineLot.ID,
+		&medicineLot.HouseID,
+		&medicineLot.BrandID,
+		&medicineLot.LotNumber,
+		&medicineLot.ExpiryDate,
+		&medicineLot.Quantity,
+		&medicineLot.ReceivedDate,
+		&medicineLot.WarehouseID,
+		&medicineLot.MedicationID,
+		&medicineLot.MedicalName,
+		&medicineLot.Locker,
+		&medicineLot.Floor,
+		&medicineLot.No,
+		&medicineLot.TradeID,
+		&medicineLot.TradeName,
+	)
+	return medicineLot, err
+}
+
+func (r *lot) GetMedicineLot(ctx context.Context, id uuid.UUID) (model.MedicineLot, error) {
+	query, args := medicineLotTable().
+		SELECT(medicineLotColumns).
+		WHERE(table.PharmaSheetMedicineLots.ID.EQ(postgres.UUID(id))).
+		Sql()
+
+	medicineLot, err := scanMedicineLot(r.pgPool.QueryRow(ctx, query, args...))
+	if err != nil {
+		logger.Context(ctx).Error(err)
+		return medicineLot, err
+	}
+	return medicineLot, nil
+}
+
+func (r *lot) ListMedicineLots(ctx context.Context, filter model.ListMedicineLot) ([]model.MedicineLot, error) {
+	condition := postgres.Bool(true)
+	if filter.WarehouseID != "" {
+		condition = condition.AND(table.PharmaSheetMedicineHouses.WarehouseID.EQ(postgres.String(filter.WarehouseID)))
+	}
+	if filter.HouseID != uuid.Nil {
+		condition = condition.AND(table.PharmaSheetMedicineLots.HouseID.EQ(postgres.UUID(filter.HouseID)))
+	}
+	if filter.ExpiryBefore != nil {
+		condition = condition.AND(table.PharmaSheetMedicineLots.ExpiryDate.LT_EQ(postgres.DateT(*filter.ExpiryBefore)))
+	}
+	if filter.IsInStock {
+		condition = condition.AND(table.PharmaSheetMedicineLots.Quantity.GT(postgres.Int32(0)))
+	}
+
+	query, args := medicineLotTable().
+		SELECT(medicineLotColumns).
+		WHERE(condition).
+		ORDER_BY(
+			table.PharmaSheetMedicineLots.ExpiryDate.ASC(),
+			table.PharmaSheetMedicineHouses.MedicationID.ASC(),
+			table.PharmaSheetMedicineLots.LotNumber.ASC(),
+		).
+		Sql()
+
+	rows, err := r.pgPool.Query(ctx, query, args...)
+	if err != nil {
+		logger.Context(ctx).Error(err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	var medicineLots []model.MedicineLot
+	for rows.Next() {
+		medicineLot, err := scanMedicineLot(rows)
+		if err != nil {
+			logger.Context(ctx).Error(err)
+			return nil, err
+		}
+		medicineLots = append(medicineLots, medicineLot)
+	}
+
+	return medicineLots, nil
+}
+
+func (r *lot) ListMedicineLotsPagination(ctx context.Context, filter model.FilterMedicineLot) (data []model.MedicineLot, total uint64, err error) {
+	sortBy := filter.SortBy("expiry_date ASC")
+	sorts := strings.Split(sortBy, " ")
+	order := "ASC"
+	if strings.EqualFold(sorts[1], "DESC") {
+		order = "DESC"
+	}
+	switch sorts[0] {
+	case "lot_number", "quantity", "received_date":
+		sortBy = fmt.Sprintf("%s.%s %s", table.PharmaSheetMedicineLots.TableName(), sorts[0], order)
+	case "medication_id":
+		sortBy = fmt.Sprintf("%s.medication_id %s", table.PharmaSheetMedicineHouses.TableName(), order)
+	case "address":
+		sortBy = fmt.Sprintf("locker %s, floor %s, no %s", order, order, order)
+	default:
+		sortBy = fmt.Sprintf("%s.expiry_date %s", table.PharmaSheetMedicineLots.TableName(), order)
+	}
+
+	condition := table.PharmaSheetMedicineHouses.WarehouseID.EQ(postgres.String(filter.WarehouseID))
+	if filter.HouseID != nil {
+		condition = condition.AND(table.PharmaSheetMedicineLots.HouseID.EQ(postgres.UUID(*filter.HouseID)))
+	}
+	if filter.MedicationID != "" {
+		condition = condition.AND(table.PharmaSheetMedicineHouses.MedicationID.EQ(postgres.String(filter.MedicationID)))
+	}
+	if search := strings.TrimSpace(filter.Search); search != "" {
+		search := postgres.String("%" + strings.ToLower(search) + "%")
+		condition = condition.AND(
+			postgres.OR(
+				postgres.LOWER(table.PharmaSheetMedicineLots.LotNumber).LIKE(search),
+				postgres.LOWER(table.PharmaSheetMedicineHouses.MedicationID).LIKE(search),
+				postgres.LOWER(table.PharmaSheetMedicines.MedicalName).LIKE(search),
+				postgres.LOWER(table.PharmaSheetMedicineBrands.TradeID).LIKE(search),
+				postgres.LOWER(table.PharmaSheetMedicineBrands.TradeName).LIKE(search),
+			),
+		)
+	}
+
+	query, args := medicineLotTable().
+		SELECT(postgres.COUNT(table.PharmaSheetMedicineLots.ID).AS("total")).
+		WHERE(condition).
+		Sql()
+	err = r.pgPool.QueryRow(ctx, query, args...).Scan(&total)
+	if err != nil {
+		logger.Context(ctx).Error(err)
+		return
+	}
+
+	if total == 0 {
+		return
+	}
+
+	query, args = medicineLotTable().
+		SELECT(medicineLotColumns).
+		WHERE(condition).
+		ORDER_BY(postgres.Raw(sortBy)).
+		LIMIT(int64(filter.Limit)).
+		OFFSET(int64(filter.Offset)).
+		Sql()
+
+	rows, err := r.pgPool.Query(ctx, query, args...)
+	if err != nil {
+		logger.Context(ctx).Error(err)
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		medicineLot, err := scanMedicineLot(rows)
+		if err != nil {
+			logger.Context(ctx).Error(err)
+			return nil, 0, err
+		}
+		data = append(data, medicineLot)
+	}
+
+	return data, total, nil
+}
+
+// CreateMedicineLot creates the lot and posts its quantity as an ADJUST movement in the same transaction
+func (r *lot) CreateMedicineLot(ctx context.Context, req model.CreateMedicineLotRequest, confirmedBy *uuid.UUID) (string, error) {
+	userID, err := useProfileUserID(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	medicineLots := table.PharmaSheetMedicineLots
+
+	now := time.Now()
+	medicineLot := genmodel.PharmaSheetMedicineLots{
+		ID:           uuid.MustParse(generator.UUID()),
+		HouseID:      req.HouseID,
+		BrandID:      req.BrandID,
+		LotNumber:    req.LotNumber,
+		ExpiryDate:   req.ExpiryDate,
+		Quantity:     0,
+		ReceivedDate: req.ReceivedDate,
+		CreatedAt:    now,
+		UpdatedAt:    now,
+	}
+
+	err = postgresql.Commit(ctx, r.pgPool, func(ctx context.Context, tx pgx.Tx) error {
+		sql, args := medicineLots.
+			INSERT(
+				medicineLots.ID,
+				medicineLots.HouseID,
+				medicineLots.BrandID,
+				medicineLots.LotNumber,
+				medicineLots.ExpiryDate,
+				medicineLots.Quantity,
+				medicineLots.ReceivedDate,
+				medicineLots.CreatedAt,
+				medicineLots.UpdatedAt,
+			).
+			MODEL(medicineLot).
+			Sql()
+		if _, err := tx.Exec(ctx, sql, args...); err != nil {
+			logger.Context(ctx).Error(err)
+			return err
+		}
+
+		entries := lotAdjustments(medicineLot.ID, medicineLot.HouseID, "lot quantity counted", confirmedBy, lotQuantity{BrandID: req.BrandID, Quantity: req.Quantity})
+		_, _, err := r.stock.createStockMovements(ctx, tx, entries, userID)
+		return err
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return medicineLot.ID.String(), nil
+}
+
+// UpdateMedicineLot updates the lot and posts the difference to its counted quantity as an ADJUST movement in the same
+// transaction, a lot moved to another brand is posted out of the old brand and into the new one
+func (r *lot) UpdateMedicineLot(ctx context.Context, req model.UpdateMedicineLotRequest, confirmedBy *uuid.UUID) error {
+	userID, err := useProfileUserID(ctx)
+	if err != nil {
+		return err
+	}
+
+	brandID := postgres.NULL
+	if req.BrandID != nil {
+		brandID = postgres.UUID(*req.BrandID)
+	}
+	receivedDate := postgres.NULL
+	if req.ReceivedDate != nil {
+		receivedDate = postgres.DateT(*req.ReceivedDate)
+	}
+
+	return postgresql.Commit(ctx, r.pgPool, func(ctx context.Context, tx pgx.Tx) error {
+		medicineLot, err := r.lockMedicineLot(ctx, tx, req.ID)
+		if err != nil {
+			return err
+		}
+
+		medicineLots := table.PharmaSheetMedicineLots
+		sql, args := medicineLots.
+			UPDATE(
+				medicineLots.BrandID,
+				medicineLots.LotNumber,
+				medicineLots.ExpiryDate,
+				medicineLots.ReceivedDate,
+				medicineLots.UpdatedAt,
+			).
+			SET(
+				brandID,
+				postgres.String(req.LotNumber),
+				postgres.DateT(req.ExpiryDate),
+				receivedDate,
+				postgres.TimestampzT(time.Now()),
+			).
+			WHERE(medicineLots.ID.EQ(postgres.UUID(req.ID))).
+			Sql()
+		if _, err = tx.Exec(ctx, sql, args...); err != nil {
+			logger.Context(ctx).Error(err)
+			return err
+		}
+
+		quantities := []lotQuantity{{BrandID: req.BrandID, Quantity: req.Quantity - medicineLot.Quantity}}
+		// the balance of the lot is moved along with its brand, it is posted out of the old brand and into the new one
+		if util.Value(req.BrandID) != util.Value(medicineLot.BrandID) {
+			quantities = []lotQuantity{
+				{BrandID: medicineLot.BrandID, Quantity: -medicineLot.Quantity},
+				{BrandID: req.BrandID, Quantity: req.Quantity},
+			}
+		}
+		entries := lotAdjustments(medicineLot.ID, medicineLot.HouseID, "lot quantity counted", confirmedBy, quantities...)
+		_, _, err = r.stock.createStockMovements(ctx, tx, entries, userID)
+		return err
+	})
+}
+
+// DeleteMedicineLot posts what is left in the lot out of the stock as an ADJUST movement and deletes the lot in the same
+// transaction, the movements of the lot stay in the ledger without it
+func (r *lot) DeleteMedicineLot(ctx context.Context, id uuid.UUID, confirmedBy *uuid.UUID) (rowsAffected int64, err error) {
+	userID, err := useProfileUserID(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	err = postgresql.Commit(ctx, r.pgPool, func(ctx context.Context, tx pgx.Tx) error {
+		medicineLot, err := r.lockMedicineLot(ctx, tx, id)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		entries := lotAdjustments(medicineLot.ID, medicineLot.HouseID, "lot deleted", confirmedBy, lotQuantity{BrandID: medicineLot.BrandID, Quantity: -medicineLot.Quantity})
+		if _, _, err = r.stock.createStockMovements(ctx, tx, entries, userID); err != nil {
+			return err
+		}
+
+		stmt, args := table.PharmaSheetMedicineLots.DELETE().WHERE(table.PharmaSheetMedicineLots.ID.EQ(postgres.UUID(id))).Sql()
+		result, err := tx.Exec(ctx, stmt, args...)
+		if err != nil {
+			logger.Context(ctx).Error(err)
+			return err
+		}
+		rowsAffected = result.RowsAffected()
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return rowsAffected, nil
+}
+
+// lockMedicineLot locks the stock of the house of the lot before reading its quantity, which only a movement holding the same lock changes
+func (r *lot) lockMedicineLot(ctx context.Context, tx pgx.Tx, id uuid.UUID) (medicineLot genmodel.PharmaSheetMedicineLots, err error) {
+	lots := table.PharmaSheetMedicineLots
+	query, args := lots.SELECT(lots.HouseID).WHERE(lots.ID.EQ(postgres.UUID(id))).Sql()
+	if err = tx.QueryRow(ctx, query, args...).Scan(&medicineLot.HouseID); err != nil {
+		if !errors.Is(err, pgx.ErrNoRows) {
+			logger.Context(ctx).Error(err)
+		}
+		return medicineLot, err
+	}
+	if err = lockStockHouses(ctx, tx, medicineLot.HouseID); err != nil {
+		return medicineLot, err
+	}
+
+	query, args = lots.SELECT(lots.ID, lots.BrandID, lots.Quantity).WHERE(lots.ID.EQ(postgres.UUID(id))).Sql()
+	if err = tx.QueryRow(ctx, query, args...).Scan(&medicineLot.ID, &medicineLot.BrandID, &medicineLot.Quantity); err != nil {
+		logger.Context(ctx).Error(err)
+		return medicineLot, err
+	}
+	return medicineLot, nil
+}
+
+// lotQuantity is a quantity of a lot posted under a brand
+type lotQuantity struct {
+	BrandID  *uuid.UUID
+	Quantity int32
+}
+
+// lotAdjustments builds the ADJUST movements of a lot, leaving out the ones without a quantity
+func lotAdjustments(lotID, houseID uuid.UUID, reason string, confirmedBy *uuid.UUID, quantities ...lotQuantity) []model.StockMovementEntry {
+	entries := make([]model.StockMovementEntry, 0, len(quantities))
+	for _, quantity := range quantities {
+		if quantity.Quantity == 0 {
+			continue
+		}
+		entries = append(entries, model.StockMovementEntry{
+			HouseID:      houseID,
+			BrandID:      quantity.BrandID,
+			LotID:        &lotID,
+			MovementType: genmodel.PharmaSheetStockMovementType_Adjust,
+			Quantity:     quantity.Quantity,
+			Reason:       &reason,
+			ConfirmedBy:  confirmedBy,
+		})
+	}
+	return entries
+}
@@ -4,16 +4,19 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"slices"
 	"strings"
 	"time"
 
 	"github.com/go-jet/jet/v2/postgres"
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/kinkando/pharma-sheet-service/.gen/pharma_sheet/public/enum"
 	genmodel "github.com/kinkando/pharma-sheet-service/.gen/pharma_sheet/public/model"
 	"github.com/kinkando/pharma-sheet-service/.gen/pharma_sheet/public/table"
 	"github.com/kinkando/pharma-sheet-service/model"
+	"github.com/kinkando/pharma-sheet-service/pkg/database/postgresql"
 	"github.com/kinkando/pharma-sheet-service/pkg/generator"
 	"github.com/kinkando/pharma-sheet-service/pkg/logger"
 	"github.com/kinkando/pharma-sheet-service/pkg/profile"
@@ -25,18 +28,28 @@ type Medicine interface {
 	GetMedicineRole(ctx context.Context, medicationID, userID string) (genmodel.PharmaSheetRole, error)
 	GetMedicine(ctx context.Context, medicationID string) (model.Medicine, error)
 	GetMedicines(ctx context.Context, filter model.FilterMedicine) (data []model.Medicine, total uint64, err error)
-	GetMedicinesPagination(ctx context.Context, filter model.Pagination) (data []model.Medicine, total uint64, err error)
+	GetMedicinesPagination(ctx context.Context, filter model.FilterMedicineMaster) (data []model.Medicine, total uint64, err error)
+	SearchMedicines(ctx context.Context, filter model.FilterMedicine) (data []model.MedicineSearchResult, total uint64, err error)
 	ListMedicines(ctx context.Context, filter model.ListMedicine) ([]model.Medicine, error)
 	ListMedicinesMaster(ctx context.Context) ([]model.Medicine, error)
 	CreateMedicine(ctx context.Context, req model.CreateMedicineRequest) (medicationID string, err error)
 	UpdateMedicine(ctx context.Context, req model.UpdateMedicineRequest) error
+	GetMedicineFlag(ctx context.Context, medicationID string) (model.MedicineFlag, error)
+	ListDeletedMedicationIDs(ctx context.Context, medicationIDs ...string) ([]string, error)
+	UpdateMedicineFlag(ctx context.Context, req model.UpdateMedicineFlagRequest) (int64, error)
 	DeleteMedicine(ctx context.Context, filter model.DeleteMedicineFilter) (int64, error)
+	GetMedicineDeleteImpact(ctx context.Context, medicationID string) (model.MedicineDeleteImpact, error)
+	ForceDeleteMedicine(ctx context.Context, medicationID string) (imageURLs []string, rowsAffected int64, err error)
+	MergeMedicine(ctx context.Context, req model.MergeMedicineRequest, userID *uuid.UUID) (model.MergedMedicine, error)
+	ListMedicineAliases(ctx context.Context) (map[string]string, error)
+	GetMedicineAlias(ctx context.Context, aliasMedicationID string) (string, error)
 
 	GetMedicineHouses(ctx context.Context, filter model.FilterMedicineHouse) ([]model.MedicineHouse, error)
 	ListMedicineHouses(ctx context.Context, filter model.ListMedicineHouse) (data []model.MedicineHouse, total uint64, err error)
 	CreateMedicineHouse(ctx context.Context, req model.CreateMedicineHouseRequest) (string, error)
 	UpdateMedicineHouse(ctx context.Context, req model.UpdateMedicineHouseRequest) error
 	DeleteMedicineHouse(ctx context.Context, filter model.DeleteMedicineHouseFilter) (int64, error)
+	RelocateMedicineHouses(ctx context.Context, relocations []model.HouseRelocation) error
 
 	GetMedicineBrands(ctx context.Context, req model.FilterMedicineBrand) ([]model.MedicineBrand, error)
 	ListMedicineBrands(ctx context.Context) ([]model.MedicineBrand, error)
@@ -50,7 +63,13 @@ type Medicine interface {
 	ListMedicineBlisterChangeDateHistory(ctx context.Context, filter model.FilterMedicineBrandBlisterDateHistory) ([]model.MedicineBlisterDateHistory, error)
 	ListMedicineBlisterChangeDateHistoryPagination(ctx context.Context, filter model.FilterMedicineBlisterDateHistory) (data []model.MedicineBlisterDateHistoryGroup, total uint64, err error)
 	CreateMedicineBlisterChangeDateHistory(ctx context.Context, req model.CreateMedicineBlisterChangeDateHistoryRequest) (string, error)
+	UpdateMedicineBlisterChangeDateHistory(ctx context.Context, req model.UpdateMedicineBlisterChangeDateHistoryRequest) error
 	DeleteMedicineBlisterChangeDateHistory(ctx context.Context, req model.DeleteMedicineBlisterChangeDateHistoryRequest) error
+
+	ListBlisterChangeIntervals(ctx context.Context, warehouseID string) ([]model.BlisterChangeInterval, error)
+	GetBlisterChangeInterval(ctx context.Context, id uuid.UUID) (model.BlisterChangeInterval, error)
+	UpsertBlisterChangeInterval(ctx context.Context, req model.UpsertBlisterChangeIntervalRequest) (string, error)
+	DeleteBlisterChangeInterval(ctx context.Context, id uuid.UUID) (int64, error)
 }
 
 type medicine struct {
@@ -63,7 +82,7 @@ func NewMedicineRepository(pgPool *pgxpool.Pool) Medicine {
 
 func (r *medicine) GetMedicineRole(ctx context.Context, medicationID, userID string) (role genmodel.PharmaSheetRole, err error) {
 	query, args := table.PharmaSheetMedicines.
-		LEFT_JOIN(table.PharmaSheetMedicineHouses, table.PharmaSheetMedicineHouses.MedicationID.EQ(table.PharmaSheetMedicines.MedicationID)).
+		LEFT_JOIN(table.PharmaSheetMedicineHouses, table.PharmaSheetMedicineHouses.MedicationID.EQ(table.PharmaSheetMedicines.MedicationID).AND(table.PharmaSheetMedicineHouses.DeletedAt.IS_NULL())).
 		LEFT_JOIN(table.PharmaSheetWarehouseUsers, table.PharmaSheetWarehouseUsers.WarehouseID.EQ(table.PharmaSheetMedicineHouses.WarehouseID)).
 		SELECT(table.PharmaSheetWarehouseUsers.UserID, table.PharmaSheetWarehouseUsers.Role, table.PharmaSheetWarehouseUsers.Status).
 		WHERE(table.PharmaSheetMedicines.MedicationID.EQ(postgres.String(medicationID))).
@@ -116,10 +135,32 @@ func (r *medicine) GetMedicine(ctx context.Context, medicationID string) (medici
 	}
 
 	query, args := table.PharmaSheetMedicines.
-		SELECT(table.PharmaSheetMedicines.MedicationID, table.PharmaSheetMedicines.MedicalName).
-		WHERE(table.PharmaSheetMedicines.MedicationID.EQ(postgres.String(medicationID))).
+		SELECT(
+			table.PharmaSheetMedicines.MedicationID,
+			table.PharmaSheetMedicines.MedicalName,
+			table.PharmaSheetMedicines.Strength,
+			table.PharmaSheetMedicines.StrengthUnit,
+			table.PharmaSheetMedicines.DosageForm,
+			table.PharmaSheetMedicines.Route,
+			table.PharmaSheetMedicines.PackageUnit,
+			table.PharmaSheetMedicines.IsHighAlert,
+			table.PharmaSheetMedicines.IsControlled,
+			table.PharmaSheetMedicines.IsRefrigerated,
+		).
+		WHERE(table.PharmaSheetMedicines.MedicationID.EQ(postgres.String(medicationID)).AND(table.PharmaSheetMedicines.DeletedAt.IS_NULL())).
 		Sql()
-	err = r.pgPool.QueryRow(ctx, query, args...).Scan(&medicine.MedicationID, &medicine.MedicalName)
+	err = r.pgPool.QueryRow(ctx, query, args...).Scan(
+		&medicine.MedicationID,
+		&medicine.MedicalName,
+		&medicine.Strength,
+		&medicine.StrengthUnit,
+		&medicine.DosageForm,
+		&medicine.Route,
+		&medicine.PackageUnit,
+		&medicine.IsHighAlert,
+		&medicine.IsControlled,
+		&medicine.IsRefrigerated,
+	)
 	if err != nil {
 		logger.Context(ctx).Error(err)
 		return
@@ -140,6 +181,8 @@ func (r *medicine) GetMedicine(ctx context.Context, medicationID string) (medici
 		).
 		WHERE(table.PharmaSheetMedicineHouses.MedicationID.EQ(postgres.String(medicationID)).AND(
 			table.PharmaSheetWarehouseUsers.UserID.EQ(postgres.UUID(uuid.MustParse(userProfile.UserID))).AND(table.PharmaSheetWarehouseUsers.Status.EQ(enum.PharmaSheetApprovalStatus.Approved)),
+		).AND(
+			table.PharmaSheetMedicineHouses.DeletedAt.IS_NULL().AND(table.PharmaSheetWarehouses.DeletedAt.IS_NULL()),
 		)).
 		ORDER_BY(table.PharmaSheetWarehouses.WarehouseID.ASC(), table.PharmaSheetMedicineHouses.Locker.ASC(), table.PharmaSheetMedicineHouses.Floor.ASC(), table.PharmaSheetMedicineHouses.No.ASC()).
 		Sql()
@@ -179,8 +222,9 @@ func (r *medicine) GetMedicine(ctx context.Context, medicationID string) (medici
 			table.PharmaSheetMedicineBrands.BlisterImageURL,
 			table.PharmaSheetMedicineBrands.TabletImageURL,
 			table.PharmaSheetMedicineBrands.BoxImageURL,
+			table.PharmaSheetMedicineBrands.Barcode,
 		).
-		WHERE(table.PharmaSheetMedicineBrands.MedicationID.EQ(postgres.String(medicationID))).
+		WHERE(table.PharmaSheetMedicineBrands.MedicationID.EQ(postgres.String(medicationID)).AND(table.PharmaSheetMedicineBrands.DeletedAt.IS_NULL())).
 		ORDER_BY(table.PharmaSheetMedicineBrands.TradeID.ASC()).
 		Sql()
 	rows, err = r.pgPool.Query(ctx, query, args...)
@@ -201,6 +245,7 @@ func (r *medicine) GetMedicine(ctx context.Context, medicationID string) (medici
 			&medicineBrand.BlisterImageURL,
 			&medicineBrand.TabletImageURL,
 			&medicineBrand.BoxImageURL,
+			&medicineBrand.Barcode,
 		)
 		if err != nil {
 			logger.Context(ctx).Error(err)
@@ -263,6 +308,7 @@ func (r *medicine) GetMedicine(ctx context.Context, medicationID string) (medici
 			BlisterImageURL: medicineBrand.BlisterImageURL,
 			TabletImageURL:  medicineBrand.TabletImageURL,
 			BoxImageURL:     medicineBrand.BoxImageURL,
+			Barcode:         medicineBrand.Barcode,
 		})
 	}
 
@@ -330,25 +376,24 @@ func (r *medicine) GetMedicine(ctx context.Context, medicationID string) (medici
 	return medicine, err
 }
 
+// GetMedicines lists the medicines in the requested order, a search is ranked by relevance instead.
 func (r *medicine) GetMedicines(ctx context.Context, filter model.FilterMedicine) (sortedData []model.Medicine, total uint64, err error) {
-	condition := postgres.Bool(true)
-	if filter.WarehouseID != "" {
-		condition = condition.AND(table.PharmaSheetMedicineHouses.ID.IS_NOT_NULL().AND(table.PharmaSheetMedicineHouses.WarehouseID.EQ(postgres.String(filter.WarehouseID))))
+	if strings.TrimSpace(filter.Search) != "" {
+		results, total, err := r.SearchMedicines(ctx, filter)
+		if err != nil {
+			return nil, 0, err
+		}
+		for _, result := range results {
+			sortedData = append(sortedData, result.Medicine)
+		}
+		return sortedData, total, nil
 	}
 
-	if search := strings.TrimSpace(filter.Search); search != "" {
-		search := postgres.String("%" + strings.ToLower(search) + "%")
-		address := postgres.CONCAT(table.PharmaSheetMedicineHouses.Locker, postgres.String("-"), table.PharmaSheetMedicineHouses.Floor, postgres.String("-"), table.PharmaSheetMedicineHouses.No)
-		condition = condition.AND(
-			postgres.OR(
-				postgres.LOWER(table.PharmaSheetMedicineBrands.TradeID).LIKE(search),
-				postgres.LOWER(table.PharmaSheetMedicineBrands.TradeName).LIKE(search),
-				postgres.LOWER(table.PharmaSheetMedicines.MedicalName).LIKE(search),
-				postgres.LOWER(table.PharmaSheetMedicines.MedicationID).LIKE(search),
-				postgres.LOWER(table.PharmaSheetMedicineHouses.Label).LIKE(search),
-				postgres.LOWER(address).LIKE(search),
-			),
-		)
+	condition := table.PharmaSheetMedicines.DeletedAt.IS_NULL().
+		AND(medicineAttributeCondition(filter.FilterMedicineAttribute)).
+		AND(medicineCategoryCondition(table.PharmaSheetMedicines.MedicationID, filter.FilterMedicineCategory))
+	if filter.WarehouseID != "" {
+		condition = condition.AND(table.PharmaSheetMedicineHouses.ID.IS_NOT_NULL().AND(table.PharmaSheetMedicineHouses.WarehouseID.EQ(postgres.String(filter.WarehouseID))))
 	}
 
 	sortBy := filter.SortBy("medical_name ASC")
@@ -362,8 +407,8 @@ func (r *medicine) GetMedicines(ctx context.Context, filter model.FilterMedicine
 	}
 
 	query, args := table.PharmaSheetMedicines.
-		LEFT_JOIN(table.PharmaSheetMedicineHouses, table.PharmaSheetMedicines.MedicationID.EQ(table.PharmaSheetMedicineHouses.MedicationID)).
-		LEFT_JOIN(table.PharmaSheetMedicineBrands, table.PharmaSheetMedicines.MedicationID.EQ(table.PharmaSheetMedicineBrands.MedicationID)).
+		LEFT_JOIN(table.PharmaSheetMedicineHouses, table.PharmaSheetMedicines.MedicationID.EQ(table.PharmaSheetMedicineHouses.MedicationID).AND(table.PharmaSheetMedicineHouses.DeletedAt.IS_NULL())).
+		LEFT_JOIN(table.PharmaSheetMedicineBrands, table.PharmaSheetMedicines.MedicationID.EQ(table.PharmaSheetMedicineBrands.MedicationID).AND(table.PharmaSheetMedicineBrands.DeletedAt.IS_NULL())).
 		SELECT(postgres.COUNT(postgres.DISTINCT(table.PharmaSheetMedicines.MedicationID)).AS("total")).
 		WHERE(condition).
 		Sql()
@@ -378,8 +423,8 @@ func (r *medicine) GetMedicines(ctx context.Context, filter model.FilterMedicine
 	}
 
 	query, args = table.PharmaSheetMedicines.
-		LEFT_JOIN(table.PharmaSheetMedicineHouses, table.PharmaSheetMedicines.MedicationID.EQ(table.PharmaSheetMedicineHouses.MedicationID)).
-		LEFT_JOIN(table.PharmaSheetMedicineBrands, table.PharmaSheetMedicines.MedicationID.EQ(table.PharmaSheetMedicineBrands.MedicationID)).
+		LEFT_JOIN(table.PharmaSheetMedicineHouses, table.PharmaSheetMedicines.MedicationID.EQ(table.PharmaSheetMedicineHouses.MedicationID).AND(table.PharmaSheetMedicineHouses.DeletedAt.IS_NULL())).
+		LEFT_JOIN(table.PharmaSheetMedicineBrands, table.PharmaSheetMedicines.MedicationID.EQ(table.PharmaSheetMedicineBrands.MedicationID).AND(table.PharmaSheetMedicineBrands.DeletedAt.IS_NULL())).
 		SELECT(
 			table.PharmaSheetMedicines.MedicationID,
 			table.PharmaSheetMedicines.MedicalName,
@@ -463,16 +508,7 @@ func (r *medicine) GetMedicines(ctx context.Context, filter model.FilterMedicine
 	return sortedData, total, nil
 }
 
-func (r *medicine) GetMedicinesPagination(ctx context.Context, filter model.Pagination) (data []model.Medicine, total uint64, err error) {
-	condition := postgres.Bool(true)
-	if search := strings.TrimSpace(filter.Search); search != "" {
-		search := postgres.String("%" + strings.ToLower(search) + "%")
-		condition = postgres.OR(
-			postgres.LOWER(table.PharmaSheetMedicines.MedicalName).LIKE(search),
-			postgres.LOWER(table.PharmaSheetMedicines.MedicationID).LIKE(search),
-		)
-	}
-
+func (r *medicine) GetMedicinesPagination(ctx context.Context, filter model.FilterMedicineMaster) (data []model.Medicine, total uint64, err error) {
 	sortBy := filter.SortBy("medication_id ASC")
 	sorts := strings.Split(sortBy, " ")
 	order := sorts[1]
@@ -480,6 +516,22 @@ func (r *medicine) GetMedicinesPagination(ctx context.Context, filter model.Pagi
 	case "medication_id":
 		sortBy = fmt.Sprintf("%s.medication_id %s", table.PharmaSheetMedicines.TableName(), order)
 	}
+	orderBy := []postgres.OrderByClause{postgres.Raw(sortBy)}
+
+	condition := table.PharmaSheetMedicines.DeletedAt.IS_NULL().AND(medicineAttributeCondition(filter.FilterMedicineAttribute))
+	if search := strings.ToLower(strings.TrimSpace(filter.Search)); search != "" {
+		condition = condition.AND(postgres.OR(
+			searchMatch(table.PharmaSheetMedicines.MedicalName, search),
+			searchMatch(table.PharmaSheetMedicines.MedicationID, search),
+		))
+		if filter.Sort == nil || *filter.Sort == "" {
+			score := postgres.GREATEST(
+				searchScore(table.PharmaSheetMedicines.MedicalName, search),
+				searchScore(table.PharmaSheetMedicines.MedicationID, search),
+			)
+			orderBy = append([]postgres.OrderByClause{postgres.FloatExp(score).DESC()}, orderBy...)
+		}
+	}
 
 	query, args := table.PharmaSheetMedicines.
 		SELECT(postgres.COUNT(table.PharmaSheetMedicines.MedicationID)).
@@ -496,11 +548,22 @@ func (r *medicine) GetMedicinesPagination(ctx context.Context, filter model.Pagi
 	}
 
 	query, args = table.PharmaSheetMedicines.
-		SELECT(table.PharmaSheetMedicines.MedicationID, table.PharmaSheetMedicines.MedicalName).
+		SELECT(
+			table.PharmaSheetMedicines.MedicationID,
+			table.PharmaSheetMedicines.MedicalName,
+			table.PharmaSheetMedicines.Strength,
+			table.PharmaSheetMedicines.StrengthUnit,
+			table.PharmaSheetMedicines.DosageForm,
+			table.PharmaSheetMedicines.Route,
+			table.PharmaSheetMedicines.PackageUnit,
+			table.PharmaSheetMedicines.IsHighAlert,
+			table.PharmaSheetMedicines.IsControlled,
+			table.PharmaSheetMedicines.IsRefrigerated,
+		).
 		WHERE(condition).
 		LIMIT(int64(filter.Limit)).
 		OFFSET(int64(filter.Offset)).
-		ORDER_BY(postgres.Raw(sortBy)).
+		ORDER_BY(orderBy...).
 		Sql()
 
 	rows, err := r.pgPool.Query(ctx, query, args...)
@@ -512,74 +575,1096 @@ func (r *medicine) GetMedicinesPagination(ctx context.Context, filter model.Pagi
 
 	for rows.Next() {
 		var medicine model.Medicine
-		err = rows.Scan(&medicine.MedicationID, &medicine.MedicalName)
+		err = rows.Scan(
+			&medicine.MedicationID,
+			&medicine.MedicalName,
+			&medicine.Strength,
+			&medicine.StrengthUnit,
+			&medicine.DosageForm,
+			&medicine.Route,
+			&medicine.PackageUnit,
+			&medicine.IsHighAlert,
+			&medicine.IsControlled,
+			&medicine.IsRefrigerated,
+		)
+		if err != nil {
+			logger.Context(ctx).Error(err)
+			return nil, 0, err
+		}
+		data = append(data, medicine)
+	}
+
+	return data, total, nil
+}
+
+// SearchMedicines ranks medicines by the trigram similarity of their names, trade ids, house labels and addresses,
+// houses only match inside the warehouse of the filter or the warehouses the user belongs to.
+func (r *medicine) SearchMedicines(ctx context.Context, filter model.FilterMedicine) (data []model.MedicineSearchResult, total uint64, err error) {
+	userProfile, err := profile.UseProfile(ctx)
+	if err != nil {
+		return
+	}
+
+	search := strings.ToLower(strings.TrimSpace(filter.Search))
+	medicines := table.PharmaSheetMedicines
+	brands := table.PharmaSheetMedicineBrands
+	houses := table.PharmaSheetMedicineHouses
+	warehouseUsers := table.PharmaSheetWarehouseUsers
+
+	houseCondition := houses.WarehouseID.IN(
+		warehouseUsers.
+			SELECT(warehouseUsers.WarehouseID).
+			WHERE(warehouseUsers.UserID.EQ(postgres.UUID(uuid.MustParse(userProfile.UserID))).AND(warehouseUsers.Status.EQ(enum.PharmaSheetApprovalStatus.Approved))),
+	).AND(houses.WarehouseID.IN(activeWarehouseIDs()))
+	if filter.WarehouseID != "" {
+		houseCondition = houses.WarehouseID.EQ(postgres.String(filter.WarehouseID))
+	}
+	houseCondition = houseCondition.AND(houses.DeletedAt.IS_NULL())
+	address := medicineHouseAddress()
+
+	matches := postgres.UNION(
+		medicineSearchSelect(medicines, medicines.MedicationID, "medicationID", medicines.MedicationID, search, medicines.DeletedAt.IS_NULL()),
+		medicineSearchSelect(medicines, medicines.MedicationID, "medicalName", medicines.MedicalName, search, medicines.DeletedAt.IS_NULL()),
+		medicineSearchSelect(brands, brands.MedicationID, "tradeID", brands.TradeID, search, brands.DeletedAt.IS_NULL()),
+		medicineSearchSelect(brands, brands.MedicationID, "tradeName", brands.TradeName, search, brands.DeletedAt.IS_NULL()),
+		medicineSearchSelect(houses, houses.MedicationID, "label", houses.Label, search, houseCondition),
+		medicineSearchSelect(houses, houses.MedicationID, "address", address, search, houseCondition),
+	).AsTable("matches")
+	matchMedicationID := postgres.StringColumn("medication_id").From(matches)
+	matchField := postgres.StringColumn("field").From(matches)
+	matchValue := postgres.StringColumn("value").From(matches)
+	matchScore := postgres.FloatColumn("score").From(matches)
+
+	condition := matchMedicationID.IN(
+		medicines.SELECT(medicines.MedicationID).WHERE(medicines.DeletedAt.IS_NULL().AND(medicineAttributeCondition(filter.FilterMedicineAttribute))),
+	)
+	if filter.WarehouseID != "" {
+		condition = condition.AND(matchMedicationID.IN(
+			houses.SELECT(houses.MedicationID).WHERE(houses.WarehouseID.EQ(postgres.String(filter.WarehouseID)).AND(houses.DeletedAt.IS_NULL())),
+		))
+	}
+	condition = condition.AND(medicineCategoryCondition(matchMedicationID, filter.FilterMedicineCategory))
+
+	query, args := matches.
+		SELECT(postgres.COUNT(postgres.DISTINCT(matchMedicationID)).AS("total")).
+		WHERE(condition).
+		Sql()
+	err = r.pgPool.QueryRow(ctx, query, args...).Scan(&total)
+	if err != nil {
+		logger.Context(ctx).Error(err)
+		return
+	}
+
+	if total == 0 {
+		return
+	}
+
+	query, args = matches.
+		SELECT(matchMedicationID, postgres.MAXf(matchScore).AS("score")).
+		WHERE(condition).
+		GROUP_BY(matchMedicationID).
+		ORDER_BY(postgres.MAXf(matchScore).DESC(), matchMedicationID.ASC()).
+		LIMIT(int64(filter.Limit)).
+		OFFSET(int64(filter.Offset)).
+		Sql()
+	rows, err := r.pgPool.Query(ctx, query, args...)
+	if err != nil {
+		logger.Context(ctx).Error(err)
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	var medicationIDs []postgres.Expression
+	for rows.Next() {
+		var result model.MedicineSearchResult
+		err = rows.Scan(&result.MedicationID, &result.Score)
+		if err != nil {
+			logger.Context(ctx).Error(err)
+			return nil, 0, err
+		}
+		data = append(data, result)
+		medicationIDs = append(medicationIDs, postgres.String(result.MedicationID))
+	}
+
+	if len(data) == 0 {
+		return data, total, nil
+	}
+
+	query, args = matches.
+		SELECT(matchMedicationID, matchField, matchValue, matchScore).
+		WHERE(matchMedicationID.IN(medicationIDs...)).
+		ORDER_BY(matchScore.DESC(), matchField.ASC(), matchValue.ASC()).
+		Sql()
+	rows, err = r.pgPool.Query(ctx, query, args...)
+	if err != nil {
+		logger.Context(ctx).Error(err)
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var medicationID string
+		var highlight model.MedicineSearchHighlight
+		err = rows.Scan(&medicationID, &highlight.Field, &highlight.Value, &highlight.Score)
+		if err != nil {
+			logger.Context(ctx).Error(err)
+			return nil, 0, err
+		}
+		for index := range data {
+			if data[index].MedicationID == medicationID {
+				data[index].Highlights = append(data[index].Highlights, highlight)
+				break
+			}
+		}
+	}
+
+	conc := pool.New().WithContext(ctx).WithMaxGoroutines(10)
+	for index := range data {
+		conc.Go(func(ctx context.Context) error {
+			medicine, err := r.GetMedicine(ctx, data[index].MedicationID)
+			if err != nil {
+				logger.Context(ctx).Error(err)
+				return err
+			}
+			data[index].Medicine = medicine
+			return nil
+		})
+	}
+	if err := conc.Wait(); err != nil {
+		return nil, 0, err
+	}
+
+	return data, total, nil
+}
+
+// medicineAttributeCondition filters the medicines by their master attributes, it matches every medicine when the filter is empty
+func medicineAttributeCondition(filter model.FilterMedicineAttribute) postgres.BoolExpression {
+	medicines := table.PharmaSheetMedicines
+	condition := postgres.Bool(true)
+	if filter.Strength > 0 {
+		condition = condition.AND(medicines.Strength.EQ(postgres.Float(filter.Strength)))
+	}
+	if filter.StrengthUnit != "" {
+		condition = condition.AND(medicines.StrengthUnit.EQ(postgres.String(filter.StrengthUnit)))
+	}
+	if filter.DosageForm != "" {
+		condition = condition.AND(medicines.DosageForm.EQ(postgres.NewEnumValue(filter.DosageForm)))
+	}
+	if filter.Route != "" {
+		condition = condition.AND(medicines.Route.EQ(postgres.NewEnumValue(filter.Route)))
+	}
+	return condition
+}
+
+// medicineSearchSelect selects the medicines of a table whose column matches the search as a single field
+func medicineSearchSelect(from postgres.ReadableTable, medicationID postgres.StringExpression, field string, column postgres.StringExpression, search string, condition postgres.BoolExpression) postgres.SelectStatement {
+	where := searchMatch(column, search)
+	if condition != nil {
+		where = where.AND(condition)
+	}
+	return from.
+		SELECT(
+			medicationID.AS("medication_id"),
+			postgres.String(field).AS("field"),
+			column.AS("value"),
+			searchScore(column, search).AS("score"),
+		).
+		WHERE(where)
+}
+
+// medicineHouseAddress is the same expression as the address indexes, bind parameters would prevent the planner from using them
+func medicineHouseAddress() postgres.StringExpression {
+	return postgres.RawString(fmt.Sprintf("(%[1]s.locker || '-' || %[1]s.floor::text || '-' || %[1]s.no::text)", table.PharmaSheetMedicineHouses.TableName()))
+}
+
+// searchMatch matches a substring of the column or a word similar to the search, both are served by the trigram indexes
+func searchMatch(column postgres.StringExpression, search string) postgres.BoolExpression {
+	value := postgres.LOWER(column)
+	return postgres.OR(
+		value.LIKE(postgres.String("%"+search+"%")),
+		postgres.BoolExp(postgres.BinaryOperator(postgres.String(search), value, "<%")),
+	)
+}
+
+// searchScore ranks an exact substring first, then by the word similarity of the search
+func searchScore(column postgres.StringExpression, search string) postgres.FloatExpression {
+	value := postgres.LOWER(column)
+	return postgres.FloatExp(postgres.GREATEST(
+		postgres.FloatExp(postgres.Func("word_similarity", postgres.String(search), value)),
+		postgres.CASE().WHEN(value.LIKE(postgres.String("%"+search+"%"))).THEN(postgres.Float(1)).ELSE(postgres.Float(0)),
+	))
+}
+
+func (r *medicine) ListMedicines(ctx context.Context, filter model.ListMedicine) (data []model.Medicine, err error) {
+	var condition postgres.BoolExpression
+	if filter.WarehouseID != "" {
+		condition = table.PharmaSheetMedicineHouses.WarehouseID.EQ(postgres.String(filter.WarehouseID)).AND(table.PharmaSheetMedicineHouses.DeletedAt.IS_NULL())
+	} else {
+		return nil, errors.New("filter is invalid")
+	}
+
+	query, args := table.PharmaSheetMedicineHouses.SELECT(postgres.DISTINCT(table.PharmaSheetMedicineHouses.MedicationID)).WHERE(condition).Sql()
+	rows, err := r.pgPool.Query(ctx, query, args...)
+	if err != nil {
+		logger.Context(ctx).Error(err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	var medicationIDs []string
+	for rows.Next() {
+		var medicationID string
+		err = rows.Scan(&medicationID)
+		if err != nil {
+			logger.Context(ctx).Error(err)
+			return nil, err
+		}
+		medicationIDs = append(medicationIDs, medicationID)
+	}
+
+	conc := pool.New().WithContext(ctx).WithMaxGoroutines(10)
+	for _, medicationID := range medicationIDs {
+		medicationID := medicationID
+		conc.Go(func(ctx context.Context) error {
+			medicine, err := r.GetMedicine(ctx, medicationID)
+			if err != nil {
+				logger.Context(ctx).Error(err)
+				return err
+			}
+			data = append(data, medicine)
+			return nil
+		})
+	}
+	if err := conc.Wait(); err != nil {
+		return nil, err
+	}
+
+	if len(data) != len(medicationIDs) {
+		return nil, errors.New("data length mismatch")
+	}
+
+	return data, nil
+}
+
+func (r *medicine) ListMedicinesMaster(ctx context.Context) ([]model.Medicine, error) {
+	query, args := table.PharmaSheetMedicines.
+		SELECT(
+			table.PharmaSheetMedicines.MedicationID,
+			table.PharmaSheetMedicines.MedicalName,
+			table.PharmaSheetMedicines.Strength,
+			table.PharmaSheetMedicines.StrengthUnit,
+			table.PharmaSheetMedicines.DosageForm,
+			table.PharmaSheetMedicines.Route,
+			table.PharmaSheetMedicines.PackageUnit,
+			table.PharmaSheetMedicines.IsHighAlert,
+			table.PharmaSheetMedicines.IsControlled,
+			table.PharmaSheetMedicines.IsRefrigerated,
+		).
+		WHERE(table.PharmaSheetMedicines.DeletedAt.IS_NULL()).
+		ORDER_BY(table.PharmaSheetMedicines.MedicationID.ASC()).
+		Sql()
+
+	rows, err := r.pgPool.Query(ctx, query, args...)
+	if err != nil {
+		logger.Context(ctx).Error(err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	var medicines []model.Medicine
+	for rows.Next() {
+		var medicine model.Medicine
+		err = rows.Scan(
+			&medicine.MedicationID,
+			&medicine.MedicalName,
+			&medicine.Strength,
+			&medicine.StrengthUnit,
+			&medicine.DosageForm,
+			&medicine.Route,
+			&medicine.PackageUnit,
+			&medicine.IsHighAlert,
+			&medicine.IsControlled,
+			&medicine.IsRefrigerated,
+		)
+		if err != nil {
+			logger.Context(ctx).Error(err)
+			return nil, err
+		}
+		medicines = append(medicines, medicine)
+	}
+
+	return medicines, nil
+}
+
+func (r *medicine) CreateMedicine(ctx context.Context, req model.CreateMedicineRequest) (medicationID string, err error) {
+	medicines := table.PharmaSheetMedicines
+
+	if req.MedicalName == nil || *req.MedicalName == "" {
+		req.MedicalName = &req.MedicationID
+	}
+
+	now := time.Now()
+	medicine := genmodel.PharmaSheetMedicines{
+		MedicationID: req.MedicationID,
+		MedicalName:  *req.MedicalName,
+		CreatedAt:    now,
+		UpdatedAt:    now,
+		Strength:     req.Strength,
+		StrengthUnit: req.StrengthUnit,
+		DosageForm:   req.DosageForm,
+		Route:        req.Route,
+		PackageUnit:  req.PackageUnit,
+	}
+
+	sql, args := medicines.
+		INSERT(
+			medicines.MedicationID,
+			medicines.MedicalName,
+			medicines.CreatedAt,
+			medicines.UpdatedAt,
+			medicines.Strength,
+			medicines.StrengthUnit,
+			medicines.DosageForm,
+			medicines.Route,
+			medicines.PackageUnit,
+		).
+		MODEL(medicine).
+		Sql()
+
+	_, err = r.pgPool.Exec(ctx, sql, args...)
+	if err != nil {
+		logger.Context(ctx).Error(err)
+		return "", err
+	}
+
+	return req.MedicationID, nil
+}
+
+func (r *medicine) UpdateMedicine(ctx context.Context, req model.UpdateMedicineRequest) error {
+	medicines := table.PharmaSheetMedicines
+
+	if req.MedicalName == nil || *req.MedicalName == "" {
+		req.MedicalName = &req.MedicationID
+	}
+
+	sql, args := medicines.
+		UPDATE(
+			medicines.MedicalName,
+			medicines.UpdatedAt,
+			medicines.Strength,
+			medicines.StrengthUnit,
+			medicines.DosageForm,
+			medicines.Route,
+			medicines.PackageUnit,
+		).
+		MODEL(genmodel.PharmaSheetMedicines{
+			MedicalName:  *req.MedicalName,
+			UpdatedAt:    time.Now(),
+			Strength:     req.Strength,
+			StrengthUnit: req.StrengthUnit,
+			DosageForm:   req.DosageForm,
+			Route:        req.Route,
+			PackageUnit:  req.PackageUnit,
+		}).
+		WHERE(medicines.MedicationID.EQ(postgres.String(req.MedicationID)).AND(medicines.DeletedAt.IS_NULL())).
+		Sql()
+	_, err := r.pgPool.Exec(ctx, sql, args...)
+	if err != nil {
+		logger.Context(ctx).Error(err)
+		return err
+	}
+
+	return nil
+}
+
+func (r *medicine) GetMedicineFlag(ctx context.Context, medicationID string) (flag model.MedicineFlag, err error) {
+	medicines := table.PharmaSheetMedicines
+	query, args := medicines.
+		SELECT(medicines.IsHighAlert, medicines.IsControlled, medicines.IsRefrigerated).
+		WHERE(medicines.MedicationID.EQ(postgres.String(medicationID)).AND(medicines.DeletedAt.IS_NULL())).
+		Sql()
+	err = r.pgPool.QueryRow(ctx, query, args...).Scan(&flag.IsHighAlert, &flag.IsControlled, &flag.IsRefrigerated)
+	if err != nil {
+		logger.Context(ctx).Error(err)
+		return
+	}
+	return flag, nil
+}
+
+// ListDeletedMedicationIDs returns which of the medicines are in the trash, their ids stay taken until they are purged
+func (r *medicine) ListDeletedMedicationIDs(ctx context.Context, medicationIDs ...string) ([]string, error) {
+	if len(medicationIDs) == 0 {
+		return nil, nil
+	}
+
+	medicines := table.PharmaSheetMedicines
+	ids := make([]postgres.Expression, 0, len(medicationIDs))
+	for _, medicationID := range medicationIDs {
+		ids = append(ids, postgres.String(medicationID))
+	}
+	query, args := medicines.
+		SELECT(medicines.MedicationID).
+		WHERE(medicines.MedicationID.IN(ids...).AND(medicines.DeletedAt.IS_NOT_NULL())).
+		Sql()
+	rows, err := r.pgPool.Query(ctx, query, args...)
+	if err != nil {
+		logger.Context(ctx).Error(err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	var deletedIDs []string
+	for rows.Next() {
+		var medicationID string
+		if err = rows.Scan(&medicationID); err != nil {
+			logger.Context(ctx).Error(err)
+			return nil, err
+		}
+		deletedIDs = append(deletedIDs, medicationID)
+	}
+	return deletedIDs, nil
+}
+
+func (r *medicine) UpdateMedicineFlag(ctx context.Context, req model.UpdateMedicineFlagRequest) (int64, error) {
+	medicines := table.PharmaSheetMedicines
+	sql, args := medicines.
+		UPDATE(medicines.IsHighAlert, medicines.IsControlled, medicines.IsRefrigerated, medicines.UpdatedAt).
+		MODEL(genmodel.PharmaSheetMedicines{
+			IsHighAlert:    req.IsHighAlert,
+			IsControlled:   req.IsControlled,
+			IsRefrigerated: req.IsRefrigerated,
+			UpdatedAt:      time.Now(),
+		}).
+		WHERE(medicines.MedicationID.EQ(postgres.String(req.MedicationID)).AND(medicines.DeletedAt.IS_NULL())).
+		Sql()
+	result, err := r.pgPool.Exec(ctx, sql, args...)
+	if err != nil {
+		logger.Context(ctx).Error(err)
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+// DeleteMedicine moves the medicines to the trash, they are purged after the retention period
+func (r *medicine) DeleteMedicine(ctx context.Context, filter model.DeleteMedicineFilter) (int64, error) {
+	var condition postgres.BoolExpression
+	if filter.MedicationID != "" {
+		condition = table.PharmaSheetMedicines.MedicationID.EQ(postgres.String(filter.MedicationID))
+	} else if filter.WarehouseID != "" {
+		condition = table.PharmaSheetMedicines.MedicationID.IN(
+			table.PharmaSheetMedicineHouses.
+				SELECT(table.PharmaSheetMedicineHouses.MedicationID).
+				WHERE(table.PharmaSheetMedicineHouses.WarehouseID.EQ(postgres.String(filter.WarehouseID))),
+		)
+	} else {
+		return 0, errors.New("filter is invalid")
+	}
+	stmt, args := table.PharmaSheetMedicines.
+		UPDATE(table.PharmaSheetMedicines.DeletedAt).
+		SET(postgres.TimestampzT(time.Now())).
+		WHERE(condition.AND(table.PharmaSheetMedicines.DeletedAt.IS_NULL())).
+		Sql()
+	result, err := r.pgPool.Exec(ctx, stmt, args...)
+	if err != nil {
+		logger.Context(ctx).Error(err)
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+func (r *medicine) GetMedicineDeleteImpact(ctx context.Context, medicationID string) (impact model.MedicineDeleteImpact, err error) {
+	brands := table.PharmaSheetMedicineBrands
+	houses := table.PharmaSheetMedicineHouses
+	histories := table.PharmaSheetMedicineBlisterDateHistories
+	lots := table.PharmaSheetMedicineLots
+	movements := table.PharmaSheetStockMovements
+	levels := table.PharmaSheetStockLevels
+	transfers := table.PharmaSheetMedicineHouseTransfers
+	impact = model.MedicineDeleteImpact{MedicationID: medicationID, Warehouses: make([]model.WarehouseDeleteImpact, 0)}
+	medication := postgres.String(medicationID)
+
+	query, args := brands.
+		SELECT(
+			postgres.COUNT(brands.ID),
+			postgres.COUNT(brands.BlisterImageURL).ADD(postgres.COUNT(brands.TabletImageURL)).ADD(postgres.COUNT(brands.BoxImageURL)),
+		).
+		WHERE(brands.MedicationID.EQ(postgres.String(medicationID))).
+		Sql()
+	if err = r.pgPool.QueryRow(ctx, query, args...).Scan(&impact.Brands, &impact.Images); err != nil {
+		logger.Context(ctx).Error(err)
+		return
+	}
+
+	query, args = transfers.SELECT(postgres.COUNT(transfers.ID)).WHERE(transfers.MedicationID.EQ(medication)).Sql()
+	if err = r.pgPool.QueryRow(ctx, query, args...).Scan(&impact.Transfers); err != nil {
+		logger.Context(ctx).Error(err)
+		return
+	}
+
+	warehouses := make(map[string]*model.WarehouseDeleteImpact)
+	for _, count := range []struct {
+		stmt  postgres.SelectStatement
+		total func(*model.WarehouseDeleteImpact) *int64
+	}{
+		{
+			houses.SELECT(houses.WarehouseID, postgres.COUNT(houses.ID)).WHERE(houses.MedicationID.EQ(postgres.String(medicationID))).GROUP_BY(houses.WarehouseID),
+			func(warehouse *model.WarehouseDeleteImpact) *int64 { return &warehouse.Houses },
+		},
+		{
+			histories.SELECT(histories.WarehouseID, postgres.COUNT(histories.ID)).WHERE(histories.MedicationID.EQ(postgres.String(medicationID))).GROUP_BY(histories.WarehouseID),
+			func(warehouse *model.WarehouseDeleteImpact) *int64 { return &warehouse.Histories },
+		},
+		{
+			lots.INNER_JOIN(houses, houses.ID.EQ(lots.HouseID)).SELECT(houses.WarehouseID, postgres.COUNT(lots.ID)).WHERE(houses.MedicationID.EQ(medication)).GROUP_BY(houses.WarehouseID),
+			func(warehouse *model.WarehouseDeleteImpact) *int64 { return &warehouse.Lots },
+		},
+		{
+			movements.INNER_JOIN(houses, houses.ID.EQ(movements.HouseID)).SELECT(houses.WarehouseID, postgres.COUNT(movements.ID)).WHERE(houses.MedicationID.EQ(medication)).GROUP_BY(houses.WarehouseID),
+			func(warehouse *model.WarehouseDeleteImpact) *int64 { return &warehouse.StockMovements },
+		},
+		{
+			levels.SELECT(levels.WarehouseID, postgres.COUNT(levels.ID)).WHERE(levels.MedicationID.EQ(medication)).GROUP_BY(levels.WarehouseID),
+			func(warehouse *model.WarehouseDeleteImpact) *int64 { return &warehouse.StockLevels },
+		},
+		{
+			transfers.SELECT(transfers.SourceWarehouseID, postgres.COUNT(transfers.ID)).WHERE(transfers.MedicationID.EQ(medication)).GROUP_BY(transfers.SourceWarehouseID),
+			func(warehouse *model.WarehouseDeleteImpact) *int64 { return &warehouse.Transfers },
+		},
+		{
+			transfers.SELECT(transfers.TargetWarehouseID, postgres.COUNT(transfers.ID)).
+				WHERE(transfers.MedicationID.EQ(medication).AND(transfers.TargetWarehouseID.NOT_EQ(transfers.SourceWarehouseID))).
+				GROUP_BY(transfers.TargetWarehouseID),
+			func(warehouse *model.WarehouseDeleteImpact) *int64 { return &warehouse.Transfers },
+		},
+	} {
+		query, args := count.stmt.Sql()
+		rows, err := r.pgPool.Query(ctx, query, args...)
 		if err != nil {
+			logger.Context(ctx).Error(err)
+			return impact, err
+		}
+		for rows.Next() {
+			var warehouseID string
+			var total int64
+			if err = rows.Scan(&warehouseID, &total); err != nil {
+				rows.Close()
+				logger.Context(ctx).Error(err)
+				return impact, err
+			}
+			if _, ok := warehouses[warehouseID]; !ok {
+				warehouses[warehouseID] = &model.WarehouseDeleteImpact{WarehouseID: warehouseID}
+			}
+			*count.total(warehouses[warehouseID]) += total
+		}
+		rows.Close()
+		if err = rows.Err(); err != nil {
+			logger.Context(ctx).Error(err)
+			return impact, err
+		}
+	}
+
+	for _, warehouse := range warehouses {
+		impact.Houses += warehouse.Houses
+		impact.Histories += warehouse.Histories
+		impact.Lots += warehouse.Lots
+		impact.StockMovements += warehouse.StockMovements
+		impact.StockLevels += warehouse.StockLevels
+		impact.Warehouses = append(impact.Warehouses, *warehouse)
+	}
+	slices.SortFunc(impact.Warehouses, func(a, b model.WarehouseDeleteImpact) int { return strings.Compare(a.WarehouseID, b.WarehouseID) })
+
+	return impact, nil
+}
+
+// deleteMedicineBrands hard-deletes the brands matching the condition and returns the image urls left to remove from the storage.
+func deleteMedicineBrands(ctx context.Context, tx pgx.Tx, condition postgres.BoolExpression) (imageURLs []string, rowsAffected int64, err error) {
+	brands := table.PharmaSheetMedicineBrands
+	sql, args := brands.
+		DELETE().
+		WHERE(condition).
+		RETURNING(brands.BlisterImageURL, brands.TabletImageURL, brands.BoxImageURL).
+		Sql()
+	rows, err := tx.Query(ctx, sql, args...)
+	if err != nil {
+		logger.Context(ctx).Error(err)
+		return nil, 0, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var blisterImageURL, tabletImageURL, boxImageURL *string
+		if err = rows.Scan(&blisterImageURL, &tabletImageURL, &boxImageURL); err != nil {
 			logger.Context(ctx).Error(err)
 			return nil, 0, err
 		}
-		data = append(data, medicine)
+		for _, url := range []*string{blisterImageURL, tabletImageURL, boxImageURL} {
+			if url != nil {
+				imageURLs = append(imageURLs, *url)
+			}
+		}
+		rowsAffected++
+	}
+	if err = rows.Err(); err != nil {
+		logger.Context(ctx).Error(err)
+		return nil, 0, err
+	}
+	return imageURLs, rowsAffected, nil
+}
+
+// ForceDeleteMedicine deletes the medicine permanently, its brands, houses, histories and the rows depending on them
+// are deleted by the cascade of the foreign keys. The image files of the brands are left for the caller to delete.
+// The stock movements restrict the delete, a medicine with any of them is refused by the database.
+func (r *medicine) ForceDeleteMedicine(ctx context.Context, medicationID string) (imageURLs []string, rowsAffected int64, err error) {
+	brands := table.PharmaSheetMedicineBrands
+	medicines := table.PharmaSheetMedicines
+
+	err = postgresql.Commit(ctx, r.pgPool, func(ctx context.Context, tx pgx.Tx) error {
+		imageURLs, _, err = deleteMedicineBrands(ctx, tx, brands.MedicationID.EQ(postgres.String(medicationID)))
+		if err != nil {
+			return err
+		}
+
+		sql, args := medicines.DELETE().WHERE(medicines.MedicationID.EQ(postgres.String(medicationID))).Sql()
+		result, err := tx.Exec(ctx, sql, args...)
+		if err != nil {
+			logger.Context(ctx).Error(err)
+			return err
+		}
+		rowsAffected = result.RowsAffected()
+		return nil
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return imageURLs, rowsAffected, nil
+}
+
+// MergeMedicine moves the brands, houses and blister date histories of the source medicine to the target, resolves
+// their collisions with the strategy and deletes the source, keeping its id as an alias of the target. The categories,
+// tags and transfers follow the rows, the blister change intervals, stock levels and LASA pairs follow unless the
+// target has them already. An aborted merge returns the collisions with ErrMergeCollision.
+func (r *medicine) MergeMedicine(ctx context.Context, req model.MergeMedicineRequest, userID *uuid.UUID) (res model.MergedMedicine, err error) {
+	medicines := table.PharmaSheetMedicines
+	brands := table.PharmaSheetMedicineBrands
+	houses := table.PharmaSheetMedicineHouses
+	histories := table.PharmaSheetMedicineBlisterDateHistories
+	sourceID, targetID := postgres.String(req.SourceMedicationID), postgres.String(req.MedicationID)
+	now := time.Now()
+
+	res = model.MergedMedicine{
+		SourceMedicationID: req.SourceMedicationID,
+		MedicationID:       req.MedicationID,
+		Strategy:           req.Strategy,
+		Collisions:         make([]model.MergeCollision, 0),
+	}
+
+	err = postgresql.Commit(ctx, r.pgPool, func(ctx context.Context, tx pgx.Tx) error {
+		sql, args := medicines.
+			SELECT(medicines.MedicationID).
+			WHERE(medicines.MedicationID.IN(sourceID, targetID).AND(medicines.DeletedAt.IS_NULL())).
+			FOR(postgres.UPDATE()).
+			Sql()
+		rows, err := tx.Query(ctx, sql, args...)
+		if err != nil {
+			logger.Context(ctx).Error(err)
+			return err
+		}
+		var locked int
+		for rows.Next() {
+			locked++
+		}
+		rows.Close()
+		if err = rows.Err(); err != nil {
+			logger.Context(ctx).Error(err)
+			return err
+		}
+		if locked != 2 {
+			return pgx.ErrNoRows
+		}
+
+		sourceBrands, targetBrands := brands.AS("source_brands"), brands.AS("target_brands")
+		brandCollisions, err := r.findMergeCollisions(ctx, tx, model.AuditEntityMedicineBrand, sourceBrands.
+			INNER_JOIN(targetBrands, targetBrands.MedicationID.EQ(targetID).AND(targetBrands.TradeID.EQ(sourceBrands.TradeID)).AND(targetBrands.DeletedAt.IS_NULL())).
+			SELECT(sourceBrands.ID, targetBrands.ID, sourceBrands.TradeID).
+			WHERE(sourceBrands.MedicationID.EQ(sourceID).AND(sourceBrands.DeletedAt.IS_NULL())))
+		if err != nil {
+			return err
+		}
+
+		sourceHouses, targetHouses := houses.AS("source_houses"), houses.AS("target_houses")
+		houseCollisions, err := r.findMergeCollisions(ctx, tx, model.AuditEntityMedicineHouse, sourceHouses.
+			INNER_JOIN(targetHouses, targetHouses.MedicationID.EQ(targetID).
+				AND(targetHouses.WarehouseID.EQ(sourceHouses.WarehouseID)).
+				AND(targetHouses.Locker.EQ(sourceHouses.Locker)).
+				AND(targetHouses.Floor.EQ(sourceHouses.Floor)).
+				AND(targetHouses.No.EQ(sourceHouses.No)).
+				AND(targetHouses.DeletedAt.IS_NULL())).
+			SELECT(sourceHouses.ID, targetHouses.ID, sourceHouses.WarehouseID.
+				CONCAT(postgres.String(" ")).CONCAT(sourceHouses.Locker).
+				CONCAT(postgres.String("-")).CONCAT(postgres.CAST(sourceHouses.Floor).AS_TEXT()).
+				CONCAT(postgres.String("-")).CONCAT(postgres.CAST(sourceHouses.No).AS_TEXT())).
+			WHERE(sourceHouses.MedicationID.EQ(sourceID).AND(sourceHouses.DeletedAt.IS_NULL())))
+		if err != nil {
+			return err
+		}
+
+		// the histories of a dropped brand are kept on the brand it collided with, so they are compared with its histories
+		for _, collision := range brandCollisions {
+			from, to := collision.SourceID, collision.TargetID
+			if req.Strategy == model.MergeStrategyKeepSource {
+				from, to = to, from
+			}
+			sql, args := histories.UPDATE(histories.BrandID).SET(postgres.UUID(to)).WHERE(histories.BrandID.EQ(postgres.UUID(from))).Sql()
+			if _, err = tx.Exec(ctx, sql, args...); err != nil {
+				logger.Context(ctx).Error(err)
+				return err
+			}
+		}
+
+		sourceHistories, targetHistories := histories.AS("source_histories"), histories.AS("target_histories")
+		historyCollisions, err := r.findMergeCollisions(ctx, tx, model.AuditEntityBlisterDateHistory, sourceHistories.
+			INNER_JOIN(targetHistories, targetHistories.MedicationID.EQ(targetID).
+				AND(targetHistories.WarehouseID.EQ(sourceHistories.WarehouseID)).
+				AND(targetHistories.BrandID.IS_NOT_DISTINCT_FROM(sourceHistories.BrandID)).
+				AND(targetHistories.BlisterChangeDate.EQ(sourceHistories.BlisterChangeDate))).
+			SELECT(sourceHistories.ID, targetHistories.ID, sourceHistories.WarehouseID.
+				CONCAT(postgres.String(" ")).CONCAT(postgres.CAST(sourceHistories.BlisterChangeDate).AS_TEXT())).
+			WHERE(sourceHistories.MedicationID.EQ(sourceID)))
+		if err != nil {
+			return err
+		}
+
+		res.Collisions = append(append(append(res.Collisions, brandCollisions...), houseCollisions...), historyCollisions...)
+		if req.Strategy == model.MergeStrategyAbort && len(res.Collisions) > 0 {
+			return model.ErrMergeCollision
+		}
+
+		// the rows depending on a dropped brand or house follow the one it collided with instead of being deleted with it
+		for _, collision := range append(append([]model.MergeCollision{}, brandCollisions...), houseCollisions...) {
+			from, to := collision.SourceID, collision.TargetID
+			if req.Strategy == model.MergeStrategyKeepSource {
+				from, to = to, from
+			}
+			if err = r.repointMergedRows(ctx, tx, collision.EntityType, from, to, &res); err != nil {
+				return err
+			}
+		}
+
+		dropped := func(collisions []model.MergeCollision) []postgres.Expression {
+			ids := make([]postgres.Expression, 0, len(collisions))
+			for _, collision := range collisions {
+				if req.Strategy == model.MergeStrategyKeepSource {
+					ids = append(ids, postgres.UUID(collision.TargetID))
+				} else {
+					ids = append(ids, postgres.UUID(collision.SourceID))
+				}
+			}
+			return ids
+		}
+
+		if len(brandCollisions) > 0 {
+			imageURLs, _, err := deleteMedicineBrands(ctx, tx, brands.ID.IN(dropped(brandCollisions)...))
+			if err != nil {
+				return err
+			}
+			res.ImageURLs = append(res.ImageURLs, imageURLs...)
+		}
+
+		statements := make([]postgres.Statement, 0)
+		if len(houseCollisions) > 0 {
+			statements = append(statements, houses.DELETE().WHERE(houses.ID.IN(dropped(houseCollisions)...)))
+		}
+		if len(historyCollisions) > 0 {
+			statements = append(statements, histories.DELETE().WHERE(histories.ID.IN(dropped(historyCollisions)...)))
+		}
+		for _, stmt := range statements {
+			sql, args := stmt.Sql()
+			if _, err = tx.Exec(ctx, sql, args...); err != nil {
+				logger.Context(ctx).Error(err)
+				return err
+			}
+		}
+
+		// the trashed rows are moved as well, they can still be restored under the target
+		for _, move := range []struct {
+			stmt  postgres.UpdateStatement
+			total *int64
+		}{
+			{brands.UPDATE(brands.MedicationID, brands.UpdatedAt).SET(targetID, postgres.TimestampzT(now)).WHERE(brands.MedicationID.EQ(sourceID)), &res.Brands},
+			{houses.UPDATE(houses.MedicationID, houses.UpdatedAt).SET(targetID, postgres.TimestampzT(now)).WHERE(houses.MedicationID.EQ(sourceID)), &res.Houses},
+			{histories.UPDATE(histories.MedicationID).SET(targetID).WHERE(histories.MedicationID.EQ(sourceID)), &res.Histories},
+		} {
+			sql, args := move.stmt.Sql()
+			result, err := tx.Exec(ctx, sql, args...)
+			if err != nil {
+				logger.Context(ctx).Error(err)
+				return err
+			}
+			*move.total = result.RowsAffected()
+		}
+
+		if err = r.mergeMedicineRelations(ctx, tx, req.SourceMedicationID, req.MedicationID); err != nil {
+			return err
+		}
+
+		aliases := table.PharmaSheetMedicineAliases
+		for _, stmt := range []postgres.Statement{
+			aliases.UPDATE(aliases.MedicationID).SET(targetID).WHERE(aliases.MedicationID.EQ(sourceID)),
+			aliases.
+				INSERT(aliases.AliasMedicationID, aliases.MedicationID, aliases.CreatedBy, aliases.CreatedAt).
+				MODEL(genmodel.PharmaSheetMedicineAliases{
+					AliasMedicationID: req.SourceMedicationID,
+					MedicationID:      req.MedicationID,
+					CreatedBy:         userID,
+					CreatedAt:         now,
+				}),
+			medicines.DELETE().WHERE(medicines.MedicationID.EQ(sourceID)),
+		} {
+			sql, args := stmt.Sql()
+			if _, err = tx.Exec(ctx, sql, args...); err != nil {
+				logger.Context(ctx).Error(err)
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return res, err
 	}
 
-	return data, total, nil
+	return res, nil
 }
 
-func (r *medicine) ListMedicines(ctx context.Context, filter model.ListMedicine) (data []model.Medicine, err error) {
-	var condition postgres.BoolExpression
-	if filter.WarehouseID != "" {
-		condition = table.PharmaSheetMedicineHouses.WarehouseID.EQ(postgres.String(filter.WarehouseID))
-	} else {
-		return nil, errors.New("filter is invalid")
-	}
-
-	query, args := table.PharmaSheetMedicineHouses.SELECT(postgres.DISTINCT(table.PharmaSheetMedicineHouses.MedicationID)).WHERE(condition).Sql()
-	rows, err := r.pgPool.Query(ctx, query, args...)
+// findMergeCollisions scans the source id, target id and key selected by the statement
+func (r *medicine) findMergeCollisions(ctx context.Context, tx pgx.Tx, entityType model.AuditEntityType, stmt postgres.SelectStatement) ([]model.MergeCollision, error) {
+	sql, args := stmt.Sql()
+	rows, err := tx.Query(ctx, sql, args...)
 	if err != nil {
 		logger.Context(ctx).Error(err)
 		return nil, err
 	}
 	defer rows.Close()
 
-	var medicationIDs []string
+	var collisions []model.MergeCollision
 	for rows.Next() {
-		var medicationID string
-		err = rows.Scan(&medicationID)
-		if err != nil {
+		collision := model.MergeCollision{EntityType: entityType}
+		if err = rows.Scan(&collision.SourceID, &collision.TargetID, &collision.Key); err != nil {
 			logger.Context(ctx).Error(err)
 			return nil, err
 		}
-		medicationIDs = append(medicationIDs, medicationID)
+		collisions = append(collisions, collision)
+	}
+	if err = rows.Err(); err != nil {
+		logger.Context(ctx).Error(err)
+		return nil, err
 	}
+	return collisions, nil
+}
 
-	conc := pool.New().WithContext(ctx).WithMaxGoroutines(10)
-	for _, medicationID := range medicationIDs {
-		medicationID := medicationID
-		conc.Go(func(ctx context.Context) error {
-			medicine, err := r.GetMedicine(ctx, medicationID)
-			if err != nil {
+// repointMergedRows moves the lots, stock movements, stock levels, blister change intervals, pick verifications and transfers
+// of a dropped brand or house to the one it collided with. A lot with the same number there takes over the movements
+// and the quantity of the dropped lot.
+func (r *medicine) repointMergedRows(ctx context.Context, tx pgx.Tx, entityType model.AuditEntityType, from, to uuid.UUID, res *model.MergedMedicine) error {
+	lots, droppedLots, keptLots := table.PharmaSheetMedicineLots, table.PharmaSheetMedicineLots.AS("dropped_lots"), table.PharmaSheetMedicineLots.AS("kept_lots")
+	movements := table.PharmaSheetStockMovements
+	levels, keptLevels := table.PharmaSheetStockLevels, table.PharmaSheetStockLevels.AS("kept_levels")
+	intervals, keptIntervals := table.PharmaSheetBlisterChangeIntervals, table.PharmaSheetBlisterChangeIntervals.AS("kept_intervals")
+	verifications := table.PharmaSheetPickVerifications
+	transfers := table.PharmaSheetMedicineHouseTransfers
+	fromID, toID := postgres.UUID(from), postgres.UUID(to)
+
+	// a lot is keyed by its house, brand and number, so the key being moved is matched on the kept row with the rest of it
+	lotKey, lotRest := func(lot *table.PharmaSheetMedicineLotsTable) postgres.ColumnString { return lot.HouseID },
+		func(lot *table.PharmaSheetMedicineLotsTable) postgres.ColumnString { return lot.BrandID }
+	movementKey, verificationKey := movements.HouseID, verifications.HouseID
+	if entityType == model.AuditEntityMedicineBrand {
+		lotKey, lotRest = lotRest, lotKey
+		movementKey, verificationKey = movements.BrandID, verifications.BrandID
+	}
+
+	query, args := droppedLots.
+		INNER_JOIN(keptLots, lotKey(keptLots).EQ(toID).
+			AND(lotRest(keptLots).IS_NOT_DISTINCT_FROM(lotRest(droppedLots))).
+			AND(keptLots.LotNumber.EQ(droppedLots.LotNumber))).
+		SELECT(droppedLots.ID, keptLots.ID, droppedLots.Quantity).
+		WHERE(lotKey(droppedLots).EQ(fromID)).
+		Sql()
+	rows, err := tx.Query(ctx, query, args...)
+	if err != nil {
+		logger.Context(ctx).Error(err)
+		return err
+	}
+	type lotCollision struct {
+		droppedID, keptID uuid.UUID
+		quantity          int32
+	}
+	var lotCollisions []lotCollision
+	for rows.Next() {
+		var collision lotCollision
+		if err = rows.Scan(&collision.droppedID, &collision.keptID, &collision.quantity); err != nil {
+			rows.Close()
+			logger.Context(ctx).Error(err)
+			return err
+		}
+		lotCollisions = append(lotCollisions, collision)
+	}
+	rows.Close()
+	if err = rows.Err(); err != nil {
+		logger.Context(ctx).Error(err)
+		return err
+	}
+	for _, collision := range lotCollisions {
+		droppedID, keptID := postgres.UUID(collision.droppedID), postgres.UUID(collision.keptID)
+		for _, stmt := range []postgres.Statement{
+			movements.UPDATE(movements.LotID).SET(keptID).WHERE(movements.LotID.EQ(droppedID)),
+			lots.UPDATE(lots.Quantity, lots.UpdatedAt).SET(lots.Quantity.ADD(postgres.Int32(collision.quantity)), postgres.TimestampzT(time.Now())).WHERE(lots.ID.EQ(keptID)),
+			lots.DELETE().WHERE(lots.ID.EQ(droppedID)),
+		} {
+			sql, args := stmt.Sql()
+			if _, err = tx.Exec(ctx, sql, args...); err != nil {
 				logger.Context(ctx).Error(err)
 				return err
 			}
-			data = append(data, medicine)
-			return nil
+		}
+		res.Lots++
+	}
+
+	moves := []struct {
+		stmt  postgres.UpdateStatement
+		total *int64
+	}{
+		{lots.UPDATE(lotKey(lots)).SET(toID).WHERE(lotKey(lots).EQ(fromID)), &res.Lots},
+		{movements.UPDATE(movementKey).SET(toID).WHERE(movementKey.EQ(fromID)), &res.StockMovements},
+		{verifications.UPDATE(verificationKey).SET(toID).WHERE(verificationKey.EQ(fromID)), &res.PickVerifications},
+	}
+	if entityType == model.AuditEntityMedicineBrand {
+		moves = append(moves, struct {
+			stmt  postgres.UpdateStatement
+			total *int64
+		}{
+			intervals.UPDATE(intervals.BrandID).SET(toID).WHERE(intervals.BrandID.EQ(fromID).AND(postgres.NOT(postgres.EXISTS(
+				postgres.SELECT(postgres.Int(1)).
+					FROM(keptIntervals).
+					WHERE(
+						keptIntervals.BrandID.EQ(toID).
+							AND(keptIntervals.WarehouseID.EQ(intervals.WarehouseID)).
+							AND(keptIntervals.MedicationID.IS_NOT_DISTINCT_FROM(intervals.MedicationID)),
+					),
+			)))),
+			&res.BlisterChangeIntervals,
 		})
+	} else {
+		var transferTotal int64
+		moves = append(moves, []struct {
+			stmt  postgres.UpdateStatement
+			total *int64
+		}{
+			{
+				levels.UPDATE(levels.HouseID).SET(toID).WHERE(levels.HouseID.EQ(fromID).AND(postgres.NOT(postgres.EXISTS(
+					postgres.SELECT(postgres.Int(1)).
+						FROM(keptLevels).
+						WHERE(
+							keptLevels.HouseID.EQ(toID).
+								AND(keptLevels.WarehouseID.EQ(levels.WarehouseID)).
+								AND(keptLevels.MedicationID.EQ(levels.MedicationID)),
+						),
+				)))),
+				&res.StockLevels,
+			},
+			{transfers.UPDATE(transfers.SourceHouseID).SET(toID).WHERE(transfers.SourceHouseID.EQ(fromID)), &transferTotal},
+			{transfers.UPDATE(transfers.TargetHouseID).SET(toID).WHERE(transfers.TargetHouseID.EQ(fromID)), &transferTotal},
+		}...)
+	}
+	for _, move := range moves {
+		sql, args := move.stmt.Sql()
+		result, err := tx.Exec(ctx, sql, args...)
+		if err != nil {
+			logger.Context(ctx).Error(err)
+			return err
+		}
+		*move.total += result.RowsAffected()
 	}
-	if err := conc.Wait(); err != nil {
-		return nil, err
-	}
+	return nil
+}
 
-	if len(data) != len(medicationIDs) {
-		return nil, errors.New("data length mismatch")
+// mergeMedicineRelations copies the categories, tags and LASA pairs of the source to the target and moves its transfers,
+// blister change intervals and stock levels. What the target has already is left on the source and deleted with it.
+func (r *medicine) mergeMedicineRelations(ctx context.Context, tx pgx.Tx, sourceMedicationID, targetMedicationID string) error {
+	sourceID, targetID := postgres.String(sourceMedicationID), postgres.String(targetMedicationID)
+	categories := table.PharmaSheetMedicineCategories
+	tags := table.PharmaSheetMedicineTags
+	pairs := table.PharmaSheetLasaPairs
+	transfers := table.PharmaSheetMedicineHouseTransfers
+	intervals, targetIntervals := table.PharmaSheetBlisterChangeIntervals, table.PharmaSheetBlisterChangeIntervals.AS("target_intervals")
+	levels, targetLevels := table.PharmaSheetStockLevels, table.PharmaSheetStockLevels.AS("target_levels")
+	similarMedicationID := postgres.StringExp(postgres.CASE().
+		WHEN(pairs.MedicationID.EQ(sourceID)).THEN(pairs.SimilarMedicationID).
+		ELSE(pairs.MedicationID))
+
+	for _, stmt := range []postgres.Statement{
+		categories.
+			INSERT(categories.MedicationID, categories.CategoryID, categories.CreatedAt).
+			QUERY(postgres.SELECT(targetID, categories.CategoryID, categories.CreatedAt).FROM(categories).WHERE(categories.MedicationID.EQ(sourceID))).
+			ON_CONFLICT(categories.MedicationID, categories.CategoryID).DO_NOTHING(),
+		tags.
+			INSERT(tags.MedicationID, tags.Tag, tags.CreatedAt).
+			QUERY(postgres.SELECT(targetID, tags.Tag, tags.CreatedAt).FROM(tags).WHERE(tags.MedicationID.EQ(sourceID))).
+			ON_CONFLICT(tags.MedicationID, tags.Tag).DO_NOTHING(),
+		// a pair is kept in order, so the pair of the source is copied in the order of the target
+		pairs.
+			INSERT(pairs.ID, pairs.MedicationID, pairs.SimilarMedicationID, pairs.Note, pairs.CreatedBy, pairs.CreatedAt).
+			QUERY(
+				postgres.SELECT(
+					postgres.Raw("gen_random_uuid()"),
+					postgres.LEAST(collateC(targetID), collateC(similarMedicationID)),
+					postgres.GREATEST(collateC(targetID), collateC(similarMedicationID)),
+					pairs.Note,
+					pairs.CreatedBy,
+					pairs.CreatedAt,
+				).
+					FROM(pairs).
+					WHERE(pairs.MedicationID.EQ(sourceID).OR(pairs.SimilarMedicationID.EQ(sourceID)).AND(similarMedicationID.NOT_EQ(targetID))),
+			).
+			ON_CONFLICT(pairs.MedicationID, pairs.SimilarMedicationID).DO_NOTHING(),
+		transfers.UPDATE(transfers.MedicationID).SET(targetID).WHERE(transfers.MedicationID.EQ(sourceID)),
+		intervals.
+			UPDATE(intervals.MedicationID).
+			SET(targetID).
+			WHERE(intervals.MedicationID.EQ(sourceID).AND(postgres.NOT(postgres.EXISTS(
+				postgres.SELECT(postgres.Int(1)).
+					FROM(targetIntervals).
+					WHERE(
+						targetIntervals.MedicationID.EQ(targetID).
+							AND(targetIntervals.WarehouseID.EQ(intervals.WarehouseID)).
+							AND(targetIntervals.BrandID.IS_NOT_DISTINCT_FROM(intervals.BrandID)),
+					),
+			)))),
+		levels.
+			UPDATE(levels.MedicationID).
+			SET(targetID).
+			WHERE(levels.MedicationID.EQ(sourceID).AND(postgres.NOT(postgres.EXISTS(
+				postgres.SELECT(postgres.Int(1)).
+					FROM(targetLevels).
+					WHERE(
+						targetLevels.MedicationID.EQ(targetID).
+							AND(targetLevels.WarehouseID.EQ(levels.WarehouseID)).
+							AND(targetLevels.HouseID.IS_NOT_DISTINCT_FROM(levels.HouseID)),
+					),
+			)))),
+	} {
+		sql, args := stmt.Sql()
+		if _, err := tx.Exec(ctx, sql, args...); err != nil {
+			logger.Context(ctx).Error(err)
+			return err
+		}
 	}
-
-	return data, nil
+	return nil
 }
 
-func (r *medicine) ListMedicinesMaster(ctx context.Context) ([]model.Medicine, error) {
-	query, args := table.PharmaSheetMedicines.
-		SELECT(table.PharmaSheetMedicines.MedicationID, table.PharmaSheetMedicines.MedicalName).
-		ORDER_BY(table.PharmaSheetMedicines.MedicationID.ASC()).
-		Sql()
-
+// ListMedicineAliases maps the id of every merged medicine to the medicine it was merged into
+func (r *medicine) ListMedicineAliases(ctx context.Context) (map[string]string, error) {
+	aliases := table.PharmaSheetMedicineAliases
+	query, args := aliases.SELECT(aliases.AliasMedicationID, aliases.MedicationID).Sql()
 	rows, err := r.pgPool.Query(ctx, query, args...)
 	if err != nil {
 		logger.Context(ctx).Error(err)
@@ -587,97 +1672,34 @@ func (r *medicine) ListMedicinesMaster(ctx context.Context) ([]model.Medicine, e
 	}
 	defer rows.Close()
 
-	var medicines []model.Medicine
+	medicationIDs := make(map[string]string)
 	for rows.Next() {
-		var medicine model.Medicine
-		err = rows.Scan(&medicine.MedicationID, &medicine.MedicalName)
-		if err != nil {
+		var aliasMedicationID, medicationID string
+		if err = rows.Scan(&aliasMedicationID, &medicationID); err != nil {
 			logger.Context(ctx).Error(err)
 			return nil, err
 		}
-		medicines = append(medicines, medicine)
-	}
-
-	return medicines, nil
-}
-
-func (r *medicine) CreateMedicine(ctx context.Context, req model.CreateMedicineRequest) (medicationID string, err error) {
-	medicines := table.PharmaSheetMedicines
-
-	if req.MedicalName == nil || *req.MedicalName == "" {
-		req.MedicalName = &req.MedicationID
-	}
-
-	now := time.Now()
-	medicine := genmodel.PharmaSheetMedicines{
-		MedicationID: req.MedicationID,
-		MedicalName:  *req.MedicalName,
-		CreatedAt:    now,
-		UpdatedAt:    now,
+		medicationIDs[aliasMedicationID] = medicationID
 	}
-
-	sql, args := medicines.
-		INSERT(
-			medicines.MedicationID,
-			medicines.MedicalName,
-			medicines.CreatedAt,
-			medicines.UpdatedAt,
-		).
-		MODEL(medicine).
-		Sql()
-
-	_, err = r.pgPool.Exec(ctx, sql, args...)
-	if err != nil {
+	if err = rows.Err(); err != nil {
 		logger.Context(ctx).Error(err)
-		return "", err
+		return nil, err
 	}
-
-	return req.MedicationID, nil
+	return medicationIDs, nil
 }
 
-func (r *medicine) UpdateMedicine(ctx context.Context, req model.UpdateMedicineRequest) error {
-	medicines := table.PharmaSheetMedicines
-
-	if req.MedicalName == nil || *req.MedicalName == "" {
-		req.MedicalName = &req.MedicationID
-	}
-
-	sql, args := medicines.
-		UPDATE(medicines.MedicalName, medicines.UpdatedAt).
-		SET(postgres.String(*req.MedicalName), postgres.TimestampzT(time.Now())).
-		WHERE(medicines.MedicationID.EQ(postgres.String(req.MedicationID))).
+// GetMedicineAlias returns the medicine a merged medicine id was merged into, no rows when it was not merged
+func (r *medicine) GetMedicineAlias(ctx context.Context, aliasMedicationID string) (medicationID string, err error) {
+	aliases := table.PharmaSheetMedicineAliases
+	query, args := aliases.
+		SELECT(aliases.MedicationID).
+		WHERE(aliases.AliasMedicationID.EQ(postgres.String(aliasMedicationID))).
 		Sql()
-	_, err := r.pgPool.Exec(ctx, sql, args...)
-	if err != nil {
-		logger.Context(ctx).Error(err)
-		return err
-	}
-
-	return nil
-}
-
-func (r *medicine) DeleteMedicine(ctx context.Context, filter model.DeleteMedicineFilter) (int64, error) {
-	var (
-		stmt string
-		args []any
-	)
-	if filter.MedicationID != "" {
-		stmt, args = table.PharmaSheetMedicines.DELETE().WHERE(table.PharmaSheetMedicines.MedicationID.EQ(postgres.String(filter.MedicationID))).Sql()
-
-	} else if filter.WarehouseID != "" {
-		stmt, args = table.PharmaSheetMedicines.DELETE().
-			USING(table.PharmaSheetMedicineHouses).
-			WHERE(table.PharmaSheetMedicines.MedicationID.EQ(table.PharmaSheetMedicineHouses.MedicationID).AND(table.PharmaSheetMedicineHouses.WarehouseID.EQ(postgres.String(filter.WarehouseID)))).Sql()
-
-	} else {
-		return 0, errors.New("filter is invalid")
-	}
-	result, err := r.pgPool.Exec(ctx, stmt, args...)
-	if err != nil {
+	err = r.pgPool.QueryRow(ctx, query, args...).Scan(&medicationID)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
 		logger.Context(ctx).Error(err)
-		return 0, err
 	}
-	return result.RowsAffected(), nil
+	return medicationID, err
 }
 
 func (r *medicine) GetMedicineHouses(ctx context.Context, filter model.FilterMedicineHouse) (houses []model.MedicineHouse, err error) {
@@ -691,6 +1713,7 @@ func (r *medicine) GetMedicineHouses(ctx context.Context, filter model.FilterMed
 	} else {
 		return nil, errors.New("filter is invalid")
 	}
+	condition = condition.AND(table.PharmaSheetMedicineHouses.DeletedAt.IS_NULL())
 
 	query, args := table.PharmaSheetMedicineHouses.
 		SELECT(
@@ -744,7 +1767,8 @@ func (r *medicine) ListMedicineHouses(ctx context.Context, filter model.ListMedi
 		sortBy = fmt.Sprintf("locker %s, floor %s, no %s", order, order, order)
 	}
 
-	condition := postgres.Bool(true)
+	condition := table.PharmaSheetMedicineHouses.DeletedAt.IS_NULL().
+		AND(medicineCategoryCondition(table.PharmaSheetMedicineHouses.MedicationID, filter.FilterMedicineCategory))
 	if filter.WarehouseID != "" {
 		condition = condition.AND(table.PharmaSheetMedicineHouses.WarehouseID.EQ(postgres.String(filter.WarehouseID)))
 	}
@@ -901,7 +1925,7 @@ func (r *medicine) UpdateMedicineHouse(ctx context.Context, req model.UpdateMedi
 			label,
 			postgres.TimestampzT(time.Now()),
 		).
-		WHERE(medicineHouses.ID.EQ(postgres.UUID(req.ID))).
+		WHERE(medicineHouses.ID.EQ(postgres.UUID(req.ID)).AND(medicineHouses.DeletedAt.IS_NULL())).
 		Sql()
 	_, err := r.pgPool.Exec(ctx, sql, args...)
 	if err != nil {
@@ -912,6 +1936,46 @@ func (r *medicine) UpdateMedicineHouse(ctx context.Context, req model.UpdateMedi
 	return nil
 }
 
+// RelocateMedicineHouses moves every house in a single transaction. Houses are parked on a locker of their own first,
+// so that shifting houses onto each other never breaks unique_house halfway through.
+func (r *medicine) RelocateMedicineHouses(ctx context.Context, relocations []model.HouseRelocation) error {
+	medicineHouses := table.PharmaSheetMedicineHouses
+	return postgresql.Commit(ctx, r.pgPool, func(ctx context.Context, tx pgx.Tx) error {
+		for _, relocation := range relocations {
+			sql, args := medicineHouses.
+				UPDATE(medicineHouses.Locker).
+				SET(postgres.String("relocating-" + relocation.HouseID.String())).
+				WHERE(medicineHouses.ID.EQ(postgres.UUID(relocation.HouseID))).
+				Sql()
+			if _, err := tx.Exec(ctx, sql, args...); err != nil {
+				logger.Context(ctx).Error(err)
+				return err
+			}
+		}
+
+		now := time.Now()
+		for _, relocation := range relocations {
+			sql, args := medicineHouses.
+				UPDATE(medicineHouses.Locker, medicineHouses.Floor, medicineHouses.No, medicineHouses.UpdatedAt).
+				SET(
+					postgres.String(relocation.ToLocker),
+					postgres.Int32(relocation.ToFloor),
+					postgres.Int32(relocation.ToNo),
+					postgres.TimestampzT(now),
+				).
+				WHERE(medicineHouses.ID.EQ(postgres.UUID(relocation.HouseID))).
+				Sql()
+			if _, err := tx.Exec(ctx, sql, args...); err != nil {
+				logger.Context(ctx).Error(err)
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+// DeleteMedicineHouse moves the houses to the trash, they are purged after the retention period
 func (r *medicine) DeleteMedicineHouse(ctx context.Context, filter model.DeleteMedicineHouseFilter) (int64, error) {
 	var condition postgres.BoolExpression
 	if filter.MedicationID != "" {
@@ -923,7 +1987,11 @@ func (r *medicine) DeleteMedicineHouse(ctx context.Context, filter model.DeleteM
 	} else {
 		return 0, errors.New("filter is invalid")
 	}
-	stmt, args := table.PharmaSheetMedicineHouses.DELETE().WHERE(condition).Sql()
+	stmt, args := table.PharmaSheetMedicineHouses.
+		UPDATE(table.PharmaSheetMedicineHouses.DeletedAt).
+		SET(postgres.TimestampzT(time.Now())).
+		WHERE(condition.AND(table.PharmaSheetMedicineHouses.DeletedAt.IS_NULL())).
+		Sql()
 	result, err := r.pgPool.Exec(ctx, stmt, args...)
 	if err != nil {
 		logger.Context(ctx).Error(err)
@@ -940,12 +2008,17 @@ func (r *medicine) GetMedicineBrands(ctx context.Context, req model.FilterMedici
 		condition = table.PharmaSheetMedicineHouses.WarehouseID.EQ(postgres.String(req.WarehouseID))
 	} else if req.BrandID != uuid.Nil {
 		condition = table.PharmaSheetMedicineBrands.ID.EQ(postgres.UUID(req.BrandID))
+	} else if req.TradeID != "" {
+		condition = table.PharmaSheetMedicineBrands.TradeID.EQ(postgres.String(req.TradeID))
+	} else if req.Barcode != "" {
+		condition = table.PharmaSheetMedicineBrands.Barcode.EQ(postgres.String(req.Barcode))
 	} else {
 		return nil, errors.New("filter is invalid")
 	}
+	condition = condition.AND(table.PharmaSheetMedicineBrands.DeletedAt.IS_NULL())
 
 	query, args := table.PharmaSheetMedicineBrands.
-		LEFT_JOIN(table.PharmaSheetMedicineHouses, table.PharmaSheetMedicineBrands.MedicationID.EQ(table.PharmaSheetMedicineHouses.MedicationID)).
+		LEFT_JOIN(table.PharmaSheetMedicineHouses, table.PharmaSheetMedicineBrands.MedicationID.EQ(table.PharmaSheetMedicineHouses.MedicationID).AND(table.PharmaSheetMedicineHouses.DeletedAt.IS_NULL())).
 		SELECT(
 			table.PharmaSheetMedicineBrands.ID,
 			table.PharmaSheetMedicineBrands.MedicationID,
@@ -954,6 +2027,7 @@ func (r *medicine) GetMedicineBrands(ctx context.Context, req model.FilterMedici
 			table.PharmaSheetMedicineBrands.BlisterImageURL,
 			table.PharmaSheetMedicineBrands.TabletImageURL,
 			table.PharmaSheetMedicineBrands.BoxImageURL,
+			table.PharmaSheetMedicineBrands.Barcode,
 		).
 		WHERE(condition).
 		GROUP_BY(
@@ -964,6 +2038,7 @@ func (r *medicine) GetMedicineBrands(ctx context.Context, req model.FilterMedici
 			table.PharmaSheetMedicineBrands.BlisterImageURL,
 			table.PharmaSheetMedicineBrands.TabletImageURL,
 			table.PharmaSheetMedicineBrands.BoxImageURL,
+			table.PharmaSheetMedicineBrands.Barcode,
 		).
 		Sql()
 
@@ -984,6 +2059,7 @@ func (r *medicine) GetMedicineBrands(ctx context.Context, req model.FilterMedici
 			&brand.BlisterImageURL,
 			&brand.TabletImageURL,
 			&brand.BoxImageURL,
+			&brand.Barcode,
 		)
 		if err != nil {
 			logger.Context(ctx).Error(err)
@@ -1005,7 +2081,9 @@ func (r *medicine) ListMedicineBrands(ctx context.Context) (brands []model.Medic
 			table.PharmaSheetMedicineBrands.BlisterImageURL,
 			table.PharmaSheetMedicineBrands.TabletImageURL,
 			table.PharmaSheetMedicineBrands.BoxImageURL,
+			table.PharmaSheetMedicineBrands.Barcode,
 		).
+		WHERE(table.PharmaSheetMedicineBrands.DeletedAt.IS_NULL()).
 		Sql()
 
 	rows, err := r.pgPool.Query(ctx, query, args...)
@@ -1025,6 +2103,7 @@ func (r *medicine) ListMedicineBrands(ctx context.Context) (brands []model.Medic
 			&brand.BlisterImageURL,
 			&brand.TabletImageURL,
 			&brand.BoxImageURL,
+			&brand.Barcode,
 		)
 		if err != nil {
 			logger.Context(ctx).Error(err)
@@ -1045,7 +2124,8 @@ func (r *medicine) GetMedicineWithBrands(ctx context.Context, filter model.Filte
 		sortBy = fmt.Sprintf("%s.medication_id %s", table.PharmaSheetMedicines.TableName(), order)
 	}
 
-	condition := postgres.Bool(true)
+	condition := table.PharmaSheetMedicines.DeletedAt.IS_NULL().
+		AND(medicineCategoryCondition(table.PharmaSheetMedicines.MedicationID, filter.FilterMedicineCategory))
 	if search := strings.TrimSpace(filter.Search); search != "" {
 		search := postgres.String("%" + strings.ToLower(search) + "%")
 		condition = condition.AND(
@@ -1059,7 +2139,7 @@ func (r *medicine) GetMedicineWithBrands(ctx context.Context, filter model.Filte
 	}
 
 	query, args := table.PharmaSheetMedicines.
-		LEFT_JOIN(table.PharmaSheetMedicineBrands, table.PharmaSheetMedicines.MedicationID.EQ(table.PharmaSheetMedicineBrands.MedicationID)).
+		LEFT_JOIN(table.PharmaSheetMedicineBrands, table.PharmaSheetMedicines.MedicationID.EQ(table.PharmaSheetMedicineBrands.MedicationID).AND(table.PharmaSheetMedicineBrands.DeletedAt.IS_NULL())).
 		SELECT(postgres.COUNT(postgres.DISTINCT(table.PharmaSheetMedicines.MedicationID)).AS("total")).
 		WHERE(condition).
 		Sql()
@@ -1074,7 +2154,7 @@ func (r *medicine) GetMedicineWithBrands(ctx context.Context, filter model.Filte
 	}
 
 	query, args = table.PharmaSheetMedicines.
-		LEFT_JOIN(table.PharmaSheetMedicineBrands, table.PharmaSheetMedicines.MedicationID.EQ(table.PharmaSheetMedicineBrands.MedicationID)).
+		LEFT_JOIN(table.PharmaSheetMedicineBrands, table.PharmaSheetMedicines.MedicationID.EQ(table.PharmaSheetMedicineBrands.MedicationID).AND(table.PharmaSheetMedicineBrands.DeletedAt.IS_NULL())).
 		SELECT(table.PharmaSheetMedicines.MedicationID, table.PharmaSheetMedicines.MedicalName).
 		WHERE(condition).
 		GROUP_BY(table.PharmaSheetMedicines.MedicationID, table.PharmaSheetMedicines.MedicalName).
@@ -1112,8 +2192,9 @@ func (r *medicine) GetMedicineWithBrands(ctx context.Context, filter model.Filte
 				table.PharmaSheetMedicineBrands.BlisterImageURL,
 				table.PharmaSheetMedicineBrands.TabletImageURL,
 				table.PharmaSheetMedicineBrands.BoxImageURL,
+				table.PharmaSheetMedicineBrands.Barcode,
 			).
-			WHERE(table.PharmaSheetMedicineBrands.MedicationID.IN(medicationIDs...)).
+			WHERE(table.PharmaSheetMedicineBrands.MedicationID.IN(medicationIDs...).AND(table.PharmaSheetMedicineBrands.DeletedAt.IS_NULL())).
 			ORDER_BY(table.PharmaSheetMedicineBrands.TradeID).
 			Sql()
 
@@ -1134,6 +2215,7 @@ func (r *medicine) GetMedicineWithBrands(ctx context.Context, filter model.Filte
 				&medicineBrand.BlisterImageURL,
 				&medicineBrand.TabletImageURL,
 				&medicineBrand.BoxImageURL,
+				&medicineBrand.Barcode,
 			)
 			if err != nil {
 				logger.Context(ctx).Error(err)
@@ -1160,7 +2242,8 @@ func (r *medicine) GetMedicineBrandsPagination(ctx context.Context, filter model
 		sortBy = fmt.Sprintf("%s.medication_id %s", table.PharmaSheetMedicines.TableName(), order)
 	}
 
-	condition := postgres.Bool(true)
+	condition := table.PharmaSheetMedicines.DeletedAt.IS_NULL().
+		AND(medicineCategoryCondition(table.PharmaSheetMedicines.MedicationID, filter.FilterMedicineCategory))
 	if search := strings.TrimSpace(filter.Search); search != "" {
 		search := postgres.String("%" + strings.ToLower(search) + "%")
 		condition = condition.AND(
@@ -1169,12 +2252,13 @@ func (r *medicine) GetMedicineBrandsPagination(ctx context.Context, filter model
 				postgres.LOWER(table.PharmaSheetMedicineBrands.TradeName).LIKE(search),
 				postgres.LOWER(table.PharmaSheetMedicines.MedicalName).LIKE(search),
 				postgres.LOWER(table.PharmaSheetMedicines.MedicationID).LIKE(search),
+				table.PharmaSheetMedicineBrands.Barcode.LIKE(search),
 			),
 		)
 	}
 
 	query, args := table.PharmaSheetMedicines.
-		INNER_JOIN(table.PharmaSheetMedicineBrands, table.PharmaSheetMedicines.MedicationID.EQ(table.PharmaSheetMedicineBrands.MedicationID)).
+		INNER_JOIN(table.PharmaSheetMedicineBrands, table.PharmaSheetMedicines.MedicationID.EQ(table.PharmaSheetMedicineBrands.MedicationID).AND(table.PharmaSheetMedicineBrands.DeletedAt.IS_NULL())).
 		SELECT(postgres.COUNT(table.PharmaSheetMedicineBrands.ID).AS("total")).
 		WHERE(condition).
 		Sql()
@@ -1189,7 +2273,7 @@ func (r *medicine) GetMedicineBrandsPagination(ctx context.Context, filter model
 	}
 
 	query, args = table.PharmaSheetMedicines.
-		INNER_JOIN(table.PharmaSheetMedicineBrands, table.PharmaSheetMedicines.MedicationID.EQ(table.PharmaSheetMedicineBrands.MedicationID)).
+		INNER_JOIN(table.PharmaSheetMedicineBrands, table.PharmaSheetMedicines.MedicationID.EQ(table.PharmaSheetMedicineBrands.MedicationID).AND(table.PharmaSheetMedicineBrands.DeletedAt.IS_NULL())).
 		LEFT_JOIN(
 			table.PharmaSheetMedicineBlisterDateHistories,
 			postgres.AND(
@@ -1206,6 +2290,7 @@ func (r *medicine) GetMedicineBrandsPagination(ctx context.Context, filter model
 			table.PharmaSheetMedicineBrands.BlisterImageURL,
 			table.PharmaSheetMedicineBrands.TabletImageURL,
 			table.PharmaSheetMedicineBrands.BoxImageURL,
+			table.PharmaSheetMedicineBrands.Barcode,
 			postgres.COALESCE(postgres.COUNT(table.PharmaSheetMedicineBlisterDateHistories.ID), postgres.Int64(0)).AS("total_blister_change_date"),
 		).
 		WHERE(condition).
@@ -1233,6 +2318,7 @@ func (r *medicine) GetMedicineBrandsPagination(ctx context.Context, filter model
 			&medicine.BlisterImageURL,
 			&medicine.TabletImageURL,
 			&medicine.BoxImageURL,
+			&medicine.Barcode,
 			&medicine.TotalBlisterChangeDate,
 		)
 		if err != nil {
@@ -1260,6 +2346,7 @@ func (r *medicine) CreateMedicineBrand(ctx context.Context, req model.CreateMedi
 		BlisterImageURL: req.BlisterImageURL,
 		TabletImageURL:  req.TabletImageURL,
 		BoxImageURL:     req.BoxImageURL,
+		Barcode:         req.Barcode,
 		CreatedAt:       now,
 		UpdatedAt:       now,
 	}
@@ -1273,6 +2360,7 @@ func (r *medicine) CreateMedicineBrand(ctx context.Context, req model.CreateMedi
 			medicineBrands.BlisterImageURL,
 			medicineBrands.TabletImageURL,
 			medicineBrands.BoxImageURL,
+			medicineBrands.Barcode,
 			medicineBrands.CreatedAt,
 			medicineBrands.UpdatedAt,
 		).
@@ -1300,6 +2388,19 @@ func (r *medicine) UpdateMedicineBrand(ctx context.Context, req model.UpdateMedi
 		columnValues = append(columnValues, postgres.NULL)
 	}
 
+	if req.TradeID != nil && *req.TradeID != "" {
+		columnNames = append(columnNames, medicineBrands.TradeID)
+		columnValues = append(columnValues, postgres.String(*req.TradeID))
+	}
+
+	if req.Barcode != nil && *req.Barcode != "" {
+		columnNames = append(columnNames, medicineBrands.Barcode)
+		columnValues = append(columnValues, postgres.String(*req.Barcode))
+	} else if req.Barcode != nil {
+		columnNames = append(columnNames, medicineBrands.Barcode)
+		columnValues = append(columnValues, postgres.NULL)
+	}
+
 	if req.BlisterImageURL != nil && *req.BlisterImageURL == "null" {
 		columnNames = append(columnNames, medicineBrands.BlisterImageURL)
 		columnValues = append(columnValues, postgres.NULL)
@@ -1327,7 +2428,7 @@ func (r *medicine) UpdateMedicineBrand(ctx context.Context, req model.UpdateMedi
 	sql, args := medicineBrands.
 		UPDATE(columnNames).
 		SET(columnValues[0], columnValues[1:]...).
-		WHERE(medicineBrands.ID.EQ(postgres.UUID(req.BrandID))).
+		WHERE(medicineBrands.ID.EQ(postgres.UUID(req.BrandID)).AND(medicineBrands.DeletedAt.IS_NULL())).
 		Sql()
 	_, err := r.pgPool.Exec(ctx, sql, args...)
 	if err != nil {
@@ -1338,6 +2439,7 @@ func (r *medicine) UpdateMedicineBrand(ctx context.Context, req model.UpdateMedi
 	return nil
 }
 
+// DeleteMedicineBrand moves the brands to the trash, their images are kept until they are purged
 func (r *medicine) DeleteMedicineBrand(ctx context.Context, filter model.DeleteMedicineBrandFilter) (int64, error) {
 	var condition postgres.BoolExpression
 	if filter.MedicationID != "" {
@@ -1349,7 +2451,11 @@ func (r *medicine) DeleteMedicineBrand(ctx context.Context, filter model.DeleteM
 	} else {
 		return 0, errors.New("filter is invalid")
 	}
-	stmt, args := table.PharmaSheetMedicineBrands.DELETE().WHERE(condition).Sql()
+	stmt, args := table.PharmaSheetMedicineBrands.
+		UPDATE(table.PharmaSheetMedicineBrands.DeletedAt).
+		SET(postgres.TimestampzT(time.Now())).
+		WHERE(condition.AND(table.PharmaSheetMedicineBrands.DeletedAt.IS_NULL())).
+		Sql()
 	result, err := r.pgPool.Exec(ctx, stmt, args...)
 	if err != nil {
 		logger.Context(ctx).Error(err)
@@ -1462,7 +2568,7 @@ func (r *medicine) ListMedicineBlisterChangeDateHistoryPagination(ctx context.Co
 		sortBy = fmt.Sprintf("%s.warehouse_id %s", table.PharmaSheetMedicineBlisterDateHistories.TableName(), order)
 	}
 
-	condition := postgres.Bool(true)
+	condition := table.PharmaSheetWarehouses.DeletedAt.IS_NULL()
 	if filter.WarehouseID != "" {
 		condition = condition.AND(table.PharmaSheetWarehouses.WarehouseID.EQ(postgres.String(filter.WarehouseID)))
 	}
@@ -1601,6 +2707,8 @@ func (r *medicine) CreateMedicineBlisterChangeDateHistory(ctx context.Context, r
 		BrandID:           req.BrandID,
 		BlisterChangeDate: req.BlisterChangeDate,
 		CreatedAt:         time.Now(),
+		CreatedBy:         req.CreatedBy,
+		ConfirmedBy:       req.ConfirmedBy,
 	}
 
 	sql, args := medcineHistoryTable.
@@ -1611,6 +2719,8 @@ func (r *medicine) CreateMedicineBlisterChangeDateHistory(ctx context.Context, r
 			medcineHistoryTable.BrandID,
 			medcineHistoryTable.BlisterChangeDate,
 			medcineHistoryTable.CreatedAt,
+			medcineHistoryTable.CreatedBy,
+			medcineHistoryTable.ConfirmedBy,
 		).
 		MODEL(medicineHistory).
 		Sql()
@@ -1624,6 +2734,35 @@ func (r *medicine) CreateMedicineBlisterChangeDateHistory(ctx context.Context, r
 	return medicineHistory.ID.String(), nil
 }
 
+func (r *medicine) UpdateMedicineBlisterChangeDateHistory(ctx context.Context, req model.UpdateMedicineBlisterChangeDateHistoryRequest) error {
+	brandID := postgres.NULL
+	if req.BrandID != nil {
+		brandID = postgres.UUID(*req.BrandID)
+	}
+
+	medicineHistories := table.PharmaSheetMedicineBlisterDateHistories
+	sql, args := medicineHistories.
+		UPDATE(
+			medicineHistories.MedicationID,
+			medicineHistories.BrandID,
+			medicineHistories.BlisterChangeDate,
+		).
+		SET(
+			postgres.String(req.MedicationID),
+			brandID,
+			postgres.DateT(req.BlisterChangeDate),
+		).
+		WHERE(medicineHistories.ID.EQ(postgres.UUID(req.ID))).
+		Sql()
+	_, err := r.pgPool.Exec(ctx, sql, args...)
+	if err != nil {
+		logger.Context(ctx).Error(err)
+		return err
+	}
+
+	return nil
+}
+
 func (r *medicine) DeleteMedicineBlisterChangeDateHistory(ctx context.Context, req model.DeleteMedicineBlisterChangeDateHistoryRequest) error {
 	condition := postgres.Bool(true)
 	validCondition := false
@@ -1656,3 +2795,151 @@ func (r *medicine) DeleteMedicineBlisterChangeDateHistory(ctx context.Context, r
 	}
 	return nil
 }
+
+func (r *medicine) ListBlisterChangeIntervals(ctx context.Context, warehouseID string) ([]model.BlisterChangeInterval, error) {
+	query, args := table.PharmaSheetBlisterChangeIntervals.
+		LEFT_JOIN(table.PharmaSheetMedicineBrands, table.PharmaSheetMedicineBrands.ID.EQ(table.PharmaSheetBlisterChangeIntervals.BrandID)).
+		SELECT(
+			table.PharmaSheetBlisterChangeIntervals.ID,
+			table.PharmaSheetBlisterChangeIntervals.WarehouseID,
+			table.PharmaSheetBlisterChangeIntervals.MedicationID,
+			table.PharmaSheetBlisterChangeIntervals.BrandID,
+			table.PharmaSheetBlisterChangeIntervals.IntervalDays,
+			table.PharmaSheetMedicineBrands.TradeID,
+		).
+		WHERE(table.PharmaSheetBlisterChangeIntervals.WarehouseID.EQ(postgres.String(warehouseID))).
+		ORDER_BY(
+			table.PharmaSheetBlisterChangeIntervals.MedicationID.ASC().NULLS_FIRST(),
+			table.PharmaSheetMedicineBrands.TradeID.ASC().NULLS_FIRST(),
+		).
+		Sql()
+
+	rows, err := r.pgPool.Query(ctx, query, args...)
+	if err != nil {
+		logger.Context(ctx).Error(err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	var intervals []model.BlisterChangeInterval
+	for rows.Next() {
+		var interval model.BlisterChangeInterval
+		err = rows.Scan(
+			&interval.ID,
+			&interval.WarehouseID,
+			&interval.MedicationID,
+			&interval.BrandID,
+			&interval.IntervalDays,
+			&interval.TradeID,
+		)
+		if err != nil {
+			logger.Context(ctx).Error(err)
+			return nil, err
+		}
+		intervals = append(intervals, interval)
+	}
+
+	return intervals, nil
+}
+
+func (r *medicine) GetBlisterChangeInterval(ctx context.Context, id uuid.UUID) (interval model.BlisterChangeInterval, err error) {
+	query, args := table.PharmaSheetBlisterChangeIntervals.
+		SELECT(
+			table.PharmaSheetBlisterChangeIntervals.ID,
+			table.PharmaSheetBlisterChangeIntervals.WarehouseID,
+			table.PharmaSheetBlisterChangeIntervals.MedicationID,
+			table.PharmaSheetBlisterChangeIntervals.BrandID,
+			table.PharmaSheetBlisterChangeIntervals.IntervalDays,
+		).
+		WHERE(table.PharmaSheetBlisterChangeIntervals.ID.EQ(postgres.UUID(id))).
+		Sql()
+
+	err = r.pgPool.QueryRow(ctx, query, args...).Scan(
+		&interval.ID,
+		&interval.WarehouseID,
+		&interval.MedicationID,
+		&interval.BrandID,
+		&interval.IntervalDays,
+	)
+	if err != nil {
+		logger.Context(ctx).Error(err)
+		return interval, err
+	}
+
+	return interval, nil
+}
+
+func (r *medicine) UpsertBlisterChangeInterval(ctx context.Context, req model.UpsertBlisterChangeIntervalRequest) (string, error) {
+	intervals := table.PharmaSheetBlisterChangeIntervals
+
+	medicationID := postgres.StringExp(postgres.NULL)
+	if req.MedicationID != nil {
+		medicationID = postgres.String(*req.MedicationID)
+	}
+	brandID := postgres.StringExp(postgres.NULL)
+	if req.BrandID != nil {
+		brandID = postgres.UUID(*req.BrandID)
+	}
+
+	// the unique index is built on expressions of the nullable columns, so the upsert is done by hand
+	var id string
+	query, args := intervals.
+		UPDATE(intervals.IntervalDays, intervals.UpdatedAt).
+		SET(postgres.Int32(req.IntervalDays), postgres.TimestampzT(time.Now())).
+		WHERE(
+			intervals.WarehouseID.EQ(postgres.String(req.WarehouseID)).
+				AND(intervals.MedicationID.IS_NOT_DISTINCT_FROM(medicationID)).
+				AND(intervals.BrandID.IS_NOT_DISTINCT_FROM(brandID)),
+		).
+		RETURNING(intervals.ID).
+		Sql()
+	err := r.pgPool.QueryRow(ctx, query, args...).Scan(&id)
+	if err == nil {
+		return id, nil
+	}
+	if !errors.Is(err, pgx.ErrNoRows) {
+		logger.Context(ctx).Error(err)
+		return "", err
+	}
+
+	now := time.Now()
+	interval := genmodel.PharmaSheetBlisterChangeIntervals{
+		ID:           uuid.MustParse(generator.UUID()),
+		WarehouseID:  req.WarehouseID,
+		MedicationID: req.MedicationID,
+		BrandID:      req.BrandID,
+		IntervalDays: req.IntervalDays,
+		CreatedAt:    now,
+		UpdatedAt:    now,
+	}
+	sql, args := intervals.
+		INSERT(
+			intervals.ID,
+			intervals.WarehouseID,
+			intervals.MedicationID,
+			intervals.BrandID,
+			intervals.IntervalDays,
+			intervals.CreatedAt,
+			intervals.UpdatedAt,
+		).
+		MODEL(interval).
+		Sql()
+
+	_, err = r.pgPool.Exec(ctx, sql, args...)
+	if err != nil {
+		logger.Context(ctx).Error(err)
+		return "", err
+	}
+
+	return interval.ID.String(), nil
+}
+
+func (r *medicine) DeleteBlisterChangeInterval(ctx context.Context, id uuid.UUID) (int64, error) {
+	stmt, args := table.PharmaSheetBlisterChangeIntervals.DELETE().WHERE(table.PharmaSheetBlisterChangeIntervals.ID.EQ(postgres.UUID(id))).Sql()
+	result, err := r.pgPool.Exec(ctx, stmt, args...)
+	if err != nil {
+		logger.Context(ctx).Error(err)
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
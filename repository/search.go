@@ -0,0 +1,264 @@
+package repository
+
+import (
+	"context"
+	"strings"
+
+	"github.com/go-jet/jet/v2/postgres"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/kinkando/pharma-sheet-service/.gen/pharma_sheet/public/enum"
+	"github.com/kinkando/pharma-sheet-service/.gen/pharma_sheet/public/table"
+	"github.com/kinkando/pharma-sheet-service/model"
+	"github.com/kinkando/pharma-sheet-service/pkg/logger"
+	"github.com/kinkando/pharma-sheet-service/pkg/profile"
+	"github.com/sourcegraph/conc/pool"
+)
+
+type Search interface {
+	ListSuggestions(ctx context.Context, filter model.FilterSuggestion) ([]model.Suggestion, error)
+}
+
+type search struct {
+	pgPool *pgxpool.Pool
+}
+
+func NewSearchRepository(pgPool *pgxpool.Pool) Search {
+	return &search{pgPool: pgPool}
+}
+
+// ListSuggestions looks up every kind of suggestion by prefix, each kind is limited on its own
+// and only covers the warehouses the user belongs to.
+func (r *search) ListSuggestions(ctx context.Context, filter model.FilterSuggestion) ([]model.Suggestion, error) {
+	userProfile, err := profile.UseProfile(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	warehouseUsers := table.PharmaSheetWarehouseUsers
+	warehouseCondition := table.PharmaSheetWarehouses.DeletedAt.IS_NULL().AND(table.PharmaSheetWarehouses.WarehouseID.IN(
+		warehouseUsers.
+			SELECT(warehouseUsers.WarehouseID).
+			WHERE(warehouseUsers.UserID.EQ(postgres.UUID(uuid.MustParse(userProfile.UserID))).AND(warehouseUsers.Status.EQ(enum.PharmaSheetApprovalStatus.Approved))),
+	))
+	if filter.WarehouseID != "" {
+		warehouseCondition = warehouseCondition.AND(table.PharmaSheetWarehouses.WarehouseID.EQ(postgres.String(filter.WarehouseID)))
+	}
+	prefix := postgres.String(likePrefix(filter.Query))
+
+	var medicines, brands, houses, warehouses []model.Suggestion
+	conc := pool.New().WithContext(ctx).WithMaxGoroutines(4)
+	conc.Go(func(ctx context.Context) (err error) {
+		medicines, err = r.listMedicineSuggestions(ctx, filter.Query, prefix, warehouseCondition, filter.Limit)
+		return err
+	})
+	conc.Go(func(ctx context.Context) (err error) {
+		brands, err = r.listBrandSuggestions(ctx, filter.Query, prefix, warehouseCondition, filter.Limit)
+		return err
+	})
+	conc.Go(func(ctx context.Context) (err error) {
+		houses, err = r.listHouseSuggestions(ctx, prefix, warehouseCondition, filter.Limit)
+		return err
+	})
+	conc.Go(func(ctx context.Context) (err error) {
+		warehouses, err = r.listWarehouseSuggestions(ctx, prefix, warehouseCondition, filter.Limit)
+		return err
+	})
+	if err := conc.Wait(); err != nil {
+		return nil, err
+	}
+
+	suggestions := append(medicines, brands...)
+	suggestions = append(suggestions, houses...)
+	suggestions = append(suggestions, warehouses...)
+	return suggestions, nil
+}
+
+func (r *search) listMedicineSuggestions(ctx context.Context, search string, prefix postgres.StringExpression, warehouseCondition postgres.BoolExpression, limit int64) ([]model.Suggestion, error) {
+	medicines := table.PharmaSheetMedicines
+	houses := table.PharmaSheetMedicineHouses
+
+	query, args := medicines.
+		SELECT(medicines.MedicationID, medicines.MedicalName).
+		WHERE(
+			postgres.OR(
+				postgres.LOWER(medicines.MedicationID).LIKE(prefix),
+				postgres.LOWER(medicines.MedicalName).LIKE(prefix),
+			).AND(medicines.DeletedAt.IS_NULL()).AND(medicines.MedicationID.IN(
+				houses.
+					INNER_JOIN(table.PharmaSheetWarehouses, table.PharmaSheetWarehouses.WarehouseID.EQ(houses.WarehouseID)).
+					SELECT(houses.MedicationID).
+					WHERE(warehouseCondition.AND(houses.DeletedAt.IS_NULL())),
+			)),
+		).
+		ORDER_BY(medicines.MedicationID.ASC()).
+		LIMIT(limit).
+		Sql()
+
+	rows, err := r.pgPool.Query(ctx, query, args...)
+	if err != nil {
+		logger.Context(ctx).Error(err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	var suggestions []model.Suggestion
+	for rows.Next() {
+		var medicationID string
+		var medicalName *string
+		if err = rows.Scan(&medicationID, &medicalName); err != nil {
+			logger.Context(ctx).Error(err)
+			return nil, err
+		}
+		suggestion := model.Suggestion{
+			Type:         model.SuggestionTypeMedicine,
+			ID:           medicationID,
+			Text:         medicationID,
+			Description:  medicalName,
+			MedicationID: &medicationID,
+		}
+		if !strings.HasPrefix(strings.ToLower(medicationID), search) && medicalName != nil {
+			suggestion.Text, suggestion.Description = *medicalName, &medicationID
+		}
+		suggestions = append(suggestions, suggestion)
+	}
+
+	return suggestions, nil
+}
+
+func (r *search) listBrandSuggestions(ctx context.Context, search string, prefix postgres.StringExpression, warehouseCondition postgres.BoolExpression, limit int64) ([]model.Suggestion, error) {
+	brands := table.PharmaSheetMedicineBrands
+	houses := table.PharmaSheetMedicineHouses
+
+	query, args := brands.
+		SELECT(brands.ID, brands.MedicationID, brands.TradeID, brands.TradeName).
+		WHERE(
+			postgres.OR(
+				postgres.LOWER(brands.TradeID).LIKE(prefix),
+				postgres.LOWER(brands.TradeName).LIKE(prefix),
+			).AND(brands.TradeID.NOT_EQ(postgres.String("-"))).AND(brands.DeletedAt.IS_NULL()).AND(brands.MedicationID.IN(
+				houses.
+					INNER_JOIN(table.PharmaSheetWarehouses, table.PharmaSheetWarehouses.WarehouseID.EQ(houses.WarehouseID)).
+					SELECT(houses.MedicationID).
+					WHERE(warehouseCondition.AND(houses.DeletedAt.IS_NULL())),
+			)),
+		).
+		ORDER_BY(brands.TradeID.ASC()).
+		LIMIT(limit).
+		Sql()
+
+	rows, err := r.pgPool.Query(ctx, query, args...)
+	if err != nil {
+		logger.Context(ctx).Error(err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	var suggestions []model.Suggestion
+	for rows.Next() {
+		var brandID uuid.UUID
+		var medicationID, tradeID string
+		var tradeName *string
+		if err = rows.Scan(&brandID, &medicationID, &tradeID, &tradeName); err != nil {
+			logger.Context(ctx).Error(err)
+			return nil, err
+		}
+		suggestion := model.Suggestion{
+			Type:         model.SuggestionTypeBrand,
+			ID:           brandID.String(),
+			Text:         tradeID,
+			Description:  tradeName,
+			MedicationID: &medicationID,
+		}
+		if !strings.HasPrefix(strings.ToLower(tradeID), search) && tradeName != nil {
+			suggestion.Text, suggestion.Description = *tradeName, &tradeID
+		}
+		suggestions = append(suggestions, suggestion)
+	}
+
+	return suggestions, nil
+}
+
+func (r *search) listHouseSuggestions(ctx context.Context, prefix postgres.StringExpression, warehouseCondition postgres.BoolExpression, limit int64) ([]model.Suggestion, error) {
+	houses := table.PharmaSheetMedicineHouses
+
+	query, args := houses.
+		INNER_JOIN(table.PharmaSheetWarehouses, table.PharmaSheetWarehouses.WarehouseID.EQ(houses.WarehouseID)).
+		SELECT(houses.ID, houses.WarehouseID, houses.MedicationID, houses.Locker, houses.Floor, houses.No, table.PharmaSheetWarehouses.Name).
+		WHERE(warehouseCondition.AND(houses.DeletedAt.IS_NULL()).AND(postgres.LOWER(medicineHouseAddress()).LIKE(prefix))).
+		ORDER_BY(houses.Locker.ASC(), houses.Floor.ASC(), houses.No.ASC()).
+		LIMIT(limit).
+		Sql()
+
+	rows, err := r.pgPool.Query(ctx, query, args...)
+	if err != nil {
+		logger.Context(ctx).Error(err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	var suggestions []model.Suggestion
+	for rows.Next() {
+		var house model.MedicineHouse
+		var warehouseName string
+		if err = rows.Scan(&house.ID, &house.WarehouseID, &house.MedicationID, &house.Locker, &house.Floor, &house.No, &warehouseName); err != nil {
+			logger.Context(ctx).Error(err)
+			return nil, err
+		}
+		suggestions = append(suggestions, model.Suggestion{
+			Type:         model.SuggestionTypeHouse,
+			ID:           house.ID.String(),
+			Text:         house.Address(),
+			Description:  &warehouseName,
+			MedicationID: &house.MedicationID,
+			WarehouseID:  &house.WarehouseID,
+		})
+	}
+
+	return suggestions, nil
+}
+
+func (r *search) listWarehouseSuggestions(ctx context.Context, prefix postgres.StringExpression, warehouseCondition postgres.BoolExpression, limit int64) ([]model.Suggestion, error) {
+	warehouses := table.PharmaSheetWarehouses
+
+	query, args := warehouses.
+		SELECT(warehouses.WarehouseID, warehouses.Name).
+		WHERE(warehouseCondition.AND(postgres.OR(
+			postgres.LOWER(warehouses.WarehouseID).LIKE(prefix),
+			postgres.LOWER(warehouses.Name).LIKE(prefix),
+		))).
+		ORDER_BY(warehouses.Name.ASC()).
+		LIMIT(limit).
+		Sql()
+
+	rows, err := r.pgPool.Query(ctx, query, args...)
+	if err != nil {
+		logger.Context(ctx).Error(err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	var suggestions []model.Suggestion
+	for rows.Next() {
+		var warehouseID, name string
+		if err = rows.Scan(&warehouseID, &name); err != nil {
+			logger.Context(ctx).Error(err)
+			return nil, err
+		}
+		suggestions = append(suggestions, model.Suggestion{
+			Type:        model.SuggestionTypeWarehouse,
+			ID:          warehouseID,
+			Text:        name,
+			Description: &warehouseID,
+			WarehouseID: &warehouseID,
+		})
+	}
+
+	return suggestions, nil
+}
+
+// likePrefix escapes the wildcards of a search so it only matches as a prefix
+func likePrefix(search string) string {
+	search = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(search)
+	return search + "%"
+}
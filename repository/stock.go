@@ -0,0 +1,853 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/go-jet/jet/v2/postgres"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	genmodel "github.com/kinkando/pharma-sheet-service/.gen/pharma_sheet/public/model"
+	"github.com/kinkando/pharma-sheet-service/.gen/pharma_sheet/public/table"
+	"github.com/kinkando/pharma-sheet-service/model"
+	"github.com/kinkando/pharma-sheet-service/pkg/database/postgresql"
+	"github.com/kinkando/pharma-sheet-service/pkg/generator"
+	"github.com/kinkando/pharma-sheet-service/pkg/logger"
+	"github.com/kinkando/pharma-sheet-service/pkg/profile"
+)
+
+type Stock interface {
+	CreateStockMovements(ctx context.Context, entries []model.StockMovementEntry) ([]string, []model.StockOnHandChange, error)
+	ListStockMovements(ctx context.Context, filter model.FilterStockMovement) (data []model.StockMovement, total uint64, err error)
+	ListStockBalances(ctx context.Context, filter model.FilterStockBalance) ([]model.StockBalance, error)
+	TransferMedicineHouse(ctx context.Context, transfer model.TransferMedicineHouse) (model.MedicineHouseTransfer, []model.StockOnHandChange, error)
+
+	ListStockLevels(ctx context.Context, warehouseID string) ([]model.StockLevel, error)
+	GetStockLevel(ctx context.Context, id uuid.UUID) (model.StockLevel, error)
+	UpsertStockLevel(ctx context.Context, req model.UpsertStockLevelRequest) (string, error)
+	DeleteStockLevel(ctx context.Context, id uuid.UUID) (int64, error)
+}
+
+type stock struct {
+	pgPool *pgxpool.Pool
+}
+
+func NewStockRepository(pgPool *pgxpool.Pool) Stock {
+	return &stock{pgPool: pgPool}
+}
+
+// CreateStockMovements appends all entries in a single transaction and returns the on-hand change of every house.
+func (r *stock) CreateStockMovements(ctx context.Context, entries []model.StockMovementEntry) (ids []string, changes []model.StockOnHandChange, err error) {
+	userID, err := useProfileUserID(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	err = postgresql.Commit(ctx, r.pgPool, func(ctx context.Context, tx pgx.Tx) error {
+		ids, changes, err = r.createStockMovements(ctx, tx, entries, userID)
+		return err
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return ids, changes, nil
+}
+
+// createStockMovements locks every house of the entries before appending them, so concurrent movements can never
+// take a balance below zero and the on-hand change of each house is read without a movement in between.
+func (r *stock) createStockMovements(ctx context.Context, tx pgx.Tx, entries []model.StockMovementEntry, userID uuid.UUID) ([]string, []model.StockOnHandChange, error) {
+	deltas := make(map[string]int64)
+	houseDeltas := make(map[uuid.UUID]int64)
+	var houseIDs []uuid.UUID
+	for _, entry := range entries {
+		deltas[stockKey(entry.HouseID, entry.BrandID)] += int64(entry.Quantity)
+		if _, ok := houseDeltas[entry.HouseID]; !ok {
+			houseIDs = append(houseIDs, entry.HouseID)
+		}
+		houseDeltas[entry.HouseID] += int64(entry.Quantity)
+	}
+	if err := lockStockHouses(ctx, tx, houseIDs...); err != nil {
+		return nil, nil, err
+	}
+
+	for _, entry := range entries {
+		key := stockKey(entry.HouseID, entry.BrandID)
+		if deltas[key] >= 0 {
+			continue
+		}
+		balance, err := r.getStockBalance(ctx, tx, entry.HouseID, entry.BrandID)
+		if err != nil {
+			return nil, nil, err
+		}
+		if balance+deltas[key] < 0 {
+			return nil, nil, model.ErrInsufficientStock
+		}
+		// the balance of each key is checked once
+		deltas[key] = 0
+	}
+
+	now := time.Now()
+	ids := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		lotID := entry.LotID
+		if lotID != nil {
+			var err error
+			lotID, err = r.moveMedicineLot(ctx, tx, *lotID, entry.HouseID, entry.Quantity)
+			if err != nil {
+				return nil, nil, err
+			}
+		}
+
+		movement := genmodel.PharmaSheetStockMovements{
+			ID:           uuid.MustParse(generator.UUID()),
+			HouseID:      entry.HouseID,
+			BrandID:      entry.BrandID,
+			LotID:        lotID,
+			MovementType: entry.MovementType,
+			Quantity:     entry.Quantity,
+			Reason:       entry.Reason,
+			TransferID:   entry.TransferID,
+			CreatedBy:    &userID,
+			CreatedAt:    now,
+			ConfirmedBy:  entry.ConfirmedBy,
+		}
+		sql, args := table.PharmaSheetStockMovements.
+			INSERT(
+				table.PharmaSheetStockMovements.ID,
+				table.PharmaSheetStockMovements.HouseID,
+				table.PharmaSheetStockMovements.BrandID,
+				table.PharmaSheetStockMovements.LotID,
+				table.PharmaSheetStockMovements.MovementType,
+				table.PharmaSheetStockMovements.Quantity,
+				table.PharmaSheetStockMovements.Reason,
+				table.PharmaSheetStockMovements.TransferID,
+				table.PharmaSheetStockMovements.CreatedBy,
+				table.PharmaSheetStockMovements.CreatedAt,
+				table.PharmaSheetStockMovements.ConfirmedBy,
+			).
+			MODEL(movement).
+			Sql()
+		if _, err := tx.Exec(ctx, sql, args...); err != nil {
+			logger.Context(ctx).Error(err)
+			return nil, nil, err
+		}
+		ids = append(ids, movement.ID.String())
+	}
+
+	changes := make([]model.StockOnHandChange, 0, len(houseIDs))
+	for _, houseID := range houseIDs {
+		onHand, err := r.getHouseStockBalance(ctx, tx, houseID)
+		if err != nil {
+			return nil, nil, err
+		}
+		changes = append(changes, model.StockOnHandChange{HouseID: houseID, Before: onHand - houseDeltas[houseID], After: onHand})
+	}
+
+	return ids, changes, nil
+}
+
+// TransferMedicineHouse relocates or splits a house, carries the blister history over to the target warehouse
+// and records both legs of every entry under the id of the transfer, all in a single transaction.
+func (r *stock) TransferMedicineHouse(ctx context.Context, transfer model.TransferMedicineHouse) (res model.MedicineHouseTransfer, changes []model.StockOnHandChange, err error) {
+	userID, err := useProfileUserID(ctx)
+	if err != nil {
+		return res, nil, err
+	}
+
+	err = postgresql.Commit(ctx, r.pgPool, func(ctx context.Context, tx pgx.Tx) error {
+		houses := table.PharmaSheetMedicineHouses
+		target := transfer.Target
+		now := time.Now()
+
+		switch transfer.Mode {
+		case genmodel.PharmaSheetHouseTransferMode_Move:
+			target.ID = transfer.Source.ID
+			if target.Label == nil {
+				target.Label = transfer.Source.Label
+			}
+			sql, args := houses.
+				UPDATE(houses.WarehouseID, houses.Locker, houses.Floor, houses.No, houses.Label, houses.UpdatedAt).
+				MODEL(genmodel.PharmaSheetMedicineHouses{
+					WarehouseID: target.WarehouseID,
+					Locker:      target.Locker,
+					Floor:       target.Floor,
+					No:          target.No,
+					Label:       target.Label,
+					UpdatedAt:   now,
+				}).
+				WHERE(houses.ID.EQ(postgres.UUID(target.ID))).
+				Sql()
+			if _, err := tx.Exec(ctx, sql, args...); err != nil {
+				logger.Context(ctx).Error(err)
+				return err
+			}
+
+			// the reorder point of the house itself goes along with the house
+			levels := table.PharmaSheetStockLevels
+			sql, args = levels.
+				UPDATE(levels.WarehouseID, levels.UpdatedAt).
+				SET(postgres.String(target.WarehouseID), postgres.TimestampzT(now)).
+				WHERE(levels.HouseID.EQ(postgres.UUID(target.ID))).
+				Sql()
+			if _, err := tx.Exec(ctx, sql, args...); err != nil {
+				logger.Context(ctx).Error(err)
+				return err
+			}
+
+		case genmodel.PharmaSheetHouseTransferMode_Split:
+			query, args := houses.
+				SELECT(houses.ID).
+				WHERE(
+					houses.WarehouseID.EQ(postgres.String(target.WarehouseID)).
+						AND(houses.MedicationID.EQ(postgres.String(target.MedicationID))).
+						AND(houses.Locker.EQ(postgres.String(target.Locker))).
+						AND(houses.Floor.EQ(postgres.Int32(target.Floor))).
+						AND(houses.No.EQ(postgres.Int32(target.No))).
+						AND(houses.DeletedAt.IS_NULL()),
+				).
+				Sql()
+			err := tx.QueryRow(ctx, query, args...).Scan(&target.ID)
+			if errors.Is(err, pgx.ErrNoRows) {
+				if target.Label == nil {
+					target.Label = transfer.Source.Label
+				}
+				target.ID = uuid.MustParse(generator.UUID())
+				sql, args := houses.
+					INSERT(houses.ID, houses.MedicationID, houses.WarehouseID, houses.Locker, houses.Floor, houses.No, houses.Label, houses.CreatedAt, houses.UpdatedAt).
+					MODEL(genmodel.PharmaSheetMedicineHouses{
+						ID:           target.ID,
+						MedicationID: target.MedicationID,
+						WarehouseID:  target.WarehouseID,
+						Locker:       target.Locker,
+						Floor:        target.Floor,
+						No:           target.No,
+						Label:        target.Label,
+						CreatedAt:    now,
+						UpdatedAt:    now,
+					}).
+					Sql()
+				_, err = tx.Exec(ctx, sql, args...)
+			}
+			if err != nil {
+				logger.Context(ctx).Error(err)
+				return err
+			}
+			if target.ID == transfer.Source.ID {
+				return errors.New("target house must be different from source house")
+			}
+		}
+
+		if transfer.Source.WarehouseID != target.WarehouseID {
+			if err := r.copyMedicineBlisterDateHistories(ctx, tx, target.MedicationID, transfer.Source.WarehouseID, target.WarehouseID); err != nil {
+				return err
+			}
+		}
+
+		res = model.MedicineHouseTransfer{
+			ID:                uuid.MustParse(generator.UUID()),
+			Mode:              transfer.Mode,
+			MedicationID:      target.MedicationID,
+			SourceHouseID:     &transfer.Source.ID,
+			SourceWarehouseID: transfer.Source.WarehouseID,
+			SourceAddress:     transfer.Source.Address(),
+			TargetHouseID:     &target.ID,
+			TargetWarehouseID: target.WarehouseID,
+			TargetAddress:     target.Address(),
+			CreatedBy:         &userID,
+			CreatedAt:         now,
+		}
+		transfers := table.PharmaSheetMedicineHouseTransfers
+		sql, args := transfers.
+			INSERT(
+				transfers.ID,
+				transfers.Mode,
+				transfers.MedicationID,
+				transfers.SourceHouseID,
+				transfers.SourceWarehouseID,
+				transfers.SourceAddress,
+				transfers.TargetHouseID,
+				transfers.TargetWarehouseID,
+				transfers.TargetAddress,
+				transfers.CreatedBy,
+				transfers.CreatedAt,
+			).
+			MODEL(genmodel.PharmaSheetMedicineHouseTransfers{
+				ID:                res.ID,
+				Mode:              res.Mode,
+				MedicationID:      res.MedicationID,
+				SourceHouseID:     res.SourceHouseID,
+				SourceWarehouseID: res.SourceWarehouseID,
+				SourceAddress:     res.SourceAddress,
+				TargetHouseID:     res.TargetHouseID,
+				TargetWarehouseID: res.TargetWarehouseID,
+				TargetAddress:     res.TargetAddress,
+				CreatedBy:         res.CreatedBy,
+				CreatedAt:         res.CreatedAt,
+			}).
+			Sql()
+		if _, err := tx.Exec(ctx, sql, args...); err != nil {
+			logger.Context(ctx).Error(err)
+			return err
+		}
+
+		entries := make([]model.StockMovementEntry, 0, len(transfer.Entries)*2)
+		for _, entry := range transfer.Entries {
+			entry.TransferID = &res.ID
+			incoming := entry
+			incoming.HouseID = target.ID
+			incoming.Quantity = -entry.Quantity
+			entries = append(entries, entry, incoming)
+		}
+		res.MovementIDs, changes, err = r.createStockMovements(ctx, tx, entries, userID)
+		return err
+	})
+	if err != nil {
+		return res, nil, err
+	}
+
+	return res, changes, nil
+}
+
+// copyMedicineBlisterDateHistories copies the blister change dates of a medicine into another warehouse, skipping dates it already has
+func (r *stock) copyMedicineBlisterDateHistories(ctx context.Context, tx pgx.Tx, medicationID, sourceWarehouseID, targetWarehouseID string) error {
+	histories := table.PharmaSheetMedicineBlisterDateHistories
+	targetHistories := table.PharmaSheetMedicineBlisterDateHistories.AS("target_histories")
+
+	sql, args := histories.
+		INSERT(histories.ID, histories.WarehouseID, histories.MedicationID, histories.BrandID, histories.BlisterChangeDate, histories.CreatedAt).
+		QUERY(
+			postgres.SELECT(
+				postgres.Raw("gen_random_uuid()"),
+				postgres.String(targetWarehouseID),
+				histories.MedicationID,
+				histories.BrandID,
+				histories.BlisterChangeDate,
+				histories.CreatedAt,
+			).
+				FROM(histories).
+				WHERE(
+					histories.WarehouseID.EQ(postgres.String(sourceWarehouseID)).
+						AND(histories.MedicationID.EQ(postgres.String(medicationID))).
+						AND(postgres.NOT(postgres.EXISTS(
+							postgres.SELECT(postgres.Int(1)).
+								FROM(targetHistories).
+								WHERE(
+									targetHistories.WarehouseID.EQ(postgres.String(targetWarehouseID)).
+										AND(targetHistories.MedicationID.EQ(histories.MedicationID)).
+										AND(targetHistories.BrandID.IS_NOT_DISTINCT_FROM(histories.BrandID)).
+										AND(targetHistories.BlisterChangeDate.EQ(histories.BlisterChangeDate)),
+								),
+						))),
+				),
+		).
+		Sql()
+	if _, err := tx.Exec(ctx, sql, args...); err != nil {
+		logger.Context(ctx).Error(err)
+		return err
+	}
+	return nil
+}
+
+func (r *stock) getHouseStockBalance(ctx context.Context, tx pgx.Tx, houseID uuid.UUID) (balance int64, err error) {
+	query, args := table.PharmaSheetStockMovements.
+		SELECT(postgres.COALESCE(postgres.SUM(table.PharmaSheetStockMovements.Quantity), postgres.Int(0)).AS("balance")).
+		WHERE(table.PharmaSheetStockMovements.HouseID.EQ(postgres.UUID(houseID))).
+		Sql()
+	err = tx.QueryRow(ctx, query, args...).Scan(&balance)
+	if err != nil {
+		logger.Context(ctx).Error(err)
+		return 0, err
+	}
+	return balance, nil
+}
+
+func (r *stock) getStockBalance(ctx context.Context, tx pgx.Tx, houseID uuid.UUID, brandID *uuid.UUID) (balance int64, err error) {
+	query, args := table.PharmaSheetStockMovements.
+		SELECT(postgres.COALESCE(postgres.SUM(table.PharmaSheetStockMovements.Quantity), postgres.Int(0)).AS("balance")).
+		WHERE(
+			table.PharmaSheetStockMovements.HouseID.EQ(postgres.UUID(houseID)).
+				AND(table.PharmaSheetStockMovements.BrandID.IS_NOT_DISTINCT_FROM(uuidOrNull(brandID))),
+		).
+		Sql()
+	err = tx.QueryRow(ctx, query, args...).Scan(&balance)
+	if err != nil {
+		logger.Context(ctx).Error(err)
+		return 0, err
+	}
+	return balance, nil
+}
+
+// moveMedicineLot applies the quantity of a movement to its lot. When a transfer moves a lot into another house,
+// the lot with the same number is used in the destination house, it is created from the source lot when missing.
+func (r *stock) moveMedicineLot(ctx context.Context, tx pgx.Tx, lotID, houseID uuid.UUID, quantity int32) (*uuid.UUID, error) {
+	lots := table.PharmaSheetMedicineLots
+	var sourceLot genmodel.PharmaSheetMedicineLots
+	query, args := lots.
+		SELECT(lots.ID, lots.HouseID, lots.BrandID, lots.LotNumber, lots.ExpiryDate, lots.ReceivedDate).
+		WHERE(lots.ID.EQ(postgres.UUID(lotID))).
+		Sql()
+	err := tx.QueryRow(ctx, query, args...).Scan(
+		&sourceLot.ID,
+		&sourceLot.HouseID,
+		&sourceLot.BrandID,
+		&sourceLot.LotNumber,
+		&sourceLot.ExpiryDate,
+		&sourceLot.ReceivedDate,
+	)
+	if err != nil {
+		logger.Context(ctx).Error(err)
+		return nil, err
+	}
+
+	if sourceLot.HouseID != houseID {
+		query, args = lots.
+			SELECT(lots.ID).
+			WHERE(
+				lots.HouseID.EQ(postgres.UUID(houseID)).
+					AND(lots.BrandID.IS_NOT_DISTINCT_FROM(uuidOrNull(sourceLot.BrandID))).
+					AND(lots.LotNumber.EQ(postgres.String(sourceLot.LotNumber))),
+			).
+			Sql()
+		err = tx.QueryRow(ctx, query, args...).Scan(&lotID)
+		if errors.Is(err, pgx.ErrNoRows) {
+			now := time.Now()
+			lotID = uuid.MustParse(generator.UUID())
+			sql, args := lots.
+				INSERT(lots.ID, lots.HouseID, lots.BrandID, lots.LotNumber, lots.ExpiryDate, lots.Quantity, lots.ReceivedDate, lots.CreatedAt, lots.UpdatedAt).
+				MODEL(genmodel.PharmaSheetMedicineLots{
+					ID:           lotID,
+					HouseID:      houseID,
+					BrandID:      sourceLot.BrandID,
+					LotNumber:    sourceLot.LotNumber,
+					ExpiryDate:   sourceLot.ExpiryDate,
+					Quantity:     0,
+					ReceivedDate: sourceLot.ReceivedDate,
+					CreatedAt:    now,
+					UpdatedAt:    now,
+				}).
+				Sql()
+			_, err = tx.Exec(ctx, sql, args...)
+		}
+		if err != nil {
+			logger.Context(ctx).Error(err)
+			return nil, err
+		}
+	}
+
+	sql, args := lots.
+		UPDATE(lots.Quantity, lots.UpdatedAt).
+		SET(lots.Quantity.ADD(postgres.Int32(quantity)), postgres.TimestampzT(time.Now())).
+		WHERE(lots.ID.EQ(postgres.UUID(lotID)).AND(lots.Quantity.ADD(postgres.Int32(quantity)).GT_EQ(postgres.Int(0)))).
+		Sql()
+	result, err := tx.Exec(ctx, sql, args...)
+	if err != nil {
+		logger.Context(ctx).Error(err)
+		return nil, err
+	}
+	if result.RowsAffected() == 0 {
+		return nil, model.ErrInsufficientStock
+	}
+
+	return &lotID, nil
+}
+
+func (r *stock) ListStockMovements(ctx context.Context, filter model.FilterStockMovement) (data []model.StockMovement, total uint64, err error) {
+	condition := table.PharmaSheetMedicineHouses.WarehouseID.EQ(postgres.String(filter.WarehouseID))
+	if filter.MedicationID != "" {
+		condition = condition.AND(table.PharmaSheetMedicineHouses.MedicationID.EQ(postgres.String(filter.MedicationID)))
+	}
+	if filter.HouseID != nil {
+		condition = condition.AND(table.PharmaSheetStockMovements.HouseID.EQ(postgres.UUID(*filter.HouseID)))
+	}
+	if filter.MovementType != "" {
+		condition = condition.AND(table.PharmaSheetStockMovements.MovementType.EQ(postgres.NewEnumValue(filter.MovementType)))
+	}
+	if search := strings.TrimSpace(filter.Search); search != "" {
+		search := postgres.String("%" + strings.ToLower(search) + "%")
+		condition = condition.AND(
+			postgres.OR(
+				postgres.LOWER(table.PharmaSheetMedicineHouses.MedicationID).LIKE(search),
+				postgres.LOWER(table.PharmaSheetMedicineBrands.TradeID).LIKE(search),
+				postgres.LOWER(table.PharmaSheetMedicineLots.LotNumber).LIKE(search),
+				postgres.LOWER(table.PharmaSheetStockMovements.Reason).LIKE(search),
+			),
+		)
+	}
+
+	confirmedUsers := table.PharmaSheetUsers.AS("confirmed_users")
+	from := table.PharmaSheetStockMovements.
+		INNER_JOIN(table.PharmaSheetMedicineHouses, table.PharmaSheetMedicineHouses.ID.EQ(table.PharmaSheetStockMovements.HouseID)).
+		LEFT_JOIN(table.PharmaSheetMedicineBrands, table.PharmaSheetMedicineBrands.ID.EQ(table.PharmaSheetStockMovements.BrandID)).
+		LEFT_JOIN(table.PharmaSheetMedicineLots, table.PharmaSheetMedicineLots.ID.EQ(table.PharmaSheetStockMovements.LotID)).
+		LEFT_JOIN(table.PharmaSheetUsers, table.PharmaSheetUsers.UserID.EQ(table.PharmaSheetStockMovements.CreatedBy)).
+		LEFT_JOIN(confirmedUsers, confirmedUsers.UserID.EQ(table.PharmaSheetStockMovements.ConfirmedBy))
+
+	query, args := from.
+		SELECT(postgres.COUNT(table.PharmaSheetStockMovements.ID).AS("total")).
+		WHERE(condition).
+		Sql()
+	err = r.pgPool.QueryRow(ctx, query, args...).Scan(&total)
+	if err != nil {
+		logger.Context(ctx).Error(err)
+		return
+	}
+
+	if total == 0 {
+		return
+	}
+
+	query, args = from.
+		SELECT(
+			table.PharmaSheetStockMovements.ID,
+			table.PharmaSheetStockMovements.HouseID,
+			table.PharmaSheetStockMovements.BrandID,
+			table.PharmaSheetStockMovements.LotID,
+			table.PharmaSheetStockMovements.MovementType,
+			table.PharmaSheetStockMovements.Quantity,
+			table.PharmaSheetStockMovements.Reason,
+			table.PharmaSheetStockMovements.TransferID,
+			table.PharmaSheetStockMovements.CreatedBy,
+			table.PharmaSheetStockMovements.CreatedAt,
+			table.PharmaSheetStockMovements.ConfirmedBy,
+			table.PharmaSheetMedicineHouses.WarehouseID,
+			table.PharmaSheetMedicineHouses.MedicationID,
+			table.PharmaSheetMedicineHouses.Locker,
+			table.PharmaSheetMedicineHouses.Floor,
+			table.PharmaSheetMedicineHouses.No,
+			table.PharmaSheetMedicineBrands.TradeID,
+			table.PharmaSheetMedicineLots.LotNumber,
+			table.PharmaSheetUsers.DisplayName,
+			confirmedUsers.DisplayName,
+		).
+		WHERE(condition).
+		ORDER_BY(table.PharmaSheetStockMovements.CreatedAt.DESC(), table.PharmaSheetStockMovements.ID.ASC()).
+		LIMIT(int64(filter.Limit)).
+		OFFSET(int64(filter.Offset)).
+		Sql()
+
+	rows, err := r.pgPool.Query(ctx, query, args...)
+	if err != nil {
+		logger.Context(ctx).Error(err)
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var movement model.StockMovement
+		err = rows.Scan(
+			&movement.ID,
+			&movement.HouseID,
+			&movement.BrandID,
+			&movement.LotID,
+			&movement.MovementType,
+			&movement.Quantity,
+			&movement.Reason,
+			&movement.TransferID,
+			&movement.CreatedBy,
+			&movement.CreatedAt,
+			&movement.ConfirmedBy,
+			&movement.WarehouseID,
+			&movement.MedicationID,
+			&movement.Locker,
+			&movement.Floor,
+			&movement.No,
+			&movement.TradeID,
+			&movement.LotNumber,
+			&movement.CreatedName,
+			&movement.ConfirmedName,
+		)
+		if err != nil {
+			logger.Context(ctx).Error(err)
+			return nil, 0, err
+		}
+		data = append(data, movement)
+	}
+
+	return data, total, nil
+}
+
+func (r *stock) ListStockBalances(ctx context.Context, filter model.FilterStockBalance) ([]model.StockBalance, error) {
+	condition := table.PharmaSheetMedicineHouses.WarehouseID.EQ(postgres.String(filter.WarehouseID))
+	if filter.MedicationID != "" {
+		condition = condition.AND(table.PharmaSheetMedicineHouses.MedicationID.EQ(postgres.String(filter.MedicationID)))
+	}
+	if filter.HouseID != nil {
+		condition = condition.AND(table.PharmaSheetMedicineHouses.ID.EQ(postgres.UUID(*filter.HouseID)))
+	}
+
+	query, args := table.PharmaSheetStockMovements.
+		INNER_JOIN(table.PharmaSheetMedicineHouses, table.PharmaSheetMedicineHouses.ID.EQ(table.PharmaSheetStockMovements.HouseID).AND(table.PharmaSheetMedicineHouses.DeletedAt.IS_NULL())).
+		INNER_JOIN(table.PharmaSheetMedicines, table.PharmaSheetMedicines.MedicationID.EQ(table.PharmaSheetMedicineHouses.MedicationID)).
+		LEFT_JOIN(table.PharmaSheetMedicineBrands, table.PharmaSheetMedicineBrands.ID.EQ(table.PharmaSheetStockMovements.BrandID)).
+		SELECT(
+			table.PharmaSheetMedicineHouses.ID,
+			table.PharmaSheetMedicineHouses.WarehouseID,
+			table.PharmaSheetMedicineHouses.MedicationID,
+			table.PharmaSheetMedicines.MedicalName,
+			table.PharmaSheetMedicineHouses.Locker,
+			table.PharmaSheetMedicineHouses.Floor,
+			table.PharmaSheetMedicineHouses.No,
+			table.PharmaSheetStockMovements.BrandID,
+			table.PharmaSheetMedicineBrands.TradeID,
+			table.PharmaSheetMedicineBrands.TradeName,
+			postgres.SUM(table.PharmaSheetStockMovements.Quantity).AS("on_hand"),
+		).
+		WHERE(condition).
+		GROUP_BY(
+			table.PharmaSheetMedicineHouses.ID,
+			table.PharmaSheetMedicineHouses.WarehouseID,
+			table.PharmaSheetMedicineHouses.MedicationID,
+			table.PharmaSheetMedicines.MedicalName,
+			table.PharmaSheetMedicineHouses.Locker,
+			table.PharmaSheetMedicineHouses.Floor,
+			table.PharmaSheetMedicineHouses.No,
+			table.PharmaSheetStockMovements.BrandID,
+			table.PharmaSheetMedicineBrands.TradeID,
+			table.PharmaSheetMedicineBrands.TradeName,
+		).
+		ORDER_BY(
+			table.PharmaSheetMedicineHouses.MedicationID.ASC(),
+			table.PharmaSheetMedicineHouses.Locker.ASC(),
+			table.PharmaSheetMedicineHouses.Floor.ASC(),
+			table.PharmaSheetMedicineHouses.No.ASC(),
+			table.PharmaSheetMedicineBrands.TradeID.ASC().NULLS_FIRST(),
+		).
+		Sql()
+
+	rows, err := r.pgPool.Query(ctx, query, args...)
+	if err != nil {
+		logger.Context(ctx).Error(err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	var balances []model.StockBalance
+	for rows.Next() {
+		var balance model.StockBalance
+		err = rows.Scan(
+			&balance.HouseID,
+			&balance.WarehouseID,
+			&balance.MedicationID,
+			&balance.MedicalName,
+			&balance.Locker,
+			&balance.Floor,
+			&balance.No,
+			&balance.BrandID,
+			&balance.TradeID,
+			&balance.TradeName,
+			&balance.OnHand,
+		)
+		if err != nil {
+			logger.Context(ctx).Error(err)
+			return nil, err
+		}
+		balances = append(balances, balance)
+	}
+
+	return balances, nil
+}
+
+func (r *stock) ListStockLevels(ctx context.Context, warehouseID string) ([]model.StockLevel, error) {
+	query, args := table.PharmaSheetStockLevels.
+		LEFT_JOIN(table.PharmaSheetMedicineHouses, table.PharmaSheetMedicineHouses.ID.EQ(table.PharmaSheetStockLevels.HouseID)).
+		SELECT(
+			table.PharmaSheetStockLevels.ID,
+			table.PharmaSheetStockLevels.WarehouseID,
+			table.PharmaSheetStockLevels.MedicationID,
+			table.PharmaSheetStockLevels.HouseID,
+			table.PharmaSheetStockLevels.MinQuantity,
+			table.PharmaSheetStockLevels.MaxQuantity,
+			table.PharmaSheetMedicineHouses.Locker,
+			table.PharmaSheetMedicineHouses.Floor,
+			table.PharmaSheetMedicineHouses.No,
+		).
+		WHERE(table.PharmaSheetStockLevels.WarehouseID.EQ(postgres.String(warehouseID))).
+		ORDER_BY(
+			table.PharmaSheetStockLevels.MedicationID.ASC(),
+			table.PharmaSheetMedicineHouses.Locker.ASC().NULLS_FIRST(),
+			table.PharmaSheetMedicineHouses.Floor.ASC(),
+			table.PharmaSheetMedicineHouses.No.ASC(),
+		).
+		Sql()
+
+	rows, err := r.pgPool.Query(ctx, query, args...)
+	if err != nil {
+		logger.Context(ctx).Error(err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	var levels []model.StockLevel
+	for rows.Next() {
+		var level model.StockLevel
+		err = rows.Scan(
+			&level.ID,
+			&level.WarehouseID,
+			&level.MedicationID,
+			&level.HouseID,
+			&level.MinQuantity,
+			&level.MaxQuantity,
+			&level.Locker,
+			&level.Floor,
+			&level.No,
+		)
+		if err != nil {
+			logger.Context(ctx).Error(err)
+			return nil, err
+		}
+		levels = append(levels, level)
+	}
+
+	return levels, nil
+}
+
+func (r *stock) GetStockLevel(ctx context.Context, id uuid.UUID) (level model.StockLevel, err error) {
+	query, args := table.PharmaSheetStockLevels.
+		SELECT(
+			table.PharmaSheetStockLevels.ID,
+			table.PharmaSheetStockLevels.WarehouseID,
+			table.PharmaSheetStockLevels.MedicationID,
+			table.PharmaSheetStockLevels.HouseID,
+			table.PharmaSheetStockLevels.MinQuantity,
+			table.PharmaSheetStockLevels.MaxQuantity,
+		).
+		WHERE(table.PharmaSheetStockLevels.ID.EQ(postgres.UUID(id))).
+		Sql()
+
+	err = r.pgPool.QueryRow(ctx, query, args...).Scan(
+		&level.ID,
+		&level.WarehouseID,
+		&level.MedicationID,
+		&level.HouseID,
+		&level.MinQuantity,
+		&level.MaxQuantity,
+	)
+	if err != nil {
+		logger.Context(ctx).Error(err)
+		return level, err
+	}
+
+	return level, nil
+}
+
+func (r *stock) UpsertStockLevel(ctx context.Context, req model.UpsertStockLevelRequest) (string, error) {
+	levels := table.PharmaSheetStockLevels
+
+	maxQuantity := postgres.IntExp(postgres.NULL)
+	if req.MaxQuantity != nil {
+		maxQuantity = postgres.Int32(*req.MaxQuantity)
+	}
+
+	// the unique index is built on an expression of the nullable house, so the upsert is done by hand
+	var id string
+	query, args := levels.
+		UPDATE(levels.MinQuantity, levels.MaxQuantity, levels.UpdatedAt).
+		SET(postgres.Int32(*req.MinQuantity), maxQuantity, postgres.TimestampzT(time.Now())).
+		WHERE(
+			levels.WarehouseID.EQ(postgres.String(req.WarehouseID)).
+				AND(levels.MedicationID.EQ(postgres.String(req.MedicationID))).
+				AND(levels.HouseID.IS_NOT_DISTINCT_FROM(uuidOrNull(req.HouseID))),
+		).
+		RETURNING(levels.ID).
+		Sql()
+	err := r.pgPool.QueryRow(ctx, query, args...).Scan(&id)
+	if err == nil {
+		return id, nil
+	}
+	if !errors.Is(err, pgx.ErrNoRows) {
+		logger.Context(ctx).Error(err)
+		return "", err
+	}
+
+	now := time.Now()
+	level := genmodel.PharmaSheetStockLevels{
+		ID:           uuid.MustParse(generator.UUID()),
+		WarehouseID:  req.WarehouseID,
+		MedicationID: req.MedicationID,
+		HouseID:      req.HouseID,
+		MinQuantity:  *req.MinQuantity,
+		MaxQuantity:  req.MaxQuantity,
+		CreatedAt:    now,
+		UpdatedAt:    now,
+	}
+	sql, args := levels.
+		INSERT(
+			levels.ID,
+			levels.WarehouseID,
+			levels.MedicationID,
+			levels.HouseID,
+			levels.MinQuantity,
+			levels.MaxQuantity,
+			levels.CreatedAt,
+			levels.UpdatedAt,
+		).
+		MODEL(level).
+		Sql()
+
+	_, err = r.pgPool.Exec(ctx, sql, args...)
+	if err != nil {
+		logger.Context(ctx).Error(err)
+		return "", err
+	}
+
+	return level.ID.String(), nil
+}
+
+func (r *stock) DeleteStockLevel(ctx context.Context, id uuid.UUID) (int64, error) {
+	stmt, args := table.PharmaSheetStockLevels.DELETE().WHERE(table.PharmaSheetStockLevels.ID.EQ(postgres.UUID(id))).Sql()
+	result, err := r.pgPool.Exec(ctx, stmt, args...)
+	if err != nil {
+		logger.Context(ctx).Error(err)
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+// lockStockHouses holds the stock of the houses until the end of the transaction, a house may be locked again by the same transaction
+func lockStockHouses(ctx context.Context, tx pgx.Tx, houseIDs ...uuid.UUID) error {
+	// locks are always taken in the same order to avoid dead locks between concurrent transactions
+	lockKeys := make([]string, 0, len(houseIDs))
+	for _, houseID := range houseIDs {
+		lockKeys = append(lockKeys, houseID.String())
+	}
+	slices.Sort(lockKeys)
+
+	for _, key := range lockKeys {
+		sql, args := postgres.RawStatement("SELECT pg_advisory_xact_lock(hashtextextended(#key, 0))", postgres.RawArgs{"#key": key}).Sql()
+		if _, err := tx.Exec(ctx, sql, args...); err != nil {
+			logger.Context(ctx).Error(err)
+			return err
+		}
+	}
+	return nil
+}
+
+func stockKey(houseID uuid.UUID, brandID *uuid.UUID) string {
+	return model.StockBalance{HouseID: houseID, BrandID: brandID}.Key()
+}
+
+func uuidOrNull(id *uuid.UUID) postgres.StringExpression {
+	if id == nil {
+		return postgres.StringExp(postgres.NULL)
+	}
+	return postgres.UUID(*id)
+}
+
+func useProfileUserID(ctx context.Context) (uuid.UUID, error) {
+	userProfile, err := profile.UseProfile(ctx)
+	if err != nil {
+		logger.Context(ctx).Error(err)
+		return uuid.Nil, err
+	}
+	userID, err := uuid.Parse(userProfile.UserID)
+	if err != nil {
+		logger.Context(ctx).Error(err)
+		return uuid.Nil, err
+	}
+	return userID, nil
+}
@@ -0,0 +1,285 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-jet/jet/v2/postgres"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/kinkando/pharma-sheet-service/.gen/pharma_sheet/public/enum"
+	"github.com/kinkando/pharma-sheet-service/.gen/pharma_sheet/public/table"
+	"github.com/kinkando/pharma-sheet-service/model"
+	"github.com/kinkando/pharma-sheet-service/pkg/database/postgresql"
+	"github.com/kinkando/pharma-sheet-service/pkg/logger"
+)
+
+type Trash interface {
+	ListDeletedWarehouses(ctx context.Context, userID string) ([]model.DeletedWarehouse, error)
+	ListWarehouseTrash(ctx context.Context, warehouseID string) (model.WarehouseTrash, error)
+	GetDeletedMedicineBrand(ctx context.Context, id uuid.UUID) (model.DeletedMedicineBrand, error)
+	GetDeletedMedicineHouse(ctx context.Context, id uuid.UUID) (model.DeletedMedicineHouse, error)
+
+	RestoreWarehouse(ctx context.Context, warehouseID string) (int64, error)
+	RestoreMedicine(ctx context.Context, medicationID string) (int64, error)
+	RestoreMedicineBrand(ctx context.Context, id uuid.UUID) (int64, error)
+	RestoreMedicineHouse(ctx context.Context, id uuid.UUID) (int64, error)
+
+	PurgeTrash(ctx context.Context, before time.Time) (model.PurgedTrash, error)
+}
+
+type trash struct {
+	pgPool *pgxpool.Pool
+}
+
+func NewTrashRepository(pgPool *pgxpool.Pool) Trash {
+	return &trash{pgPool: pgPool}
+}
+
+// ListDeletedWarehouses lists the deleted warehouses the user was approved to, along with the role of the user
+func (r *trash) ListDeletedWarehouses(ctx context.Context, userID string) ([]model.DeletedWarehouse, error) {
+	warehouses := table.PharmaSheetWarehouses
+	warehouseUsers := table.PharmaSheetWarehouseUsers
+
+	query, args := warehouses.
+		INNER_JOIN(warehouseUsers, warehouseUsers.WarehouseID.EQ(warehouses.WarehouseID)).
+		SELECT(warehouses.WarehouseID, warehouses.Name, warehouseUsers.Role, warehouses.DeletedAt).
+		WHERE(
+			warehouses.DeletedAt.IS_NOT_NULL().
+				AND(warehouseUsers.UserID.EQ(postgres.UUID(uuid.MustParse(userID)))).
+				AND(warehouseUsers.Status.EQ(enum.PharmaSheetApprovalStatus.Approved)),
+		).
+		ORDER_BY(warehouses.DeletedAt.DESC()).
+		Sql()
+
+	rows, err := r.pgPool.Query(ctx, query, args...)
+	if err != nil {
+		logger.Context(ctx).Error(err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	data := []model.DeletedWarehouse{}
+	for rows.Next() {
+		var warehouse model.DeletedWarehouse
+		if err = rows.Scan(&warehouse.WarehouseID, &warehouse.Name, &warehouse.Role, &warehouse.DeletedAt); err != nil {
+			logger.Context(ctx).Error(err)
+			return nil, err
+		}
+		data = append(data, warehouse)
+	}
+
+	return data, nil
+}
+
+// ListWarehouseTrash lists the deleted houses of the warehouse, a deleted medicine or brand belongs to the trash
+// of every warehouse its medicine has a house in, deleted or not.
+func (r *trash) ListWarehouseTrash(ctx context.Context, warehouseID string) (data model.WarehouseTrash, err error) {
+	medicines := table.PharmaSheetMedicines
+	brands := table.PharmaSheetMedicineBrands
+	houses := table.PharmaSheetMedicineHouses
+
+	warehouseMedicationIDs := houses.SELECT(houses.MedicationID).WHERE(houses.WarehouseID.EQ(postgres.String(warehouseID)))
+
+	query, args := medicines.
+		SELECT(medicines.MedicationID, medicines.MedicalName, medicines.DeletedAt).
+		WHERE(medicines.DeletedAt.IS_NOT_NULL().AND(medicines.MedicationID.IN(warehouseMedicationIDs))).
+		ORDER_BY(medicines.DeletedAt.DESC()).
+		Sql()
+	rows, err := r.pgPool.Query(ctx, query, args...)
+	if err != nil {
+		logger.Context(ctx).Error(err)
+		return data, err
+	}
+	defer rows.Close()
+
+	data.Medicines = []model.DeletedMedicine{}
+	for rows.Next() {
+		var medicine model.DeletedMedicine
+		if err = rows.Scan(&medicine.MedicationID, &medicine.MedicalName, &medicine.DeletedAt); err != nil {
+			logger.Context(ctx).Error(err)
+			return data, err
+		}
+		data.Medicines = append(data.Medicines, medicine)
+	}
+
+	query, args = brands.
+		SELECT(brands.ID, brands.MedicationID, brands.TradeID, brands.TradeName, brands.DeletedAt).
+		WHERE(brands.DeletedAt.IS_NOT_NULL().AND(brands.MedicationID.IN(warehouseMedicationIDs))).
+		ORDER_BY(brands.DeletedAt.DESC()).
+		Sql()
+	rows, err = r.pgPool.Query(ctx, query, args...)
+	if err != nil {
+		logger.Context(ctx).Error(err)
+		return data, err
+	}
+	defer rows.Close()
+
+	data.Brands = []model.DeletedMedicineBrand{}
+	for rows.Next() {
+		var brand model.DeletedMedicineBrand
+		if err = rows.Scan(&brand.ID, &brand.MedicationID, &brand.TradeID, &brand.TradeName, &brand.DeletedAt); err != nil {
+			logger.Context(ctx).Error(err)
+			return data, err
+		}
+		data.Brands = append(data.Brands, brand)
+	}
+
+	query, args = houses.
+		SELECT(houses.ID, houses.WarehouseID, houses.MedicationID, houses.Locker, houses.Floor, houses.No, houses.Label, houses.DeletedAt).
+		WHERE(houses.DeletedAt.IS_NOT_NULL().AND(houses.WarehouseID.EQ(postgres.String(warehouseID)))).
+		ORDER_BY(houses.DeletedAt.DESC()).
+		Sql()
+	rows, err = r.pgPool.Query(ctx, query, args...)
+	if err != nil {
+		logger.Context(ctx).Error(err)
+		return data, err
+	}
+	defer rows.Close()
+
+	data.Houses = []model.DeletedMedicineHouse{}
+	for rows.Next() {
+		var house model.DeletedMedicineHouse
+		if err = rows.Scan(&house.ID, &house.WarehouseID, &house.MedicationID, &house.Locker, &house.Floor, &house.No, &house.Label, &house.DeletedAt); err != nil {
+			logger.Context(ctx).Error(err)
+			return data, err
+		}
+		data.Houses = append(data.Houses, house)
+	}
+
+	return data, nil
+}
+
+func (r *trash) GetDeletedMedicineBrand(ctx context.Context, id uuid.UUID) (brand model.DeletedMedicineBrand, err error) {
+	brands := table.PharmaSheetMedicineBrands
+	query, args := brands.
+		SELECT(brands.ID, brands.MedicationID, brands.TradeID, brands.TradeName, brands.DeletedAt).
+		WHERE(brands.ID.EQ(postgres.UUID(id)).AND(brands.DeletedAt.IS_NOT_NULL())).
+		Sql()
+
+	err = r.pgPool.QueryRow(ctx, query, args...).Scan(&brand.ID, &brand.MedicationID, &brand.TradeID, &brand.TradeName, &brand.DeletedAt)
+	if err != nil {
+		logger.Context(ctx).Error(err)
+		return brand, err
+	}
+	return brand, nil
+}
+
+func (r *trash) GetDeletedMedicineHouse(ctx context.Context, id uuid.UUID) (house model.DeletedMedicineHouse, err error) {
+	houses := table.PharmaSheetMedicineHouses
+	query, args := houses.
+		SELECT(houses.ID, houses.WarehouseID, houses.MedicationID, houses.Locker, houses.Floor, houses.No, houses.Label, houses.DeletedAt).
+		WHERE(houses.ID.EQ(postgres.UUID(id)).AND(houses.DeletedAt.IS_NOT_NULL())).
+		Sql()
+
+	err = r.pgPool.QueryRow(ctx, query, args...).Scan(&house.ID, &house.WarehouseID, &house.MedicationID, &house.Locker, &house.Floor, &house.No, &house.Label, &house.DeletedAt)
+	if err != nil {
+		logger.Context(ctx).Error(err)
+		return house, err
+	}
+	return house, nil
+}
+
+func (r *trash) RestoreWarehouse(ctx context.Context, warehouseID string) (int64, error) {
+	warehouses := table.PharmaSheetWarehouses
+	return r.restore(ctx, warehouses.
+		UPDATE(warehouses.DeletedAt).
+		SET(postgres.NULL).
+		WHERE(warehouses.WarehouseID.EQ(postgres.String(warehouseID)).AND(warehouses.DeletedAt.IS_NOT_NULL())),
+	)
+}
+
+func (r *trash) RestoreMedicine(ctx context.Context, medicationID string) (int64, error) {
+	medicines := table.PharmaSheetMedicines
+	return r.restore(ctx, medicines.
+		UPDATE(medicines.DeletedAt).
+		SET(postgres.NULL).
+		WHERE(medicines.MedicationID.EQ(postgres.String(medicationID)).AND(medicines.DeletedAt.IS_NOT_NULL())),
+	)
+}
+
+func (r *trash) RestoreMedicineBrand(ctx context.Context, id uuid.UUID) (int64, error) {
+	brands := table.PharmaSheetMedicineBrands
+	return r.restore(ctx, brands.
+		UPDATE(brands.DeletedAt).
+		SET(postgres.NULL).
+		WHERE(brands.ID.EQ(postgres.UUID(id)).AND(brands.DeletedAt.IS_NOT_NULL())),
+	)
+}
+
+func (r *trash) RestoreMedicineHouse(ctx context.Context, id uuid.UUID) (int64, error) {
+	houses := table.PharmaSheetMedicineHouses
+	return r.restore(ctx, houses.
+		UPDATE(houses.DeletedAt).
+		SET(postgres.NULL).
+		WHERE(houses.ID.EQ(postgres.UUID(id)).AND(houses.DeletedAt.IS_NOT_NULL())),
+	)
+}
+
+func (r *trash) restore(ctx context.Context, stmt postgres.UpdateStatement) (int64, error) {
+	sql, args := stmt.Sql()
+	result, err := r.pgPool.Exec(ctx, sql, args...)
+	if err != nil {
+		logger.Context(ctx).Error(err)
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+// PurgeTrash permanently deletes everything deleted before the given time. The brands go first and explicitly,
+// also the ones cascading from their medicine, so that their images are known to the caller. The stock ledger is
+// append-only, so a house or a brand with movements is kept in the trash along with its medicine and warehouse.
+func (r *trash) PurgeTrash(ctx context.Context, before time.Time) (res model.PurgedTrash, err error) {
+	warehouses := table.PharmaSheetWarehouses
+	medicines := table.PharmaSheetMedicines
+	brands := table.PharmaSheetMedicineBrands
+	houses := table.PharmaSheetMedicineHouses
+	movements := table.PharmaSheetStockMovements
+	cutoff := postgres.TimestampzT(before)
+
+	brandsWithMovements := movements.SELECT(movements.BrandID).WHERE(movements.BrandID.IS_NOT_NULL())
+	housesWithMovements := movements.SELECT(movements.HouseID)
+	purgedMedicines := medicines.
+		SELECT(medicines.MedicationID).
+		WHERE(
+			medicines.DeletedAt.LT(cutoff).
+				AND(medicines.MedicationID.NOT_IN(houses.SELECT(houses.MedicationID).WHERE(houses.ID.IN(housesWithMovements)))).
+				AND(medicines.MedicationID.NOT_IN(brands.SELECT(brands.MedicationID).WHERE(brands.ID.IN(brandsWithMovements)))),
+		)
+
+	err = postgresql.Commit(ctx, r.pgPool, func(ctx context.Context, tx pgx.Tx) error {
+		imageURLs, purged, err := deleteMedicineBrands(ctx, tx, brands.DeletedAt.LT(cutoff).AND(brands.ID.NOT_IN(brandsWithMovements)).
+			OR(brands.MedicationID.IN(purgedMedicines)))
+		if err != nil {
+			return err
+		}
+		res.ImageURLs = append(res.ImageURLs, imageURLs...)
+		res.Brands += purged
+
+		for _, purge := range []struct {
+			stmt  postgres.DeleteStatement
+			total *int64
+		}{
+			{houses.DELETE().WHERE(houses.DeletedAt.LT(cutoff).AND(houses.ID.NOT_IN(housesWithMovements))), &res.Houses},
+			{medicines.DELETE().WHERE(medicines.MedicationID.IN(purgedMedicines)), &res.Medicines},
+			{warehouses.DELETE().WHERE(warehouses.DeletedAt.LT(cutoff).AND(warehouses.WarehouseID.NOT_IN(
+				houses.SELECT(houses.WarehouseID).WHERE(houses.ID.IN(housesWithMovements)),
+			))), &res.Warehouses},
+		} {
+			sql, args := purge.stmt.Sql()
+			result, err := tx.Exec(ctx, sql, args...)
+			if err != nil {
+				logger.Context(ctx).Error(err)
+				return err
+			}
+			*purge.total = result.RowsAffected()
+		}
+		return nil
+	})
+	if err != nil {
+		return model.PurgedTrash{}, err
+	}
+
+	return res, nil
+}
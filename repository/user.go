@@ -9,6 +9,7 @@ import (
 
 	"github.com/go-jet/jet/v2/postgres"
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/kinkando/pharma-sheet-service/.gen/pharma_sheet/public/model"
 	"github.com/kinkando/pharma-sheet-service/.gen/pharma_sheet/public/table"
@@ -20,6 +21,7 @@ type User interface {
 	GetUser(ctx context.Context, user model.PharmaSheetUsers) (model.PharmaSheetUsers, error)
 	CreateUser(ctx context.Context, user model.PharmaSheetUsers) (string, error)
 	UpdateUser(ctx context.Context, user model.PharmaSheetUsers) error
+	IsSystemAdmin(ctx context.Context, userID string) (bool, error)
 }
 
 type user struct {
@@ -121,3 +123,17 @@ func (r *user) UpdateUser(ctx context.Context, user model.PharmaSheetUsers) erro
 
 	return nil
 }
+
+func (r *user) IsSystemAdmin(ctx context.Context, userID string) (isSystemAdmin bool, err error) {
+	users := table.PharmaSheetUsers
+	query, args := users.SELECT(users.IsSystemAdmin).WHERE(users.UserID.EQ(postgres.UUID(uuid.MustParse(userID)))).Sql()
+	err = r.pgPool.QueryRow(ctx, query, args...).Scan(&isSystemAdmin)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return false, nil
+	}
+	if err != nil {
+		logger.Context(ctx).Error(err)
+		return false, err
+	}
+	return isSystemAdmin, nil
+}
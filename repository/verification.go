@@ -0,0 +1,167 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-jet/jet/v2/postgres"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+	genmodel "github.com/kinkando/pharma-sheet-service/.gen/pharma_sheet/public/model"
+	"github.com/kinkando/pharma-sheet-service/.gen/pharma_sheet/public/table"
+	"github.com/kinkando/pharma-sheet-service/model"
+	"github.com/kinkando/pharma-sheet-service/pkg/generator"
+	"github.com/kinkando/pharma-sheet-service/pkg/logger"
+)
+
+type Verification interface {
+	CreatePickVerification(ctx context.Context, verification model.PickVerification) (model.PickVerification, error)
+	ListPickVerifications(ctx context.Context, filter model.FilterPickVerification) (data []model.PickVerification, total uint64, err error)
+}
+
+type verification struct {
+	pgPool *pgxpool.Pool
+}
+
+func NewVerificationRepository(pgPool *pgxpool.Pool) Verification {
+	return &verification{pgPool: pgPool}
+}
+
+func (r *verification) CreatePickVerification(ctx context.Context, verification model.PickVerification) (model.PickVerification, error) {
+	userID, err := useProfileUserID(ctx)
+	if err != nil {
+		return verification, err
+	}
+
+	verification.ID = uuid.MustParse(generator.UUID())
+	verification.CreatedBy = &userID
+	verification.CreatedAt = time.Now()
+
+	pickVerifications := table.PharmaSheetPickVerifications
+	sql, args := pickVerifications.
+		INSERT(
+			pickVerifications.ID,
+			pickVerifications.WarehouseID,
+			pickVerifications.HouseID,
+			pickVerifications.MedicationID,
+			pickVerifications.Address,
+			pickVerifications.ProductCode,
+			pickVerifications.BrandID,
+			pickVerifications.ScannedMedicationID,
+			pickVerifications.Result,
+			pickVerifications.CreatedBy,
+			pickVerifications.CreatedAt,
+		).
+		MODEL(genmodel.PharmaSheetPickVerifications{
+			ID:                  verification.ID,
+			WarehouseID:         verification.WarehouseID,
+			HouseID:             verification.HouseID,
+			MedicationID:        verification.MedicationID,
+			Address:             verification.Address,
+			ProductCode:         verification.ProductCode,
+			BrandID:             verification.BrandID,
+			ScannedMedicationID: verification.ScannedMedicationID,
+			Result:              verification.Result,
+			CreatedBy:           verification.CreatedBy,
+			CreatedAt:           verification.CreatedAt,
+		}).
+		Sql()
+	if _, err = r.pgPool.Exec(ctx, sql, args...); err != nil {
+		logger.Context(ctx).Error(err)
+		return verification, err
+	}
+
+	return verification, nil
+}
+
+func (r *verification) ListPickVerifications(ctx context.Context, filter model.FilterPickVerification) (data []model.PickVerification, total uint64, err error) {
+	condition := table.PharmaSheetPickVerifications.WarehouseID.EQ(postgres.String(filter.WarehouseID))
+	if filter.MedicationID != "" {
+		condition = condition.AND(table.PharmaSheetPickVerifications.MedicationID.EQ(postgres.String(filter.MedicationID)))
+	}
+	if filter.HouseID != nil {
+		condition = condition.AND(table.PharmaSheetPickVerifications.HouseID.EQ(postgres.UUID(*filter.HouseID)))
+	}
+	if filter.Result != "" {
+		condition = condition.AND(table.PharmaSheetPickVerifications.Result.EQ(postgres.NewEnumValue(filter.Result)))
+	}
+
+	// a verification outlives its medicine, it stays in the listing after the medicine is deleted or merged away
+	from := table.PharmaSheetPickVerifications.
+		LEFT_JOIN(table.PharmaSheetMedicines, table.PharmaSheetMedicines.MedicationID.EQ(table.PharmaSheetPickVerifications.MedicationID)).
+		LEFT_JOIN(table.PharmaSheetMedicineBrands, table.PharmaSheetMedicineBrands.ID.EQ(table.PharmaSheetPickVerifications.BrandID)).
+		LEFT_JOIN(table.PharmaSheetUsers, table.PharmaSheetUsers.UserID.EQ(table.PharmaSheetPickVerifications.CreatedBy))
+
+	query, args := from.
+		SELECT(postgres.COUNT(table.PharmaSheetPickVerifications.ID).AS("total")).
+		WHERE(condition).
+		Sql()
+	err = r.pgPool.QueryRow(ctx, query, args...).Scan(&total)
+	if err != nil {
+		logger.Context(ctx).Error(err)
+		return
+	}
+
+	if total == 0 {
+		return
+	}
+
+	query, args = from.
+		SELECT(
+			table.PharmaSheetPickVerifications.ID,
+			table.PharmaSheetPickVerifications.WarehouseID,
+			table.PharmaSheetPickVerifications.HouseID,
+			table.PharmaSheetPickVerifications.MedicationID,
+			table.PharmaSheetPickVerifications.Address,
+			table.PharmaSheetPickVerifications.ProductCode,
+			table.PharmaSheetPickVerifications.BrandID,
+			table.PharmaSheetPickVerifications.ScannedMedicationID,
+			table.PharmaSheetPickVerifications.Result,
+			table.PharmaSheetPickVerifications.CreatedBy,
+			table.PharmaSheetPickVerifications.CreatedAt,
+			table.PharmaSheetMedicines.MedicalName,
+			table.PharmaSheetMedicineBrands.TradeID,
+			table.PharmaSheetMedicineBrands.TradeName,
+			table.PharmaSheetUsers.DisplayName,
+		).
+		WHERE(condition).
+		ORDER_BY(table.PharmaSheetPickVerifications.CreatedAt.DESC(), table.PharmaSheetPickVerifications.ID.ASC()).
+		LIMIT(int64(filter.Limit)).
+		OFFSET(int64(filter.Offset)).
+		Sql()
+
+	rows, err := r.pgPool.Query(ctx, query, args...)
+	if err != nil {
+		logger.Context(ctx).Error(err)
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var verification model.PickVerification
+		err = rows.Scan(
+			&verification.ID,
+			&verification.WarehouseID,
+			&verification.HouseID,
+			&verification.MedicationID,
+			&verification.Address,
+			&verification.ProductCode,
+			&verification.BrandID,
+			&verification.ScannedMedicationID,
+			&verification.Result,
+			&verification.CreatedBy,
+			&verification.CreatedAt,
+			&verification.MedicalName,
+			&verification.TradeID,
+			&verification.TradeName,
+			&verification.CreatedName,
+		)
+		if err != nil {
+			logger.Context(ctx).Error(err)
+			return nil, 0, err
+		}
+		data = append(data, verification)
+	}
+
+	return data, total, nil
+}
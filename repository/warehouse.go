@@ -17,6 +17,7 @@ import (
 	"github.com/kinkando/pharma-sheet-service/.gen/pharma_sheet/public/table"
 	"github.com/kinkando/pharma-sheet-service/model"
 	"github.com/kinkando/pharma-sheet-service/pkg/database/postgresql"
+	"github.com/kinkando/pharma-sheet-service/pkg/generator"
 	"github.com/kinkando/pharma-sheet-service/pkg/logger"
 	"github.com/kinkando/pharma-sheet-service/pkg/profile"
 )
@@ -26,6 +27,7 @@ type Warehouse interface {
 	GetWarehouses(ctx context.Context) ([]model.Warehouse, error)
 	GetWarehouseDetails(ctx context.Context, filter model.FilterWarehouseDetail) (data []model.WarehouseDetail, total uint64, err error)
 	GetWarehouseRole(ctx context.Context, warehouseID, userID string) (genmodel.PharmaSheetRole, error)
+	IsWarehouseDeleted(ctx context.Context, warehouseID string) (bool, error)
 	CreateWarehouse(ctx context.Context, req model.Warehouse) (string, error)
 	UpdateWarehouse(ctx context.Context, req model.Warehouse) error
 	DeleteWarehouse(ctx context.Context, warehouseID string) error
@@ -40,6 +42,10 @@ type Warehouse interface {
 	CheckConflictWarehouseSheet(ctx context.Context, warehouseID string, spreadsheetID string, sheetID int32) (bool, error)
 	UpsertWarehouseSheet(ctx context.Context, warehouseSheet genmodel.PharmaSheetWarehouseSheets) error
 	DeleteWarehouseSheet(ctx context.Context, warehouseID string) error
+
+	ListWarehouseLockers(ctx context.Context, warehouseID string) ([]model.WarehouseLocker, error)
+	UpsertWarehouseLocker(ctx context.Context, locker model.WarehouseLocker) (string, error)
+	DeleteWarehouseLocker(ctx context.Context, warehouseID string, lockerID uuid.UUID) (int64, error)
 }
 
 type warehouse struct {
@@ -65,7 +71,7 @@ func (r *warehouse) GetWarehouse(ctx context.Context, warehouseID string) (model
 			table.PharmaSheetWarehouseSheets.MedicineBlisterDateHistorySheetName,
 			table.PharmaSheetWarehouseSheets.LatestSyncedAt,
 		).
-		WHERE(table.PharmaSheetWarehouses.WarehouseID.EQ(postgres.String(warehouseID))).
+		WHERE(table.PharmaSheetWarehouses.WarehouseID.EQ(postgres.String(warehouseID)).AND(table.PharmaSheetWarehouses.DeletedAt.IS_NULL())).
 		Sql()
 
 	var warehouse model.Warehouse
@@ -121,7 +127,11 @@ func (r *warehouse) GetWarehouses(ctx context.Context) (warehouses []model.Wareh
 			table.PharmaSheetWarehouseSheets.MedicineBlisterDateHistorySheetName,
 			table.PharmaSheetWarehouseSheets.LatestSyncedAt,
 		).
-		WHERE(table.PharmaSheetWarehouseUsers.UserID.EQ(postgres.UUID(uuid.MustParse(userProfile.UserID))).AND(table.PharmaSheetWarehouseUsers.Status.EQ(enum.PharmaSheetApprovalStatus.Approved))).
+		WHERE(
+			table.PharmaSheetWarehouseUsers.UserID.EQ(postgres.UUID(uuid.MustParse(userProfile.UserID))).AND(
+				table.PharmaSheetWarehouseUsers.Status.EQ(enum.PharmaSheetApprovalStatus.Approved)).AND(
+				table.PharmaSheetWarehouses.DeletedAt.IS_NULL()),
+		).
 		ORDER_BY(table.PharmaSheetWarehouses.Name.ASC()).
 		Sql()
 
@@ -172,7 +182,7 @@ func (r *warehouse) GetWarehouseDetails(ctx context.Context, filter model.Filter
 		return
 	}
 
-	condition := postgres.Bool(true)
+	condition := table.PharmaSheetWarehouses.DeletedAt.IS_NULL()
 	switch filter.Group {
 	case model.MyWarehouse:
 		condition = condition.AND(table.PharmaSheetWarehouseUsers.UserID.EQ(postgres.UUID(uuid.MustParse(userProfile.UserID))).AND(table.PharmaSheetWarehouseUsers.Status.EQ(enum.PharmaSheetApprovalStatus.Approved)))
@@ -311,8 +321,14 @@ func (r *warehouse) UpdateWarehouse(ctx context.Context, req model.Warehouse) er
 	return nil
 }
 
+// DeleteWarehouse moves the warehouse to the trash, it is purged with everything in it after the retention period
 func (r *warehouse) DeleteWarehouse(ctx context.Context, warehouseID string) error {
-	stmt, args := table.PharmaSheetWarehouses.DELETE().WHERE(table.PharmaSheetWarehouses.WarehouseID.EQ(postgres.String(warehouseID))).Sql()
+	warehouses := table.PharmaSheetWarehouses
+	stmt, args := warehouses.
+		UPDATE(warehouses.DeletedAt).
+		SET(postgres.TimestampzT(time.Now())).
+		WHERE(warehouses.WarehouseID.EQ(postgres.String(warehouseID)).AND(warehouses.DeletedAt.IS_NULL())).
+		Sql()
 	result, err := r.pgPool.Exec(ctx, stmt, args...)
 	if err != nil {
 		logger.Context(ctx).Error(err)
@@ -324,13 +340,32 @@ func (r *warehouse) DeleteWarehouse(ctx context.Context, warehouseID string) err
 	return nil
 }
 
+// IsWarehouseDeleted tells whether the warehouse is in the trash, its id stays taken until it is purged
+func (r *warehouse) IsWarehouseDeleted(ctx context.Context, warehouseID string) (isDeleted bool, err error) {
+	warehouses := table.PharmaSheetWarehouses
+	query, args := warehouses.
+		SELECT(warehouses.DeletedAt.IS_NOT_NULL()).
+		WHERE(warehouses.WarehouseID.EQ(postgres.String(warehouseID))).
+		Sql()
+	err = r.pgPool.QueryRow(ctx, query, args...).Scan(&isDeleted)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return false, nil
+	}
+	if err != nil {
+		logger.Context(ctx).Error(err)
+		return false, err
+	}
+	return isDeleted, nil
+}
+
 func (r *warehouse) GetWarehouseRole(ctx context.Context, warehouseID, userID string) (role genmodel.PharmaSheetRole, err error) {
 	query, args := table.PharmaSheetWarehouseUsers.
 		SELECT(table.PharmaSheetWarehouseUsers.Role).
 		WHERE(
 			table.PharmaSheetWarehouseUsers.UserID.EQ(postgres.UUID(uuid.MustParse(userID))).AND(
 				table.PharmaSheetWarehouseUsers.Status.EQ(enum.PharmaSheetApprovalStatus.Approved)).AND(
-				table.PharmaSheetWarehouseUsers.WarehouseID.EQ(postgres.String(warehouseID))),
+				table.PharmaSheetWarehouseUsers.WarehouseID.EQ(postgres.String(warehouseID))).AND(
+				table.PharmaSheetWarehouseUsers.WarehouseID.IN(activeWarehouseIDs())),
 		).
 		Sql()
 
@@ -392,6 +427,9 @@ func (r *warehouse) GetWarehouseUsers(ctx context.Context, warehouseID string, f
 	if filter.Status != "" {
 		condition = condition.AND(table.PharmaSheetWarehouseUsers.Status.EQ(postgres.NewEnumValue(string(filter.Status))))
 	}
+	if filter.UserID != "" {
+		condition = condition.AND(table.PharmaSheetWarehouseUsers.UserID.EQ(postgres.UUID(uuid.MustParse(filter.UserID))))
+	}
 	if strings.TrimSpace(filter.Search) != "" {
 		search := postgres.String("%" + strings.ToLower(filter.Search) + "%")
 		condition = condition.AND(
@@ -639,3 +677,105 @@ func (r *warehouse) DeleteWarehouseSheet(ctx context.Context, warehouseID string
 
 	return nil
 }
+
+func (r *warehouse) ListWarehouseLockers(ctx context.Context, warehouseID string) ([]model.WarehouseLocker, error) {
+	query, args := table.PharmaSheetWarehouseLockers.
+		SELECT(
+			table.PharmaSheetWarehouseLockers.ID,
+			table.PharmaSheetWarehouseLockers.WarehouseID,
+			table.PharmaSheetWarehouseLockers.Name,
+			table.PharmaSheetWarehouseLockers.Floors,
+			table.PharmaSheetWarehouseLockers.SlotsPerFloor,
+		).
+		WHERE(table.PharmaSheetWarehouseLockers.WarehouseID.EQ(postgres.String(warehouseID))).
+		ORDER_BY(table.PharmaSheetWarehouseLockers.Name.ASC()).
+		Sql()
+
+	rows, err := r.pgPool.Query(ctx, query, args...)
+	if err != nil {
+		logger.Context(ctx).Error(err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	var lockers []model.WarehouseLocker
+	for rows.Next() {
+		var locker model.WarehouseLocker
+		err = rows.Scan(
+			&locker.ID,
+			&locker.WarehouseID,
+			&locker.Name,
+			&locker.Floors,
+			&locker.SlotsPerFloor,
+		)
+		if err != nil {
+			logger.Context(ctx).Error(err)
+			return nil, err
+		}
+		lockers = append(lockers, locker)
+	}
+
+	return lockers, nil
+}
+
+func (r *warehouse) UpsertWarehouseLocker(ctx context.Context, locker model.WarehouseLocker) (string, error) {
+	now := time.Now()
+	var id string
+	stmt, args := table.PharmaSheetWarehouseLockers.
+		INSERT(
+			table.PharmaSheetWarehouseLockers.ID,
+			table.PharmaSheetWarehouseLockers.WarehouseID,
+			table.PharmaSheetWarehouseLockers.Name,
+			table.PharmaSheetWarehouseLockers.Floors,
+			table.PharmaSheetWarehouseLockers.SlotsPerFloor,
+			table.PharmaSheetWarehouseLockers.CreatedAt,
+			table.PharmaSheetWarehouseLockers.UpdatedAt,
+		).
+		MODEL(genmodel.PharmaSheetWarehouseLockers{
+			ID:            uuid.MustParse(generator.UUID()),
+			WarehouseID:   locker.WarehouseID,
+			Name:          locker.Name,
+			Floors:        locker.Floors,
+			SlotsPerFloor: locker.SlotsPerFloor,
+			CreatedAt:     now,
+			UpdatedAt:     now,
+		}).
+		ON_CONFLICT(table.PharmaSheetWarehouseLockers.WarehouseID, table.PharmaSheetWarehouseLockers.Name).
+		DO_UPDATE(postgres.SET(
+			table.PharmaSheetWarehouseLockers.Floors.SET(postgres.Int32(locker.Floors)),
+			table.PharmaSheetWarehouseLockers.SlotsPerFloor.SET(postgres.Int32(locker.SlotsPerFloor)),
+			table.PharmaSheetWarehouseLockers.UpdatedAt.SET(postgres.TimestampzT(now)),
+		)).
+		RETURNING(table.PharmaSheetWarehouseLockers.ID).
+		Sql()
+	err := r.pgPool.QueryRow(ctx, stmt, args...).Scan(&id)
+	if err != nil {
+		logger.Context(ctx).Error(err)
+		return "", err
+	}
+
+	return id, nil
+}
+
+func (r *warehouse) DeleteWarehouseLocker(ctx context.Context, warehouseID string, lockerID uuid.UUID) (int64, error) {
+	stmt, args := table.PharmaSheetWarehouseLockers.
+		DELETE().
+		WHERE(
+			table.PharmaSheetWarehouseLockers.WarehouseID.EQ(postgres.String(warehouseID)).
+				AND(table.PharmaSheetWarehouseLockers.ID.EQ(postgres.UUID(lockerID))),
+		).
+		Sql()
+	result, err := r.pgPool.Exec(ctx, stmt, args...)
+	if err != nil {
+		logger.Context(ctx).Error(err)
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+// activeWarehouseIDs selects the warehouses which are not in the trash
+func activeWarehouseIDs() postgres.SelectStatement {
+	return table.PharmaSheetWarehouses.
+		SELECT(table.PharmaSheetWarehouses.WarehouseID).
+		WHERE(table.PharmaSheetWarehouses.DeletedAt.IS_NULL())
+}
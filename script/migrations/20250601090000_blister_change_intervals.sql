@@ -0,0 +1,20 @@
+-- migrate:up
+CREATE TABLE IF NOT EXISTS pharma_sheet_blister_change_intervals (
+  id UUID PRIMARY KEY,
+  warehouse_id TEXT NOT NULL,
+  medication_id TEXT,
+  brand_id UUID,
+  interval_days INT NOT NULL,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  CONSTRAINT fk_blister_change_interval_warehouse_id FOREIGN KEY (warehouse_id) REFERENCES pharma_sheet_warehouses (warehouse_id) ON DELETE CASCADE,
+  CONSTRAINT fk_blister_change_interval_medication_id FOREIGN KEY (medication_id) REFERENCES pharma_sheet_medicines (medication_id) ON DELETE CASCADE,
+  CONSTRAINT fk_blister_change_interval_brand_id FOREIGN KEY (brand_id) REFERENCES pharma_sheet_medicine_brands (id) ON DELETE CASCADE,
+  CONSTRAINT check_blister_change_interval_days CHECK (interval_days > 0)
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS unique_blister_change_interval ON pharma_sheet_blister_change_intervals (warehouse_id, COALESCE(medication_id, ''), COALESCE(brand_id, '00000000-0000-0000-0000-000000000000'));
+
+-- migrate:down
+DROP INDEX IF EXISTS unique_blister_change_interval;
+DROP TABLE IF EXISTS pharma_sheet_blister_change_intervals;
@@ -0,0 +1,23 @@
+-- migrate:up
+CREATE TABLE IF NOT EXISTS pharma_sheet_medicine_lots (
+  id UUID PRIMARY KEY,
+  house_id UUID NOT NULL,
+  brand_id UUID,
+  lot_number TEXT NOT NULL,
+  expiry_date DATE NOT NULL,
+  quantity INT NOT NULL DEFAULT 0,
+  received_date DATE,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  CONSTRAINT fk_medicine_lot_house_id FOREIGN KEY (house_id) REFERENCES pharma_sheet_medicine_houses (id) ON DELETE CASCADE,
+  CONSTRAINT fk_medicine_lot_brand_id FOREIGN KEY (brand_id) REFERENCES pharma_sheet_medicine_brands (id) ON DELETE CASCADE,
+  CONSTRAINT check_medicine_lot_quantity CHECK (quantity >= 0)
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS unique_medicine_lot ON pharma_sheet_medicine_lots (house_id, COALESCE(brand_id, '00000000-0000-0000-0000-000000000000'), lot_number);
+CREATE INDEX IF NOT EXISTS index_medicine_lot_expiry_date ON pharma_sheet_medicine_lots (expiry_date);
+
+-- migrate:down
+DROP INDEX IF EXISTS index_medicine_lot_expiry_date;
+DROP INDEX IF EXISTS unique_medicine_lot;
+DROP TABLE IF EXISTS pharma_sheet_medicine_lots;
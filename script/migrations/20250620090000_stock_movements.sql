@@ -0,0 +1,35 @@
+-- migrate:up
+CREATE TYPE pharma_sheet_stock_movement_type AS ENUM (
+  'RECEIVE',
+  'DISPENSE',
+  'TRANSFER',
+  'ADJUST',
+  'DISPOSE'
+);
+
+CREATE TABLE IF NOT EXISTS pharma_sheet_stock_movements (
+  id UUID PRIMARY KEY,
+  house_id UUID NOT NULL,
+  brand_id UUID,
+  lot_id UUID,
+  movement_type pharma_sheet_stock_movement_type NOT NULL,
+  quantity INT NOT NULL,
+  reason TEXT,
+  transfer_id UUID,
+  created_by UUID,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  CONSTRAINT fk_stock_movement_house_id FOREIGN KEY (house_id) REFERENCES pharma_sheet_medicine_houses (id) ON DELETE RESTRICT,
+  CONSTRAINT fk_stock_movement_brand_id FOREIGN KEY (brand_id) REFERENCES pharma_sheet_medicine_brands (id) ON DELETE RESTRICT,
+  CONSTRAINT fk_stock_movement_lot_id FOREIGN KEY (lot_id) REFERENCES pharma_sheet_medicine_lots (id) ON DELETE SET NULL,
+  CONSTRAINT fk_stock_movement_created_by FOREIGN KEY (created_by) REFERENCES pharma_sheet_users (user_id) ON DELETE SET NULL,
+  CONSTRAINT check_stock_movement_quantity CHECK (quantity <> 0)
+);
+
+CREATE INDEX IF NOT EXISTS index_stock_movement_house_brand ON pharma_sheet_stock_movements (house_id, brand_id);
+CREATE INDEX IF NOT EXISTS index_stock_movement_transfer_id ON pharma_sheet_stock_movements (transfer_id) WHERE transfer_id IS NOT NULL;
+
+-- migrate:down
+DROP INDEX IF EXISTS index_stock_movement_transfer_id;
+DROP INDEX IF EXISTS index_stock_movement_house_brand;
+DROP TABLE IF EXISTS pharma_sheet_stock_movements;
+DROP TYPE IF EXISTS pharma_sheet_stock_movement_type;
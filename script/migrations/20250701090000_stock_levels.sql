@@ -0,0 +1,22 @@
+-- migrate:up
+CREATE TABLE IF NOT EXISTS pharma_sheet_stock_levels (
+  id UUID PRIMARY KEY,
+  warehouse_id TEXT NOT NULL,
+  medication_id TEXT NOT NULL,
+  house_id UUID,
+  min_quantity INT NOT NULL,
+  max_quantity INT,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  CONSTRAINT fk_stock_level_warehouse_id FOREIGN KEY (warehouse_id) REFERENCES pharma_sheet_warehouses (warehouse_id) ON DELETE CASCADE,
+  CONSTRAINT fk_stock_level_medication_id FOREIGN KEY (medication_id) REFERENCES pharma_sheet_medicines (medication_id) ON DELETE CASCADE,
+  CONSTRAINT fk_stock_level_house_id FOREIGN KEY (house_id) REFERENCES pharma_sheet_medicine_houses (id) ON DELETE CASCADE,
+  CONSTRAINT check_stock_level_min_quantity CHECK (min_quantity >= 0),
+  CONSTRAINT check_stock_level_max_quantity CHECK (max_quantity IS NULL OR max_quantity >= min_quantity)
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS unique_stock_level ON pharma_sheet_stock_levels (warehouse_id, medication_id, COALESCE(house_id, '00000000-0000-0000-0000-000000000000'));
+
+-- migrate:down
+DROP INDEX IF EXISTS unique_stock_level;
+DROP TABLE IF EXISTS pharma_sheet_stock_levels;
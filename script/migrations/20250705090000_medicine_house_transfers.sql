@@ -0,0 +1,27 @@
+-- migrate:up
+CREATE TYPE pharma_sheet_house_transfer_mode AS ENUM (
+  'MOVE',
+  'SPLIT'
+);
+
+CREATE TABLE IF NOT EXISTS pharma_sheet_medicine_house_transfers (
+  id UUID PRIMARY KEY,
+  mode pharma_sheet_house_transfer_mode NOT NULL,
+  medication_id TEXT NOT NULL,
+  source_house_id UUID,
+  source_warehouse_id TEXT NOT NULL,
+  source_address TEXT NOT NULL,
+  target_house_id UUID,
+  target_warehouse_id TEXT NOT NULL,
+  target_address TEXT NOT NULL,
+  created_by UUID,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  CONSTRAINT fk_medicine_house_transfer_medication_id FOREIGN KEY (medication_id) REFERENCES pharma_sheet_medicines (medication_id) ON DELETE CASCADE,
+  CONSTRAINT fk_medicine_house_transfer_source_house_id FOREIGN KEY (source_house_id) REFERENCES pharma_sheet_medicine_houses (id) ON DELETE SET NULL,
+  CONSTRAINT fk_medicine_house_transfer_target_house_id FOREIGN KEY (target_house_id) REFERENCES pharma_sheet_medicine_houses (id) ON DELETE SET NULL,
+  CONSTRAINT fk_medicine_house_transfer_created_by FOREIGN KEY (created_by) REFERENCES pharma_sheet_users (user_id) ON DELETE SET NULL
+);
+
+-- migrate:down
+DROP TABLE IF EXISTS pharma_sheet_medicine_house_transfers;
+DROP TYPE IF EXISTS pharma_sheet_house_transfer_mode;
@@ -0,0 +1,18 @@
+-- migrate:up
+CREATE TABLE IF NOT EXISTS pharma_sheet_warehouse_lockers (
+  id UUID PRIMARY KEY,
+  warehouse_id TEXT NOT NULL,
+  name TEXT NOT NULL,
+  floors INT NOT NULL,
+  slots_per_floor INT NOT NULL,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  CONSTRAINT fk_warehouse_locker_warehouse_id FOREIGN KEY (warehouse_id) REFERENCES pharma_sheet_warehouses (warehouse_id) ON DELETE CASCADE,
+  CONSTRAINT unique_warehouse_locker UNIQUE (warehouse_id, name),
+  CONSTRAINT check_warehouse_locker_name CHECK (name <> '' AND name = BTRIM(name)),
+  CONSTRAINT check_warehouse_locker_floors CHECK (floors > 0),
+  CONSTRAINT check_warehouse_locker_slots_per_floor CHECK (slots_per_floor > 0)
+);
+
+-- migrate:down
+DROP TABLE IF EXISTS pharma_sheet_warehouse_lockers;
@@ -0,0 +1,11 @@
+-- migrate:up
+ALTER TABLE pharma_sheet_medicine_brands
+  ADD COLUMN IF NOT EXISTS barcode TEXT,
+  ADD CONSTRAINT unique_brand_barcode UNIQUE (barcode),
+  ADD CONSTRAINT check_brand_barcode CHECK (barcode ~ '^[0-9]{14}$');
+
+-- migrate:down
+ALTER TABLE pharma_sheet_medicine_brands
+  DROP CONSTRAINT IF EXISTS check_brand_barcode,
+  DROP CONSTRAINT IF EXISTS unique_brand_barcode,
+  DROP COLUMN IF EXISTS barcode;
@@ -0,0 +1,30 @@
+-- migrate:up
+CREATE TYPE pharma_sheet_pick_verification_result AS ENUM (
+  'MATCH',
+  'MISMATCH',
+  'UNKNOWN'
+);
+
+CREATE TABLE IF NOT EXISTS pharma_sheet_pick_verifications (
+  id UUID PRIMARY KEY,
+  warehouse_id TEXT NOT NULL,
+  house_id UUID,
+  medication_id TEXT NOT NULL,
+  address TEXT NOT NULL,
+  product_code TEXT NOT NULL,
+  brand_id UUID,
+  scanned_medication_id TEXT,
+  result pharma_sheet_pick_verification_result NOT NULL,
+  created_by UUID,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  CONSTRAINT fk_pick_verification_warehouse_id FOREIGN KEY (warehouse_id) REFERENCES pharma_sheet_warehouses (warehouse_id) ON DELETE CASCADE,
+  CONSTRAINT fk_pick_verification_house_id FOREIGN KEY (house_id) REFERENCES pharma_sheet_medicine_houses (id) ON DELETE SET NULL,
+  CONSTRAINT fk_pick_verification_brand_id FOREIGN KEY (brand_id) REFERENCES pharma_sheet_medicine_brands (id) ON DELETE SET NULL,
+  CONSTRAINT fk_pick_verification_created_by FOREIGN KEY (created_by) REFERENCES pharma_sheet_users (user_id) ON DELETE SET NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_pick_verification_warehouse_created_at ON pharma_sheet_pick_verifications (warehouse_id, created_at DESC);
+
+-- migrate:down
+DROP TABLE IF EXISTS pharma_sheet_pick_verifications;
+DROP TYPE IF EXISTS pharma_sheet_pick_verification_result;
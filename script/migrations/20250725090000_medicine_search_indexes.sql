@@ -0,0 +1,17 @@
+-- migrate:up
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+CREATE INDEX IF NOT EXISTS idx_medicine_medication_id_trgm ON pharma_sheet_medicines USING GIN (LOWER(medication_id) gin_trgm_ops);
+CREATE INDEX IF NOT EXISTS idx_medicine_medical_name_trgm ON pharma_sheet_medicines USING GIN (LOWER(medical_name) gin_trgm_ops);
+CREATE INDEX IF NOT EXISTS idx_medicine_brand_trade_id_trgm ON pharma_sheet_medicine_brands USING GIN (LOWER(trade_id) gin_trgm_ops);
+CREATE INDEX IF NOT EXISTS idx_medicine_brand_trade_name_trgm ON pharma_sheet_medicine_brands USING GIN (LOWER(trade_name) gin_trgm_ops);
+CREATE INDEX IF NOT EXISTS idx_medicine_house_label_trgm ON pharma_sheet_medicine_houses USING GIN (LOWER(label) gin_trgm_ops);
+CREATE INDEX IF NOT EXISTS idx_medicine_house_address_trgm ON pharma_sheet_medicine_houses USING GIN (LOWER(locker || '-' || floor::text || '-' || no::text) gin_trgm_ops);
+
+-- migrate:down
+DROP INDEX IF EXISTS idx_medicine_house_address_trgm;
+DROP INDEX IF EXISTS idx_medicine_house_label_trgm;
+DROP INDEX IF EXISTS idx_medicine_brand_trade_name_trgm;
+DROP INDEX IF EXISTS idx_medicine_brand_trade_id_trgm;
+DROP INDEX IF EXISTS idx_medicine_medical_name_trgm;
+DROP INDEX IF EXISTS idx_medicine_medication_id_trgm;
@@ -0,0 +1,15 @@
+-- migrate:up
+CREATE INDEX IF NOT EXISTS idx_medicine_medication_id_prefix ON pharma_sheet_medicines (LOWER(medication_id) text_pattern_ops);
+CREATE INDEX IF NOT EXISTS idx_medicine_medical_name_prefix ON pharma_sheet_medicines (LOWER(medical_name) text_pattern_ops);
+CREATE INDEX IF NOT EXISTS idx_medicine_brand_trade_id_prefix ON pharma_sheet_medicine_brands (LOWER(trade_id) text_pattern_ops);
+CREATE INDEX IF NOT EXISTS idx_medicine_brand_trade_name_prefix ON pharma_sheet_medicine_brands (LOWER(trade_name) text_pattern_ops);
+CREATE INDEX IF NOT EXISTS idx_medicine_house_address_prefix ON pharma_sheet_medicine_houses (warehouse_id, LOWER(locker || '-' || floor::text || '-' || no::text) text_pattern_ops);
+CREATE INDEX IF NOT EXISTS idx_warehouse_name_prefix ON pharma_sheet_warehouses (LOWER(name) text_pattern_ops);
+
+-- migrate:down
+DROP INDEX IF EXISTS idx_warehouse_name_prefix;
+DROP INDEX IF EXISTS idx_medicine_house_address_prefix;
+DROP INDEX IF EXISTS idx_medicine_brand_trade_name_prefix;
+DROP INDEX IF EXISTS idx_medicine_brand_trade_id_prefix;
+DROP INDEX IF EXISTS idx_medicine_medical_name_prefix;
+DROP INDEX IF EXISTS idx_medicine_medication_id_prefix;
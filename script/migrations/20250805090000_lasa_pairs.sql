@@ -0,0 +1,19 @@
+-- migrate:up
+CREATE TABLE IF NOT EXISTS pharma_sheet_lasa_pairs (
+  id UUID PRIMARY KEY,
+  medication_id TEXT NOT NULL,
+  similar_medication_id TEXT NOT NULL,
+  note TEXT,
+  created_by UUID,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  CONSTRAINT unique_lasa_pair UNIQUE (medication_id, similar_medication_id),
+  CONSTRAINT check_lasa_pair_order CHECK (medication_id COLLATE "C" < similar_medication_id COLLATE "C"),
+  CONSTRAINT fk_lasa_pair_medication_id FOREIGN KEY (medication_id) REFERENCES pharma_sheet_medicines (medication_id) ON DELETE CASCADE,
+  CONSTRAINT fk_lasa_pair_similar_medication_id FOREIGN KEY (similar_medication_id) REFERENCES pharma_sheet_medicines (medication_id) ON DELETE CASCADE,
+  CONSTRAINT fk_lasa_pair_created_by FOREIGN KEY (created_by) REFERENCES pharma_sheet_users (user_id) ON DELETE SET NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_lasa_pair_similar_medication_id ON pharma_sheet_lasa_pairs (similar_medication_id);
+
+-- migrate:down
+DROP TABLE IF EXISTS pharma_sheet_lasa_pairs;
@@ -0,0 +1,63 @@
+-- migrate:up
+CREATE TYPE pharma_sheet_dosage_form AS ENUM (
+  'TABLET',
+  'CAPSULE',
+  'SYRUP',
+  'SUSPENSION',
+  'SOLUTION',
+  'INJECTION',
+  'CREAM',
+  'OINTMENT',
+  'GEL',
+  'DROPS',
+  'INHALER',
+  'SUPPOSITORY',
+  'PATCH',
+  'POWDER',
+  'OTHER'
+);
+
+CREATE TYPE pharma_sheet_medicine_route AS ENUM (
+  'ORAL',
+  'SUBLINGUAL',
+  'TOPICAL',
+  'OPHTHALMIC',
+  'OTIC',
+  'NASAL',
+  'INHALATION',
+  'RECTAL',
+  'VAGINAL',
+  'INTRAVENOUS',
+  'INTRAMUSCULAR',
+  'SUBCUTANEOUS',
+  'TRANSDERMAL',
+  'OTHER'
+);
+
+ALTER TABLE pharma_sheet_medicines
+  ADD COLUMN IF NOT EXISTS strength NUMERIC,
+  ADD COLUMN IF NOT EXISTS strength_unit TEXT,
+  ADD COLUMN IF NOT EXISTS dosage_form pharma_sheet_dosage_form,
+  ADD COLUMN IF NOT EXISTS route pharma_sheet_medicine_route,
+  ADD COLUMN IF NOT EXISTS package_unit TEXT,
+  ADD CONSTRAINT check_medicine_strength CHECK (strength > 0),
+  ADD CONSTRAINT check_medicine_strength_unit CHECK ((strength IS NULL) = (strength_unit IS NULL));
+
+CREATE INDEX IF NOT EXISTS idx_medicine_dosage_form ON pharma_sheet_medicines (dosage_form);
+CREATE INDEX IF NOT EXISTS idx_medicine_route ON pharma_sheet_medicines (route);
+
+-- migrate:down
+DROP INDEX IF EXISTS idx_medicine_route;
+DROP INDEX IF EXISTS idx_medicine_dosage_form;
+
+ALTER TABLE pharma_sheet_medicines
+  DROP CONSTRAINT IF EXISTS check_medicine_strength_unit,
+  DROP CONSTRAINT IF EXISTS check_medicine_strength,
+  DROP COLUMN IF EXISTS package_unit,
+  DROP COLUMN IF EXISTS route,
+  DROP COLUMN IF EXISTS dosage_form,
+  DROP COLUMN IF EXISTS strength_unit,
+  DROP COLUMN IF EXISTS strength;
+
+DROP TYPE IF EXISTS pharma_sheet_medicine_route;
+DROP TYPE IF EXISTS pharma_sheet_dosage_form;
@@ -0,0 +1,39 @@
+-- migrate:up
+CREATE TABLE IF NOT EXISTS pharma_sheet_categories (
+  id UUID PRIMARY KEY,
+  code TEXT NOT NULL,
+  name TEXT NOT NULL,
+  parent_id UUID,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  CONSTRAINT unique_category_code UNIQUE (code),
+  CONSTRAINT fk_category_parent_id FOREIGN KEY (parent_id) REFERENCES pharma_sheet_categories (id) ON DELETE RESTRICT
+);
+
+CREATE INDEX IF NOT EXISTS idx_category_parent_id ON pharma_sheet_categories (parent_id);
+
+CREATE TABLE IF NOT EXISTS pharma_sheet_medicine_categories (
+  medication_id TEXT NOT NULL,
+  category_id UUID NOT NULL,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  PRIMARY KEY (medication_id, category_id),
+  CONSTRAINT fk_medicine_category_medication_id FOREIGN KEY (medication_id) REFERENCES pharma_sheet_medicines (medication_id) ON DELETE CASCADE,
+  CONSTRAINT fk_medicine_category_category_id FOREIGN KEY (category_id) REFERENCES pharma_sheet_categories (id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_medicine_category_category_id ON pharma_sheet_medicine_categories (category_id);
+
+CREATE TABLE IF NOT EXISTS pharma_sheet_medicine_tags (
+  medication_id TEXT NOT NULL,
+  tag TEXT NOT NULL,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  PRIMARY KEY (medication_id, tag),
+  CONSTRAINT fk_medicine_tag_medication_id FOREIGN KEY (medication_id) REFERENCES pharma_sheet_medicines (medication_id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_medicine_tag_tag ON pharma_sheet_medicine_tags (tag);
+
+-- migrate:down
+DROP TABLE IF EXISTS pharma_sheet_medicine_tags;
+DROP TABLE IF EXISTS pharma_sheet_medicine_categories;
+DROP TABLE IF EXISTS pharma_sheet_categories;
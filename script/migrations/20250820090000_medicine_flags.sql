@@ -0,0 +1,28 @@
+-- migrate:up
+ALTER TABLE pharma_sheet_medicines
+  ADD COLUMN IF NOT EXISTS is_high_alert BOOLEAN NOT NULL DEFAULT FALSE,
+  ADD COLUMN IF NOT EXISTS is_controlled BOOLEAN NOT NULL DEFAULT FALSE,
+  ADD COLUMN IF NOT EXISTS is_refrigerated BOOLEAN NOT NULL DEFAULT FALSE;
+
+ALTER TABLE pharma_sheet_stock_movements
+  ADD COLUMN IF NOT EXISTS confirmed_by UUID,
+  ADD CONSTRAINT fk_stock_movement_confirmed_by FOREIGN KEY (confirmed_by) REFERENCES pharma_sheet_users (user_id) ON DELETE SET NULL;
+
+ALTER TABLE pharma_sheet_medicine_blister_date_histories
+  ADD COLUMN IF NOT EXISTS created_by UUID,
+  ADD COLUMN IF NOT EXISTS confirmed_by UUID,
+  ADD CONSTRAINT fk_blister_date_history_created_by FOREIGN KEY (created_by) REFERENCES pharma_sheet_users (user_id) ON DELETE SET NULL,
+  ADD CONSTRAINT fk_blister_date_history_confirmed_by FOREIGN KEY (confirmed_by) REFERENCES pharma_sheet_users (user_id) ON DELETE SET NULL;
+
+-- migrate:down
+ALTER TABLE pharma_sheet_medicine_blister_date_histories
+  DROP COLUMN IF EXISTS confirmed_by,
+  DROP COLUMN IF EXISTS created_by;
+
+ALTER TABLE pharma_sheet_stock_movements
+  DROP COLUMN IF EXISTS confirmed_by;
+
+ALTER TABLE pharma_sheet_medicines
+  DROP COLUMN IF EXISTS is_refrigerated,
+  DROP COLUMN IF EXISTS is_controlled,
+  DROP COLUMN IF EXISTS is_high_alert;
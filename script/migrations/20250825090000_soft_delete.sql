@@ -0,0 +1,54 @@
+-- migrate:up
+ALTER TABLE pharma_sheet_warehouses
+  ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMPTZ;
+
+ALTER TABLE pharma_sheet_medicines
+  ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMPTZ;
+
+ALTER TABLE pharma_sheet_medicine_brands
+  ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMPTZ,
+  DROP CONSTRAINT IF EXISTS unique_brand,
+  DROP CONSTRAINT IF EXISTS unique_brand_barcode;
+
+ALTER TABLE pharma_sheet_medicine_houses
+  ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMPTZ,
+  DROP CONSTRAINT IF EXISTS unique_house;
+
+-- a deleted row keeps its values in the trash, so it must not block a new row taking them
+CREATE UNIQUE INDEX IF NOT EXISTS unique_brand ON pharma_sheet_medicine_brands (medication_id, trade_id) WHERE deleted_at IS NULL;
+CREATE UNIQUE INDEX IF NOT EXISTS unique_brand_barcode ON pharma_sheet_medicine_brands (barcode) WHERE deleted_at IS NULL;
+CREATE UNIQUE INDEX IF NOT EXISTS unique_house ON pharma_sheet_medicine_houses (warehouse_id, medication_id, locker, floor, no) WHERE deleted_at IS NULL;
+
+CREATE INDEX IF NOT EXISTS idx_warehouse_deleted_at ON pharma_sheet_warehouses (deleted_at) WHERE deleted_at IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_medicine_deleted_at ON pharma_sheet_medicines (deleted_at) WHERE deleted_at IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_medicine_brand_deleted_at ON pharma_sheet_medicine_brands (deleted_at) WHERE deleted_at IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_medicine_house_deleted_at ON pharma_sheet_medicine_houses (deleted_at) WHERE deleted_at IS NOT NULL;
+
+-- migrate:down
+DELETE FROM pharma_sheet_medicine_houses WHERE deleted_at IS NOT NULL;
+DELETE FROM pharma_sheet_medicine_brands WHERE deleted_at IS NOT NULL;
+DELETE FROM pharma_sheet_medicines WHERE deleted_at IS NOT NULL;
+DELETE FROM pharma_sheet_warehouses WHERE deleted_at IS NOT NULL;
+
+DROP INDEX IF EXISTS idx_medicine_house_deleted_at;
+DROP INDEX IF EXISTS idx_medicine_brand_deleted_at;
+DROP INDEX IF EXISTS idx_medicine_deleted_at;
+DROP INDEX IF EXISTS idx_warehouse_deleted_at;
+DROP INDEX IF EXISTS unique_house;
+DROP INDEX IF EXISTS unique_brand_barcode;
+DROP INDEX IF EXISTS unique_brand;
+
+ALTER TABLE pharma_sheet_medicine_houses
+  ADD CONSTRAINT unique_house UNIQUE (warehouse_id, medication_id, locker, floor, no),
+  DROP COLUMN IF EXISTS deleted_at;
+
+ALTER TABLE pharma_sheet_medicine_brands
+  ADD CONSTRAINT unique_brand UNIQUE (medication_id, trade_id),
+  ADD CONSTRAINT unique_brand_barcode UNIQUE (barcode),
+  DROP COLUMN IF EXISTS deleted_at;
+
+ALTER TABLE pharma_sheet_medicines
+  DROP COLUMN IF EXISTS deleted_at;
+
+ALTER TABLE pharma_sheet_warehouses
+  DROP COLUMN IF EXISTS deleted_at;
@@ -0,0 +1,28 @@
+-- migrate:up
+CREATE TYPE pharma_sheet_audit_action AS ENUM (
+  'CREATE',
+  'UPDATE',
+  'DELETE'
+);
+
+-- the log outlives what it records, so the entity and warehouse are not foreign keys
+CREATE TABLE IF NOT EXISTS pharma_sheet_audit_logs (
+  id UUID PRIMARY KEY,
+  user_id UUID,
+  action pharma_sheet_audit_action NOT NULL,
+  entity_type TEXT NOT NULL,
+  entity_id TEXT NOT NULL,
+  warehouse_id TEXT,
+  before JSONB,
+  after JSONB,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  CONSTRAINT fk_audit_log_user_id FOREIGN KEY (user_id) REFERENCES pharma_sheet_users (user_id) ON DELETE SET NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_audit_log_entity ON pharma_sheet_audit_logs (entity_type, entity_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_audit_log_warehouse_id ON pharma_sheet_audit_logs (warehouse_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_audit_log_user_id ON pharma_sheet_audit_logs (user_id, created_at DESC);
+
+-- migrate:down
+DROP TABLE IF EXISTS pharma_sheet_audit_logs;
+DROP TYPE IF EXISTS pharma_sheet_audit_action;
@@ -0,0 +1,15 @@
+-- migrate:up
+-- the id of a medicine merged into another, so the sheet sync translates the old id to the medicine it was merged into
+CREATE TABLE IF NOT EXISTS pharma_sheet_medicine_aliases (
+  alias_medication_id TEXT PRIMARY KEY,
+  medication_id TEXT NOT NULL,
+  created_by UUID,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  CONSTRAINT fk_medicine_alias_medication_id FOREIGN KEY (medication_id) REFERENCES pharma_sheet_medicines (medication_id) ON DELETE CASCADE,
+  CONSTRAINT fk_medicine_alias_created_by FOREIGN KEY (created_by) REFERENCES pharma_sheet_users (user_id) ON DELETE SET NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_medicine_alias_medication_id ON pharma_sheet_medicine_aliases (medication_id);
+
+-- migrate:down
+DROP TABLE IF EXISTS pharma_sheet_medicine_aliases;
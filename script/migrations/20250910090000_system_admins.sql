@@ -0,0 +1,7 @@
+-- migrate:up
+-- the master data shared by every warehouse is maintained by system admins, any user becomes the admin of a warehouse
+-- they create so a warehouse role cannot guard it. system admins are granted directly in the database.
+ALTER TABLE pharma_sheet_users ADD COLUMN IF NOT EXISTS is_system_admin BOOLEAN NOT NULL DEFAULT FALSE;
+
+-- migrate:down
+ALTER TABLE pharma_sheet_users DROP COLUMN IF EXISTS is_system_admin;
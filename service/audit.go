@@ -0,0 +1,183 @@
+package service
+
+import (
+	"context"
+	"net/http"
+	"slices"
+
+	"github.com/google/uuid"
+	genmodel "github.com/kinkando/pharma-sheet-service/.gen/pharma_sheet/public/model"
+	"github.com/kinkando/pharma-sheet-service/model"
+	"github.com/kinkando/pharma-sheet-service/pkg/logger"
+	"github.com/kinkando/pharma-sheet-service/pkg/profile"
+	"github.com/kinkando/pharma-sheet-service/repository"
+	"github.com/labstack/echo/v4"
+)
+
+type Audit interface {
+	ListAuditLogs(ctx context.Context, filter model.FilterAuditLog) (model.PagingWithMetadata[model.AuditLog], error)
+	ListWarehouseAuditLogs(ctx context.Context, filter model.FilterAuditLog) (model.PagingWithMetadata[model.AuditLog], error)
+}
+
+type audit struct {
+	auditRepository     repository.Audit
+	warehouseRepository repository.Warehouse
+}
+
+func NewAuditService(auditRepository repository.Audit, warehouseRepository repository.Warehouse) Audit {
+	return &audit{
+		auditRepository:     auditRepository,
+		warehouseRepository: warehouseRepository,
+	}
+}
+
+// ListAuditLogs lists the logs the user may see, the ones of the master data and of the warehouses the user manages
+func (s *audit) ListAuditLogs(ctx context.Context, filter model.FilterAuditLog) (res model.PagingWithMetadata[model.AuditLog], err error) {
+	userProfile, err := profile.UseProfile(ctx)
+	if err != nil {
+		return res, echo.NewHTTPError(http.StatusUnauthorized, echo.Map{"error": err.Error()})
+	}
+	filter.ViewerID = userProfile.UserID
+
+	data, total, err := s.auditRepository.ListAuditLogs(ctx, filter)
+	if err != nil {
+		return res, echo.NewHTTPError(http.StatusInternalServerError, echo.Map{"error": err.Error()})
+	}
+
+	res = model.PaginationResponse(data, filter.Pagination, total)
+	return res, nil
+}
+
+func (s *audit) ListWarehouseAuditLogs(ctx context.Context, filter model.FilterAuditLog) (res model.PagingWithMetadata[model.AuditLog], err error) {
+	err = s.checkWarehouseManagementRole(ctx, filter.WarehouseID, genmodel.PharmaSheetRole_Admin, genmodel.PharmaSheetRole_Editor)
+	if err != nil {
+		logger.Context(ctx).Error(err)
+		return res, err
+	}
+
+	return s.ListAuditLogs(ctx, filter)
+}
+
+func (s *audit) checkWarehouseManagementRole(ctx context.Context, warehouseID string, roles ...genmodel.PharmaSheetRole) (err error) {
+	userProfile, err := profile.UseProfile(ctx)
+	if err != nil {
+		return
+	}
+
+	role, err := s.warehouseRepository.GetWarehouseRole(ctx, warehouseID, userProfile.UserID)
+	if err != nil {
+		logger.Context(ctx).Error(err)
+		return echo.NewHTTPError(http.StatusInternalServerError, echo.Map{"error": err.Error()})
+	}
+
+	if !slices.Contains(roles, role) {
+		return echo.NewHTTPError(http.StatusForbidden, echo.Map{"error": "permission denied"})
+	}
+
+	return nil
+}
+
+// recordAudit writes the mutations to the audit log as done by the user of the context. The mutations are done
+// already, so a failure to record them is only logged.
+func recordAudit(ctx context.Context, auditRepository repository.Audit, logs ...model.CreateAuditLog) {
+	if len(logs) == 0 {
+		return
+	}
+
+	var userID *uuid.UUID
+	if userProfile, err := profile.UseProfile(ctx); err == nil {
+		if id, err := uuid.Parse(userProfile.UserID); err == nil {
+			userID = &id
+		}
+	}
+
+	if err := auditRepository.CreateAuditLogs(context.WithoutCancel(ctx), userID, logs); err != nil {
+		logger.Context(ctx).Error(err)
+	}
+}
+
+// medicineSnapshot is the state of a medicine kept in the audit log, its brands and houses are logged on their own
+func medicineSnapshot(ctx context.Context, medicineRepository repository.Medicine, categoryRepository repository.Category, medicationID string) any {
+	medicine, err := medicineRepository.GetMedicine(ctx, medicationID)
+	if err != nil {
+		logger.Context(ctx).Warn(err)
+		return nil
+	}
+	medicine.Brands, medicine.Houses, medicine.BlisterDateHistories = nil, nil, nil
+
+	if medicine.Categories, err = categoryRepository.ListMedicineCategories(ctx, model.FilterCategoryMedicine{MedicationID: medicationID}); err != nil {
+		logger.Context(ctx).Warn(err)
+	}
+	if medicine.Tags, err = categoryRepository.ListMedicineTags(ctx, medicationID); err != nil {
+		logger.Context(ctx).Warn(err)
+	}
+	return medicine
+}
+
+func medicineHouseSnapshot(ctx context.Context, medicineRepository repository.Medicine, id uuid.UUID) any {
+	houses, err := medicineRepository.GetMedicineHouses(ctx, model.FilterMedicineHouse{ID: id})
+	if err != nil {
+		logger.Context(ctx).Warn(err)
+		return nil
+	}
+	if len(houses) == 0 {
+		return nil
+	}
+	return houses[0]
+}
+
+func medicineBrandSnapshot(ctx context.Context, medicineRepository repository.Medicine, id uuid.UUID) any {
+	brands, err := medicineRepository.GetMedicineBrands(ctx, model.FilterMedicineBrand{BrandID: id})
+	if err != nil {
+		logger.Context(ctx).Warn(err)
+		return nil
+	}
+	if len(brands) == 0 {
+		return nil
+	}
+	return brands[0]
+}
+
+func blisterDateHistorySnapshot(ctx context.Context, medicineRepository repository.Medicine, id uuid.UUID) any {
+	history, err := medicineRepository.GetMedicineBlisterChangeDateHistory(ctx, id)
+	if err != nil {
+		logger.Context(ctx).Warn(err)
+		return nil
+	}
+	return history
+}
+
+func medicineLotSnapshot(ctx context.Context, lotRepository repository.Lot, id uuid.UUID) any {
+	medicineLot, err := lotRepository.GetMedicineLot(ctx, id)
+	if err != nil {
+		logger.Context(ctx).Warn(err)
+		return nil
+	}
+	return medicineLot
+}
+
+// warehouseSnapshot is the state of a warehouse kept in the audit log, without the role of any user
+func warehouseSnapshot(ctx context.Context, warehouseRepository repository.Warehouse, warehouseID string) any {
+	warehouse, err := warehouseRepository.GetWarehouse(ctx, warehouseID)
+	if err != nil {
+		logger.Context(ctx).Warn(err)
+		return nil
+	}
+	warehouse.Role = ""
+	return warehouse
+}
+
+func warehouseUserSnapshot(ctx context.Context, warehouseRepository repository.Warehouse, warehouseID, userID string) any {
+	users, _, err := warehouseRepository.GetWarehouseUsers(ctx, warehouseID, model.FilterWarehouseUser{
+		Pagination: model.Pagination{Page: 1, Limit: 1},
+		UserID:     userID,
+	})
+	if err != nil {
+		logger.Context(ctx).Warn(err)
+		return nil
+	}
+	if len(users) == 0 {
+		return nil
+	}
+	return users[0]
+}
@@ -0,0 +1,174 @@
+package service
+
+import (
+	"context"
+	"net/http"
+	"slices"
+
+	"github.com/google/uuid"
+	"github.com/kinkando/pharma-sheet-service/model"
+	"github.com/kinkando/pharma-sheet-service/pkg/logger"
+	"github.com/kinkando/pharma-sheet-service/repository"
+	"github.com/labstack/echo/v4"
+)
+
+type Category interface {
+	ListCategories(ctx context.Context) ([]model.Category, error)
+	CreateCategory(ctx context.Context, req model.CreateCategoryRequest) (string, error)
+	UpdateCategory(ctx context.Context, req model.UpdateCategoryRequest) error
+	DeleteCategory(ctx context.Context, id uuid.UUID) error
+	ListTags(ctx context.Context, filter model.FilterTag) ([]model.Tag, error)
+}
+
+type category struct {
+	categoryRepository repository.Category
+	userRepository     repository.User
+}
+
+func NewCategoryService(
+	categoryRepository repository.Category,
+	userRepository repository.User,
+) Category {
+	return &category{
+		categoryRepository: categoryRepository,
+		userRepository:     userRepository,
+	}
+}
+
+// ListCategories returns the category tree, the medicines of a sub category are counted by its ancestors too
+func (s *category) ListCategories(ctx context.Context) ([]model.Category, error) {
+	categories, err := s.categoryRepository.ListCategories(ctx)
+	if err != nil {
+		return nil, echo.NewHTTPError(http.StatusInternalServerError, echo.Map{"error": err.Error()})
+	}
+
+	medicineCategories, err := s.categoryRepository.ListMedicineCategories(ctx, model.FilterCategoryMedicine{})
+	if err != nil {
+		return nil, echo.NewHTTPError(http.StatusInternalServerError, echo.Map{"error": err.Error()})
+	}
+
+	for _, count := range model.CountCategoryMedicines(categories, medicineCategories) {
+		index := slices.IndexFunc(categories, func(category model.Category) bool { return category.ID == count.ID })
+		categories[index].TotalMedicine = count.TotalMedicine
+	}
+
+	return model.CategoryTree(categories), nil
+}
+
+// CreateCategory adds a category under its parent, the category tree is maintained by system admins
+// as the classification of the medicines is the same in every warehouse.
+func (s *category) CreateCategory(ctx context.Context, req model.CreateCategoryRequest) (string, error) {
+	if err := checkSystemAdminRole(ctx, s.userRepository); err != nil {
+		logger.Context(ctx).Error(err)
+		return "", err
+	}
+
+	req.Code = model.NormalizeCategoryCode(req.Code)
+	if req.ParentID != nil {
+		categories, err := s.categoryRepository.ListCategories(ctx)
+		if err != nil {
+			return "", echo.NewHTTPError(http.StatusInternalServerError, echo.Map{"error": err.Error()})
+		}
+		if !slices.ContainsFunc(categories, func(category model.Category) bool { return category.ID == *req.ParentID }) {
+			logger.Context(ctx).Errorf("parentID %s is not found", req.ParentID.String())
+			return "", echo.NewHTTPError(http.StatusNotFound, echo.Map{"error": "parentID is not found"})
+		}
+	}
+
+	id, err := s.categoryRepository.CreateCategory(ctx, req)
+	if err != nil {
+		if model.IsConflictError(err) {
+			return "", echo.NewHTTPError(http.StatusConflict, echo.Map{"error": "category code is already in use"})
+		}
+		return "", echo.NewHTTPError(http.StatusInternalServerError, echo.Map{"error": err.Error()})
+	}
+	return id, nil
+}
+
+func (s *category) UpdateCategory(ctx context.Context, req model.UpdateCategoryRequest) error {
+	if err := checkSystemAdminRole(ctx, s.userRepository); err != nil {
+		logger.Context(ctx).Error(err)
+		return err
+	}
+
+	categories, err := s.categoryRepository.ListCategories(ctx)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, echo.Map{"error": err.Error()})
+	}
+	if !slices.ContainsFunc(categories, func(category model.Category) bool { return category.ID == req.ID }) {
+		logger.Context(ctx).Errorf("categoryID %s is not found", req.ID.String())
+		return echo.NewHTTPError(http.StatusNotFound, echo.Map{"error": "categoryID is not found"})
+	}
+	if req.ParentID != nil {
+		if !slices.ContainsFunc(categories, func(category model.Category) bool { return category.ID == *req.ParentID }) {
+			logger.Context(ctx).Errorf("parentID %s is not found", req.ParentID.String())
+			return echo.NewHTTPError(http.StatusNotFound, echo.Map{"error": "parentID is not found"})
+		}
+		if slices.Contains(model.CategoryDescendants(categories, req.ID), *req.ParentID) {
+			return echo.NewHTTPError(http.StatusBadRequest, echo.Map{"error": "category cannot be moved under itself"})
+		}
+	}
+
+	req.Code = model.NormalizeCategoryCode(req.Code)
+	if _, err = s.categoryRepository.UpdateCategory(ctx, req); err != nil {
+		if model.IsConflictError(err) {
+			return echo.NewHTTPError(http.StatusConflict, echo.Map{"error": "category code is already in use"})
+		}
+		return echo.NewHTTPError(http.StatusInternalServerError, echo.Map{"error": err.Error()})
+	}
+	return nil
+}
+
+// DeleteCategory removes a category from every medicine, a category having sub categories cannot be deleted
+func (s *category) DeleteCategory(ctx context.Context, id uuid.UUID) error {
+	if err := checkSystemAdminRole(ctx, s.userRepository); err != nil {
+		logger.Context(ctx).Error(err)
+		return err
+	}
+
+	categories, err := s.categoryRepository.ListCategories(ctx)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, echo.Map{"error": err.Error()})
+	}
+	if len(model.CategoryDescendants(categories, id)) > 1 {
+		return echo.NewHTTPError(http.StatusConflict, echo.Map{"error": "category has sub categories"})
+	}
+
+	rowsAffected, err := s.categoryRepository.DeleteCategory(ctx, id)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, echo.Map{"error": err.Error()})
+	}
+	if rowsAffected == 0 {
+		logger.Context(ctx).Errorf("categoryID %s is not found", id.String())
+		return echo.NewHTTPError(http.StatusNotFound, echo.Map{"error": "categoryID is not found"})
+	}
+	return nil
+}
+
+func (s *category) ListTags(ctx context.Context, filter model.FilterTag) ([]model.Tag, error) {
+	tags, err := s.categoryRepository.ListTags(ctx, filter)
+	if err != nil {
+		return nil, echo.NewHTTPError(http.StatusInternalServerError, echo.Map{"error": err.Error()})
+	}
+	return tags, nil
+}
+
+// expandCategoryFilter matches the medicines of the sub categories of the filtered category as well
+func expandCategoryFilter(ctx context.Context, categoryRepository repository.Category, filter model.FilterMedicineCategory) (model.FilterMedicineCategory, error) {
+	if filter.CategoryID == "" {
+		return filter, nil
+	}
+
+	categoryID, err := uuid.Parse(filter.CategoryID)
+	if err != nil {
+		return filter, echo.NewHTTPError(http.StatusBadRequest, echo.Map{"error": err.Error()})
+	}
+
+	categories, err := categoryRepository.ListCategories(ctx)
+	if err != nil {
+		return filter, echo.NewHTTPError(http.StatusInternalServerError, echo.Map{"error": err.Error()})
+	}
+
+	filter.CategoryIDs = model.CategoryDescendants(categories, categoryID)
+	return filter, nil
+}
@@ -0,0 +1,150 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"slices"
+
+	"github.com/google/uuid"
+	genmodel "github.com/kinkando/pharma-sheet-service/.gen/pharma_sheet/public/model"
+	"github.com/kinkando/pharma-sheet-service/model"
+	"github.com/kinkando/pharma-sheet-service/pkg/logger"
+	"github.com/kinkando/pharma-sheet-service/pkg/profile"
+	"github.com/kinkando/pharma-sheet-service/repository"
+	"github.com/labstack/echo/v4"
+)
+
+const (
+	confirmationCodeLength   = 6
+	confirmationCodeAttempts = 5
+	confirmationFailureLimit = 5
+)
+
+type Confirmation interface {
+	CreateConfirmation(ctx context.Context, req model.CreateConfirmationRequest) (model.Confirmation, error)
+}
+
+type confirmation struct {
+	cacheRepository     repository.Cache
+	warehouseRepository repository.Warehouse
+}
+
+func NewConfirmationService(cacheRepository repository.Cache, warehouseRepository repository.Warehouse) Confirmation {
+	return &confirmation{
+		cacheRepository:     cacheRepository,
+		warehouseRepository: warehouseRepository,
+	}
+}
+
+// CreateConfirmation issues a code of the user for a single change, the user hands it over to the one changing a flagged medicine
+// to confirm the change as the second user.
+func (s *confirmation) CreateConfirmation(ctx context.Context, req model.CreateConfirmationRequest) (model.Confirmation, error) {
+	userProfile, err := profile.UseProfile(ctx)
+	if err != nil {
+		return model.Confirmation{}, err
+	}
+
+	if err = checkConfirmingRole(ctx, s.warehouseRepository, req.WarehouseID, userProfile.UserID); err != nil {
+		logger.Context(ctx).Error(err)
+		return model.Confirmation{}, err
+	}
+
+	for range confirmationCodeAttempts {
+		number, err := rand.Int(rand.Reader, big.NewInt(1_000_000))
+		if err != nil {
+			logger.Context(ctx).Error(err)
+			return model.Confirmation{}, echo.NewHTTPError(http.StatusInternalServerError, echo.Map{"error": err.Error()})
+		}
+
+		code := fmt.Sprintf("%0*d", confirmationCodeLength, number.Int64())
+		confirmation, ok, err := s.cacheRepository.CreateConfirmationCode(ctx, req.ConfirmationScope, code, userProfile.UserID)
+		if err != nil {
+			return model.Confirmation{}, echo.NewHTTPError(http.StatusInternalServerError, echo.Map{"error": err.Error()})
+		}
+		if ok {
+			return confirmation, nil
+		}
+	}
+
+	return model.Confirmation{}, echo.NewHTTPError(http.StatusServiceUnavailable, echo.Map{"error": "unable to issue a confirmation code, please try again"})
+}
+
+// confirmFlaggedMedicine resolves the user confirming a change of a flagged medicine, the user must be someone else
+// managing the same warehouse who issued the code for this very change. The code is used up only once every check passes,
+// so the caller confirms right before writing. Nothing is confirmed for a medicine which is not flagged.
+func confirmFlaggedMedicine(ctx context.Context, cacheRepository repository.Cache, warehouseRepository repository.Warehouse, scope model.ConfirmationScope, flag model.MedicineFlag, code string) (*uuid.UUID, error) {
+	if !flag.IsFlagged() {
+		return nil, nil
+	}
+	return useConfirmationCode(ctx, cacheRepository, warehouseRepository, scope, code)
+}
+
+// useConfirmationCode resolves the user who issued the code for the change and uses the code up once every check passes
+func useConfirmationCode(ctx context.Context, cacheRepository repository.Cache, warehouseRepository repository.Warehouse, scope model.ConfirmationScope, code string) (*uuid.UUID, error) {
+	if code == "" {
+		return nil, echo.NewHTTPError(http.StatusBadRequest, echo.Map{"error": "confirmationCode is required for a flagged medicine"})
+	}
+
+	userProfile, err := profile.UseProfile(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// the codes are short, so the failed lookups of a user are limited to keep them from being guessed
+	failures, err := cacheRepository.CountConfirmationFailures(ctx, userProfile.UserID)
+	if err != nil {
+		return nil, echo.NewHTTPError(http.StatusInternalServerError, echo.Map{"error": err.Error()})
+	}
+	if failures >= confirmationFailureLimit {
+		return nil, echo.NewHTTPError(http.StatusTooManyRequests, echo.Map{"error": "too many invalid confirmation codes, please try again later"})
+	}
+
+	userID, ok, err := cacheRepository.GetConfirmationCode(ctx, scope, code)
+	if err != nil {
+		return nil, echo.NewHTTPError(http.StatusInternalServerError, echo.Map{"error": err.Error()})
+	}
+	if !ok {
+		if err = cacheRepository.AddConfirmationFailure(ctx, userProfile.UserID); err != nil {
+			return nil, echo.NewHTTPError(http.StatusInternalServerError, echo.Map{"error": err.Error()})
+		}
+		return nil, echo.NewHTTPError(http.StatusBadRequest, echo.Map{"error": "confirmationCode is invalid, has expired or was issued for another change"})
+	}
+	if userID == userProfile.UserID {
+		return nil, echo.NewHTTPError(http.StatusBadRequest, echo.Map{"error": "confirmationCode must be issued by another user"})
+	}
+
+	if err = checkConfirmingRole(ctx, warehouseRepository, scope.WarehouseID, userID); err != nil {
+		return nil, err
+	}
+
+	confirmedBy, err := uuid.Parse(userID)
+	if err != nil {
+		return nil, echo.NewHTTPError(http.StatusInternalServerError, echo.Map{"error": err.Error()})
+	}
+
+	ok, err = cacheRepository.DeleteConfirmationCode(ctx, scope, code)
+	if err != nil {
+		return nil, echo.NewHTTPError(http.StatusInternalServerError, echo.Map{"error": err.Error()})
+	}
+	if !ok {
+		return nil, echo.NewHTTPError(http.StatusConflict, echo.Map{"error": "confirmationCode has already been used"})
+	}
+	return &confirmedBy, nil
+}
+
+// checkConfirmingRole checks the user is allowed to confirm a change in the warehouse
+func checkConfirmingRole(ctx context.Context, warehouseRepository repository.Warehouse, warehouseID, userID string) error {
+	role, err := warehouseRepository.GetWarehouseRole(ctx, warehouseID, userID)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return echo.NewHTTPError(http.StatusInternalServerError, echo.Map{"error": err.Error()})
+	}
+	if !slices.Contains([]genmodel.PharmaSheetRole{genmodel.PharmaSheetRole_Admin, genmodel.PharmaSheetRole_Editor}, role) {
+		return echo.NewHTTPError(http.StatusForbidden, echo.Map{"error": "confirming user must be an admin or editor of the warehouse"})
+	}
+	return nil
+}
@@ -0,0 +1,259 @@
+package service
+
+import (
+	"cmp"
+	"context"
+	"net/http"
+	"slices"
+
+	"github.com/google/uuid"
+	genmodel "github.com/kinkando/pharma-sheet-service/.gen/pharma_sheet/public/model"
+	"github.com/kinkando/pharma-sheet-service/model"
+	"github.com/kinkando/pharma-sheet-service/pkg/logger"
+	"github.com/kinkando/pharma-sheet-service/pkg/profile"
+	"github.com/kinkando/pharma-sheet-service/repository"
+	"github.com/labstack/echo/v4"
+)
+
+type LASA interface {
+	ListLASAPairs(ctx context.Context, filter model.FilterLASAPair) (model.PagingWithMetadata[model.LASAPair], error)
+	CreateLASAPair(ctx context.Context, req model.CreateLASAPairRequest) (string, error)
+	DeleteLASAPair(ctx context.Context, id uuid.UUID) error
+	GetLASAProximityReport(ctx context.Context, filter model.FilterLASAProximity) ([]model.LASAProximity, error)
+}
+
+type lasa struct {
+	lasaRepository      repository.LASA
+	medicineRepository  repository.Medicine
+	warehouseRepository repository.Warehouse
+	userRepository      repository.User
+}
+
+func NewLASAService(
+	lasaRepository repository.LASA,
+	medicineRepository repository.Medicine,
+	warehouseRepository repository.Warehouse,
+	userRepository repository.User,
+) LASA {
+	return &lasa{
+		lasaRepository:      lasaRepository,
+		medicineRepository:  medicineRepository,
+		warehouseRepository: warehouseRepository,
+		userRepository:      userRepository,
+	}
+}
+
+func (s *lasa) ListLASAPairs(ctx context.Context, filter model.FilterLASAPair) (res model.PagingWithMetadata[model.LASAPair], err error) {
+	data, total, err := s.lasaRepository.ListLASAPairs(ctx, filter)
+	if err != nil {
+		return res, echo.NewHTTPError(http.StatusInternalServerError, echo.Map{"error": err.Error()})
+	}
+
+	res = model.PaginationResponse(data, filter.Pagination, total)
+	return res, nil
+}
+
+// CreateLASAPair adds a pair to the LASA list, the list is shared by every warehouse
+// so only a system admin can maintain it.
+func (s *lasa) CreateLASAPair(ctx context.Context, req model.CreateLASAPairRequest) (string, error) {
+	if err := checkSystemAdminRole(ctx, s.userRepository); err != nil {
+		logger.Context(ctx).Error(err)
+		return "", err
+	}
+
+	names, err := s.lasaRepository.ListMedicineNames(ctx, []string{req.MedicationID, req.SimilarMedicationID})
+	if err != nil {
+		return "", echo.NewHTTPError(http.StatusInternalServerError, echo.Map{"error": err.Error()})
+	}
+	for _, medicationID := range []string{req.MedicationID, req.SimilarMedicationID} {
+		if _, ok := names[medicationID]; !ok {
+			logger.Context(ctx).Errorf("medicationID %s is not found", medicationID)
+			return "", echo.NewHTTPError(http.StatusNotFound, echo.Map{"error": "medicationID is not found"})
+		}
+	}
+
+	id, err := s.lasaRepository.CreateLASAPair(ctx, req)
+	if err != nil {
+		if model.IsConflictError(err) {
+			return "", echo.NewHTTPError(http.StatusConflict, echo.Map{"error": "medicines are already a LASA pair"})
+		}
+		return "", echo.NewHTTPError(http.StatusInternalServerError, echo.Map{"error": err.Error()})
+	}
+	return id, nil
+}
+
+func (s *lasa) DeleteLASAPair(ctx context.Context, id uuid.UUID) error {
+	if err := checkSystemAdminRole(ctx, s.userRepository); err != nil {
+		logger.Context(ctx).Error(err)
+		return err
+	}
+
+	rowsAffected, err := s.lasaRepository.DeleteLASAPair(ctx, id)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, echo.Map{"error": err.Error()})
+	}
+	if rowsAffected == 0 {
+		logger.Context(ctx).Errorf("lasaPairID %s is not found", id.String())
+		return echo.NewHTTPError(http.StatusNotFound, echo.Map{"error": "lasaPairID is not found"})
+	}
+	return nil
+}
+
+// GetLASAProximityReport lists every two adjacent houses of a warehouse keeping medicines that look or sound alike
+func (s *lasa) GetLASAProximityReport(ctx context.Context, filter model.FilterLASAProximity) ([]model.LASAProximity, error) {
+	err := s.checkWarehouseManagementRole(ctx, filter.WarehouseID, genmodel.PharmaSheetRole_Admin, genmodel.PharmaSheetRole_Editor, genmodel.PharmaSheetRole_Viewer)
+	if err != nil {
+		logger.Context(ctx).Error(err)
+		return nil, err
+	}
+
+	houses, err := s.medicineRepository.GetMedicineHouses(ctx, model.FilterMedicineHouse{WarehouseID: filter.WarehouseID})
+	if err != nil {
+		return nil, echo.NewHTTPError(http.StatusInternalServerError, echo.Map{"error": err.Error()})
+	}
+	slices.SortFunc(houses, func(a, b model.MedicineHouse) int {
+		return cmp.Or(cmp.Compare(a.Locker, b.Locker), cmp.Compare(a.Floor, b.Floor), cmp.Compare(a.No, b.No))
+	})
+
+	matcher, err := newLASAMatcher(ctx, s.lasaRepository, houses)
+	if err != nil {
+		return nil, echo.NewHTTPError(http.StatusInternalServerError, echo.Map{"error": err.Error()})
+	}
+
+	report := []model.LASAProximity{}
+	for i, house := range houses {
+		for _, neighbour := range houses[i+1:] {
+			if !house.IsAdjacent(neighbour) {
+				continue
+			}
+			match, ok := matcher.match(house.MedicationID, neighbour.MedicationID)
+			if !ok {
+				continue
+			}
+			report = append(report, model.LASAProximity{
+				LASAMatch:      match,
+				HouseID:        house.ID,
+				Address:        house.Address(),
+				SimilarHouseID: neighbour.ID,
+				SimilarAddress: neighbour.Address(),
+			})
+		}
+	}
+
+	return report, nil
+}
+
+func (s *lasa) checkWarehouseManagementRole(ctx context.Context, warehouseID string, roles ...genmodel.PharmaSheetRole) (err error) {
+	userProfile, err := profile.UseProfile(ctx)
+	if err != nil {
+		return
+	}
+
+	role, err := s.warehouseRepository.GetWarehouseRole(ctx, warehouseID, userProfile.UserID)
+	if err != nil {
+		logger.Context(ctx).Error(err)
+		return echo.NewHTTPError(http.StatusInternalServerError, echo.Map{"error": err.Error()})
+	}
+
+	if !slices.Contains(roles, role) {
+		return echo.NewHTTPError(http.StatusForbidden, echo.Map{"error": "permission denied"})
+	}
+
+	return nil
+}
+
+type lasaMatcher struct {
+	names  map[string][]string
+	listed map[[2]string]bool
+}
+
+// newLASAMatcher loads the names and the listed pairs of the medicines kept in the houses
+func newLASAMatcher(ctx context.Context, lasaRepository repository.LASA, houses []model.MedicineHouse) (lasaMatcher, error) {
+	var medicationIDs []string
+	for _, house := range houses {
+		if !slices.Contains(medicationIDs, house.MedicationID) {
+			medicationIDs = append(medicationIDs, house.MedicationID)
+		}
+	}
+
+	names, err := lasaRepository.ListMedicineNames(ctx, medicationIDs)
+	if err != nil {
+		return lasaMatcher{}, err
+	}
+
+	lasaPairs, err := lasaRepository.GetLASAPairs(ctx, medicationIDs)
+	if err != nil {
+		return lasaMatcher{}, err
+	}
+
+	listed := make(map[[2]string]bool, len(lasaPairs))
+	for _, lasaPair := range lasaPairs {
+		listed[[2]string{lasaPair.MedicationID, lasaPair.SimilarMedicationID}] = true
+	}
+
+	return lasaMatcher{names: names, listed: listed}, nil
+}
+
+func (m lasaMatcher) match(medicationID, similarMedicationID string) (model.LASAMatch, bool) {
+	if medicationID == similarMedicationID {
+		return model.LASAMatch{}, false
+	}
+
+	match, ok := model.MatchLASANames(m.names[medicationID], m.names[similarMedicationID])
+	key := [2]string{min(medicationID, similarMedicationID), max(medicationID, similarMedicationID)}
+	if m.listed[key] {
+		if !ok {
+			match.Name, match.SimilarName = medicationID, similarMedicationID
+			if names := m.names[medicationID]; len(names) > 0 {
+				match.Name = names[0]
+			}
+			if names := m.names[similarMedicationID]; len(names) > 0 {
+				match.SimilarName = names[0]
+			}
+		}
+		match.Reason, match.Score, ok = model.LASAReasonList, 1, true
+	}
+	if !ok {
+		return model.LASAMatch{}, false
+	}
+
+	match.MedicationID, match.SimilarMedicationID = medicationID, similarMedicationID
+	return match, true
+}
+
+// findLASAWarnings lists the houses next to a house keeping a medicine that looks or sounds alike its own
+func findLASAWarnings(ctx context.Context, lasaRepository repository.LASA, medicineRepository repository.Medicine, house model.MedicineHouse) ([]model.LASAWarning, error) {
+	houses, err := medicineRepository.GetMedicineHouses(ctx, model.FilterMedicineHouse{WarehouseID: house.WarehouseID})
+	if err != nil {
+		return nil, err
+	}
+
+	neighbours := []model.MedicineHouse{house}
+	for _, neighbour := range houses {
+		if neighbour.ID != house.ID && neighbour.MedicationID != house.MedicationID && house.IsAdjacent(neighbour) {
+			neighbours = append(neighbours, neighbour)
+		}
+	}
+
+	warnings := []model.LASAWarning{}
+	if len(neighbours) == 1 {
+		return warnings, nil
+	}
+
+	matcher, err := newLASAMatcher(ctx, lasaRepository, neighbours)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, neighbour := range neighbours[1:] {
+		if match, ok := matcher.match(house.MedicationID, neighbour.MedicationID); ok {
+			warnings = append(warnings, model.LASAWarning{
+				LASAMatch: match,
+				HouseID:   neighbour.ID,
+				Address:   neighbour.Address(),
+			})
+		}
+	}
+
+	return warnings, nil
+}
@@ -0,0 +1,303 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"net/http"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+	genmodel "github.com/kinkando/pharma-sheet-service/.gen/pharma_sheet/public/model"
+	"github.com/kinkando/pharma-sheet-service/model"
+	"github.com/kinkando/pharma-sheet-service/pkg/logger"
+	"github.com/kinkando/pharma-sheet-service/pkg/profile"
+	"github.com/kinkando/pharma-sheet-service/pkg/util"
+	"github.com/kinkando/pharma-sheet-service/repository"
+	"github.com/labstack/echo/v4"
+)
+
+type Lot interface {
+	GetMedicineLot(ctx context.Context, id uuid.UUID) (model.MedicineLot, error)
+	ListMedicineLots(ctx context.Context, filter model.FilterMedicineLot) (model.PagingWithMetadata[model.MedicineLot], error)
+	ListExpiringMedicineLots(ctx context.Context, filter model.FilterExpiringMedicineLot) ([]model.MedicineLotExpiry, error)
+	CreateMedicineLot(ctx context.Context, req model.CreateMedicineLotRequest) (string, error)
+	UpdateMedicineLot(ctx context.Context, req model.UpdateMedicineLotRequest) error
+	DeleteMedicineLot(ctx context.Context, req model.DeleteMedicineLotRequest) error
+}
+
+type lot struct {
+	lotRepository       repository.Lot
+	medicineRepository  repository.Medicine
+	warehouseRepository repository.Warehouse
+	cacheRepository     repository.Cache
+}
+
+func NewLotService(
+	lotRepository repository.Lot,
+	medicineRepository repository.Medicine,
+	warehouseRepository repository.Warehouse,
+	cacheRepository repository.Cache,
+) Lot {
+	return &lot{
+		lotRepository:       lotRepository,
+		medicineRepository:  medicineRepository,
+		warehouseRepository: warehouseRepository,
+		cacheRepository:     cacheRepository,
+	}
+}
+
+func (s *lot) GetMedicineLot(ctx context.Context, id uuid.UUID) (model.MedicineLot, error) {
+	medicineLot, err := s.getMedicineLot(ctx, id)
+	if err != nil {
+		return medicineLot, err
+	}
+
+	err = s.checkWarehouseManagementRole(ctx, medicineLot.WarehouseID, genmodel.PharmaSheetRole_Admin, genmodel.PharmaSheetRole_Editor, genmodel.PharmaSheetRole_Viewer)
+	if err != nil {
+		logger.Context(ctx).Error(err)
+		return medicineLot, err
+	}
+
+	return medicineLot, nil
+}
+
+func (s *lot) ListMedicineLots(ctx context.Context, filter model.FilterMedicineLot) (res model.PagingWithMetadata[model.MedicineLot], err error) {
+	err = s.checkWarehouseManagementRole(ctx, filter.WarehouseID, genmodel.PharmaSheetRole_Admin, genmodel.PharmaSheetRole_Editor, genmodel.PharmaSheetRole_Viewer)
+	if err != nil {
+		logger.Context(ctx).Error(err)
+		return res, err
+	}
+
+	data, total, err := s.lotRepository.ListMedicineLotsPagination(ctx, filter)
+	if err != nil {
+		return res, echo.NewHTTPError(http.StatusInternalServerError, echo.Map{"error": err.Error()})
+	}
+
+	res = model.PaginationResponse(data, filter.Pagination, total)
+	return res, nil
+}
+
+func (s *lot) ListExpiringMedicineLots(ctx context.Context, filter model.FilterExpiringMedicineLot) ([]model.MedicineLotExpiry, error) {
+	err := s.checkWarehouseManagementRole(ctx, filter.WarehouseID, genmodel.PharmaSheetRole_Admin, genmodel.PharmaSheetRole_Editor, genmodel.PharmaSheetRole_Viewer)
+	if err != nil {
+		logger.Context(ctx).Error(err)
+		return nil, err
+	}
+
+	now := time.Now()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	expiryBefore := today.Add(filter.Duration)
+
+	// empty lots are left out, there is nothing left to remove from the shelf
+	medicineLots, err := s.lotRepository.ListMedicineLots(ctx, model.ListMedicineLot{
+		WarehouseID:  filter.WarehouseID,
+		ExpiryBefore: &expiryBefore,
+		IsInStock:    true,
+	})
+	if err != nil {
+		return nil, echo.NewHTTPError(http.StatusInternalServerError, echo.Map{"error": err.Error()})
+	}
+
+	expiries := make([]model.MedicineLotExpiry, 0, len(medicineLots))
+	for _, medicineLot := range medicineLots {
+		expiryDate := time.Date(medicineLot.ExpiryDate.Year(), medicineLot.ExpiryDate.Month(), medicineLot.ExpiryDate.Day(), 0, 0, 0, 0, time.UTC)
+		daysUntilExpiry := int(expiryDate.Sub(today) / util.Day)
+		status := model.MedicineLotStatusExpiring
+		if daysUntilExpiry < 0 {
+			status = model.MedicineLotStatusExpired
+		}
+		expiries = append(expiries, model.MedicineLotExpiry{
+			MedicineLot:     medicineLot,
+			DaysUntilExpiry: daysUntilExpiry,
+			Status:          status,
+		})
+	}
+
+	return expiries, nil
+}
+
+func (s *lot) CreateMedicineLot(ctx context.Context, req model.CreateMedicineLotRequest) (string, error) {
+	house, err := s.getMedicineHouse(ctx, req.HouseID)
+	if err != nil {
+		return "", err
+	}
+
+	if err = s.checkLotManagementRole(ctx, house); err != nil {
+		return "", err
+	}
+
+	if err = s.checkMedicineBrand(ctx, house, req.BrandID); err != nil {
+		return "", err
+	}
+
+	confirmedBy, err := s.confirmLotChange(ctx, house, req.ConfirmationCode)
+	if err != nil {
+		return "", err
+	}
+
+	id, err := s.lotRepository.CreateMedicineLot(ctx, req, confirmedBy)
+	if err != nil {
+		return "", medicineLotError(ctx, err, "lot number already exists in the house")
+	}
+	return id, nil
+}
+
+func (s *lot) UpdateMedicineLot(ctx context.Context, req model.UpdateMedicineLotRequest) error {
+	medicineLot, err := s.getMedicineLot(ctx, req.ID)
+	if err != nil {
+		return err
+	}
+
+	house := model.MedicineHouse{ID: medicineLot.HouseID, WarehouseID: medicineLot.WarehouseID, MedicationID: medicineLot.MedicationID}
+	if err = s.checkLotManagementRole(ctx, house); err != nil {
+		return err
+	}
+
+	if err = s.checkMedicineBrand(ctx, house, req.BrandID); err != nil {
+		return err
+	}
+
+	confirmedBy, err := s.confirmLotChange(ctx, house, req.ConfirmationCode)
+	if err != nil {
+		return err
+	}
+
+	err = s.lotRepository.UpdateMedicineLot(ctx, req, confirmedBy)
+	if err != nil {
+		return medicineLotError(ctx, err, "lot number already exists in the house")
+	}
+	return nil
+}
+
+func (s *lot) DeleteMedicineLot(ctx context.Context, req model.DeleteMedicineLotRequest) error {
+	medicineLot, err := s.getMedicineLot(ctx, req.ID)
+	if err != nil {
+		return err
+	}
+
+	house := model.MedicineHouse{ID: medicineLot.HouseID, WarehouseID: medicineLot.WarehouseID, MedicationID: medicineLot.MedicationID}
+	if err = s.checkLotManagementRole(ctx, house); err != nil {
+		return err
+	}
+
+	confirmedBy, err := s.confirmLotChange(ctx, house, req.ConfirmationCode)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.lotRepository.DeleteMedicineLot(ctx, req.ID, confirmedBy)
+	if err != nil {
+		return medicineLotError(ctx, err, "lot cannot be deleted")
+	}
+	return nil
+}
+
+func (s *lot) getMedicineLot(ctx context.Context, id uuid.UUID) (model.MedicineLot, error) {
+	medicineLot, err := s.lotRepository.GetMedicineLot(ctx, id)
+	if err != nil {
+		logger.Context(ctx).Error(err)
+		if errors.Is(err, sql.ErrNoRows) {
+			return medicineLot, echo.NewHTTPError(http.StatusNotFound, echo.Map{"error": "lotID is not found"})
+		}
+		return medicineLot, echo.NewHTTPError(http.StatusInternalServerError, echo.Map{"error": err.Error()})
+	}
+	return medicineLot, nil
+}
+
+func (s *lot) getMedicineHouse(ctx context.Context, id uuid.UUID) (model.MedicineHouse, error) {
+	houses, err := s.medicineRepository.GetMedicineHouses(ctx, model.FilterMedicineHouse{ID: id})
+	if err != nil {
+		logger.Context(ctx).Error(err)
+		return model.MedicineHouse{}, echo.NewHTTPError(http.StatusInternalServerError, echo.Map{"error": err.Error()})
+	}
+	if len(houses) == 0 {
+		logger.Context(ctx).Errorf("houseID %s is not found", id.String())
+		return model.MedicineHouse{}, echo.NewHTTPError(http.StatusNotFound, echo.Map{"error": "houseID is not found"})
+	}
+	return houses[0], nil
+}
+
+// checkMedicineBrand makes sure the brand of a lot is a brand of the medicine stored in the house
+func (s *lot) checkMedicineBrand(ctx context.Context, house model.MedicineHouse, brandID *uuid.UUID) error {
+	if brandID == nil {
+		return nil
+	}
+
+	brands, err := s.medicineRepository.GetMedicineBrands(ctx, model.FilterMedicineBrand{BrandID: *brandID})
+	if err != nil {
+		logger.Context(ctx).Error(err)
+		return echo.NewHTTPError(http.StatusInternalServerError, echo.Map{"error": err.Error()})
+	}
+	if len(brands) == 0 {
+		return echo.NewHTTPError(http.StatusNotFound, echo.Map{"error": "brandID is not found"})
+	}
+	if brands[0].MedicationID != house.MedicationID {
+		return echo.NewHTTPError(http.StatusBadRequest, echo.Map{"error": "brandID does not belong to the medicine of the house"})
+	}
+	return nil
+}
+
+// checkLotManagementRole allows the lots of a house to the ones allowed to change the house, a flagged medicine needs an admin
+func (s *lot) checkLotManagementRole(ctx context.Context, house model.MedicineHouse) error {
+	roles, err := houseManagementRoles(ctx, s.medicineRepository, house.MedicationID)
+	if err != nil {
+		return err
+	}
+	if err = s.checkWarehouseManagementRole(ctx, house.WarehouseID, roles...); err != nil {
+		logger.Context(ctx).Error(err)
+		return err
+	}
+	return nil
+}
+
+// confirmLotChange resolves the second user confirming a lot change of a flagged medicine
+func (s *lot) confirmLotChange(ctx context.Context, house model.MedicineHouse, code string) (*uuid.UUID, error) {
+	flag, err := s.medicineRepository.GetMedicineFlag(ctx, house.MedicationID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, echo.NewHTTPError(http.StatusNotFound, echo.Map{"error": "medicationID is not found"})
+		}
+		return nil, echo.NewHTTPError(http.StatusInternalServerError, echo.Map{"error": err.Error()})
+	}
+
+	scope := model.ConfirmationScope{WarehouseID: house.WarehouseID, MedicationID: house.MedicationID, Action: model.ConfirmationActionLotChange}
+	confirmedBy, err := confirmFlaggedMedicine(ctx, s.cacheRepository, s.warehouseRepository, scope, flag, code)
+	if err != nil {
+		logger.Context(ctx).Error(err)
+		return nil, err
+	}
+	return confirmedBy, nil
+}
+
+func (s *lot) checkWarehouseManagementRole(ctx context.Context, warehouseID string, roles ...genmodel.PharmaSheetRole) (err error) {
+	userProfile, err := profile.UseProfile(ctx)
+	if err != nil {
+		return
+	}
+
+	role, err := s.warehouseRepository.GetWarehouseRole(ctx, warehouseID, userProfile.UserID)
+	if err != nil {
+		logger.Context(ctx).Error(err)
+		return echo.NewHTTPError(http.StatusInternalServerError, echo.Map{"error": err.Error()})
+	}
+
+	if !slices.Contains(roles, role) {
+		return echo.NewHTTPError(http.StatusForbidden, echo.Map{"error": "permission denied"})
+	}
+
+	return nil
+}
+
+// medicineLotError maps the error of a lot change, the change posts its quantity to the stock ledger in the same transaction
+func medicineLotError(ctx context.Context, err error, conflict string) error {
+	logger.Context(ctx).Error(err)
+	if model.IsConflictError(err) {
+		return echo.NewHTTPError(http.StatusConflict, echo.Map{"error": conflict})
+	}
+	if errors.Is(err, model.ErrInsufficientStock) {
+		return echo.NewHTTPError(http.StatusConflict, echo.Map{"error": err.Error()})
+	}
+	return echo.NewHTTPError(http.StatusInternalServerError, echo.Map{"error": err.Error()})
+}
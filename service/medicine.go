@@ -1,6 +1,7 @@
 package service
 
 import (
+	"cmp"
 	"context"
 	"database/sql"
 	"errors"
@@ -8,13 +9,16 @@ import (
 	"net/http"
 	"slices"
 	"strings"
+	"time"
 
 	"github.com/google/uuid"
 	genmodel "github.com/kinkando/pharma-sheet-service/.gen/pharma_sheet/public/model"
 	"github.com/kinkando/pharma-sheet-service/model"
 	"github.com/kinkando/pharma-sheet-service/pkg/google"
+	"github.com/kinkando/pharma-sheet-service/pkg/label"
 	"github.com/kinkando/pharma-sheet-service/pkg/logger"
 	"github.com/kinkando/pharma-sheet-service/pkg/profile"
+	"github.com/kinkando/pharma-sheet-service/pkg/util"
 	"github.com/kinkando/pharma-sheet-service/repository"
 	"github.com/labstack/echo/v4"
 	"github.com/sourcegraph/conc/pool"
@@ -28,16 +32,25 @@ const (
 type Medicine interface {
 	GetMedicine(ctx context.Context, medicationID string) (model.Medicine, error)
 	GetMedicines(ctx context.Context, filter model.FilterMedicine) (model.PagingWithMetadata[model.Medicine], error)
-	GetMedicinesPagination(ctx context.Context, filter model.Pagination) (model.PagingWithMetadata[model.Medicine], error)
+	GetMedicinesPagination(ctx context.Context, filter model.FilterMedicineMaster) (model.PagingWithMetadata[model.Medicine], error)
+	SearchMedicines(ctx context.Context, filter model.FilterMedicine) (model.PagingWithMetadata[model.MedicineSearchResult], error)
 	ListMedicinesMaster(ctx context.Context) ([]model.Medicine, error)
 	CreateMedicine(ctx context.Context, req model.CreateMedicineRequest) (string, error)
 	UpdateMedicine(ctx context.Context, req model.UpdateMedicineRequest) error
 	DeleteMedicine(ctx context.Context, medicationID string) error
+	PreviewForceDeleteMedicine(ctx context.Context, medicationID string) (model.ForceDeleteMedicinePreview, error)
+	ForceDeleteMedicine(ctx context.Context, req model.DeleteMedicineRequest) error
+	MergeMedicine(ctx context.Context, req model.MergeMedicineRequest) (model.MergedMedicine, error)
+	UpdateMedicineCategories(ctx context.Context, req model.UpdateMedicineCategoryRequest) error
+	UpdateMedicineTags(ctx context.Context, req model.UpdateMedicineTagRequest) error
+	UpdateMedicineFlag(ctx context.Context, req model.UpdateMedicineFlagRequest) error
 
 	GetMedicineHouses(ctx context.Context, filter model.ListMedicineHouse) (model.PagingWithMetadata[model.MedicineHouse], error)
-	CreateMedicineHouse(ctx context.Context, req model.CreateMedicineHouseRequest) (string, error)
-	UpdateMedicineHouse(ctx context.Context, req model.UpdateMedicineHouseRequest) error
+	CreateMedicineHouse(ctx context.Context, req model.CreateMedicineHouseRequest) (string, []model.LASAWarning, error)
+	UpdateMedicineHouse(ctx context.Context, req model.UpdateMedicineHouseRequest) ([]model.LASAWarning, error)
 	DeleteMedicineHouse(ctx context.Context, id uuid.UUID) (int64, error)
+	PrintMedicineHouseLabels(ctx context.Context, req model.PrintMedicineHouseLabelRequest) ([]byte, error)
+	ScanMedicine(ctx context.Context, req model.ScanRequest) ([]model.ScanResult, error)
 
 	GetMedicineWithBrands(ctx context.Context, filter model.FilterMedicineWithBrand) (model.PagingWithMetadata[model.Medicine], error)
 	GetMedicineBrands(ctx context.Context, filter model.FilterMedicineWithBrand) (model.PagingWithMetadata[model.MedicineBrandView], error)
@@ -48,29 +61,67 @@ type Medicine interface {
 	ListMedicineBlisterChangeDateHistory(ctx context.Context, req model.FilterMedicineBlisterDateHistory) (model.PagingWithMetadata[model.MedicineBlisterDateHistoryGroup], error)
 	CreateMedicineBlisterChangeDateHistory(ctx context.Context, req model.CreateMedicineBlisterChangeDateHistoryRequest) (string, error)
 	DeleteMedicineBlisterChangeDateHistory(ctx context.Context, req model.DeleteMedicineBlisterChangeDateHistoryRequest) error
+
+	ListBlisterChangeIntervals(ctx context.Context, req model.FilterBlisterChangeInterval) ([]model.BlisterChangeInterval, error)
+	UpsertBlisterChangeInterval(ctx context.Context, req model.UpsertBlisterChangeIntervalRequest) (string, error)
+	DeleteBlisterChangeInterval(ctx context.Context, id uuid.UUID) error
+	ListBlisterChangeDues(ctx context.Context, req model.FilterBlisterChangeDue) ([]model.BlisterChangeDue, error)
 }
 
 type medicine struct {
-	medicineRepository  repository.Medicine
-	warehouseRepository repository.Warehouse
-	storage             google.Drive
-	isSelfHostImage     bool
+	medicineRepository        repository.Medicine
+	warehouseRepository       repository.Warehouse
+	lasaRepository            repository.LASA
+	categoryRepository        repository.Category
+	cacheRepository           repository.Cache
+	auditRepository           repository.Audit
+	userRepository            repository.User
+	trashRepository           repository.Trash
+	storage                   google.Drive
+	labelPrinter              label.Printer
+	isSelfHostImage           bool
+	blisterChangeIntervalDays int32
 }
 
 func NewMedicineService(
 	medicineRepository repository.Medicine,
 	warehouseRepository repository.Warehouse,
+	lasaRepository repository.LASA,
+	categoryRepository repository.Category,
+	cacheRepository repository.Cache,
+	auditRepository repository.Audit,
+	userRepository repository.User,
+	trashRepository repository.Trash,
 	storage google.Drive,
+	labelPrinter label.Printer,
+	blisterChangeIntervalDays int32,
 ) Medicine {
 	return &medicine{
-		medicineRepository:  medicineRepository,
-		warehouseRepository: warehouseRepository,
-		storage:             storage,
-		isSelfHostImage:     false,
+		medicineRepository:        medicineRepository,
+		warehouseRepository:       warehouseRepository,
+		lasaRepository:            lasaRepository,
+		categoryRepository:        categoryRepository,
+		cacheRepository:           cacheRepository,
+		auditRepository:           auditRepository,
+		userRepository:            userRepository,
+		trashRepository:           trashRepository,
+		storage:                   storage,
+		labelPrinter:              labelPrinter,
+		isSelfHostImage:           false,
+		blisterChangeIntervalDays: blisterChangeIntervalDays,
 	}
 }
 
 func (s *medicine) GetMedicines(ctx context.Context, filter model.FilterMedicine) (res model.PagingWithMetadata[model.Medicine], err error) {
+	filter.FilterMedicineAttribute, err = filter.FilterMedicineAttribute.Normalize()
+	if err != nil {
+		return res, echo.NewHTTPError(http.StatusBadRequest, echo.Map{"error": err.Error()})
+	}
+	filter.FilterMedicineCategory, err = expandCategoryFilter(ctx, s.categoryRepository, filter.FilterMedicineCategory)
+	if err != nil {
+		return res, err
+	}
+
 	data, total, err := s.medicineRepository.GetMedicines(ctx, filter)
 	if err != nil {
 		logger.Context(ctx).Error(err)
@@ -96,9 +147,42 @@ func (s *medicine) GetMedicine(ctx context.Context, medicationID string) (model.
 
 	data = s.injectMedicineImageURL(ctx, data)
 
+	data.Categories, err = s.categoryRepository.ListMedicineCategories(ctx, model.FilterCategoryMedicine{MedicationID: medicationID})
+	if err != nil {
+		return data, echo.NewHTTPError(http.StatusInternalServerError, echo.Map{"error": err.Error()})
+	}
+	data.Tags, err = s.categoryRepository.ListMedicineTags(ctx, medicationID)
+	if err != nil {
+		return data, echo.NewHTTPError(http.StatusInternalServerError, echo.Map{"error": err.Error()})
+	}
+
 	return data, nil
 }
 
+func (s *medicine) SearchMedicines(ctx context.Context, filter model.FilterMedicine) (res model.PagingWithMetadata[model.MedicineSearchResult], err error) {
+	filter.FilterMedicineAttribute, err = filter.FilterMedicineAttribute.Normalize()
+	if err != nil {
+		return res, echo.NewHTTPError(http.StatusBadRequest, echo.Map{"error": err.Error()})
+	}
+	filter.FilterMedicineCategory, err = expandCategoryFilter(ctx, s.categoryRepository, filter.FilterMedicineCategory)
+	if err != nil {
+		return res, err
+	}
+
+	data, total, err := s.medicineRepository.SearchMedicines(ctx, filter)
+	if err != nil {
+		logger.Context(ctx).Error(err)
+		return res, echo.NewHTTPError(http.StatusInternalServerError, echo.Map{"error": err.Error()})
+	}
+
+	for index := range data {
+		data[index].Medicine = s.injectMedicineImageURL(ctx, data[index].Medicine)
+	}
+
+	res = model.PaginationResponse(data, filter.Pagination, total)
+	return res, nil
+}
+
 func (s *medicine) ListMedicinesMaster(ctx context.Context) ([]model.Medicine, error) {
 	medicines, err := s.medicineRepository.ListMedicinesMaster(ctx)
 	if err != nil {
@@ -108,7 +192,12 @@ func (s *medicine) ListMedicinesMaster(ctx context.Context) ([]model.Medicine, e
 	return medicines, nil
 }
 
-func (s *medicine) GetMedicinesPagination(ctx context.Context, filter model.Pagination) (res model.PagingWithMetadata[model.Medicine], err error) {
+func (s *medicine) GetMedicinesPagination(ctx context.Context, filter model.FilterMedicineMaster) (res model.PagingWithMetadata[model.Medicine], err error) {
+	filter.FilterMedicineAttribute, err = filter.FilterMedicineAttribute.Normalize()
+	if err != nil {
+		return res, echo.NewHTTPError(http.StatusBadRequest, echo.Map{"error": err.Error()})
+	}
+
 	data, total, err := s.medicineRepository.GetMedicinesPagination(ctx, filter)
 	if err != nil {
 		logger.Context(ctx).Error(err)
@@ -119,16 +208,70 @@ func (s *medicine) GetMedicinesPagination(ctx context.Context, filter model.Pagi
 		data[index] = s.injectMedicineImageURL(ctx, data[index])
 	}
 
-	res = model.PaginationResponse(data, filter, total)
+	res = model.PaginationResponse(data, filter.Pagination, total)
 	return res, nil
 }
 
 func (s *medicine) CreateMedicine(ctx context.Context, req model.CreateMedicineRequest) (string, error) {
-	return s.medicineRepository.CreateMedicine(ctx, req)
+	attribute, err := model.NormalizeMedicineAttribute(req.MedicineAttribute)
+	if err != nil {
+		return "", echo.NewHTTPError(http.StatusBadRequest, echo.Map{"error": err.Error()})
+	}
+	req.MedicineAttribute = attribute
+
+	mergedInto, err := s.medicineRepository.GetMedicineAlias(ctx, req.MedicationID)
+	if err == nil {
+		return "", echo.NewHTTPError(http.StatusConflict, echo.Map{"error": "medicationID has been merged into " + mergedInto})
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return "", echo.NewHTTPError(http.StatusInternalServerError, echo.Map{"error": err.Error()})
+	}
+
+	medicationID, err := s.medicineRepository.CreateMedicine(ctx, req)
+	if err != nil {
+		if model.IsConflictError(err) {
+			// a medicine in the trash keeps its id until it is purged
+			deletedIDs, err := s.medicineRepository.ListDeletedMedicationIDs(ctx, req.MedicationID)
+			if err != nil {
+				return "", echo.NewHTTPError(http.StatusInternalServerError, echo.Map{"error": err.Error()})
+			}
+			if len(deletedIDs) > 0 {
+				return "", echo.NewHTTPError(http.StatusConflict, echo.Map{"error": "medicationID is in the trash, restore it from the trash instead"})
+			}
+			return "", echo.NewHTTPError(http.StatusConflict, echo.Map{"error": "medicationID already exists"})
+		}
+		return "", echo.NewHTTPError(http.StatusInternalServerError, echo.Map{"error": err.Error()})
+	}
+
+	recordAudit(ctx, s.auditRepository, model.CreateAuditLog{
+		Action:     genmodel.PharmaSheetAuditAction_Create,
+		EntityType: model.AuditEntityMedicine,
+		EntityID:   medicationID,
+		After:      medicineSnapshot(ctx, s.medicineRepository, s.categoryRepository, medicationID),
+	})
+	return medicationID, nil
 }
 
 func (s *medicine) UpdateMedicine(ctx context.Context, req model.UpdateMedicineRequest) error {
-	return s.medicineRepository.UpdateMedicine(ctx, req)
+	attribute, err := model.NormalizeMedicineAttribute(req.MedicineAttribute)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, echo.Map{"error": err.Error()})
+	}
+	req.MedicineAttribute = attribute
+
+	before := medicineSnapshot(ctx, s.medicineRepository, s.categoryRepository, req.MedicationID)
+	if err = s.medicineRepository.UpdateMedicine(ctx, req); err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, echo.Map{"error": err.Error()})
+	}
+
+	recordAudit(ctx, s.auditRepository, model.CreateAuditLog{
+		Action:     genmodel.PharmaSheetAuditAction_Update,
+		EntityType: model.AuditEntityMedicine,
+		EntityID:   req.MedicationID,
+		Before:     before,
+		After:      medicineSnapshot(ctx, s.medicineRepository, s.categoryRepository, req.MedicationID),
+	})
+	return nil
 }
 
 func (s *medicine) DeleteMedicine(ctx context.Context, medicationID string) error {
@@ -144,72 +287,363 @@ func (s *medicine) DeleteMedicine(ctx context.Context, medicationID string) erro
 		return echo.NewHTTPError(http.StatusInternalServerError, echo.Map{"error": err.Error()})
 	}
 
-	forceDelete := false
-	if !forceDelete {
-		// Validate: prevent delete if have medicine brands
-		if len(medicineBrands) > 0 {
-			logger.Context(ctx).Error("cannot delete medicine because it has medicine brands")
-			return echo.NewHTTPError(http.StatusLocked, echo.Map{"error": "cannot delete medicine because it has medicine brands"})
+	// Validate: prevent delete if have medicine brands, a forced delete removes them along with the medicine
+	if len(medicineBrands) > 0 {
+		logger.Context(ctx).Error("cannot delete medicine because it has medicine brands")
+		return echo.NewHTTPError(http.StatusLocked, echo.Map{"error": "cannot delete medicine because it has medicine brands"})
+	}
+
+	// Validate: prevent delete if have medicine blister change date histories
+	histories, err := s.medicineRepository.ListMedicineBlisterChangeDateHistory(ctx, model.FilterMedicineBrandBlisterDateHistory{MedicationID: &medicationID})
+	if err != nil {
+		logger.Context(ctx).Error(err)
+		return echo.NewHTTPError(http.StatusInternalServerError, echo.Map{"error": err.Error()})
+	}
+	if len(histories) > 0 {
+		logger.Context(ctx).Error("cannot delete medicine because it has medicine blister change date history")
+		return echo.NewHTTPError(http.StatusLocked, echo.Map{"error": "cannot delete medicine because it has medicine blister change date history"})
+	}
+
+	// Validate: prevent delete if have medicine houses
+	houses, err := s.medicineRepository.GetMedicineHouses(ctx, model.FilterMedicineHouse{MedicationID: medicationID})
+	if err != nil {
+		logger.Context(ctx).Error(err)
+		return echo.NewHTTPError(http.StatusInternalServerError, echo.Map{"error": err.Error()})
+	}
+	if len(houses) > 0 {
+		logger.Context(ctx).Error("cannot delete medicine because it has medicine houses")
+		return echo.NewHTTPError(http.StatusLocked, echo.Map{"error": "cannot delete medicine because it has medicine houses"})
+	}
+
+	before := medicineSnapshot(ctx, s.medicineRepository, s.categoryRepository, medicationID)
+	rowsAffected, err := s.medicineRepository.DeleteMedicine(ctx, model.DeleteMedicineFilter{MedicationID: medicationID})
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, echo.Map{"error": err.Error()})
+	}
+	if rowsAffected == 0 {
+		return echo.NewHTTPError(http.StatusNotFound, echo.Map{"error": "medicationID is not found"})
+	}
+
+	recordAudit(ctx, s.auditRepository, model.CreateAuditLog{
+		Action:     genmodel.PharmaSheetAuditAction_Delete,
+		EntityType: model.AuditEntityMedicine,
+		EntityID:   medicationID,
+		Before:     before,
+	})
+	return nil
+}
+
+// PreviewForceDeleteMedicine counts what a forced delete of the medicine removes and issues the token confirming it
+func (s *medicine) PreviewForceDeleteMedicine(ctx context.Context, medicationID string) (model.ForceDeleteMedicinePreview, error) {
+	userProfile, err := profile.UseProfile(ctx)
+	if err != nil {
+		return model.ForceDeleteMedicinePreview{}, echo.NewHTTPError(http.StatusUnauthorized, echo.Map{"error": err.Error()})
+	}
+
+	if _, err = s.medicineRepository.GetMedicineFlag(ctx, medicationID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return model.ForceDeleteMedicinePreview{}, echo.NewHTTPError(http.StatusNotFound, echo.Map{"error": "medicationID is not found"})
 		}
+		return model.ForceDeleteMedicinePreview{}, echo.NewHTTPError(http.StatusInternalServerError, echo.Map{"error": err.Error()})
+	}
 
-		// Validate: prevent delete if have medicine blister change date histories
-		histories, err := s.medicineRepository.ListMedicineBlisterChangeDateHistory(ctx, model.FilterMedicineBrandBlisterDateHistory{MedicationID: &medicationID})
-		if err != nil {
-			logger.Context(ctx).Error(err)
-			return echo.NewHTTPError(http.StatusInternalServerError, echo.Map{"error": err.Error()})
+	impact, err := s.medicineRepository.GetMedicineDeleteImpact(ctx, medicationID)
+	if err != nil {
+		return model.ForceDeleteMedicinePreview{}, echo.NewHTTPError(http.StatusInternalServerError, echo.Map{"error": err.Error()})
+	}
+	if err = s.checkMedicineAdminRole(ctx, impact); err != nil {
+		logger.Context(ctx).Error(err)
+		return model.ForceDeleteMedicinePreview{}, err
+	}
+	// the stock ledger is append-only, its movements keep the houses and brands they were posted on
+	if impact.StockMovements > 0 {
+		return model.ForceDeleteMedicinePreview{}, echo.NewHTTPError(http.StatusConflict, echo.Map{"error": "medicine has stock movements, which cannot be deleted"})
+	}
+
+	token := uuid.NewString()
+	expiredAt, err := s.cacheRepository.CreateForceDeleteToken(ctx, token, model.ForceDeleteToken{UserID: userProfile.UserID, Impact: impact})
+	if err != nil {
+		return model.ForceDeleteMedicinePreview{}, echo.NewHTTPError(http.StatusInternalServerError, echo.Map{"error": err.Error()})
+	}
+
+	return model.ForceDeleteMedicinePreview{MedicineDeleteImpact: impact, Token: token, ExpiredAt: expiredAt}, nil
+}
+
+// ForceDeleteMedicine permanently deletes the medicine with everything depending on it, as previewed for the token.
+// The delete is refused when anything has changed since the preview.
+func (s *medicine) ForceDeleteMedicine(ctx context.Context, req model.DeleteMedicineRequest) error {
+	userProfile, err := profile.UseProfile(ctx)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusUnauthorized, echo.Map{"error": err.Error()})
+	}
+
+	token, ok, err := s.cacheRepository.UseForceDeleteToken(ctx, req.Token)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, echo.Map{"error": err.Error()})
+	}
+	if !ok {
+		return echo.NewHTTPError(http.StatusBadRequest, echo.Map{"error": "token is invalid or has expired"})
+	}
+	if token.UserID != userProfile.UserID || token.Impact.MedicationID != req.MedicationID {
+		return echo.NewHTTPError(http.StatusBadRequest, echo.Map{"error": "token is not issued for this medicine"})
+	}
+
+	impact, err := s.medicineRepository.GetMedicineDeleteImpact(ctx, req.MedicationID)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, echo.Map{"error": err.Error()})
+	}
+	if err = s.checkMedicineAdminRole(ctx, impact); err != nil {
+		logger.Context(ctx).Error(err)
+		return err
+	}
+	if !impact.Equal(token.Impact) {
+		return echo.NewHTTPError(http.StatusConflict, echo.Map{"error": "medicine has changed since the preview", "impact": impact})
+	}
+	if impact.StockMovements > 0 {
+		return echo.NewHTTPError(http.StatusConflict, echo.Map{"error": "medicine has stock movements, which cannot be deleted"})
+	}
+
+	before := medicineSnapshot(ctx, s.medicineRepository, s.categoryRepository, req.MedicationID)
+	imageURLs, rowsAffected, err := s.medicineRepository.ForceDeleteMedicine(ctx, req.MedicationID)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, echo.Map{"error": err.Error()})
+	}
+	if rowsAffected == 0 {
+		return echo.NewHTTPError(http.StatusNotFound, echo.Map{"error": "medicationID is not found"})
+	}
+
+	// the rows are gone already, an image which cannot be deleted is only left behind on the drive
+	for _, url := range imageURLs {
+		if err = s.storage.Delete(ctx, url); err != nil {
+			logger.Context(ctx).Warn(err)
 		}
-		if len(histories) > 0 {
-			logger.Context(ctx).Error("cannot delete medicine because it has medicine blister change date history")
-			return echo.NewHTTPError(http.StatusLocked, echo.Map{"error": "cannot delete medicine because it has medicine blister change date history"})
+	}
+
+	recordAudit(ctx, s.auditRepository, model.CreateAuditLog{
+		Action:     genmodel.PharmaSheetAuditAction_Delete,
+		EntityType: model.AuditEntityMedicine,
+		EntityID:   req.MedicationID,
+		Before:     before,
+	})
+	return nil
+}
+
+// checkMedicineAdminRole allows a forced delete, a merge or a flag change to an admin of every warehouse holding a house, a history
+// or stock of the medicines, deleted warehouses included, medicines kept in no warehouse are master data left to a system admin
+func (s *medicine) checkMedicineAdminRole(ctx context.Context, impacts ...model.MedicineDeleteImpact) error {
+	userProfile, err := profile.UseProfile(ctx)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusUnauthorized, echo.Map{"error": err.Error()})
+	}
+
+	var warehouseIDs []string
+	for _, impact := range impacts {
+		for _, warehouse := range impact.Warehouses {
+			if !slices.Contains(warehouseIDs, warehouse.WarehouseID) {
+				warehouseIDs = append(warehouseIDs, warehouse.WarehouseID)
+			}
 		}
+	}
 
-		// Validate: prevent delete if have medicine houses
-		houses, err := s.medicineRepository.GetMedicineHouses(ctx, model.FilterMedicineHouse{MedicationID: medicationID})
-		if err != nil {
-			logger.Context(ctx).Error(err)
+	if len(warehouseIDs) == 0 {
+		return checkSystemAdminRole(ctx, s.userRepository)
+	}
+
+	// a warehouse in the trash still loses its houses and histories, its role is only listed among the deleted warehouses
+	var deletedWarehouses []model.DeletedWarehouse
+	for _, warehouseID := range warehouseIDs {
+		role, err := s.warehouseRepository.GetWarehouseRole(ctx, warehouseID, userProfile.UserID)
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
 			return echo.NewHTTPError(http.StatusInternalServerError, echo.Map{"error": err.Error()})
 		}
-		if len(houses) > 0 {
-			logger.Context(ctx).Error("cannot delete medicine because it has medicine houses")
-			return echo.NewHTTPError(http.StatusLocked, echo.Map{"error": "cannot delete medicine because it has medicine houses"})
+		if errors.Is(err, sql.ErrNoRows) {
+			if deletedWarehouses == nil {
+				if deletedWarehouses, err = s.trashRepository.ListDeletedWarehouses(ctx, userProfile.UserID); err != nil {
+					return echo.NewHTTPError(http.StatusInternalServerError, echo.Map{"error": err.Error()})
+				}
+			}
+			if index := slices.IndexFunc(deletedWarehouses, func(warehouse model.DeletedWarehouse) bool { return warehouse.WarehouseID == warehouseID }); index >= 0 {
+				role = genmodel.PharmaSheetRole(deletedWarehouses[index].Role)
+			}
+		}
+		if role != genmodel.PharmaSheetRole_Admin {
+			return echo.NewHTTPError(http.StatusForbidden, echo.Map{"error": "admin role of warehouse " + warehouseID + " is required"})
 		}
+	}
+	return nil
+}
 
+// MergeMedicine merges the source medicine into the target, the sheet sync translates the id of the source afterwards
+func (s *medicine) MergeMedicine(ctx context.Context, req model.MergeMedicineRequest) (model.MergedMedicine, error) {
+	if req.Strategy == "" {
+		req.Strategy = model.MergeStrategyAbort
 	}
 
-	for _, brand := range medicineBrands {
-		if brand.BlisterImageURL != nil {
-			err = s.storage.Delete(ctx, *brand.BlisterImageURL)
-			if err != nil {
-				logger.Context(ctx).Warn(err)
-			}
+	userProfile, err := profile.UseProfile(ctx)
+	if err != nil {
+		return model.MergedMedicine{}, echo.NewHTTPError(http.StatusUnauthorized, echo.Map{"error": err.Error()})
+	}
+	userID, err := uuid.Parse(userProfile.UserID)
+	if err != nil {
+		return model.MergedMedicine{}, echo.NewHTTPError(http.StatusUnauthorized, echo.Map{"error": err.Error()})
+	}
+
+	sourceImpact, err := s.medicineRepository.GetMedicineDeleteImpact(ctx, req.SourceMedicationID)
+	if err != nil {
+		return model.MergedMedicine{}, echo.NewHTTPError(http.StatusInternalServerError, echo.Map{"error": err.Error()})
+	}
+	targetImpact, err := s.medicineRepository.GetMedicineDeleteImpact(ctx, req.MedicationID)
+	if err != nil {
+		return model.MergedMedicine{}, echo.NewHTTPError(http.StatusInternalServerError, echo.Map{"error": err.Error()})
+	}
+	if err = s.checkMedicineAdminRole(ctx, sourceImpact, targetImpact); err != nil {
+		logger.Context(ctx).Error(err)
+		return model.MergedMedicine{}, err
+	}
+
+	sourceBefore := medicineSnapshot(ctx, s.medicineRepository, s.categoryRepository, req.SourceMedicationID)
+	targetBefore := medicineSnapshot(ctx, s.medicineRepository, s.categoryRepository, req.MedicationID)
+	merged, err := s.medicineRepository.MergeMedicine(ctx, req, &userID)
+	if err != nil {
+		if errors.Is(err, model.ErrMergeCollision) {
+			return merged, echo.NewHTTPError(http.StatusConflict, echo.Map{"error": err.Error(), "collisions": merged.Collisions})
+		}
+		if errors.Is(err, sql.ErrNoRows) {
+			return merged, echo.NewHTTPError(http.StatusNotFound, echo.Map{"error": "medicationID is not found"})
 		}
+		if model.IsConflictError(err) {
+			return merged, echo.NewHTTPError(http.StatusConflict, echo.Map{"error": err.Error()})
+		}
+		return merged, echo.NewHTTPError(http.StatusInternalServerError, echo.Map{"error": err.Error()})
+	}
 
-		if brand.BoxImageURL != nil {
-			err = s.storage.Delete(ctx, *brand.BoxImageURL)
-			if err != nil {
-				logger.Context(ctx).Warn(err)
-			}
+	for _, url := range merged.ImageURLs {
+		if err = s.storage.Delete(ctx, url); err != nil {
+			logger.Context(ctx).Warn(err)
 		}
+	}
 
-		if brand.TabletImageURL != nil {
-			err = s.storage.Delete(ctx, *brand.TabletImageURL)
-			if err != nil {
-				logger.Context(ctx).Warn(err)
-			}
+	recordAudit(ctx, s.auditRepository,
+		model.CreateAuditLog{
+			Action:     genmodel.PharmaSheetAuditAction_Delete,
+			EntityType: model.AuditEntityMedicine,
+			EntityID:   req.SourceMedicationID,
+			Before:     sourceBefore,
+			After:      merged,
+		},
+		model.CreateAuditLog{
+			Action:     genmodel.PharmaSheetAuditAction_Update,
+			EntityType: model.AuditEntityMedicine,
+			EntityID:   req.MedicationID,
+			Before:     targetBefore,
+			After:      medicineSnapshot(ctx, s.medicineRepository, s.categoryRepository, req.MedicationID),
+		},
+	)
+	return merged, nil
+}
+
+// UpdateMedicineCategories replaces the categories of a medicine, like the rest of the master data any user can maintain them
+func (s *medicine) UpdateMedicineCategories(ctx context.Context, req model.UpdateMedicineCategoryRequest) error {
+	if _, err := s.medicineRepository.GetMedicine(ctx, req.MedicationID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return echo.NewHTTPError(http.StatusNotFound, echo.Map{"error": "medicationID is not found"})
 		}
+		return echo.NewHTTPError(http.StatusInternalServerError, echo.Map{"error": err.Error()})
 	}
 
-	rowsAffected, err := s.medicineRepository.DeleteMedicine(ctx, model.DeleteMedicineFilter{MedicationID: medicationID})
+	categories, err := s.categoryRepository.ListCategories(ctx)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, echo.Map{"error": err.Error()})
+	}
+
+	var categoryIDs []uuid.UUID
+	for _, categoryID := range req.CategoryIDs {
+		if !slices.ContainsFunc(categories, func(category model.Category) bool { return category.ID == categoryID }) {
+			logger.Context(ctx).Errorf("categoryID %s is not found", categoryID.String())
+			return echo.NewHTTPError(http.StatusNotFound, echo.Map{"error": "categoryID is not found"})
+		}
+		if !slices.Contains(categoryIDs, categoryID) {
+			categoryIDs = append(categoryIDs, categoryID)
+		}
+	}
+
+	before := medicineSnapshot(ctx, s.medicineRepository, s.categoryRepository, req.MedicationID)
+	if err = s.categoryRepository.UpdateMedicineCategories(ctx, req.MedicationID, categoryIDs); err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, echo.Map{"error": err.Error()})
+	}
+
+	recordAudit(ctx, s.auditRepository, model.CreateAuditLog{
+		Action:     genmodel.PharmaSheetAuditAction_Update,
+		EntityType: model.AuditEntityMedicine,
+		EntityID:   req.MedicationID,
+		Before:     before,
+		After:      medicineSnapshot(ctx, s.medicineRepository, s.categoryRepository, req.MedicationID),
+	})
+	return nil
+}
+
+// UpdateMedicineTags replaces the tags of a medicine, tags are kept in lower case
+func (s *medicine) UpdateMedicineTags(ctx context.Context, req model.UpdateMedicineTagRequest) error {
+	if _, err := s.medicineRepository.GetMedicine(ctx, req.MedicationID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return echo.NewHTTPError(http.StatusNotFound, echo.Map{"error": "medicationID is not found"})
+		}
+		return echo.NewHTTPError(http.StatusInternalServerError, echo.Map{"error": err.Error()})
+	}
+
+	before := medicineSnapshot(ctx, s.medicineRepository, s.categoryRepository, req.MedicationID)
+	if err := s.categoryRepository.UpdateMedicineTags(ctx, req.MedicationID, model.NormalizeTags(req.Tags)); err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, echo.Map{"error": err.Error()})
+	}
+
+	recordAudit(ctx, s.auditRepository, model.CreateAuditLog{
+		Action:     genmodel.PharmaSheetAuditAction_Update,
+		EntityType: model.AuditEntityMedicine,
+		EntityID:   req.MedicationID,
+		Before:     before,
+		After:      medicineSnapshot(ctx, s.medicineRepository, s.categoryRepository, req.MedicationID),
+	})
+	return nil
+}
+
+// UpdateMedicineFlag marks a medicine needing extra care, only an admin of every warehouse holding a house or a history
+// of the medicine can change it, a medicine kept in no warehouse is left to a system admin
+func (s *medicine) UpdateMedicineFlag(ctx context.Context, req model.UpdateMedicineFlagRequest) error {
+	impact, err := s.medicineRepository.GetMedicineDeleteImpact(ctx, req.MedicationID)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, echo.Map{"error": err.Error()})
+	}
+	if err = s.checkMedicineAdminRole(ctx, impact); err != nil {
+		logger.Context(ctx).Error(err)
+		return err
+	}
+
+	before := medicineSnapshot(ctx, s.medicineRepository, s.categoryRepository, req.MedicationID)
+	rowsAffected, err := s.medicineRepository.UpdateMedicineFlag(ctx, req)
 	if err != nil {
 		return echo.NewHTTPError(http.StatusInternalServerError, echo.Map{"error": err.Error()})
 	}
 	if rowsAffected == 0 {
 		return echo.NewHTTPError(http.StatusNotFound, echo.Map{"error": "medicationID is not found"})
 	}
+
+	recordAudit(ctx, s.auditRepository, model.CreateAuditLog{
+		Action:     genmodel.PharmaSheetAuditAction_Update,
+		EntityType: model.AuditEntityMedicine,
+		EntityID:   req.MedicationID,
+		Before:     before,
+		After:      medicineSnapshot(ctx, s.medicineRepository, s.categoryRepository, req.MedicationID),
+	})
 	return nil
 }
 
 func (s *medicine) GetMedicineHouses(ctx context.Context, filter model.ListMedicineHouse) (model.PagingWithMetadata[model.MedicineHouse], error) {
+	var err error
+	filter.FilterMedicineCategory, err = expandCategoryFilter(ctx, s.categoryRepository, filter.FilterMedicineCategory)
+	if err != nil {
+		return model.PagingWithMetadata[model.MedicineHouse]{}, err
+	}
+
 	houses, total, err := s.medicineRepository.ListMedicineHouses(ctx, filter)
 	if err != nil {
 		logger.Context(ctx).Error(err)
@@ -219,47 +653,111 @@ func (s *medicine) GetMedicineHouses(ctx context.Context, filter model.ListMedic
 	return res, nil
 }
 
-func (s *medicine) CreateMedicineHouse(ctx context.Context, req model.CreateMedicineHouseRequest) (string, error) {
-	err := s.checkWarehouseManagementRole(ctx, req.WarehouseID, idTypeWarehouse, genmodel.PharmaSheetRole_Admin, genmodel.PharmaSheetRole_Editor)
+func (s *medicine) CreateMedicineHouse(ctx context.Context, req model.CreateMedicineHouseRequest) (string, []model.LASAWarning, error) {
+	roles, err := houseManagementRoles(ctx, s.medicineRepository, req.MedicationID)
+	if err != nil {
+		return "", nil, err
+	}
+	err = s.checkWarehouseManagementRole(ctx, req.WarehouseID, idTypeWarehouse, roles...)
 	if err != nil {
 		logger.Context(ctx).Error(err)
-		return "", err
+		return "", nil, err
+	}
+
+	req.Locker = strings.TrimSpace(req.Locker)
+	if err = s.checkHouseAddress(ctx, req.WarehouseID, req.Locker, req.Floor, req.No); err != nil {
+		return "", nil, err
 	}
 
 	id, err := s.medicineRepository.CreateMedicineHouse(ctx, req)
 	if err != nil {
 		if model.IsConflictError(err) {
-			return "", echo.NewHTTPError(http.StatusConflict, echo.Map{"error": err.Error()})
+			return "", nil, echo.NewHTTPError(http.StatusConflict, echo.Map{"error": err.Error()})
 		}
-		return "", echo.NewHTTPError(http.StatusInternalServerError, echo.Map{"error": err.Error()})
+		return "", nil, echo.NewHTTPError(http.StatusInternalServerError, echo.Map{"error": err.Error()})
 	}
-	return id, nil
+	recordAudit(ctx, s.auditRepository, model.CreateAuditLog{
+		Action:      genmodel.PharmaSheetAuditAction_Create,
+		EntityType:  model.AuditEntityMedicineHouse,
+		EntityID:    id,
+		WarehouseID: &req.WarehouseID,
+		After:       medicineHouseSnapshot(ctx, s.medicineRepository, uuid.MustParse(id)),
+	})
+
+	warnings := s.lasaWarnings(ctx, model.MedicineHouse{
+		ID:           uuid.MustParse(id),
+		MedicationID: req.MedicationID,
+		WarehouseID:  req.WarehouseID,
+		Locker:       req.Locker,
+		Floor:        req.Floor,
+		No:           req.No,
+	})
+	return id, warnings, nil
 }
 
-func (s *medicine) UpdateMedicineHouse(ctx context.Context, req model.UpdateMedicineHouseRequest) error {
+func (s *medicine) UpdateMedicineHouse(ctx context.Context, req model.UpdateMedicineHouseRequest) ([]model.LASAWarning, error) {
 	houses, err := s.medicineRepository.GetMedicineHouses(ctx, model.FilterMedicineHouse{ID: req.ID})
 	if err != nil {
 		logger.Context(ctx).Error(err)
-		return echo.NewHTTPError(http.StatusInternalServerError, echo.Map{"error": err.Error()})
+		return nil, echo.NewHTTPError(http.StatusInternalServerError, echo.Map{"error": err.Error()})
 	}
 	if len(houses) == 0 {
 		logger.Context(ctx).Errorf("houseID %s is not found", req.ID)
-		return echo.NewHTTPError(http.StatusNotFound, echo.Map{"error": "houseID is not found"})
+		return nil, echo.NewHTTPError(http.StatusNotFound, echo.Map{"error": "houseID is not found"})
 	}
-	err = s.checkWarehouseManagementRole(ctx, houses[0].WarehouseID, idTypeWarehouse, genmodel.PharmaSheetRole_Admin, genmodel.PharmaSheetRole_Editor)
+	roles, err := houseManagementRoles(ctx, s.medicineRepository, houses[0].MedicationID, req.MedicationID)
+	if err != nil {
+		return nil, err
+	}
+	err = s.checkWarehouseManagementRole(ctx, houses[0].WarehouseID, idTypeWarehouse, roles...)
 	if err != nil {
 		logger.Context(ctx).Error(err)
-		return err
+		return nil, err
+	}
+
+	req.Locker = strings.TrimSpace(req.Locker)
+	if err = s.checkHouseAddress(ctx, houses[0].WarehouseID, req.Locker, req.Floor, req.No); err != nil {
+		return nil, err
 	}
 
 	err = s.medicineRepository.UpdateMedicineHouse(ctx, req)
 	if err != nil {
 		if model.IsConflictError(err) {
-			return echo.NewHTTPError(http.StatusConflict, echo.Map{"error": err.Error()})
+			return nil, echo.NewHTTPError(http.StatusConflict, echo.Map{"error": err.Error()})
 		}
-		return echo.NewHTTPError(http.StatusInternalServerError, echo.Map{"error": err.Error()})
+		return nil, echo.NewHTTPError(http.StatusInternalServerError, echo.Map{"error": err.Error()})
 	}
-	return nil
+	recordAudit(ctx, s.auditRepository, model.CreateAuditLog{
+		Action:      genmodel.PharmaSheetAuditAction_Update,
+		EntityType:  model.AuditEntityMedicineHouse,
+		EntityID:    req.ID.String(),
+		WarehouseID: &houses[0].WarehouseID,
+		Before:      houses[0],
+		After:       medicineHouseSnapshot(ctx, s.medicineRepository, req.ID),
+	})
+
+	warnings := s.lasaWarnings(ctx, model.MedicineHouse{
+		ID:           req.ID,
+		MedicationID: req.MedicationID,
+		WarehouseID:  houses[0].WarehouseID,
+		Locker:       req.Locker,
+		Floor:        req.Floor,
+		No:           req.No,
+	})
+	return warnings, nil
+}
+
+// lasaWarnings only advises on where a house is placed, a failure to check it never fails the change
+func (s *medicine) lasaWarnings(ctx context.Context, house model.MedicineHouse) []model.LASAWarning {
+	warnings, err := findLASAWarnings(ctx, s.lasaRepository, s.medicineRepository, house)
+	if err != nil {
+		logger.Context(ctx).Warn(err)
+		return []model.LASAWarning{}
+	}
+	for _, warning := range warnings {
+		logger.Context(ctx).Warnf("house %s of %s is next to %s of look-alike or sound-alike %s", house.Address(), warning.MedicationID, warning.Address, warning.SimilarMedicationID)
+	}
+	return warnings
 }
 
 func (s *medicine) DeleteMedicineHouse(ctx context.Context, id uuid.UUID) (int64, error) {
@@ -272,25 +770,193 @@ func (s *medicine) DeleteMedicineHouse(ctx context.Context, id uuid.UUID) (int64
 		logger.Context(ctx).Errorf("houseID %s is not found", id.String())
 		return 0, echo.NewHTTPError(http.StatusNotFound, echo.Map{"error": "houseID is not found"})
 	}
-	err = s.checkWarehouseManagementRole(ctx, houses[0].WarehouseID, idTypeWarehouse, genmodel.PharmaSheetRole_Admin, genmodel.PharmaSheetRole_Editor)
+	roles, err := houseManagementRoles(ctx, s.medicineRepository, houses[0].MedicationID)
+	if err != nil {
+		return 0, err
+	}
+	err = s.checkWarehouseManagementRole(ctx, houses[0].WarehouseID, idTypeWarehouse, roles...)
 	if err != nil {
 		logger.Context(ctx).Error(err)
 		return 0, err
 	}
 
-	rowsAffected, err := s.medicineRepository.DeleteMedicineHouse(ctx, model.DeleteMedicineHouseFilter{ID: id})
-	if err != nil {
-		logger.Context(ctx).Error(err)
-		return 0, echo.NewHTTPError(http.StatusInternalServerError, echo.Map{"error": err.Error()})
+	rowsAffected, err := s.medicineRepository.DeleteMedicineHouse(ctx, model.DeleteMedicineHouseFilter{ID: id})
+	if err != nil {
+		logger.Context(ctx).Error(err)
+		return 0, echo.NewHTTPError(http.StatusInternalServerError, echo.Map{"error": err.Error()})
+	}
+	if rowsAffected == 0 {
+		logger.Context(ctx).Errorf("houseID %s is not found", id.String())
+		return 0, echo.NewHTTPError(http.StatusNotFound, echo.Map{"error": "houseID is not found"})
+	}
+
+	recordAudit(ctx, s.auditRepository, model.CreateAuditLog{
+		Action:      genmodel.PharmaSheetAuditAction_Delete,
+		EntityType:  model.AuditEntityMedicineHouse,
+		EntityID:    id.String(),
+		WarehouseID: &houses[0].WarehouseID,
+		Before:      houses[0],
+	})
+	return rowsAffected, nil
+}
+
+func (s *medicine) PrintMedicineHouseLabels(ctx context.Context, req model.PrintMedicineHouseLabelRequest) ([]byte, error) {
+	err := s.checkWarehouseManagementRole(ctx, req.WarehouseID, idTypeWarehouse, genmodel.PharmaSheetRole_Admin, genmodel.PharmaSheetRole_Editor, genmodel.PharmaSheetRole_Viewer)
+	if err != nil {
+		logger.Context(ctx).Error(err)
+		return nil, err
+	}
+
+	houses, err := s.medicineRepository.GetMedicineHouses(ctx, model.FilterMedicineHouse{WarehouseID: req.WarehouseID})
+	if err != nil {
+		return nil, echo.NewHTTPError(http.StatusInternalServerError, echo.Map{"error": err.Error()})
+	}
+	houses = slices.DeleteFunc(houses, func(house model.MedicineHouse) bool {
+		if len(req.HouseIDs) > 0 {
+			return !slices.Contains(req.HouseIDs, house.ID.String())
+		}
+		return house.Locker != req.Locker
+	})
+	if len(houses) == 0 {
+		return nil, echo.NewHTTPError(http.StatusNotFound, echo.Map{"error": "no medicine house to print"})
+	}
+	slices.SortFunc(houses, func(a, b model.MedicineHouse) int {
+		return cmp.Or(
+			cmp.Compare(a.Locker, b.Locker),
+			cmp.Compare(a.Floor, b.Floor),
+			cmp.Compare(a.No, b.No),
+			cmp.Compare(a.MedicationID, b.MedicationID),
+		)
+	})
+
+	medicines, err := s.medicineRepository.ListMedicinesMaster(ctx)
+	if err != nil {
+		return nil, echo.NewHTTPError(http.StatusInternalServerError, echo.Map{"error": err.Error()})
+	}
+	medicalNames := make(map[string]string)
+	flags := make(map[string]model.MedicineFlag)
+	for _, medicine := range medicines {
+		medicalNames[medicine.MedicationID] = medicine.MedicalName
+		flags[medicine.MedicationID] = medicine.MedicineFlag
+	}
+	brands, err := s.medicineRepository.GetMedicineBrands(ctx, model.FilterMedicineBrand{WarehouseID: req.WarehouseID})
+	if err != nil {
+		return nil, echo.NewHTTPError(http.StatusInternalServerError, echo.Map{"error": err.Error()})
+	}
+	tradeNames := make(map[string][]string)
+	for _, brand := range brands {
+		tradeName := brand.TradeID
+		if brand.TradeName != nil && *brand.TradeName != "" {
+			tradeName = *brand.TradeName
+		}
+		if !slices.Contains(tradeNames[brand.MedicationID], tradeName) {
+			tradeNames[brand.MedicationID] = append(tradeNames[brand.MedicationID], tradeName)
+		}
+	}
+
+	labels := make([]label.Label, 0, len(houses))
+	for _, house := range houses {
+		labels = append(labels, label.Label{
+			Title:    medicalNames[house.MedicationID],
+			Subtitle: strings.Join(tradeNames[house.MedicationID], ", "),
+			Address:  house.Address(),
+			Text:     util.Value(house.Label),
+			Warning:  strings.Join(flags[house.MedicationID].Warnings(), " | "),
+			QRCode:   house.ID.String(),
+		})
+	}
+
+	size := label.SizeMedium
+	switch {
+	case req.Width > 0 && req.Height > 0:
+		size = label.Size{Width: req.Width, Height: req.Height}
+	case req.Size == "SMALL":
+		size = label.SizeSmall
+	case req.Size == "LARGE":
+		size = label.SizeLarge
+	}
+
+	pdf, err := s.labelPrinter.Print(size, labels)
+	if errors.Is(err, label.ErrNoFont) {
+		return nil, echo.NewHTTPError(http.StatusServiceUnavailable, echo.Map{"error": err.Error()})
+	}
+	if err != nil {
+		logger.Context(ctx).Error(err)
+		return nil, echo.NewHTTPError(http.StatusInternalServerError, echo.Map{"error": err.Error()})
+	}
+	return pdf, nil
+}
+
+func (s *medicine) ScanMedicine(ctx context.Context, req model.ScanRequest) ([]model.ScanResult, error) {
+	code := strings.TrimSpace(req.Code)
+
+	var results []model.ScanResult
+	if houseID, err := uuid.Parse(code); err == nil {
+		houses, err := s.medicineRepository.GetMedicineHouses(ctx, model.FilterMedicineHouse{ID: houseID})
+		if err != nil {
+			logger.Context(ctx).Error(err)
+			return nil, echo.NewHTTPError(http.StatusInternalServerError, echo.Map{"error": err.Error()})
+		}
+		for _, house := range houses {
+			results = append(results, model.ScanResult{
+				Code:     code,
+				Type:     model.ScanTypeHouse,
+				HouseID:  &house.ID,
+				Medicine: model.Medicine{MedicationID: house.MedicationID},
+			})
+		}
+	} else {
+		brands, scanType, err := findMedicineBrands(ctx, s.medicineRepository, code)
+		if err != nil {
+			return nil, err
+		}
+		for _, brand := range brands {
+			results = append(results, model.ScanResult{
+				Code:     code,
+				Type:     scanType,
+				BrandID:  &brand.ID,
+				Medicine: model.Medicine{MedicationID: brand.MedicationID},
+			})
+		}
+	}
+
+	medicines := make(map[string]model.Medicine)
+	data := make([]model.ScanResult, 0, len(results))
+	for _, result := range results {
+		medicine, ok := medicines[result.Medicine.MedicationID]
+		if !ok {
+			var err error
+			medicine, err = s.medicineRepository.GetMedicine(ctx, result.Medicine.MedicationID)
+			if err != nil {
+				logger.Context(ctx).Error(err)
+				return nil, echo.NewHTTPError(http.StatusInternalServerError, echo.Map{"error": err.Error()})
+			}
+			medicine = filterMedicineWarehouse(medicine, req.WarehouseID)
+			medicine = s.injectMedicineImageURL(ctx, medicine)
+			medicines[medicine.MedicationID] = medicine
+		}
+
+		// houses of the medicine are already limited to the caller's warehouses
+		if !containsMedicineHouse(medicine, result.HouseID) {
+			continue
+		}
+		result.Medicine = medicine
+		data = append(data, result)
 	}
-	if rowsAffected == 0 {
-		logger.Context(ctx).Errorf("houseID %s is not found", id.String())
-		return 0, echo.NewHTTPError(http.StatusNotFound, echo.Map{"error": "houseID is not found"})
+
+	if len(data) == 0 {
+		return nil, echo.NewHTTPError(http.StatusNotFound, echo.Map{"error": "code is not found"})
 	}
-	return rowsAffected, nil
+
+	return data, nil
 }
 
 func (s *medicine) GetMedicineWithBrands(ctx context.Context, filter model.FilterMedicineWithBrand) (res model.PagingWithMetadata[model.Medicine], err error) {
+	filter.FilterMedicineCategory, err = expandCategoryFilter(ctx, s.categoryRepository, filter.FilterMedicineCategory)
+	if err != nil {
+		return res, err
+	}
+
 	data, total, err := s.medicineRepository.GetMedicineWithBrands(ctx, filter)
 	if err != nil {
 		logger.Context(ctx).Error(err)
@@ -306,6 +972,11 @@ func (s *medicine) GetMedicineWithBrands(ctx context.Context, filter model.Filte
 }
 
 func (s *medicine) GetMedicineBrands(ctx context.Context, filter model.FilterMedicineWithBrand) (res model.PagingWithMetadata[model.MedicineBrandView], err error) {
+	filter.FilterMedicineCategory, err = expandCategoryFilter(ctx, s.categoryRepository, filter.FilterMedicineCategory)
+	if err != nil {
+		return res, err
+	}
+
 	data, total, err := s.medicineRepository.GetMedicineBrandsPagination(ctx, filter)
 	if err != nil {
 		logger.Context(ctx).Error(err)
@@ -363,6 +1034,7 @@ func (s *medicine) GetMedicineBrands(ctx context.Context, filter model.FilterMed
 			BlisterImageURL: data[index].BlisterImageURL,
 			TabletImageURL:  data[index].TabletImageURL,
 			BoxImageURL:     data[index].BoxImageURL,
+			Barcode:         data[index].Barcode,
 			BlisterDates:    blisterDates,
 		})
 	}
@@ -372,6 +1044,22 @@ func (s *medicine) GetMedicineBrands(ctx context.Context, filter model.FilterMed
 }
 
 func (s *medicine) CreateMedicineBrand(ctx context.Context, req model.CreateMedicineBrandRequest) (string, error) {
+	barcode, err := normalizeBarcode(req.Barcode)
+	if err != nil {
+		logger.Context(ctx).Error(err)
+		return "", err
+	}
+	req.Barcode = barcode
+
+	// a medicine in the trash cannot take new brands until it is restored
+	if _, err := s.medicineRepository.GetMedicineFlag(ctx, req.MedicationID); err != nil {
+		logger.Context(ctx).Error(err)
+		if errors.Is(err, sql.ErrNoRows) {
+			return "", echo.NewHTTPError(http.StatusNotFound, echo.Map{"error": "medicationID is not found"})
+		}
+		return "", echo.NewHTTPError(http.StatusInternalServerError, echo.Map{"error": err.Error()})
+	}
+
 	if req.BlisterImageFile != nil {
 		resolveFileName("แผงยา", req.BlisterImageFile)
 		id, err := s.storage.UploadMultipart(ctx, "รูปภาพยา/แผงยา", req.BlisterImageFile)
@@ -410,6 +1098,12 @@ func (s *medicine) CreateMedicineBrand(ctx context.Context, req model.CreateMedi
 		return "", echo.NewHTTPError(http.StatusInternalServerError, echo.Map{"error": err.Error()})
 	}
 
+	recordAudit(ctx, s.auditRepository, model.CreateAuditLog{
+		Action:     genmodel.PharmaSheetAuditAction_Create,
+		EntityType: model.AuditEntityMedicineBrand,
+		EntityID:   brandID,
+		After:      medicineBrandSnapshot(ctx, s.medicineRepository, uuid.MustParse(brandID)),
+	})
 	return brandID, nil
 }
 
@@ -431,6 +1125,15 @@ func (s *medicine) UpdateMedicineBrand(ctx context.Context, req model.UpdateMedi
 		return echo.NewHTTPError(http.StatusBadRequest, echo.Map{"error": "brandID is invalid"})
 	}
 
+	if req.Barcode != nil {
+		barcode, err := normalizeBarcode(req.Barcode)
+		if err != nil {
+			logger.Context(ctx).Error(err)
+			return err
+		}
+		req.Barcode = util.Pointer(util.Value(barcode))
+	}
+
 	if req.BlisterImageFile != nil {
 		resolveFileName("แผงยา", req.BlisterImageFile)
 		id, err := s.storage.UploadMultipart(ctx, "รูปภาพยา/แผงยา", req.BlisterImageFile)
@@ -502,8 +1205,19 @@ func (s *medicine) UpdateMedicineBrand(ctx context.Context, req model.UpdateMedi
 	err = s.medicineRepository.UpdateMedicineBrand(ctx, req)
 	if err != nil {
 		logger.Context(ctx).Error(err)
+		if model.IsConflictError(err) {
+			return echo.NewHTTPError(http.StatusConflict, echo.Map{"error": err.Error()})
+		}
 		return echo.NewHTTPError(http.StatusInternalServerError, echo.Map{"error": err.Error()})
 	}
+
+	recordAudit(ctx, s.auditRepository, model.CreateAuditLog{
+		Action:     genmodel.PharmaSheetAuditAction_Update,
+		EntityType: model.AuditEntityMedicineBrand,
+		EntityID:   req.BrandID.String(),
+		Before:     brand,
+		After:      medicineBrandSnapshot(ctx, s.medicineRepository, req.BrandID),
+	})
 	return nil
 }
 
@@ -537,29 +1251,6 @@ func (s *medicine) DeleteMedicineBrand(ctx context.Context, id uuid.UUID) (int64
 
 	}
 
-	for _, brand := range medicineBrands {
-		if brand.BlisterImageURL != nil {
-			err = s.storage.Delete(ctx, *brand.BlisterImageURL)
-			if err != nil {
-				logger.Context(ctx).Warn(err)
-			}
-		}
-
-		if brand.BoxImageURL != nil {
-			err = s.storage.Delete(ctx, *brand.BoxImageURL)
-			if err != nil {
-				logger.Context(ctx).Warn(err)
-			}
-		}
-
-		if brand.TabletImageURL != nil {
-			err = s.storage.Delete(ctx, *brand.TabletImageURL)
-			if err != nil {
-				logger.Context(ctx).Warn(err)
-			}
-		}
-	}
-
 	rowsAffected, err := s.medicineRepository.DeleteMedicineBrand(ctx, model.DeleteMedicineBrandFilter{BrandID: id})
 	if err != nil {
 		return 0, echo.NewHTTPError(http.StatusInternalServerError, echo.Map{"error": err.Error()})
@@ -567,6 +1258,15 @@ func (s *medicine) DeleteMedicineBrand(ctx context.Context, id uuid.UUID) (int64
 	if rowsAffected == 0 {
 		return 0, echo.NewHTTPError(http.StatusNotFound, echo.Map{"error": "medicationID is not found"})
 	}
+
+	for _, brand := range medicineBrands {
+		recordAudit(ctx, s.auditRepository, model.CreateAuditLog{
+			Action:     genmodel.PharmaSheetAuditAction_Delete,
+			EntityType: model.AuditEntityMedicineBrand,
+			EntityID:   brand.ID.String(),
+			Before:     brand,
+		})
+	}
 	return rowsAffected, nil
 }
 
@@ -582,12 +1282,39 @@ func (s *medicine) ListMedicineBlisterChangeDateHistory(ctx context.Context, req
 }
 
 func (s *medicine) CreateMedicineBlisterChangeDateHistory(ctx context.Context, req model.CreateMedicineBlisterChangeDateHistoryRequest) (string, error) {
-	err := s.checkWarehouseManagementRole(ctx, req.WarehouseID, idTypeWarehouse, genmodel.PharmaSheetRole_Admin, genmodel.PharmaSheetRole_Editor)
+	roles, err := houseManagementRoles(ctx, s.medicineRepository, req.MedicationID)
+	if err != nil {
+		return "", err
+	}
+	err = s.checkWarehouseManagementRole(ctx, req.WarehouseID, idTypeWarehouse, roles...)
+	if err != nil {
+		logger.Context(ctx).Error(err)
+		return "", err
+	}
+
+	flag, err := s.medicineRepository.GetMedicineFlag(ctx, req.MedicationID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return "", echo.NewHTTPError(http.StatusNotFound, echo.Map{"error": "medicationID is not found"})
+		}
+		return "", echo.NewHTTPError(http.StatusInternalServerError, echo.Map{"error": err.Error()})
+	}
+	req.ConfirmedBy, err = confirmFlaggedMedicine(ctx, s.cacheRepository, s.warehouseRepository, model.ConfirmationScope{WarehouseID: req.WarehouseID, MedicationID: req.MedicationID, Action: model.ConfirmationActionBlisterChange}, flag, req.ConfirmationCode)
 	if err != nil {
 		logger.Context(ctx).Error(err)
 		return "", err
 	}
 
+	userProfile, err := profile.UseProfile(ctx)
+	if err != nil {
+		return "", err
+	}
+	createdBy, err := uuid.Parse(userProfile.UserID)
+	if err != nil {
+		return "", echo.NewHTTPError(http.StatusInternalServerError, echo.Map{"error": err.Error()})
+	}
+	req.CreatedBy = &createdBy
+
 	id, err := s.medicineRepository.CreateMedicineBlisterChangeDateHistory(ctx, req)
 	if err != nil {
 		if model.IsConflictError(err) {
@@ -595,11 +1322,21 @@ func (s *medicine) CreateMedicineBlisterChangeDateHistory(ctx context.Context, r
 		}
 		return "", echo.NewHTTPError(http.StatusInternalServerError, echo.Map{"error": err.Error()})
 	}
+
+	recordAudit(ctx, s.auditRepository, model.CreateAuditLog{
+		Action:      genmodel.PharmaSheetAuditAction_Create,
+		EntityType:  model.AuditEntityBlisterDateHistory,
+		EntityID:    id,
+		WarehouseID: &req.WarehouseID,
+		After:       blisterDateHistorySnapshot(ctx, s.medicineRepository, uuid.MustParse(id)),
+	})
 	return id, nil
 }
 
+// DeleteMedicineBlisterChangeDateHistory removes the blister changes of a single medicine, like creating one
+// a flagged medicine needs an admin and the code of a confirming user
 func (s *medicine) DeleteMedicineBlisterChangeDateHistory(ctx context.Context, filter model.DeleteMedicineBlisterChangeDateHistoryRequest) error {
-	warehouseID := ""
+	warehouseID, medicationID := "", ""
 	if filter.HistoryID != nil {
 		blisterChangeDateHistory, err := s.medicineRepository.GetMedicineBlisterChangeDateHistory(ctx, *filter.HistoryID)
 		if err != nil {
@@ -609,24 +1346,290 @@ func (s *medicine) DeleteMedicineBlisterChangeDateHistory(ctx context.Context, f
 			}
 			return echo.NewHTTPError(http.StatusInternalServerError, echo.Map{"error": err.Error()})
 		}
-		warehouseID = blisterChangeDateHistory.WarehouseID
-	} else if filter.WarehouseID != nil {
-		warehouseID = *filter.WarehouseID
+		warehouseID, medicationID = blisterChangeDateHistory.WarehouseID, blisterChangeDateHistory.MedicationID
+	} else if filter.WarehouseID != nil && filter.MedicationID != nil {
+		warehouseID, medicationID = *filter.WarehouseID, *filter.MedicationID
 	} else {
-		return echo.NewHTTPError(http.StatusBadRequest, echo.Map{"error": "warehouseID is required"})
+		return echo.NewHTTPError(http.StatusBadRequest, echo.Map{"error": "warehouseID and medicationID are required"})
+	}
+
+	roles, err := houseManagementRoles(ctx, s.medicineRepository, medicationID)
+	if err != nil {
+		return err
+	}
+	err = s.checkWarehouseManagementRole(ctx, warehouseID, idTypeWarehouse, roles...)
+	if err != nil {
+		logger.Context(ctx).Error(err)
+		return err
+	}
+
+	histories, err := s.medicineRepository.ListMedicineBlisterChangeDateHistory(ctx, model.FilterMedicineBrandBlisterDateHistory{
+		BrandID:      filter.BrandID,
+		MedicationID: &medicationID,
+		WarehouseID:  &warehouseID,
+	})
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, echo.Map{"error": err.Error()})
 	}
-	err := s.checkWarehouseManagementRole(ctx, warehouseID, idTypeWarehouse, genmodel.PharmaSheetRole_Admin, genmodel.PharmaSheetRole_Editor)
+
+	flag, err := s.medicineRepository.GetMedicineFlag(ctx, medicationID)
 	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, echo.Map{"error": err.Error()})
+	}
+	scope := model.ConfirmationScope{WarehouseID: warehouseID, MedicationID: medicationID, Action: model.ConfirmationActionBlisterChange}
+	if _, err = confirmFlaggedMedicine(ctx, s.cacheRepository, s.warehouseRepository, scope, flag, filter.ConfirmationCode); err != nil {
 		logger.Context(ctx).Error(err)
 		return err
 	}
+
 	err = s.medicineRepository.DeleteMedicineBlisterChangeDateHistory(ctx, filter)
 	if err != nil {
 		return echo.NewHTTPError(http.StatusInternalServerError, echo.Map{"error": err.Error()})
 	}
+
+	var logs []model.CreateAuditLog
+	for _, history := range histories {
+		if filter.HistoryID != nil && history.ID != *filter.HistoryID {
+			continue
+		}
+		logs = append(logs, model.CreateAuditLog{
+			Action:      genmodel.PharmaSheetAuditAction_Delete,
+			EntityType:  model.AuditEntityBlisterDateHistory,
+			EntityID:    history.ID.String(),
+			WarehouseID: &history.WarehouseID,
+			Before:      history,
+		})
+	}
+	recordAudit(ctx, s.auditRepository, logs...)
+	return nil
+}
+
+func (s *medicine) ListBlisterChangeIntervals(ctx context.Context, req model.FilterBlisterChangeInterval) ([]model.BlisterChangeInterval, error) {
+	err := s.checkWarehouseManagementRole(ctx, req.WarehouseID, idTypeWarehouse, genmodel.PharmaSheetRole_Admin, genmodel.PharmaSheetRole_Editor, genmodel.PharmaSheetRole_Viewer)
+	if err != nil {
+		logger.Context(ctx).Error(err)
+		return nil, err
+	}
+
+	intervals, err := s.medicineRepository.ListBlisterChangeIntervals(ctx, req.WarehouseID)
+	if err != nil {
+		return nil, echo.NewHTTPError(http.StatusInternalServerError, echo.Map{"error": err.Error()})
+	}
+	return intervals, nil
+}
+
+func (s *medicine) UpsertBlisterChangeInterval(ctx context.Context, req model.UpsertBlisterChangeIntervalRequest) (string, error) {
+	err := s.checkWarehouseManagementRole(ctx, req.WarehouseID, idTypeWarehouse, genmodel.PharmaSheetRole_Admin, genmodel.PharmaSheetRole_Editor)
+	if err != nil {
+		logger.Context(ctx).Error(err)
+		return "", err
+	}
+
+	if req.MedicationID != nil && *req.MedicationID == "" {
+		req.MedicationID = nil
+	}
+	if req.BrandID != nil {
+		brands, err := s.medicineRepository.GetMedicineBrands(ctx, model.FilterMedicineBrand{BrandID: *req.BrandID})
+		if err != nil {
+			logger.Context(ctx).Error(err)
+			return "", echo.NewHTTPError(http.StatusInternalServerError, echo.Map{"error": err.Error()})
+		}
+		if len(brands) == 0 {
+			return "", echo.NewHTTPError(http.StatusNotFound, echo.Map{"error": "brandID is not found"})
+		}
+		if req.MedicationID != nil && *req.MedicationID != brands[0].MedicationID {
+			return "", echo.NewHTTPError(http.StatusBadRequest, echo.Map{"error": "brandID does not belong to medicationID"})
+		}
+		req.MedicationID = &brands[0].MedicationID
+	}
+
+	intervals, err := s.medicineRepository.ListBlisterChangeIntervals(ctx, req.WarehouseID)
+	if err != nil {
+		return "", echo.NewHTTPError(http.StatusInternalServerError, echo.Map{"error": err.Error()})
+	}
+
+	id, err := s.medicineRepository.UpsertBlisterChangeInterval(ctx, req)
+	if err != nil {
+		if model.IsConflictError(err) {
+			return "", echo.NewHTTPError(http.StatusConflict, echo.Map{"error": err.Error()})
+		}
+		return "", echo.NewHTTPError(http.StatusInternalServerError, echo.Map{"error": err.Error()})
+	}
+
+	log := model.CreateAuditLog{
+		Action:      genmodel.PharmaSheetAuditAction_Create,
+		EntityType:  model.AuditEntityBlisterChangeInterval,
+		EntityID:    id,
+		WarehouseID: &req.WarehouseID,
+	}
+	for _, interval := range intervals {
+		if interval.ID.String() == id {
+			log.Action, log.Before = genmodel.PharmaSheetAuditAction_Update, interval
+		}
+	}
+	if interval, err := s.medicineRepository.GetBlisterChangeInterval(ctx, uuid.MustParse(id)); err == nil {
+		log.After = interval
+	}
+	recordAudit(ctx, s.auditRepository, log)
+	return id, nil
+}
+
+func (s *medicine) DeleteBlisterChangeInterval(ctx context.Context, id uuid.UUID) error {
+	interval, err := s.medicineRepository.GetBlisterChangeInterval(ctx, id)
+	if err != nil {
+		logger.Context(ctx).Error(err)
+		if errors.Is(err, sql.ErrNoRows) {
+			return echo.NewHTTPError(http.StatusNotFound, echo.Map{"error": "blisterChangeIntervalID is not found"})
+		}
+		return echo.NewHTTPError(http.StatusInternalServerError, echo.Map{"error": err.Error()})
+	}
+
+	err = s.checkWarehouseManagementRole(ctx, interval.WarehouseID, idTypeWarehouse, genmodel.PharmaSheetRole_Admin, genmodel.PharmaSheetRole_Editor)
+	if err != nil {
+		logger.Context(ctx).Error(err)
+		return err
+	}
+
+	_, err = s.medicineRepository.DeleteBlisterChangeInterval(ctx, id)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, echo.Map{"error": err.Error()})
+	}
+
+	recordAudit(ctx, s.auditRepository, model.CreateAuditLog{
+		Action:      genmodel.PharmaSheetAuditAction_Delete,
+		EntityType:  model.AuditEntityBlisterChangeInterval,
+		EntityID:    id.String(),
+		WarehouseID: &interval.WarehouseID,
+		Before:      interval,
+	})
 	return nil
 }
 
+func (s *medicine) ListBlisterChangeDues(ctx context.Context, req model.FilterBlisterChangeDue) ([]model.BlisterChangeDue, error) {
+	err := s.checkWarehouseManagementRole(ctx, req.WarehouseID, idTypeWarehouse, genmodel.PharmaSheetRole_Admin, genmodel.PharmaSheetRole_Editor, genmodel.PharmaSheetRole_Viewer)
+	if err != nil {
+		logger.Context(ctx).Error(err)
+		return nil, err
+	}
+
+	houses, err := s.medicineRepository.GetMedicineHouses(ctx, model.FilterMedicineHouse{WarehouseID: req.WarehouseID})
+	if err != nil {
+		return nil, echo.NewHTTPError(http.StatusInternalServerError, echo.Map{"error": err.Error()})
+	}
+	histories, err := s.medicineRepository.ListMedicineBlisterChangeDateHistory(ctx, model.FilterMedicineBrandBlisterDateHistory{WarehouseID: &req.WarehouseID})
+	if err != nil {
+		return nil, echo.NewHTTPError(http.StatusInternalServerError, echo.Map{"error": err.Error()})
+	}
+	intervals, err := s.medicineRepository.ListBlisterChangeIntervals(ctx, req.WarehouseID)
+	if err != nil {
+		return nil, echo.NewHTTPError(http.StatusInternalServerError, echo.Map{"error": err.Error()})
+	}
+
+	// a house holds every brand of its medicine, so the latest change of any brand counts towards its next due date
+	latestHistories := make(map[string]model.MedicineBlisterDateHistory)
+	for _, history := range histories {
+		if latest, ok := latestHistories[history.MedicationID]; !ok || history.BlisterChangeDate.After(latest.BlisterChangeDate) {
+			latestHistories[history.MedicationID] = history
+		}
+	}
+
+	now := time.Now()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	withinDays := int(req.Duration / util.Day)
+
+	dues := make([]model.BlisterChangeDue, 0)
+	for _, house := range houses {
+		due := model.BlisterChangeDue{
+			HouseID:      house.ID,
+			WarehouseID:  house.WarehouseID,
+			MedicationID: house.MedicationID,
+			Locker:       house.Locker,
+			Floor:        house.Floor,
+			No:           house.No,
+			Label:        house.Label,
+		}
+
+		history, ok := latestHistories[house.MedicationID]
+		if !ok {
+			// a house whose blister was never changed has no due date, it is listed first for a check
+			due.IntervalDays = s.resolveBlisterChangeIntervalDays(intervals, house.MedicationID, nil)
+			due.Status = model.BlisterChangeDueStatusUnknown
+			dues = append(dues, due)
+			continue
+		}
+
+		intervalDays := s.resolveBlisterChangeIntervalDays(intervals, history.MedicationID, history.BrandID)
+		lastChangeDate := time.Date(history.BlisterChangeDate.Year(), history.BlisterChangeDate.Month(), history.BlisterChangeDate.Day(), 0, 0, 0, 0, time.UTC)
+		dueDate := lastChangeDate.AddDate(0, 0, int(intervalDays))
+		daysUntilDue := int(dueDate.Sub(today) / util.Day)
+		if daysUntilDue > withinDays {
+			continue
+		}
+
+		due.BrandID = history.BrandID
+		due.TradeID = history.TradeID
+		due.IntervalDays = intervalDays
+		due.LastChangeDate = lastChangeDate.Format(model.DateAppLayout)
+		due.DueDate = dueDate.Format(model.DateAppLayout)
+		due.DaysUntilDue = daysUntilDue
+		due.Status = model.BlisterChangeDueStatusUpcoming
+		if daysUntilDue < 0 {
+			due.Status = model.BlisterChangeDueStatusOverdue
+		}
+		dues = append(dues, due)
+	}
+
+	slices.SortFunc(dues, func(a, b model.BlisterChangeDue) int {
+		if isUnknownA, isUnknownB := a.Status == model.BlisterChangeDueStatusUnknown, b.Status == model.BlisterChangeDueStatusUnknown; isUnknownA != isUnknownB {
+			if isUnknownA {
+				return -1
+			}
+			return 1
+		}
+		if a.DaysUntilDue != b.DaysUntilDue {
+			return a.DaysUntilDue - b.DaysUntilDue
+		}
+		if a.MedicationID != b.MedicationID {
+			return strings.Compare(a.MedicationID, b.MedicationID)
+		}
+		if a.Locker != b.Locker {
+			return strings.Compare(a.Locker, b.Locker)
+		}
+		if a.Floor != b.Floor {
+			return int(a.Floor - b.Floor)
+		}
+		return int(a.No - b.No)
+	})
+
+	return dues, nil
+}
+
+// resolveBlisterChangeIntervalDays picks the most specific interval: brand, then medicine, then warehouse, then the default.
+func (s *medicine) resolveBlisterChangeIntervalDays(intervals []model.BlisterChangeInterval, medicationID string, brandID *uuid.UUID) int32 {
+	var medicineInterval, warehouseInterval *int32
+	for _, interval := range intervals {
+		switch {
+		case interval.BrandID != nil:
+			if brandID != nil && *interval.BrandID == *brandID {
+				return interval.IntervalDays
+			}
+		case interval.MedicationID != nil:
+			if *interval.MedicationID == medicationID {
+				medicineInterval = &interval.IntervalDays
+			}
+		default:
+			warehouseInterval = &interval.IntervalDays
+		}
+	}
+	if medicineInterval != nil {
+		return *medicineInterval
+	}
+	if warehouseInterval != nil {
+		return *warehouseInterval
+	}
+	return s.blisterChangeIntervalDays
+}
+
 func (s *medicine) checkWarehouseManagementRole(ctx context.Context, id string, idType string, roles ...genmodel.PharmaSheetRole) (err error) {
 	userProfile, err := profile.UseProfile(ctx)
 	if err != nil {
@@ -682,6 +1685,9 @@ func (s *medicine) imageURL(ctx context.Context, fileID *string) *string {
 		return nil
 	}
 	url := s.storage.PublicURL(ctx, *fileID)
+	if url == "" {
+		return nil
+	}
 	if s.isSelfHostImage {
 		host := ctx.Value("host").(string)
 		url = host + "/file/" + *fileID
@@ -689,8 +1695,96 @@ func (s *medicine) imageURL(ctx context.Context, fileID *string) *string {
 	return &url
 }
 
+// findMedicineBrands resolves a product code by the barcode of the brand first, then by its trade id
+func findMedicineBrands(ctx context.Context, medicineRepository repository.Medicine, code string) ([]model.MedicineBrand, model.ScanType, error) {
+	if gtin, ok := model.NormalizeGTIN(code); ok {
+		brands, err := medicineRepository.GetMedicineBrands(ctx, model.FilterMedicineBrand{Barcode: gtin})
+		if err != nil {
+			logger.Context(ctx).Error(err)
+			return nil, "", echo.NewHTTPError(http.StatusInternalServerError, echo.Map{"error": err.Error()})
+		}
+		if len(brands) > 0 {
+			return brands, model.ScanTypeBarcode, nil
+		}
+	}
+
+	brands, err := medicineRepository.GetMedicineBrands(ctx, model.FilterMedicineBrand{TradeID: code})
+	if err != nil {
+		logger.Context(ctx).Error(err)
+		return nil, "", echo.NewHTTPError(http.StatusInternalServerError, echo.Map{"error": err.Error()})
+	}
+	return brands, model.ScanTypeTradeID, nil
+}
+
+func filterMedicineWarehouse(medicine model.Medicine, warehouseID string) model.Medicine {
+	if warehouseID == "" {
+		return medicine
+	}
+	medicine.Houses = slices.DeleteFunc(medicine.Houses, func(house model.MedicineHouseView) bool {
+		return house.WarehouseID != warehouseID
+	})
+	medicine.BlisterDateHistories = slices.DeleteFunc(medicine.BlisterDateHistories, func(history model.MedicineBlisterDateHistoryView) bool {
+		return history.WarehouseID != warehouseID
+	})
+	return medicine
+}
+
+func containsMedicineHouse(medicine model.Medicine, houseID *uuid.UUID) bool {
+	for _, house := range medicine.Houses {
+		if houseID == nil {
+			return true
+		}
+		for _, address := range house.Addresses {
+			if address.ID == *houseID {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func normalizeBarcode(barcode *string) (*string, error) {
+	if barcode == nil || strings.TrimSpace(*barcode) == "" {
+		return nil, nil
+	}
+	gtin, ok := model.NormalizeGTIN(*barcode)
+	if !ok {
+		return nil, echo.NewHTTPError(http.StatusBadRequest, echo.Map{"error": "barcode must be a valid GTIN"})
+	}
+	return &gtin, nil
+}
+
 func resolveFileName(prefix string, file *multipart.FileHeader) {
 	if !strings.HasPrefix(file.Filename, prefix+"_") {
 		file.Filename = prefix + "_" + file.Filename
 	}
 }
+
+// checkHouseAddress makes sure the address of a house fits the locker layout of its warehouse
+func (s *medicine) checkHouseAddress(ctx context.Context, warehouseID, locker string, floor, no int32) error {
+	lockers, err := s.warehouseRepository.ListWarehouseLockers(ctx, warehouseID)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, echo.Map{"error": err.Error()})
+	}
+	if err = model.CheckHouseAddress(lockers, locker, floor, no); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, echo.Map{"error": err.Error()})
+	}
+	return nil
+}
+
+// houseManagementRoles are the roles allowed to change the houses of the medicines, a flagged medicine needs an admin
+func houseManagementRoles(ctx context.Context, medicineRepository repository.Medicine, medicationIDs ...string) ([]genmodel.PharmaSheetRole, error) {
+	for _, medicationID := range medicationIDs {
+		flag, err := medicineRepository.GetMedicineFlag(ctx, medicationID)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return nil, echo.NewHTTPError(http.StatusNotFound, echo.Map{"error": "medicationID is not found"})
+			}
+			return nil, echo.NewHTTPError(http.StatusInternalServerError, echo.Map{"error": err.Error()})
+		}
+		if flag.IsFlagged() {
+			return []genmodel.PharmaSheetRole{genmodel.PharmaSheetRole_Admin}, nil
+		}
+	}
+	return []genmodel.PharmaSheetRole{genmodel.PharmaSheetRole_Admin, genmodel.PharmaSheetRole_Editor}, nil
+}
@@ -0,0 +1,96 @@
+package service
+
+import (
+	"cmp"
+	"context"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/kinkando/pharma-sheet-service/model"
+	"github.com/kinkando/pharma-sheet-service/pkg/logger"
+	"github.com/kinkando/pharma-sheet-service/pkg/profile"
+	"github.com/kinkando/pharma-sheet-service/repository"
+	"github.com/labstack/echo/v4"
+)
+
+const defaultSuggestionLimit = 10
+
+type Search interface {
+	Suggest(ctx context.Context, filter model.FilterSuggestion) ([]model.Suggestion, error)
+}
+
+type search struct {
+	searchRepository repository.Search
+	cacheRepository  repository.Cache
+}
+
+func NewSearchService(searchRepository repository.Search, cacheRepository repository.Cache) Search {
+	return &search{
+		searchRepository: searchRepository,
+		cacheRepository:  cacheRepository,
+	}
+}
+
+// Suggest serves the cached suggestions of a query when there are any, the cache is only an optimization
+// so a failure of it never fails the request.
+func (s *search) Suggest(ctx context.Context, filter model.FilterSuggestion) ([]model.Suggestion, error) {
+	userProfile, err := profile.UseProfile(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	filter.Query = strings.ToLower(strings.TrimSpace(filter.Query))
+	if filter.Query == "" {
+		return []model.Suggestion{}, nil
+	}
+	if filter.Limit == 0 {
+		filter.Limit = defaultSuggestionLimit
+	}
+
+	suggestions, ok, err := s.cacheRepository.GetSuggestions(ctx, userProfile.UserID, filter)
+	if err != nil {
+		logger.Context(ctx).Warn(err)
+	}
+	if ok {
+		return suggestions, nil
+	}
+
+	suggestions, err = s.searchRepository.ListSuggestions(ctx, filter)
+	if err != nil {
+		logger.Context(ctx).Error(err)
+		return nil, echo.NewHTTPError(http.StatusInternalServerError, echo.Map{"error": err.Error()})
+	}
+
+	suggestions = rankSuggestions(suggestions, filter.Query)
+	if int64(len(suggestions)) > filter.Limit {
+		suggestions = suggestions[:filter.Limit]
+	}
+
+	if err = s.cacheRepository.SetSuggestions(ctx, userProfile.UserID, filter, suggestions); err != nil {
+		logger.Context(ctx).Warn(err)
+	}
+
+	return suggestions, nil
+}
+
+// rankSuggestions puts an exact match first, then the shortest text since it is the closest to what was typed
+func rankSuggestions(suggestions []model.Suggestion, query string) []model.Suggestion {
+	if suggestions == nil {
+		return []model.Suggestion{}
+	}
+	slices.SortStableFunc(suggestions, func(a, b model.Suggestion) int {
+		aExact, bExact := strings.EqualFold(a.Text, query), strings.EqualFold(b.Text, query)
+		if aExact != bExact {
+			if aExact {
+				return -1
+			}
+			return 1
+		}
+		return cmp.Or(
+			cmp.Compare(len([]rune(a.Text)), len([]rune(b.Text))),
+			strings.Compare(strings.ToLower(a.Text), strings.ToLower(b.Text)),
+		)
+	})
+	return suggestions
+}
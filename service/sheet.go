@@ -2,18 +2,24 @@ package service
 
 import (
 	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"regexp"
 	"slices"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
 	genmodel "github.com/kinkando/pharma-sheet-service/.gen/pharma_sheet/public/model"
 	"github.com/kinkando/pharma-sheet-service/model"
+	"github.com/kinkando/pharma-sheet-service/pkg/circuitbreaker"
 	"github.com/kinkando/pharma-sheet-service/pkg/google"
 	"github.com/kinkando/pharma-sheet-service/pkg/logger"
+	options "github.com/kinkando/pharma-sheet-service/pkg/option"
 	"github.com/kinkando/pharma-sheet-service/pkg/profile"
 	"github.com/kinkando/pharma-sheet-service/repository"
 	"github.com/labstack/echo/v4"
@@ -26,36 +32,78 @@ const (
 	brandSheetName       = "Pictures"
 	blisterDateSheetName = "วันที่เปลี่ยนแผงยา"
 	houseSheetName       = "บ้านเลขที่ยา"
+	lotSheetName         = "Lot"
 )
 
+const (
+	warehouseColumnName    = "ศูนย์"
+	medicationIDColumnName = "Medication_ID"
+	tradeIDColumnName      = "TRADENAME_ID"
+	blisterDateColumnName  = "วันที่เปลี่ยนแผงยา"
+	systemIDColumnName     = "System_ID"
+	strengthUnitColumnName = "หน่วยความแรง"
+	dosageFormColumnName   = "รูปแบบยา"
+	routeColumnName        = "วิธีให้ยา"
+)
+
+// maxSheetListValues is the most values google accepts in a ONE_OF_LIST validation
+const maxSheetListValues = 500
+
 type Sheet interface {
 	SummarizeMedicineFromGoogleSheet(ctx context.Context, req model.GetSyncMedicineMetadataRequest) (model.SyncMedicineMetadata, error)
 	SyncMedicineFromGoogleSheet(ctx context.Context, req model.SyncMedicineRequest) error
+	StreamSyncProgress(ctx context.Context, req model.GetSyncProgressRequest) (<-chan model.SyncProgress, error)
 }
 
 type sheet struct {
 	warehouseRepository repository.Warehouse
 	medicineRepository  repository.Medicine
+	categoryRepository  repository.Category
+	lotRepository       repository.Lot
+	eventRepository     repository.Event
+	auditRepository     repository.Audit
+	cacheRepository     repository.Cache
 	drive               google.Drive
 	sheet               google.Sheet
+
+	blisterChangeIntervalDays int32
 }
 
 func NewSheetService(
 	warehouseRepository repository.Warehouse,
 	medicineRepository repository.Medicine,
+	categoryRepository repository.Category,
+	lotRepository repository.Lot,
+	eventRepository repository.Event,
+	auditRepository repository.Audit,
+	cacheRepository repository.Cache,
 	drive google.Drive,
 	googleSheet google.Sheet,
+	blisterChangeIntervalDays int32,
 ) Sheet {
 	return &sheet{
 		warehouseRepository: warehouseRepository,
 		medicineRepository:  medicineRepository,
+		categoryRepository:  categoryRepository,
+		lotRepository:       lotRepository,
+		eventRepository:     eventRepository,
+		auditRepository:     auditRepository,
+		cacheRepository:     cacheRepository,
 		drive:               drive,
 		sheet:               googleSheet,
+
+		blisterChangeIntervalDays: blisterChangeIntervalDays,
 	}
 }
 
 func (s *sheet) SummarizeMedicineFromGoogleSheet(ctx context.Context, req model.GetSyncMedicineMetadataRequest) (metadata model.SyncMedicineMetadata, err error) {
-	data, err := s.getGoogleSheetData(ctx, model.SyncMedicineRequest(req), false)
+	err = s.checkWarehouseManagementRole(ctx, req.WarehouseID, genmodel.PharmaSheetRole_Admin, genmodel.PharmaSheetRole_Editor)
+	if err != nil {
+		logger.Context(ctx).Error(err)
+		return
+	}
+
+	data, err := s.getGoogleSheetData(ctx, model.SyncMedicineRequest{WarehouseID: req.WarehouseID, URL: req.URL}, false)
 	if err != nil {
 		return
 	}
@@ -95,7 +143,7 @@ func (s *sheet) SummarizeMedicineFromGoogleSheet(ctx context.Context, req model.
 	for _, medicineSheet := range data.Brand.MedicineSheets {
 		metadata.Brand.TotalMedicine++
 
-		medicine, ok := data.Brand.MedicineData[medicineSheet.ExternalID()]
+		medicine, ok := data.Brand.Match(medicineSheet)
 		if !ok {
 			metadata.Brand.TotalNewMedicine++
 			continue
@@ -111,7 +159,7 @@ func (s *sheet) SummarizeMedicineFromGoogleSheet(ctx context.Context, req model.
 	for _, medicineSheet := range data.House.MedicineSheets {
 		metadata.House.TotalMedicine++
 
-		medicine, ok := data.House.MedicineData[medicineSheet.ExternalID()]
+		medicine, ok := data.House.Match(medicineSheet)
 		if !ok {
 			metadata.House.TotalNewMedicine++
 			continue
@@ -127,26 +175,119 @@ func (s *sheet) SummarizeMedicineFromGoogleSheet(ctx context.Context, req model.
 	for _, medicineSheet := range data.BlisterDate.MedicineSheets {
 		metadata.BlisterDate.TotalMedicine++
 
-		if _, ok := data.BlisterDate.MedicineData[medicineSheet.ExternalID()]; !ok {
+		medicine, ok := data.BlisterDate.Match(medicineSheet)
+		if !ok {
 			metadata.BlisterDate.TotalNewMedicine++
+			continue
+		}
+
+		if medicineSheet.IsDifferent(medicine) {
+			metadata.BlisterDate.TotalUpdatedMedicine++
 		} else {
 			metadata.BlisterDate.TotalSkippedMedicine++
 		}
 	}
 
+	if data.Lot.Sheet != nil {
+		metadata.Lot = &model.MedicineMetadata{SheetName: data.Lot.Sheet.Properties.Title}
+		for _, medicineSheet := range data.Lot.MedicineSheets {
+			metadata.Lot.TotalMedicine++
+
+			house, ok := data.House.MedicineData[medicineSheet.HouseExternalID()]
+			if !ok {
+				metadata.Lot.TotalNewMedicine++
+				continue
+			}
+			var medicineBrandID *uuid.UUID
+			if brand, ok := data.Brand.MedicineData[medicineSheet.MedicationID+"-"+medicineSheet.TradeID]; ok {
+				medicineBrandID = &brand.ID
+			}
+
+			medicine, ok := data.Lot.Match(medicineSheet, house.ID, medicineBrandID)
+			if !ok {
+				metadata.Lot.TotalNewMedicine++
+				continue
+			}
+
+			if medicineSheet.IsDifferent(medicine, medicineBrandID) {
+				metadata.Lot.TotalUpdatedMedicine++
+			} else {
+				metadata.Lot.TotalSkippedMedicine++
+			}
+		}
+	}
+
 	return metadata, nil
 }
 
-func (s *sheet) SyncMedicineFromGoogleSheet(ctx context.Context, req model.SyncMedicineRequest) error {
+func (s *sheet) SyncMedicineFromGoogleSheet(ctx context.Context, req model.SyncMedicineRequest) (err error) {
+	err = s.checkWarehouseManagementRole(ctx, req.WarehouseID, genmodel.PharmaSheetRole_Admin, genmodel.PharmaSheetRole_Editor)
+	if err != nil {
+		logger.Context(ctx).Error(err)
+		return err
+	}
+
+	progress := s.newSyncProgress(req.WarehouseID)
+	defer func() {
+		progress.finish(context.WithoutCancel(ctx), err)
+	}()
+
+	// a failed sync keeps the rows written before the failure, so they are logged as well
+	var auditLogs []model.CreateAuditLog
+	defer func() {
+		recordAudit(ctx, s.auditRepository, auditLogs...)
+	}()
+
+	progress.start(ctx, model.SyncProgressPhaseReadingTabs, 0)
 	data, err := s.getGoogleSheetData(ctx, req, true)
 	if err != nil {
 		return err
 	}
+	invalidRows := []struct {
+		sheet *sheets.Sheet
+		rows  []int
+	}{
+		{data.Medication.Sheet, data.Medication.InvalidRows},
+		{data.Brand.Sheet, data.Brand.InvalidRows},
+		{data.House.Sheet, data.House.InvalidRows},
+		{data.BlisterDate.Sheet, data.BlisterDate.InvalidRows},
+	}
+	if data.Lot.Sheet != nil {
+		invalidRows = append(invalidRows, struct {
+			sheet *sheets.Sheet
+			rows  []int
+		}{data.Lot.Sheet, data.Lot.InvalidRows})
+	}
+	for _, invalidRow := range invalidRows {
+		if len(invalidRow.rows) > 0 {
+			progress.warn(ctx, "sheet %s: %d invalid rows are skipped (rows %s)", invalidRow.sheet.Properties.Title, len(invalidRow.rows), formatRowNumbers(invalidRow.rows))
+		}
+	}
 
+	if err = s.skipDeletedMedicines(ctx, &data, progress); err != nil {
+		return err
+	}
+
+	// like changing them one by one, a sync changing the houses, blister changes or lots of a flagged medicine
+	// needs an admin and the code of a confirming user
+	confirmedBy, err := s.confirmFlaggedMedicines(ctx, req, data)
+	if err != nil {
+		logger.Context(ctx).Error(err)
+		return err
+	}
+
+	progress.start(ctx, model.SyncProgressPhaseMedicines, len(data.Medication.MedicineSheets))
 	for _, medicineSheet := range data.Medication.MedicineSheets {
 		medicine, ok := data.Medication.MedicineData[medicineSheet.MedicationID]
+		var attribute model.MedicineAttribute
+		attribute, err = medicineSheet.Attribute(medicine.MedicineAttribute)
+		if err != nil {
+			logger.Context(ctx).Error(err)
+			return echo.NewHTTPError(http.StatusBadRequest, echo.Map{"error": err.Error()})
+		}
 		if !ok {
-			_, err = s.medicineRepository.CreateMedicine(ctx, model.CreateMedicineRequest{MedicationID: medicineSheet.MedicationID, MedicalName: &medicineSheet.MedicalName})
+			createMedicine := model.CreateMedicineRequest{MedicationID: medicineSheet.MedicationID, MedicalName: &medicineSheet.MedicalName, MedicineAttribute: attribute}
+			_, err = s.medicineRepository.CreateMedicine(ctx, createMedicine)
 			if err != nil {
 				logger.Context(ctx).Error(err)
 				if model.IsConflictError(err) {
@@ -154,30 +295,51 @@ func (s *sheet) SyncMedicineFromGoogleSheet(ctx context.Context, req model.SyncM
 				}
 				return echo.NewHTTPError(http.StatusInternalServerError, echo.Map{"error": err.Error()})
 			}
+			auditLogs = append(auditLogs, model.CreateAuditLog{
+				Action:     genmodel.PharmaSheetAuditAction_Create,
+				EntityType: model.AuditEntityMedicine,
+				EntityID:   medicineSheet.MedicationID,
+				After:      medicineSnapshot(ctx, s.medicineRepository, s.categoryRepository, medicineSheet.MedicationID),
+			})
+			progress.created(ctx)
 			continue
 		}
 
-		if medicineSheet.IsDifferent(medicine) {
-			err = s.medicineRepository.UpdateMedicine(ctx, model.UpdateMedicineRequest{MedicationID: medicineSheet.MedicationID, MedicalName: &medicineSheet.MedicalName})
-			if err != nil {
-				logger.Context(ctx).Error(err)
-				return echo.NewHTTPError(http.StatusInternalServerError, echo.Map{"error": err.Error()})
-			}
+		if !medicineSheet.IsDifferent(medicine) {
+			progress.skipped(ctx)
+			continue
 		}
+		before := medicineSnapshot(ctx, s.medicineRepository, s.categoryRepository, medicineSheet.MedicationID)
+		updateMedicine := model.UpdateMedicineRequest{MedicationID: medicineSheet.MedicationID, MedicalName: &medicineSheet.MedicalName, MedicineAttribute: attribute}
+		err = s.medicineRepository.UpdateMedicine(ctx, updateMedicine)
+		if err != nil {
+			logger.Context(ctx).Error(err)
+			return echo.NewHTTPError(http.StatusInternalServerError, echo.Map{"error": err.Error()})
+		}
+		auditLogs = append(auditLogs, model.CreateAuditLog{
+			Action:     genmodel.PharmaSheetAuditAction_Update,
+			EntityType: model.AuditEntityMedicine,
+			EntityID:   medicineSheet.MedicationID,
+			Before:     before,
+			After:      medicineSnapshot(ctx, s.medicineRepository, s.categoryRepository, medicineSheet.MedicationID),
+		})
+		progress.updated(ctx)
 	}
 
+	progress.start(ctx, model.SyncProgressPhaseBrands, len(data.Brand.MedicineSheets))
 	for _, medicineSheet := range data.Brand.MedicineSheets {
 		blisterFileID, tabletFileID, boxFileID := medicineSheet.FileIDs()
-		medicine, ok := data.Brand.MedicineData[medicineSheet.ExternalID()]
+		medicine, ok := data.Brand.Match(medicineSheet)
 		if !ok {
-			_, err := s.medicineRepository.CreateMedicineBrand(ctx, model.CreateMedicineBrandRequest{
+			createBrand := model.CreateMedicineBrandRequest{
 				MedicationID:    medicineSheet.MedicationID,
 				TradeID:         medicineSheet.TradeID,
 				TradeName:       &medicineSheet.TradeName,
 				BlisterImageURL: blisterFileID,
 				TabletImageURL:  tabletFileID,
 				BoxImageURL:     boxFileID,
-			})
+			}
+			id, err := s.medicineRepository.CreateMedicineBrand(ctx, createBrand)
 			if err != nil {
 				logger.Context(ctx).Error(err)
 				if model.IsConflictError(err) {
@@ -185,38 +347,60 @@ func (s *sheet) SyncMedicineFromGoogleSheet(ctx context.Context, req model.SyncM
 				}
 				return echo.NewHTTPError(http.StatusInternalServerError, echo.Map{"error": err.Error()})
 			}
+			data.Brand.SystemIDs[medicineSheet.RowNumber] = id
+			auditLogs = append(auditLogs, model.CreateAuditLog{
+				Action:     genmodel.PharmaSheetAuditAction_Create,
+				EntityType: model.AuditEntityMedicineBrand,
+				EntityID:   id,
+				After:      medicineBrandSnapshot(ctx, s.medicineRepository, uuid.MustParse(id)),
+			})
+			progress.created(ctx)
 			continue
 		}
+		data.Brand.SystemIDs[medicineSheet.RowNumber] = medicine.ID.String()
 
-		if medicineSheet.IsDifferent(medicine) {
-			deleteFileID := "null"
-			if blisterFileID == nil {
-				blisterFileID = &deleteFileID
-			}
-			if tabletFileID == nil {
-				tabletFileID = &deleteFileID
-			}
-			if boxFileID == nil {
-				boxFileID = &deleteFileID
-			}
-			err = s.medicineRepository.UpdateMedicineBrand(ctx, model.UpdateMedicineBrandRequest{
-				BrandID:         medicine.ID,
-				TradeName:       &medicineSheet.TradeName,
-				BlisterImageURL: blisterFileID,
-				TabletImageURL:  tabletFileID,
-				BoxImageURL:     boxFileID,
-			})
-			if err != nil {
-				logger.Context(ctx).Error(err)
-				return echo.NewHTTPError(http.StatusInternalServerError, echo.Map{"error": err.Error()})
-			}
+		if !medicineSheet.IsDifferent(medicine) {
+			progress.skipped(ctx)
+			continue
+		}
+		deleteFileID := "null"
+		if blisterFileID == nil {
+			blisterFileID = &deleteFileID
+		}
+		if tabletFileID == nil {
+			tabletFileID = &deleteFileID
 		}
+		if boxFileID == nil {
+			boxFileID = &deleteFileID
+		}
+		updateBrand := model.UpdateMedicineBrandRequest{
+			BrandID:         medicine.ID,
+			TradeID:         &medicineSheet.TradeID,
+			TradeName:       &medicineSheet.TradeName,
+			BlisterImageURL: blisterFileID,
+			TabletImageURL:  tabletFileID,
+			BoxImageURL:     boxFileID,
+		}
+		err = s.medicineRepository.UpdateMedicineBrand(ctx, updateBrand)
+		if err != nil {
+			logger.Context(ctx).Error(err)
+			return echo.NewHTTPError(http.StatusInternalServerError, echo.Map{"error": err.Error()})
+		}
+		auditLogs = append(auditLogs, model.CreateAuditLog{
+			Action:     genmodel.PharmaSheetAuditAction_Update,
+			EntityType: model.AuditEntityMedicineBrand,
+			EntityID:   medicine.ID.String(),
+			Before:     medicine,
+			After:      medicineBrandSnapshot(ctx, s.medicineRepository, medicine.ID),
+		})
+		progress.updated(ctx)
 	}
 
+	progress.start(ctx, model.SyncProgressPhaseHouses, len(data.House.MedicineSheets))
 	for _, medicineSheet := range data.House.MedicineSheets {
-		medicine, ok := data.House.MedicineData[medicineSheet.ExternalID()]
+		medicine, ok := data.House.Match(medicineSheet)
 		if !ok {
-			data := model.CreateMedicineHouseRequest{
+			house := model.CreateMedicineHouseRequest{
 				MedicationID: medicineSheet.MedicationID,
 				WarehouseID:  medicineSheet.WarehouseID,
 				Locker:       medicineSheet.Locker,
@@ -224,32 +408,56 @@ func (s *sheet) SyncMedicineFromGoogleSheet(ctx context.Context, req model.SyncM
 				No:           medicineSheet.No(),
 				Label:        &medicineSheet.Label,
 			}
-			_, err := s.medicineRepository.CreateMedicineHouse(ctx, data)
+			id, err := s.medicineRepository.CreateMedicineHouse(ctx, house)
 			if err != nil {
-				logger.Context(ctx).With("data", data).Error(err)
+				logger.Context(ctx).With("data", house).Error(err)
 				if model.IsConflictError(err) {
 					return echo.NewHTTPError(http.StatusConflict, echo.Map{"error": "medicine house already exists"})
 				}
 				return echo.NewHTTPError(http.StatusInternalServerError, echo.Map{"error": err.Error()})
 			}
+			data.House.SystemIDs[medicineSheet.RowNumber] = id
+			auditLogs = append(auditLogs, model.CreateAuditLog{
+				Action:      genmodel.PharmaSheetAuditAction_Create,
+				EntityType:  model.AuditEntityMedicineHouse,
+				EntityID:    id,
+				WarehouseID: &req.WarehouseID,
+				After:       medicineHouseSnapshot(ctx, s.medicineRepository, uuid.MustParse(id)),
+			})
+			progress.created(ctx)
 			continue
 		}
+		data.House.SystemIDs[medicineSheet.RowNumber] = medicine.ID.String()
 
-		if medicineSheet.IsDifferent(medicine) {
-			data := model.UpdateMedicineHouseRequest{
-				ID:           medicine.ID,
-				MedicationID: medicineSheet.MedicationID,
-				Locker:       medicineSheet.Locker,
-				Floor:        medicineSheet.Floor(),
-				No:           medicineSheet.No(),
-				Label:        &medicineSheet.Label,
-			}
-			err = s.medicineRepository.UpdateMedicineHouse(ctx, data)
-			if err != nil {
-				logger.Context(ctx).With("data", data).Error(err)
-				return echo.NewHTTPError(http.StatusInternalServerError, echo.Map{"error": err.Error()})
+		if !medicineSheet.IsDifferent(medicine) {
+			progress.skipped(ctx)
+			continue
+		}
+		house := model.UpdateMedicineHouseRequest{
+			ID:           medicine.ID,
+			MedicationID: medicineSheet.MedicationID,
+			Locker:       medicineSheet.Locker,
+			Floor:        medicineSheet.Floor(),
+			No:           medicineSheet.No(),
+			Label:        &medicineSheet.Label,
+		}
+		err = s.medicineRepository.UpdateMedicineHouse(ctx, house)
+		if err != nil {
+			logger.Context(ctx).With("data", house).Error(err)
+			if model.IsConflictError(err) {
+				return echo.NewHTTPError(http.StatusConflict, echo.Map{"error": "medicine house already exists"})
 			}
+			return echo.NewHTTPError(http.StatusInternalServerError, echo.Map{"error": err.Error()})
 		}
+		auditLogs = append(auditLogs, model.CreateAuditLog{
+			Action:      genmodel.PharmaSheetAuditAction_Update,
+			EntityType:  model.AuditEntityMedicineHouse,
+			EntityID:    medicine.ID.String(),
+			WarehouseID: &req.WarehouseID,
+			Before:      medicine,
+			After:       medicineHouseSnapshot(ctx, s.medicineRepository, medicine.ID),
+		})
+		progress.updated(ctx)
 	}
 
 	brands, err := s.medicineRepository.ListMedicineBrands(ctx)
@@ -261,6 +469,8 @@ func (s *sheet) SyncMedicineFromGoogleSheet(ctx context.Context, req model.SyncM
 	for _, brand := range brands {
 		brandID[brand.MedicationID+"-"+brand.TradeID] = brand.ID
 	}
+
+	progress.start(ctx, model.SyncProgressPhaseBlisterDates, len(data.BlisterDate.MedicineSheets))
 	for _, medicineSheet := range data.BlisterDate.MedicineSheets {
 		date, _ := time.Parse(model.DateLayout, medicineSheet.BlisterDate)
 		var medicineBrandID *uuid.UUID
@@ -268,14 +478,16 @@ func (s *sheet) SyncMedicineFromGoogleSheet(ctx context.Context, req model.SyncM
 			medicineBrandID = &id
 		}
 
-		_, ok := data.BlisterDate.MedicineData[medicineSheet.ExternalID()]
+		history, ok := data.BlisterDate.Match(medicineSheet)
 		if !ok {
-			_, err := s.medicineRepository.CreateMedicineBlisterChangeDateHistory(ctx, model.CreateMedicineBlisterChangeDateHistoryRequest{
+			createHistory := model.CreateMedicineBlisterChangeDateHistoryRequest{
 				MedicationID:      medicineSheet.MedicationID,
 				WarehouseID:       medicineSheet.WarehouseID,
 				BrandID:           medicineBrandID,
 				BlisterChangeDate: date,
-			})
+				ConfirmedBy:       confirmedBy[medicineSheet.MedicationID],
+			}
+			id, err := s.medicineRepository.CreateMedicineBlisterChangeDateHistory(ctx, createHistory)
 			if err != nil {
 				logger.Context(ctx).Error(err)
 				if model.IsConflictError(err) {
@@ -283,13 +495,579 @@ func (s *sheet) SyncMedicineFromGoogleSheet(ctx context.Context, req model.SyncM
 				}
 				return echo.NewHTTPError(http.StatusInternalServerError, echo.Map{"error": err.Error()})
 			}
+			data.BlisterDate.SystemIDs[medicineSheet.RowNumber] = id
+			auditLogs = append(auditLogs, model.CreateAuditLog{
+				Action:      genmodel.PharmaSheetAuditAction_Create,
+				EntityType:  model.AuditEntityBlisterDateHistory,
+				EntityID:    id,
+				WarehouseID: &req.WarehouseID,
+				After:       blisterDateHistorySnapshot(ctx, s.medicineRepository, uuid.MustParse(id)),
+			})
+			progress.created(ctx)
+			continue
+		}
+		data.BlisterDate.SystemIDs[medicineSheet.RowNumber] = history.ID.String()
+
+		if !medicineSheet.IsDifferent(history) {
+			progress.skipped(ctx)
 			continue
 		}
+		updateHistory := model.UpdateMedicineBlisterChangeDateHistoryRequest{
+			ID:                history.ID,
+			MedicationID:      medicineSheet.MedicationID,
+			BrandID:           medicineBrandID,
+			BlisterChangeDate: date,
+		}
+		err = s.medicineRepository.UpdateMedicineBlisterChangeDateHistory(ctx, updateHistory)
+		if err != nil {
+			logger.Context(ctx).Error(err)
+			if model.IsConflictError(err) {
+				return echo.NewHTTPError(http.StatusConflict, echo.Map{"error": "medicine blister date history already exists"})
+			}
+			return echo.NewHTTPError(http.StatusInternalServerError, echo.Map{"error": err.Error()})
+		}
+		auditLogs = append(auditLogs, model.CreateAuditLog{
+			Action:      genmodel.PharmaSheetAuditAction_Update,
+			EntityType:  model.AuditEntityBlisterDateHistory,
+			EntityID:    history.ID.String(),
+			WarehouseID: &req.WarehouseID,
+			Before:      history,
+			After:       blisterDateHistorySnapshot(ctx, s.medicineRepository, history.ID),
+		})
+		progress.updated(ctx)
+	}
+
+	if data.Lot.Sheet != nil {
+		if err = s.syncMedicineLots(ctx, req.WarehouseID, &data.Lot, brandID, confirmedBy, progress, &auditLogs); err != nil {
+			return err
+		}
 	}
 
+	// ids written back to the sheet let the next sync match rows even when their natural keys change
+	writeBacks := []struct {
+		sheet     *sheets.Sheet
+		systemIDs map[int]string
+		rowCount  int
+	}{
+		{data.Brand.Sheet, data.Brand.SystemIDs, data.Brand.RowCount},
+		{data.House.Sheet, data.House.SystemIDs, data.House.RowCount},
+		{data.BlisterDate.Sheet, data.BlisterDate.SystemIDs, data.BlisterDate.RowCount},
+	}
+	if data.Lot.Sheet != nil {
+		writeBacks = append(writeBacks, struct {
+			sheet     *sheets.Sheet
+			systemIDs map[int]string
+			rowCount  int
+		}{data.Lot.Sheet, data.Lot.SystemIDs, data.Lot.RowCount})
+	}
+	progress.start(ctx, model.SyncProgressPhaseWritingBack, len(writeBacks)+1)
+	for _, writeBack := range writeBacks {
+		if err := s.writeBackSystemIDs(ctx, data.SpreadsheetID, writeBack.sheet, writeBack.systemIDs, writeBack.rowCount); err != nil {
+			logger.Context(ctx).Warnf("unable to write system id back to sheet %s: %v", writeBack.sheet.Properties.Title, err)
+			progress.warn(ctx, "sheet %s: unable to write system id back", writeBack.sheet.Properties.Title)
+		}
+		progress.updated(ctx)
+	}
+
+	// validation only guides the next edits of the sheet, a failure must not fail the completed sync
+	if err := s.applySheetValidation(ctx, req.WarehouseID, data); err != nil {
+		logger.Context(ctx).Warnf("unable to apply sheet validation: %v", err)
+		progress.warn(ctx, "unable to apply sheet validation")
+	}
+	progress.updated(ctx)
+
 	return nil
 }
 
+func (s *sheet) syncMedicineLots(ctx context.Context, warehouseID string, data *model.MedicineLotSheetMetadata, brandID map[string]uuid.UUID, confirmedBy map[string]*uuid.UUID, progress *syncProgress, auditLogs *[]model.CreateAuditLog) error {
+	// houses are listed again, so lots may refer to houses created earlier in this sync
+	houses, err := s.medicineRepository.GetMedicineHouses(ctx, model.FilterMedicineHouse{WarehouseID: warehouseID})
+	if err != nil {
+		logger.Context(ctx).Error(err)
+		return echo.NewHTTPError(http.StatusInternalServerError, echo.Map{"error": err.Error()})
+	}
+	houseID := make(map[string]uuid.UUID)
+	for _, house := range houses {
+		houseID[house.ExternalID()] = house.ID
+	}
+
+	progress.start(ctx, model.SyncProgressPhaseLots, len(data.MedicineSheets))
+	var unknownHouseRows []int
+	for _, medicineSheet := range data.MedicineSheets {
+		medicineHouseID, ok := houseID[medicineSheet.HouseExternalID()]
+		if !ok {
+			unknownHouseRows = append(unknownHouseRows, medicineSheet.RowNumber)
+			progress.skipped(ctx)
+			continue
+		}
+		var medicineBrandID *uuid.UUID
+		if id, ok := brandID[medicineSheet.MedicationID+"-"+medicineSheet.TradeID]; ok && id != uuid.Nil {
+			medicineBrandID = &id
+		}
+
+		medicineLot, ok := data.Match(medicineSheet, medicineHouseID, medicineBrandID)
+		if !ok {
+			createLot := model.CreateMedicineLotRequest{
+				HouseID:      medicineHouseID,
+				BrandID:      medicineBrandID,
+				LotNumber:    medicineSheet.LotNumber,
+				Quantity:     medicineSheet.Quantity(),
+				ExpiryDate:   medicineSheet.Expiry(),
+				ReceivedDate: medicineSheet.Received(),
+			}
+			id, err := s.lotRepository.CreateMedicineLot(ctx, createLot, confirmedBy[medicineSheet.MedicationID])
+			if err != nil {
+				return medicineLotError(ctx, err, "medicine lot already exists")
+			}
+			data.SystemIDs[medicineSheet.RowNumber] = id
+			*auditLogs = append(*auditLogs, model.CreateAuditLog{
+				Action:      genmodel.PharmaSheetAuditAction_Create,
+				EntityType:  model.AuditEntityMedicineLot,
+				EntityID:    id,
+				WarehouseID: &warehouseID,
+				After:       medicineLotSnapshot(ctx, s.lotRepository, uuid.MustParse(id)),
+			})
+			progress.created(ctx)
+			continue
+		}
+		data.SystemIDs[medicineSheet.RowNumber] = medicineLot.ID.String()
+
+		if !medicineSheet.IsDifferent(medicineLot, medicineBrandID) {
+			progress.skipped(ctx)
+			continue
+		}
+		updateLot := model.UpdateMedicineLotRequest{
+			ID:           medicineLot.ID,
+			BrandID:      medicineBrandID,
+			LotNumber:    medicineSheet.LotNumber,
+			Quantity:     medicineSheet.Quantity(),
+			ExpiryDate:   medicineSheet.Expiry(),
+			ReceivedDate: medicineSheet.Received(),
+		}
+		err = s.lotRepository.UpdateMedicineLot(ctx, updateLot, confirmedBy[medicineSheet.MedicationID])
+		if err != nil {
+			return medicineLotError(ctx, err, "medicine lot already exists")
+		}
+		*auditLogs = append(*auditLogs, model.CreateAuditLog{
+			Action:      genmodel.PharmaSheetAuditAction_Update,
+			EntityType:  model.AuditEntityMedicineLot,
+			EntityID:    medicineLot.ID.String(),
+			WarehouseID: &warehouseID,
+			Before:      medicineLot,
+			After:       medicineLotSnapshot(ctx, s.lotRepository, medicineLot.ID),
+		})
+		progress.updated(ctx)
+	}
+
+	if len(unknownHouseRows) > 0 {
+		progress.warn(ctx, "sheet %s: %d rows refer to unknown houses and are skipped (rows %s)", data.Sheet.Properties.Title, len(unknownHouseRows), formatRowNumbers(unknownHouseRows))
+	}
+	return nil
+}
+
+// skipDeletedMedicines leaves out the rows of medicines in the trash, their ids stay taken until they are purged
+// so the rows are kept aside with their system ids until the medicines are restored from the trash
+func (s *sheet) skipDeletedMedicines(ctx context.Context, data *model.GoogleSheetData, progress *syncProgress) error {
+	var medicationIDs []string
+	addMedicationID := func(medicationID string) {
+		if _, ok := data.Medication.MedicineData[medicationID]; !ok && !slices.Contains(medicationIDs, medicationID) {
+			medicationIDs = append(medicationIDs, medicationID)
+		}
+	}
+	for _, medicineSheet := range data.Medication.MedicineSheets {
+		addMedicationID(medicineSheet.MedicationID)
+	}
+	for _, medicineSheet := range data.Brand.MedicineSheets {
+		addMedicationID(medicineSheet.MedicationID)
+	}
+	for _, medicineSheet := range data.House.MedicineSheets {
+		addMedicationID(medicineSheet.MedicationID)
+	}
+	for _, medicineSheet := range data.BlisterDate.MedicineSheets {
+		addMedicationID(medicineSheet.MedicationID)
+	}
+	for _, medicineSheet := range data.Lot.MedicineSheets {
+		addMedicationID(medicineSheet.MedicationID)
+	}
+
+	deletedIDs, err := s.medicineRepository.ListDeletedMedicationIDs(ctx, medicationIDs...)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, echo.Map{"error": err.Error()})
+	}
+	if len(deletedIDs) == 0 {
+		return nil
+	}
+
+	data.Medication.MedicineSheets = slices.DeleteFunc(data.Medication.MedicineSheets, func(medicineSheet model.MedicineSheet) bool {
+		return slices.Contains(deletedIDs, medicineSheet.MedicationID)
+	})
+	data.Brand.MedicineSheets = slices.DeleteFunc(data.Brand.MedicineSheets, func(medicineSheet model.MedicineBrandSheet) bool {
+		if !slices.Contains(deletedIDs, medicineSheet.MedicationID) {
+			return false
+		}
+		data.Brand.SystemIDs[medicineSheet.RowNumber] = medicineSheet.SystemID
+		return true
+	})
+	data.House.MedicineSheets = slices.DeleteFunc(data.House.MedicineSheets, func(medicineSheet model.MedicineHouseSheet) bool {
+		if !slices.Contains(deletedIDs, medicineSheet.MedicationID) {
+			return false
+		}
+		data.House.SystemIDs[medicineSheet.RowNumber] = medicineSheet.SystemID
+		return true
+	})
+	data.BlisterDate.MedicineSheets = slices.DeleteFunc(data.BlisterDate.MedicineSheets, func(medicineSheet model.MedicineBlisterDateSheet) bool {
+		if !slices.Contains(deletedIDs, medicineSheet.MedicationID) {
+			return false
+		}
+		data.BlisterDate.SystemIDs[medicineSheet.RowNumber] = medicineSheet.SystemID
+		return true
+	})
+	data.Lot.MedicineSheets = slices.DeleteFunc(data.Lot.MedicineSheets, func(medicineSheet model.MedicineLotSheet) bool {
+		if !slices.Contains(deletedIDs, medicineSheet.MedicationID) {
+			return false
+		}
+		data.Lot.SystemIDs[medicineSheet.RowNumber] = medicineSheet.SystemID
+		return true
+	})
+
+	progress.warn(ctx, "medicines %s are in the trash, their rows are skipped until they are restored from the trash", strings.Join(deletedIDs, ", "))
+	return nil
+}
+
+// confirmFlaggedMedicines checks the sync against the flagged medicines whose houses, blister changes or lots it would change,
+// it returns the confirming user of each of them. Lots of a house the sync has yet to create are counted as changes.
+func (s *sheet) confirmFlaggedMedicines(ctx context.Context, req model.SyncMedicineRequest, data model.GoogleSheetData) (map[string]*uuid.UUID, error) {
+	var medicationIDs []string
+	for _, medicineSheet := range data.House.MedicineSheets {
+		house, ok := data.House.Match(medicineSheet)
+		if !ok {
+			medicationIDs = append(medicationIDs, medicineSheet.MedicationID)
+		} else if medicineSheet.IsDifferent(house) {
+			medicationIDs = append(medicationIDs, medicineSheet.MedicationID, house.MedicationID)
+		}
+	}
+	for _, medicineSheet := range data.BlisterDate.MedicineSheets {
+		history, ok := data.BlisterDate.Match(medicineSheet)
+		if !ok {
+			medicationIDs = append(medicationIDs, medicineSheet.MedicationID)
+		} else if medicineSheet.IsDifferent(history) {
+			medicationIDs = append(medicationIDs, medicineSheet.MedicationID, history.MedicationID)
+		}
+	}
+	if data.Lot.Sheet != nil {
+		houses, err := s.medicineRepository.GetMedicineHouses(ctx, model.FilterMedicineHouse{WarehouseID: req.WarehouseID})
+		if err != nil {
+			return nil, echo.NewHTTPError(http.StatusInternalServerError, echo.Map{"error": err.Error()})
+		}
+		brands, err := s.medicineRepository.ListMedicineBrands(ctx)
+		if err != nil {
+			return nil, echo.NewHTTPError(http.StatusInternalServerError, echo.Map{"error": err.Error()})
+		}
+		houseID := make(map[string]uuid.UUID)
+		for _, house := range houses {
+			houseID[house.ExternalID()] = house.ID
+		}
+		brandID := make(map[string]uuid.UUID)
+		for _, brand := range brands {
+			brandID[brand.MedicationID+"-"+brand.TradeID] = brand.ID
+		}
+		for _, medicineSheet := range data.Lot.MedicineSheets {
+			medicineHouseID, ok := houseID[medicineSheet.HouseExternalID()]
+			if !ok {
+				medicationIDs = append(medicationIDs, medicineSheet.MedicationID)
+				continue
+			}
+			var medicineBrandID *uuid.UUID
+			if id, ok := brandID[medicineSheet.MedicationID+"-"+medicineSheet.TradeID]; ok && id != uuid.Nil {
+				medicineBrandID = &id
+			}
+			if medicineLot, ok := data.Lot.Match(medicineSheet, medicineHouseID, medicineBrandID); !ok || medicineSheet.IsDifferent(medicineLot, medicineBrandID) {
+				medicationIDs = append(medicationIDs, medicineSheet.MedicationID)
+			}
+		}
+	}
+
+	var flaggedIDs []string
+	for _, medicationID := range medicationIDs {
+		if slices.Contains(flaggedIDs, medicationID) {
+			continue
+		}
+		// a medicine created by this sync is not flagged yet
+		flag, err := s.medicineRepository.GetMedicineFlag(ctx, medicationID)
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			return nil, echo.NewHTTPError(http.StatusInternalServerError, echo.Map{"error": err.Error()})
+		}
+		if flag.IsFlagged() {
+			flaggedIDs = append(flaggedIDs, medicationID)
+		}
+	}
+	if len(flaggedIDs) == 0 {
+		return nil, nil
+	}
+
+	err := s.checkWarehouseManagementRole(ctx, req.WarehouseID, genmodel.PharmaSheetRole_Admin)
+	if err != nil {
+		return nil, err
+	}
+	scope := model.ConfirmationScope{WarehouseID: req.WarehouseID, Action: model.ConfirmationActionSheetSync}
+	userID, err := useConfirmationCode(ctx, s.cacheRepository, s.warehouseRepository, scope, req.ConfirmationCode)
+	if err != nil {
+		return nil, err
+	}
+
+	confirmedBy := make(map[string]*uuid.UUID, len(flaggedIDs))
+	for _, medicationID := range flaggedIDs {
+		confirmedBy[medicationID] = userID
+	}
+	return confirmedBy, nil
+}
+
+func (s *sheet) StreamSyncProgress(ctx context.Context, req model.GetSyncProgressRequest) (<-chan model.SyncProgress, error) {
+	err := s.checkWarehouseManagementRole(ctx, req.WarehouseID, genmodel.PharmaSheetRole_Admin, genmodel.PharmaSheetRole_Editor, genmodel.PharmaSheetRole_Viewer)
+	if err != nil {
+		logger.Context(ctx).Error(err)
+		return nil, err
+	}
+
+	// subscribe before reading the latest progress, so no event is missed in between
+	topic := syncProgressTopic(req.WarehouseID)
+	messages, unsubscribe := s.eventRepository.Subscribe(ctx, topic)
+	latest, err := s.eventRepository.Latest(ctx, topic)
+	if err != nil {
+		logger.Context(ctx).Error(err)
+		unsubscribe()
+		return nil, echo.NewHTTPError(http.StatusInternalServerError, echo.Map{"error": err.Error()})
+	}
+
+	progresses := make(chan model.SyncProgress)
+	go func() {
+		defer close(progresses)
+		defer unsubscribe()
+
+		send := func(payload []byte, isReplay bool) bool {
+			var progress model.SyncProgress
+			if err := json.Unmarshal(payload, &progress); err != nil {
+				logger.Context(ctx).Error(err)
+				return true
+			}
+			// a finished sync is not replayed, otherwise the stream would end before the next sync starts
+			if isReplay && progress.IsFinished() {
+				return true
+			}
+			select {
+			case progresses <- progress:
+				return true
+			case <-ctx.Done():
+				return false
+			}
+		}
+
+		if latest != nil && !send(latest, true) {
+			return
+		}
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case payload, ok := <-messages:
+				if !ok || !send(payload, false) {
+					return
+				}
+			}
+		}
+	}()
+
+	return progresses, nil
+}
+
+// applySheetValidation guides the next edits of the sheet of a warehouse, the dropdowns only offer the synced warehouse
+// and the trade ids of the brand sheet. Google limits a list to 500 values, a longer list refers to the brand sheet instead.
+func (s *sheet) applySheetValidation(ctx context.Context, warehouseID string, data model.GoogleSheetData) (err error) {
+	warehouseIDs := []string{warehouseID}
+
+	tradeIDs := []string{"-"}
+	for _, brand := range data.Brand.MedicineSheets {
+		if brand.TradeID != "" && !slices.Contains(tradeIDs, brand.TradeID) {
+			tradeIDs = append(tradeIDs, brand.TradeID)
+		}
+	}
+	tradeIDValidation := options.GoogleSheetDataValidation{Condition: options.GoogleSheetConditionTypeOneOfList, Values: tradeIDs}
+	if len(tradeIDs) > maxSheetListValues {
+		brandSheet := data.Brand.Sheet
+		if column := sheetColumnIndex(brandSheet, tradeIDColumnName); column > 0 {
+			letter := google.ColumnNumberToLetter(int(column))
+			tradeIDValidation = options.GoogleSheetDataValidation{
+				Condition: options.GoogleSheetConditionTypeOneOfRange,
+				Values:    []string{fmt.Sprintf("='%s'!%s2:%s", brandSheet.Properties.Title, letter, letter)},
+			}
+		}
+	}
+
+	if err = s.applyMedicineSheetValidation(ctx, data.SpreadsheetID, data.Medication.Sheet); err != nil {
+		return err
+	}
+
+	houseSheet := data.House.Sheet
+	if column := sheetColumnIndex(houseSheet, warehouseColumnName); column > 0 {
+		err = s.sheet.Update(ctx, data.SpreadsheetID,
+			options.WithGoogleSheetUpdateSheetID(houseSheet.Properties.SheetId),
+			options.WithGoogleSheetUpdateSheetTitle(houseSheet.Properties.Title),
+			options.WithGoogleSheetUpdateDataValidations([]options.GoogleSheetDataValidation{
+				{ColumnIndex: column, Condition: options.GoogleSheetConditionTypeOneOfList, Values: warehouseIDs},
+			}),
+		)
+		if err != nil {
+			return err
+		}
+	}
+
+	blisterDateSheet := data.BlisterDate.Sheet
+	warehouseColumn := sheetColumnIndex(blisterDateSheet, warehouseColumnName)
+	medicationIDColumn := sheetColumnIndex(blisterDateSheet, medicationIDColumnName)
+	tradeIDColumn := sheetColumnIndex(blisterDateSheet, tradeIDColumnName)
+	blisterDateColumn := sheetColumnIndex(blisterDateSheet, blisterDateColumnName)
+
+	var validations []options.GoogleSheetDataValidation
+	if warehouseColumn > 0 {
+		validations = append(validations, options.GoogleSheetDataValidation{ColumnIndex: warehouseColumn, Condition: options.GoogleSheetConditionTypeOneOfList, Values: warehouseIDs})
+	}
+	if tradeIDColumn > 0 && len(tradeIDValidation.Values) <= maxSheetListValues {
+		tradeIDValidation.ColumnIndex = tradeIDColumn
+		validations = append(validations, tradeIDValidation)
+	}
+	var conditionalFormats []options.GoogleSheetConditionalFormat
+	if blisterDateColumn > 0 {
+		validations = append(validations, options.GoogleSheetDataValidation{
+			ColumnIndex:  blisterDateColumn,
+			Condition:    options.GoogleSheetConditionTypeDateIsValid,
+			InputMessage: "วันที่ในรูปแบบ วัน/เดือน/ปี ค.ศ.",
+		})
+
+		if warehouseColumn > 0 && medicationIDColumn > 0 {
+			// only the latest change of each medicine in a warehouse can be overdue
+			date := "$" + google.ColumnNumberToLetter(int(blisterDateColumn))
+			warehouse := "$" + google.ColumnNumberToLetter(int(warehouseColumn))
+			medicationID := "$" + google.ColumnNumberToLetter(int(medicationIDColumn))
+			formula := fmt.Sprintf("=AND(ISDATE(%[1]s2), %[1]s2=MAXIFS(%[1]s:%[1]s, %[2]s:%[2]s, %[2]s2, %[3]s:%[3]s, %[3]s2), %[1]s2<TODAY()-%[4]d)",
+				date, warehouse, medicationID, s.blisterChangeIntervalDays)
+			conditionalFormats = append(conditionalFormats, options.GoogleSheetConditionalFormat{
+				ColumnIndex: blisterDateColumn,
+				Condition:   options.GoogleSheetConditionTypeCustomFormula,
+				Values:      []string{formula},
+				Format: &sheets.CellFormat{
+					BackgroundColor: &sheets.Color{Red: 0.96, Green: 0.8, Blue: 0.8},
+					TextFormat:      &sheets.TextFormat{Bold: true, ForegroundColor: &sheets.Color{Red: 0.6}},
+				},
+			})
+		}
+	}
+
+	if len(validations) == 0 && len(conditionalFormats) == 0 {
+		return nil
+	}
+	return s.sheet.Update(ctx, data.SpreadsheetID,
+		options.WithGoogleSheetUpdateSheetID(blisterDateSheet.Properties.SheetId),
+		options.WithGoogleSheetUpdateSheetTitle(blisterDateSheet.Properties.Title),
+		options.WithGoogleSheetUpdateDataValidations(validations),
+		options.WithGoogleSheetUpdateConditionalFormats(conditionalFormats),
+	)
+}
+
+// applyMedicineSheetValidation lists the known values of the attribute columns a medication sheet has
+func (s *sheet) applyMedicineSheetValidation(ctx context.Context, spreadsheetID string, medicineSheet *sheets.Sheet) error {
+	dosageForms := make([]string, 0, len(genmodel.PharmaSheetDosageFormAllValues))
+	for _, dosageForm := range genmodel.PharmaSheetDosageFormAllValues {
+		dosageForms = append(dosageForms, dosageForm.String())
+	}
+	routes := make([]string, 0, len(genmodel.PharmaSheetMedicineRouteAllValues))
+	for _, route := range genmodel.PharmaSheetMedicineRouteAllValues {
+		routes = append(routes, route.String())
+	}
+
+	var validations []options.GoogleSheetDataValidation
+	for _, column := range []struct {
+		name   string
+		values []string
+	}{
+		{strengthUnitColumnName, model.MedicineStrengthUnits},
+		{dosageFormColumnName, dosageForms},
+		{routeColumnName, routes},
+	} {
+		if index := sheetColumnIndex(medicineSheet, column.name); index > 0 {
+			validations = append(validations, options.GoogleSheetDataValidation{ColumnIndex: index, Condition: options.GoogleSheetConditionTypeOneOfList, Values: column.values})
+		}
+	}
+
+	if len(validations) == 0 {
+		return nil
+	}
+	return s.sheet.Update(ctx, spreadsheetID,
+		options.WithGoogleSheetUpdateSheetID(medicineSheet.Properties.SheetId),
+		options.WithGoogleSheetUpdateSheetTitle(medicineSheet.Properties.Title),
+		options.WithGoogleSheetUpdateDataValidations(validations),
+	)
+}
+
+func (s *sheet) writeBackSystemIDs(ctx context.Context, spreadsheetID string, sheet *sheets.Sheet, systemIDs map[int]string, rowCount int) error {
+	if rowCount <= 1 {
+		return nil
+	}
+
+	column := sheetColumnIndex(sheet, systemIDColumnName)
+	if column == 0 {
+		for index, cell := range sheet.Data[0].RowData[0].Values {
+			if strings.TrimSpace(cell.FormattedValue) != "" {
+				column = int64(index) + 2
+			}
+		}
+		err := s.sheet.Update(ctx, spreadsheetID,
+			options.WithGoogleSheetUpdateSheetID(sheet.Properties.SheetId),
+			options.WithGoogleSheetUpdateSheetTitle(sheet.Properties.Title),
+			options.WithGoogleSheetUpdateColumns([]options.GoogleSheetUpdateColumn{{Value: systemIDColumnName}}),
+			options.WithGoogleSheetUpdateColumnStartIndex(column),
+			options.WithGoogleSheetUpdateIsHiddenColumns(true),
+		)
+		if err != nil {
+			return err
+		}
+	}
+
+	data := make([][]options.GoogleSheetUpdateData, 0, rowCount-1)
+	for row := 2; row <= rowCount; row++ {
+		data = append(data, []options.GoogleSheetUpdateData{{Value: systemIDs[row]}})
+	}
+
+	return s.sheet.Update(ctx, spreadsheetID,
+		options.WithGoogleSheetUpdateSheetID(sheet.Properties.SheetId),
+		options.WithGoogleSheetUpdateSheetTitle(sheet.Properties.Title),
+		options.WithGoogleSheetUpdateStartCellRange(google.ColumnNumberToLetter(int(column))+"2"),
+		options.WithGoogleSheetUpdateData(data),
+	)
+}
+
+// uniqueSystemID keeps the system id of the first row carrying it. A copied row carries the system id of its origin,
+// it is cleared so the copy is synced as a new row instead of overwriting the record of the origin.
+func uniqueSystemID(seen map[string]bool, systemID string) string {
+	if systemID == "" || seen[systemID] {
+		return ""
+	}
+	seen[systemID] = true
+	return systemID
+}
+
+// sheetColumnIndex returns the 1-based index of the header column, or 0 when the sheet has no such column
+func sheetColumnIndex(sheet *sheets.Sheet, columnName string) int64 {
+	if len(sheet.Data) == 0 || len(sheet.Data[0].RowData) == 0 {
+		return 0
+	}
+	for index, cell := range sheet.Data[0].RowData[0].Values {
+		if strings.TrimSpace(cell.FormattedValue) == columnName {
+			return int64(index) + 1
+		}
+	}
+	return 0
+}
+
 func (s *sheet) checkWarehouseManagementRole(ctx context.Context, warehouseID string, roles ...genmodel.PharmaSheetRole) (err error) {
 	userProfile, err := profile.UseProfile(ctx)
 	if err != nil {
@@ -309,13 +1087,8 @@ func (s *sheet) checkWarehouseManagementRole(ctx context.Context, warehouseID st
 	return nil
 }
 
+// getGoogleSheetData reads the tabs of the spreadsheet, the caller checks the warehouse role beforehand
 func (s *sheet) getGoogleSheetData(ctx context.Context, req model.SyncMedicineRequest, isUpdateWarehouseSheet bool) (data model.GoogleSheetData, err error) {
-	err = s.checkWarehouseManagementRole(ctx, req.WarehouseID, genmodel.PharmaSheetRole_Admin, genmodel.PharmaSheetRole_Editor)
-	if err != nil {
-		logger.Context(ctx).Error(err)
-		return data, err
-	}
-
 	spreadsheetID, _, err := extractSpreadsheetInfo(req.URL)
 	if err != nil {
 		logger.Context(ctx).Error(err)
@@ -323,6 +1096,10 @@ func (s *sheet) getGoogleSheetData(ctx context.Context, req model.SyncMedicineRe
 	}
 
 	spreadsheet, err := s.sheet.Get(ctx, spreadsheetID)
+	if errors.Is(err, circuitbreaker.ErrOpenState) {
+		logger.Context(ctx).Error(err)
+		return data, echo.NewHTTPError(http.StatusServiceUnavailable, echo.Map{"error": "google sheet is temporarily unavailable"})
+	}
 	if err != nil {
 		logger.Context(ctx).Error(err)
 		return data, echo.NewHTTPError(http.StatusNotFound, echo.Map{"error": "spreadsheetID is not found"})
@@ -339,8 +1116,14 @@ func (s *sheet) getGoogleSheetData(ctx context.Context, req model.SyncMedicineRe
 		logger.Context(ctx).Errorf("sheet is invalid")
 		return data, echo.NewHTTPError(http.StatusBadRequest, echo.Map{"error": "sheet is invalid"})
 	}
+	for _, spreadSheet := range spreadsheet.Sheets {
+		if spreadSheet.Properties.Title == lotSheetName {
+			sheets[lotSheetName] = spreadSheet
+		}
+	}
 
 	if isUpdateWarehouseSheet {
+		before := warehouseSnapshot(ctx, s.warehouseRepository, req.WarehouseID)
 		err = s.warehouseRepository.UpsertWarehouseSheet(ctx, genmodel.PharmaSheetWarehouseSheets{
 			WarehouseID:                         req.WarehouseID,
 			SpreadsheetID:                       spreadsheetID,
@@ -357,6 +1140,14 @@ func (s *sheet) getGoogleSheetData(ctx context.Context, req model.SyncMedicineRe
 			logger.Context(ctx).Error(err)
 			return data, echo.NewHTTPError(http.StatusInternalServerError, echo.Map{"error": err.Error()})
 		}
+		recordAudit(ctx, s.auditRepository, model.CreateAuditLog{
+			Action:      genmodel.PharmaSheetAuditAction_Update,
+			EntityType:  model.AuditEntityWarehouse,
+			EntityID:    req.WarehouseID,
+			WarehouseID: &req.WarehouseID,
+			Before:      before,
+			After:       warehouseSnapshot(ctx, s.warehouseRepository, req.WarehouseID),
+		})
 	}
 
 	data = model.GoogleSheetData{
@@ -381,13 +1172,69 @@ func (s *sheet) getGoogleSheetData(ctx context.Context, req model.SyncMedicineRe
 		data.BlisterDate, err = s.mappingMedicineBlisterDateSheet(ctx, sheets[blisterDateSheetName], req.WarehouseID)
 		return err
 	})
+	if lotSheet, ok := sheets[lotSheetName]; ok {
+		conc.Go(func(ctx context.Context) error {
+			data.Lot, err = s.mappingMedicineLotSheet(ctx, lotSheet, req.WarehouseID)
+			return err
+		})
+	}
 	if err = conc.Wait(); err != nil {
 		return data, echo.NewHTTPError(http.StatusInternalServerError, echo.Map{"error": err.Error()})
 	}
 
+	// the rows of a merged medicine are synced to the medicine it was merged into
+	aliases, err := s.medicineRepository.ListMedicineAliases(ctx)
+	if err != nil {
+		logger.Context(ctx).Error(err)
+		return data, echo.NewHTTPError(http.StatusInternalServerError, echo.Map{"error": err.Error()})
+	}
+	if len(aliases) > 0 {
+		data.Medication.MedicineSheets = translateMedicationIDs(data.Medication.MedicineSheets, aliases,
+			func(row *model.MedicineSheet) *string { return &row.MedicationID },
+			func(row *model.MedicineSheet) string { return row.MedicationID })
+		data.Brand.MedicineSheets = translateMedicationIDs(data.Brand.MedicineSheets, aliases,
+			func(row *model.MedicineBrandSheet) *string { return &row.MedicationID },
+			(*model.MedicineBrandSheet).ExternalID)
+		data.House.MedicineSheets = translateMedicationIDs(data.House.MedicineSheets, aliases,
+			func(row *model.MedicineHouseSheet) *string { return &row.MedicationID },
+			(*model.MedicineHouseSheet).ExternalID)
+		data.BlisterDate.MedicineSheets = translateMedicationIDs(data.BlisterDate.MedicineSheets, aliases,
+			func(row *model.MedicineBlisterDateSheet) *string { return &row.MedicationID },
+			func(row *model.MedicineBlisterDateSheet) string {
+				return row.WarehouseID + "-" + row.MedicationID + "-" + row.TradeID + "-" + row.BlisterDate
+			})
+		data.Lot.MedicineSheets = translateMedicationIDs(data.Lot.MedicineSheets, aliases,
+			func(row *model.MedicineLotSheet) *string { return &row.MedicationID },
+			func(row *model.MedicineLotSheet) string {
+				return row.HouseExternalID() + "-" + row.TradeID + "-" + row.LotNumber
+			})
+	}
+
 	return data, nil
 }
 
+// translateMedicationIDs rewrites the rows of a merged medicine to the medicine it was merged into. A rewritten row is
+// dropped when the sheet has a row with the same key already, it is a duplicate left from before the merge then.
+func translateMedicationIDs[T any](rows []T, aliases map[string]string, medicationID func(*T) *string, key func(*T) string) []T {
+	keys := make(map[string]bool, len(rows))
+	for index := range rows {
+		keys[key(&rows[index])] = true
+	}
+
+	translated := make([]T, 0, len(rows))
+	for _, row := range rows {
+		if target, ok := aliases[*medicationID(&row)]; ok {
+			*medicationID(&row) = target
+			if keys[key(&row)] {
+				continue
+			}
+			keys[key(&row)] = true
+		}
+		translated = append(translated, row)
+	}
+	return translated
+}
+
 func (s *sheet) mappingMedicineSheet(ctx context.Context, sheet *sheets.Sheet) (data model.MedicineSheetMetadata, err error) {
 	data.Sheet = sheet
 
@@ -403,15 +1250,17 @@ func (s *sheet) mappingMedicineSheet(ctx context.Context, sheet *sheets.Sheet) (
 	}
 
 	var sheetData []model.MedicineSheet
-	_, err = s.sheet.Read(ctx, sheet, &sheetData)
+	_, err = s.sheet.Read(ctx, sheet, &sheetData, options.WithGoogleSheetReadExcludeEmptyRow(false))
 	if err != nil {
 		logger.Context(ctx).Error(err)
 		return data, echo.NewHTTPError(http.StatusBadRequest, echo.Map{"error": err.Error()})
 	}
 
-	for _, sheetData := range sheetData {
+	for index, sheetData := range sheetData {
 		if !sheetData.IsInvalid() {
 			data.MedicineSheets = append(data.MedicineSheets, sheetData)
+		} else if sheetData.MedicationID != "" || sheetData.MedicalName != "" {
+			data.InvalidRows = append(data.InvalidRows, index+2)
 		}
 	}
 
@@ -428,20 +1277,31 @@ func (s *sheet) mappingMedicineBrandSheet(ctx context.Context, sheet *sheets.She
 	}
 
 	data.MedicineData = make(map[string]model.MedicineBrand)
+	data.MedicineDataByID = make(map[uuid.UUID]model.MedicineBrand)
 	for _, medicine := range medicineData {
 		data.MedicineData[medicine.ExternalID()] = medicine
+		data.MedicineDataByID[medicine.ID] = medicine
 	}
 
+	// empty rows are kept, so the index of each row maps to its row number in the sheet
 	var sheetData []model.MedicineBrandSheet
-	_, err = s.sheet.Read(ctx, sheet, &sheetData)
+	_, err = s.sheet.Read(ctx, sheet, &sheetData, options.WithGoogleSheetReadExcludeEmptyRow(false))
 	if err != nil {
 		logger.Context(ctx).Error(err)
 		return data, echo.NewHTTPError(http.StatusBadRequest, echo.Map{"error": err.Error()})
 	}
 
-	for _, sheetData := range sheetData {
+	data.RowCount = len(sheetData) + 1
+	data.SystemIDs = make(map[int]string)
+	systemIDs := make(map[string]bool)
+	for index, sheetData := range sheetData {
+		sheetData.RowNumber = index + 2
+		sheetData.SystemID = uniqueSystemID(systemIDs, sheetData.SystemID)
+		data.SystemIDs[sheetData.RowNumber] = sheetData.SystemID
 		if !sheetData.IsInvalid() {
 			data.MedicineSheets = append(data.MedicineSheets, sheetData)
+		} else if sheetData.MedicationID != "" || sheetData.TradeID != "" {
+			data.InvalidRows = append(data.InvalidRows, sheetData.RowNumber)
 		}
 	}
 
@@ -457,22 +1317,43 @@ func (s *sheet) mappingMedicineHouseSheet(ctx context.Context, sheet *sheets.She
 		return data, echo.NewHTTPError(http.StatusInternalServerError, echo.Map{"error": err.Error()})
 	}
 
+	lockers, err := s.warehouseRepository.ListWarehouseLockers(ctx, warehouseID)
+	if err != nil {
+		logger.Context(ctx).Error(err)
+		return data, echo.NewHTTPError(http.StatusInternalServerError, echo.Map{"error": err.Error()})
+	}
+
 	data.MedicineData = make(map[string]model.MedicineHouse)
+	data.MedicineDataByID = make(map[uuid.UUID]model.MedicineHouse)
 	for _, medicine := range medicineData {
 		data.MedicineData[medicine.ExternalID()] = medicine
+		data.MedicineDataByID[medicine.ID] = medicine
 	}
 
+	// empty rows are kept, so the index of each row maps to its row number in the sheet
 	var sheetData []model.MedicineHouseSheet
-	_, err = s.sheet.Read(ctx, sheet, &sheetData)
+	_, err = s.sheet.Read(ctx, sheet, &sheetData, options.WithGoogleSheetReadExcludeEmptyRow(false))
 	if err != nil {
 		logger.Context(ctx).Error(err)
 		return data, echo.NewHTTPError(http.StatusBadRequest, echo.Map{"error": err.Error()})
 	}
 
-	for _, sheetData := range sheetData {
-		if !sheetData.IsInvalid() && sheetData.WarehouseID == warehouseID {
-			data.MedicineSheets = append(data.MedicineSheets, sheetData)
+	data.RowCount = len(sheetData) + 1
+	data.SystemIDs = make(map[int]string)
+	systemIDs := make(map[string]bool)
+	for index, sheetData := range sheetData {
+		sheetData.RowNumber = index + 2
+		sheetData.SystemID = uniqueSystemID(systemIDs, sheetData.SystemID)
+		data.SystemIDs[sheetData.RowNumber] = sheetData.SystemID
+		if sheetData.WarehouseID != warehouseID {
+			continue
 		}
+		// rows outside the locker layout are flagged like any other invalid row, so typos are caught in the sheet
+		if sheetData.IsInvalid() || model.CheckHouseAddress(lockers, sheetData.Locker, sheetData.Floor(), sheetData.No()) != nil {
+			data.InvalidRows = append(data.InvalidRows, sheetData.RowNumber)
+			continue
+		}
+		data.MedicineSheets = append(data.MedicineSheets, sheetData)
 	}
 
 	return data, nil
@@ -488,24 +1369,85 @@ func (s *sheet) mappingMedicineBlisterDateSheet(ctx context.Context, sheet *shee
 	}
 
 	data.MedicineData = make(map[string]model.MedicineBlisterDateHistory)
+	data.MedicineDataByID = make(map[uuid.UUID]model.MedicineBlisterDateHistory)
 	for _, medicine := range medicineData {
 		data.MedicineData[medicine.ExternalID()] = medicine
+		data.MedicineDataByID[medicine.ID] = medicine
 	}
 
+	// empty rows are kept, so the index of each row maps to its row number in the sheet
 	var sheetData []model.MedicineBlisterDateSheet
-	_, err = s.sheet.Read(ctx, sheet, &sheetData)
+	_, err = s.sheet.Read(ctx, sheet, &sheetData, options.WithGoogleSheetReadExcludeEmptyRow(false))
 	if err != nil {
 		logger.Context(ctx).Error(err)
 		return data, echo.NewHTTPError(http.StatusBadRequest, echo.Map{"error": err.Error()})
 	}
 
-	for _, sheetData := range sheetData {
-		if !sheetData.IsInvalid() && sheetData.WarehouseID == warehouseID {
-			if sheetData.TradeID == "-" {
-				sheetData.TradeID = ""
-			}
-			data.MedicineSheets = append(data.MedicineSheets, sheetData)
+	data.RowCount = len(sheetData) + 1
+	data.SystemIDs = make(map[int]string)
+	systemIDs := make(map[string]bool)
+	for index, sheetData := range sheetData {
+		sheetData.RowNumber = index + 2
+		sheetData.SystemID = uniqueSystemID(systemIDs, sheetData.SystemID)
+		data.SystemIDs[sheetData.RowNumber] = sheetData.SystemID
+		if sheetData.WarehouseID != warehouseID {
+			continue
+		}
+		if sheetData.IsInvalid() {
+			data.InvalidRows = append(data.InvalidRows, sheetData.RowNumber)
+			continue
 		}
+		if sheetData.TradeID == "-" {
+			sheetData.TradeID = ""
+		}
+		data.MedicineSheets = append(data.MedicineSheets, sheetData)
+	}
+
+	return data, nil
+}
+
+func (s *sheet) mappingMedicineLotSheet(ctx context.Context, sheet *sheets.Sheet, warehouseID string) (data model.MedicineLotSheetMetadata, err error) {
+	data.Sheet = sheet
+
+	medicineData, err := s.lotRepository.ListMedicineLots(ctx, model.ListMedicineLot{WarehouseID: warehouseID})
+	if err != nil {
+		logger.Context(ctx).Error(err)
+		return data, echo.NewHTTPError(http.StatusInternalServerError, echo.Map{"error": err.Error()})
+	}
+
+	data.MedicineData = make(map[string]model.MedicineLot)
+	data.MedicineDataByID = make(map[uuid.UUID]model.MedicineLot)
+	for _, medicine := range medicineData {
+		data.MedicineData[medicine.ExternalID()] = medicine
+		data.MedicineDataByID[medicine.ID] = medicine
+	}
+
+	// empty rows are kept, so the index of each row maps to its row number in the sheet
+	var sheetData []model.MedicineLotSheet
+	_, err = s.sheet.Read(ctx, sheet, &sheetData, options.WithGoogleSheetReadExcludeEmptyRow(false))
+	if err != nil {
+		logger.Context(ctx).Error(err)
+		return data, echo.NewHTTPError(http.StatusBadRequest, echo.Map{"error": err.Error()})
+	}
+
+	data.RowCount = len(sheetData) + 1
+	data.SystemIDs = make(map[int]string)
+	systemIDs := make(map[string]bool)
+	for index, sheetData := range sheetData {
+		sheetData.RowNumber = index + 2
+		sheetData.SystemID = uniqueSystemID(systemIDs, sheetData.SystemID)
+		data.SystemIDs[sheetData.RowNumber] = sheetData.SystemID
+		if sheetData.WarehouseID != warehouseID {
+			continue
+		}
+		if sheetData.IsInvalid() {
+			data.InvalidRows = append(data.InvalidRows, sheetData.RowNumber)
+			continue
+		}
+		if sheetData.TradeID == "-" {
+			sheetData.TradeID = ""
+		}
+		data.MedicineSheets = append(data.MedicineSheets, sheetData)
 	}
 
 	return data, nil
@@ -546,3 +1488,99 @@ func extractSpreadsheetInfo(url string) (string, int32, error) {
 	// Return the extracted values
 	return spreadsheetIDMatches[1], int32(gid), nil
 }
+
+const syncProgressPublishEvery = 25
+
+type syncProgress struct {
+	eventRepository repository.Event
+	progress        model.SyncProgress
+}
+
+func (s *sheet) newSyncProgress(warehouseID string) *syncProgress {
+	now := time.Now()
+	return &syncProgress{
+		eventRepository: s.eventRepository,
+		progress: model.SyncProgress{
+			WarehouseID: warehouseID,
+			StartedAt:   now,
+			UpdatedAt:   now,
+		},
+	}
+}
+
+func syncProgressTopic(warehouseID string) string {
+	return "SYNC_PROGRESS:" + warehouseID
+}
+
+func (p *syncProgress) start(ctx context.Context, phase model.SyncProgressPhase, total int) {
+	p.progress.Phase = phase
+	p.progress.Total = uint64(total)
+	p.progress.Processed = 0
+	p.progress.Created = 0
+	p.progress.Updated = 0
+	p.progress.Skipped = 0
+	p.publish(ctx)
+}
+
+func (p *syncProgress) created(ctx context.Context) {
+	p.progress.Created++
+	p.processed(ctx)
+}
+
+func (p *syncProgress) updated(ctx context.Context) {
+	p.progress.Updated++
+	p.processed(ctx)
+}
+
+func (p *syncProgress) skipped(ctx context.Context) {
+	p.progress.Skipped++
+	p.processed(ctx)
+}
+
+func (p *syncProgress) processed(ctx context.Context) {
+	p.progress.Processed++
+	if p.progress.Processed%syncProgressPublishEvery == 0 || p.progress.Processed == p.progress.Total {
+		p.publish(ctx)
+	}
+}
+
+func (p *syncProgress) warn(ctx context.Context, format string, args ...any) {
+	p.progress.Warnings = append(p.progress.Warnings, fmt.Sprintf(format, args...))
+	p.publish(ctx)
+}
+
+func (p *syncProgress) finish(ctx context.Context, err error) {
+	p.progress.Phase = model.SyncProgressPhaseCompleted
+	if err != nil {
+		message := err.Error()
+		if httpErr, ok := err.(*echo.HTTPError); ok {
+			if m, ok := httpErr.Message.(echo.Map); ok {
+				message = fmt.Sprint(m["error"])
+			}
+		}
+		p.progress.Phase = model.SyncProgressPhaseFailed
+		p.progress.Error = &message
+	}
+	p.publish(ctx)
+}
+
+// publish is best effort, a broken progress stream must not fail the sync itself
+func (p *syncProgress) publish(ctx context.Context) {
+	p.progress.UpdatedAt = time.Now()
+	if err := p.eventRepository.Publish(ctx, syncProgressTopic(p.progress.WarehouseID), p.progress); err != nil {
+		logger.Context(ctx).Warnf("unable to publish sync progress: %v", err)
+	}
+}
+
+func formatRowNumbers(rows []int) string {
+	const maxRows = 20
+	texts := make([]string, 0, maxRows)
+	for index, row := range rows {
+		if index == maxRows {
+			texts = append(texts, "...")
+			break
+		}
+		texts = append(texts, strconv.Itoa(row))
+	}
+	return strings.Join(texts, ", ")
+}
@@ -0,0 +1,643 @@
+package service
+
+import (
+	"cmp"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	genmodel "github.com/kinkando/pharma-sheet-service/.gen/pharma_sheet/public/model"
+	"github.com/kinkando/pharma-sheet-service/model"
+	"github.com/kinkando/pharma-sheet-service/pkg/generator"
+	"github.com/kinkando/pharma-sheet-service/pkg/logger"
+	"github.com/kinkando/pharma-sheet-service/pkg/profile"
+	"github.com/kinkando/pharma-sheet-service/repository"
+	"github.com/labstack/echo/v4"
+)
+
+type Stock interface {
+	CreateStockMovement(ctx context.Context, req model.CreateStockMovementRequest) ([]string, error)
+	ListStockMovements(ctx context.Context, filter model.FilterStockMovement) (model.PagingWithMetadata[model.StockMovement], error)
+	ListStockBalances(ctx context.Context, filter model.FilterStockBalance) ([]model.StockBalance, error)
+	TransferMedicineHouse(ctx context.Context, req model.TransferMedicineHouseRequest) (model.MedicineHouseTransfer, error)
+
+	ListStockLevels(ctx context.Context, filter model.FilterStockLevel) ([]model.StockLevel, error)
+	UpsertStockLevel(ctx context.Context, req model.UpsertStockLevelRequest) (string, error)
+	DeleteStockLevel(ctx context.Context, id uuid.UUID) error
+	ListLowStocks(ctx context.Context, filter model.FilterLowStock) ([]model.LowStock, error)
+	StreamStockLevelEvents(ctx context.Context, req model.StreamStockLevelEventRequest) (<-chan model.StockLevelEvent, error)
+}
+
+type stock struct {
+	stockRepository     repository.Stock
+	lotRepository       repository.Lot
+	medicineRepository  repository.Medicine
+	warehouseRepository repository.Warehouse
+	eventRepository     repository.Event
+	cacheRepository     repository.Cache
+}
+
+func NewStockService(
+	stockRepository repository.Stock,
+	lotRepository repository.Lot,
+	medicineRepository repository.Medicine,
+	warehouseRepository repository.Warehouse,
+	eventRepository repository.Event,
+	cacheRepository repository.Cache,
+) Stock {
+	return &stock{
+		stockRepository:     stockRepository,
+		lotRepository:       lotRepository,
+		medicineRepository:  medicineRepository,
+		warehouseRepository: warehouseRepository,
+		eventRepository:     eventRepository,
+		cacheRepository:     cacheRepository,
+	}
+}
+
+func (s *stock) CreateStockMovement(ctx context.Context, req model.CreateStockMovementRequest) ([]string, error) {
+	house, err := s.getMedicineHouse(ctx, req.HouseID)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.checkWarehouseManagementRole(ctx, house.WarehouseID, genmodel.PharmaSheetRole_Admin, genmodel.PharmaSheetRole_Editor)
+	if err != nil {
+		logger.Context(ctx).Error(err)
+		return nil, err
+	}
+
+	if req.MovementType != genmodel.PharmaSheetStockMovementType_Adjust && req.Quantity < 0 {
+		return nil, echo.NewHTTPError(http.StatusBadRequest, echo.Map{"error": "quantity must be positive"})
+	}
+
+	if err = s.checkMedicineBrand(ctx, house, req.BrandID); err != nil {
+		return nil, err
+	}
+	if err = s.checkMedicineLot(ctx, house, req.BrandID, req.LotID); err != nil {
+		return nil, err
+	}
+
+	entry := model.StockMovementEntry{
+		HouseID:      req.HouseID,
+		BrandID:      req.BrandID,
+		LotID:        req.LotID,
+		MovementType: req.MovementType,
+		Quantity:     req.Quantity,
+		Reason:       req.Reason,
+	}
+
+	houses := []model.MedicineHouse{house}
+	var entries []model.StockMovementEntry
+	switch req.MovementType {
+	case genmodel.PharmaSheetStockMovementType_Receive, genmodel.PharmaSheetStockMovementType_Adjust:
+		entries = append(entries, entry)
+
+	case genmodel.PharmaSheetStockMovementType_Dispense, genmodel.PharmaSheetStockMovementType_Dispose:
+		entry.Quantity = -req.Quantity
+		entries = append(entries, entry)
+
+	case genmodel.PharmaSheetStockMovementType_Transfer:
+		if *req.ToHouseID == req.HouseID {
+			return nil, echo.NewHTTPError(http.StatusBadRequest, echo.Map{"error": "toHouseID must be different from houseID"})
+		}
+
+		toHouse, err := s.getMedicineHouse(ctx, *req.ToHouseID)
+		if err != nil {
+			return nil, err
+		}
+		if toHouse.MedicationID != house.MedicationID {
+			return nil, echo.NewHTTPError(http.StatusBadRequest, echo.Map{"error": "toHouseID must store the same medicine"})
+		}
+		if toHouse.WarehouseID != house.WarehouseID {
+			err = s.checkWarehouseManagementRole(ctx, toHouse.WarehouseID, genmodel.PharmaSheetRole_Admin, genmodel.PharmaSheetRole_Editor)
+			if err != nil {
+				logger.Context(ctx).Error(err)
+				return nil, err
+			}
+		}
+
+		transferID := uuid.MustParse(generator.UUID())
+		entry.Quantity = -req.Quantity
+		entry.TransferID = &transferID
+		incoming := entry
+		incoming.HouseID = toHouse.ID
+		incoming.Quantity = req.Quantity
+		entries = append(entries, entry, incoming)
+		houses = append(houses, toHouse)
+	}
+
+	confirmedBy, err := s.confirmMovement(ctx, house, model.ConfirmationActionStockMovement, req.ConfirmationCode)
+	if err != nil {
+		return nil, err
+	}
+	for i := range entries {
+		entries[i].ConfirmedBy = confirmedBy
+	}
+
+	ids, changes, err := s.stockRepository.CreateStockMovements(ctx, entries)
+	if err != nil {
+		if errors.Is(err, model.ErrInsufficientStock) {
+			return nil, echo.NewHTTPError(http.StatusConflict, echo.Map{"error": err.Error()})
+		}
+		return nil, echo.NewHTTPError(http.StatusInternalServerError, echo.Map{"error": err.Error()})
+	}
+
+	// the movement is already posted, a failed alert must not fail the request
+	s.publishStockLevelEvents(context.WithoutCancel(ctx), houses, changes)
+
+	return ids, nil
+}
+
+func (s *stock) ListStockMovements(ctx context.Context, filter model.FilterStockMovement) (res model.PagingWithMetadata[model.StockMovement], err error) {
+	err = s.checkWarehouseManagementRole(ctx, filter.WarehouseID, genmodel.PharmaSheetRole_Admin, genmodel.PharmaSheetRole_Editor, genmodel.PharmaSheetRole_Viewer)
+	if err != nil {
+		logger.Context(ctx).Error(err)
+		return res, err
+	}
+
+	data, total, err := s.stockRepository.ListStockMovements(ctx, filter)
+	if err != nil {
+		return res, echo.NewHTTPError(http.StatusInternalServerError, echo.Map{"error": err.Error()})
+	}
+
+	res = model.PaginationResponse(data, filter.Pagination, total)
+	return res, nil
+}
+
+func (s *stock) ListStockBalances(ctx context.Context, filter model.FilterStockBalance) ([]model.StockBalance, error) {
+	err := s.checkWarehouseManagementRole(ctx, filter.WarehouseID, genmodel.PharmaSheetRole_Admin, genmodel.PharmaSheetRole_Editor, genmodel.PharmaSheetRole_Viewer)
+	if err != nil {
+		logger.Context(ctx).Error(err)
+		return nil, err
+	}
+
+	balances, err := s.stockRepository.ListStockBalances(ctx, filter)
+	if err != nil {
+		return nil, echo.NewHTTPError(http.StatusInternalServerError, echo.Map{"error": err.Error()})
+	}
+	if balances == nil {
+		balances = []model.StockBalance{}
+	}
+	return balances, nil
+}
+
+func (s *stock) TransferMedicineHouse(ctx context.Context, req model.TransferMedicineHouseRequest) (res model.MedicineHouseTransfer, err error) {
+	house, err := s.getMedicineHouse(ctx, req.ID)
+	if err != nil {
+		return res, err
+	}
+
+	// both the sending and the receiving warehouse must be managed by the user
+	roles, err := houseManagementRoles(ctx, s.medicineRepository, house.MedicationID)
+	if err != nil {
+		return res, err
+	}
+	for _, warehouseID := range []string{house.WarehouseID, req.WarehouseID} {
+		err = s.checkWarehouseManagementRole(ctx, warehouseID, roles...)
+		if err != nil {
+			logger.Context(ctx).Error(err)
+			return res, err
+		}
+	}
+
+	if req.Label != nil && *req.Label == "" {
+		req.Label = nil
+	}
+	req.Locker = strings.TrimSpace(req.Locker)
+	if err = s.checkHouseAddress(ctx, req.WarehouseID, req.Locker, req.Floor, req.No); err != nil {
+		return res, err
+	}
+	target := model.MedicineHouse{
+		MedicationID: house.MedicationID,
+		WarehouseID:  req.WarehouseID,
+		Locker:       req.Locker,
+		Floor:        req.Floor,
+		No:           req.No,
+		Label:        req.Label,
+	}
+	if target.WarehouseID == house.WarehouseID && target.Address() == house.Address() {
+		return res, echo.NewHTTPError(http.StatusBadRequest, echo.Map{"error": "target address must be different from the house"})
+	}
+
+	transfer := model.TransferMedicineHouse{
+		Mode:   req.Mode,
+		Source: house,
+		Target: target,
+	}
+	outgoing := model.StockMovementEntry{
+		HouseID:      house.ID,
+		MovementType: genmodel.PharmaSheetStockMovementType_Transfer,
+		Reason:       req.Reason,
+	}
+
+	switch req.Mode {
+	case genmodel.PharmaSheetHouseTransferMode_Move:
+		// the house keeps its lots, the paired movements only record what was on hand when it moved
+		balances, err := s.stockRepository.ListStockBalances(ctx, model.FilterStockBalance{WarehouseID: house.WarehouseID, HouseID: &house.ID})
+		if err != nil {
+			return res, echo.NewHTTPError(http.StatusInternalServerError, echo.Map{"error": err.Error()})
+		}
+		for _, balance := range balances {
+			if balance.OnHand <= 0 {
+				continue
+			}
+			outgoing.BrandID = balance.BrandID
+			outgoing.Quantity = -int32(balance.OnHand)
+			transfer.Entries = append(transfer.Entries, outgoing)
+		}
+
+	case genmodel.PharmaSheetHouseTransferMode_Split:
+		for _, item := range req.Items {
+			if err = s.checkMedicineBrand(ctx, house, item.BrandID); err != nil {
+				return res, err
+			}
+			if err = s.checkMedicineLot(ctx, house, item.BrandID, item.LotID); err != nil {
+				return res, err
+			}
+			outgoing.BrandID = item.BrandID
+			outgoing.LotID = item.LotID
+			outgoing.Quantity = -item.Quantity
+			transfer.Entries = append(transfer.Entries, outgoing)
+		}
+	}
+
+	confirmedBy, err := s.confirmMovement(ctx, house, model.ConfirmationActionHouseTransfer, req.ConfirmationCode)
+	if err != nil {
+		return res, err
+	}
+	for i := range transfer.Entries {
+		transfer.Entries[i].ConfirmedBy = confirmedBy
+	}
+
+	res, changes, err := s.stockRepository.TransferMedicineHouse(ctx, transfer)
+	if err != nil {
+		if errors.Is(err, model.ErrInsufficientStock) {
+			return res, echo.NewHTTPError(http.StatusConflict, echo.Map{"error": err.Error()})
+		}
+		if model.IsConflictError(err) {
+			return res, echo.NewHTTPError(http.StatusConflict, echo.Map{"error": "target address already stores the medicine"})
+		}
+		return res, echo.NewHTTPError(http.StatusInternalServerError, echo.Map{"error": err.Error()})
+	}
+
+	target.ID = *res.TargetHouseID
+	s.publishStockLevelEvents(context.WithoutCancel(ctx), []model.MedicineHouse{house, target}, changes)
+
+	return res, nil
+}
+
+func (s *stock) ListStockLevels(ctx context.Context, filter model.FilterStockLevel) ([]model.StockLevel, error) {
+	err := s.checkWarehouseManagementRole(ctx, filter.WarehouseID, genmodel.PharmaSheetRole_Admin, genmodel.PharmaSheetRole_Editor, genmodel.PharmaSheetRole_Viewer)
+	if err != nil {
+		logger.Context(ctx).Error(err)
+		return nil, err
+	}
+
+	levels, err := s.stockRepository.ListStockLevels(ctx, filter.WarehouseID)
+	if err != nil {
+		return nil, echo.NewHTTPError(http.StatusInternalServerError, echo.Map{"error": err.Error()})
+	}
+	if levels == nil {
+		levels = []model.StockLevel{}
+	}
+	return levels, nil
+}
+
+func (s *stock) UpsertStockLevel(ctx context.Context, req model.UpsertStockLevelRequest) (string, error) {
+	err := s.checkWarehouseManagementRole(ctx, req.WarehouseID, genmodel.PharmaSheetRole_Admin, genmodel.PharmaSheetRole_Editor)
+	if err != nil {
+		logger.Context(ctx).Error(err)
+		return "", err
+	}
+
+	if req.MaxQuantity != nil && *req.MaxQuantity < *req.MinQuantity {
+		return "", echo.NewHTTPError(http.StatusBadRequest, echo.Map{"error": "maxQuantity must not be less than minQuantity"})
+	}
+
+	if req.HouseID != nil {
+		house, err := s.getMedicineHouse(ctx, *req.HouseID)
+		if err != nil {
+			return "", err
+		}
+		if house.WarehouseID != req.WarehouseID || house.MedicationID != req.MedicationID {
+			return "", echo.NewHTTPError(http.StatusBadRequest, echo.Map{"error": "houseID does not store the medicine in the warehouse"})
+		}
+	} else if _, err = s.medicineRepository.GetMedicine(ctx, req.MedicationID); err != nil {
+		logger.Context(ctx).Error(err)
+		if errors.Is(err, sql.ErrNoRows) {
+			return "", echo.NewHTTPError(http.StatusNotFound, echo.Map{"error": "medicationID is not found"})
+		}
+		return "", echo.NewHTTPError(http.StatusInternalServerError, echo.Map{"error": err.Error()})
+	}
+
+	id, err := s.stockRepository.UpsertStockLevel(ctx, req)
+	if err != nil {
+		if model.IsConflictError(err) {
+			return "", echo.NewHTTPError(http.StatusConflict, echo.Map{"error": err.Error()})
+		}
+		return "", echo.NewHTTPError(http.StatusInternalServerError, echo.Map{"error": err.Error()})
+	}
+	return id, nil
+}
+
+func (s *stock) DeleteStockLevel(ctx context.Context, id uuid.UUID) error {
+	level, err := s.stockRepository.GetStockLevel(ctx, id)
+	if err != nil {
+		logger.Context(ctx).Error(err)
+		if errors.Is(err, sql.ErrNoRows) {
+			return echo.NewHTTPError(http.StatusNotFound, echo.Map{"error": "stockLevelID is not found"})
+		}
+		return echo.NewHTTPError(http.StatusInternalServerError, echo.Map{"error": err.Error()})
+	}
+
+	err = s.checkWarehouseManagementRole(ctx, level.WarehouseID, genmodel.PharmaSheetRole_Admin, genmodel.PharmaSheetRole_Editor)
+	if err != nil {
+		logger.Context(ctx).Error(err)
+		return err
+	}
+
+	_, err = s.stockRepository.DeleteStockLevel(ctx, id)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, echo.Map{"error": err.Error()})
+	}
+	return nil
+}
+
+func (s *stock) ListLowStocks(ctx context.Context, filter model.FilterLowStock) ([]model.LowStock, error) {
+	err := s.checkWarehouseManagementRole(ctx, filter.WarehouseID, genmodel.PharmaSheetRole_Admin, genmodel.PharmaSheetRole_Editor, genmodel.PharmaSheetRole_Viewer)
+	if err != nil {
+		logger.Context(ctx).Error(err)
+		return nil, err
+	}
+
+	houses, err := s.medicineRepository.GetMedicineHouses(ctx, model.FilterMedicineHouse{WarehouseID: filter.WarehouseID, MedicationID: filter.MedicationID})
+	if err != nil {
+		return nil, echo.NewHTTPError(http.StatusInternalServerError, echo.Map{"error": err.Error()})
+	}
+	balances, err := s.stockRepository.ListStockBalances(ctx, model.FilterStockBalance{WarehouseID: filter.WarehouseID, MedicationID: filter.MedicationID})
+	if err != nil {
+		return nil, echo.NewHTTPError(http.StatusInternalServerError, echo.Map{"error": err.Error()})
+	}
+	levels, err := s.stockRepository.ListStockLevels(ctx, filter.WarehouseID)
+	if err != nil {
+		return nil, echo.NewHTTPError(http.StatusInternalServerError, echo.Map{"error": err.Error()})
+	}
+
+	// houses without any movement have nothing on hand, so they are evaluated from the house list
+	onHands := make(map[uuid.UUID]int64)
+	for _, balance := range balances {
+		onHands[balance.HouseID] += balance.OnHand
+	}
+
+	lowStocks := make([]model.LowStock, 0)
+	for _, house := range houses {
+		level := resolveStockLevel(levels, house)
+		if level == nil || onHands[house.ID] >= int64(level.MinQuantity) {
+			continue
+		}
+		lowStocks = append(lowStocks, newLowStock(house, *level, onHands[house.ID]))
+	}
+
+	slices.SortFunc(lowStocks, func(a, b model.LowStock) int {
+		return cmp.Or(
+			cmp.Compare(a.MedicationID, b.MedicationID),
+			cmp.Compare(a.Locker, b.Locker),
+			cmp.Compare(a.Floor, b.Floor),
+			cmp.Compare(a.No, b.No),
+		)
+	})
+
+	return lowStocks, nil
+}
+
+func (s *stock) StreamStockLevelEvents(ctx context.Context, req model.StreamStockLevelEventRequest) (<-chan model.StockLevelEvent, error) {
+	err := s.checkWarehouseManagementRole(ctx, req.WarehouseID, genmodel.PharmaSheetRole_Admin, genmodel.PharmaSheetRole_Editor, genmodel.PharmaSheetRole_Viewer)
+	if err != nil {
+		logger.Context(ctx).Error(err)
+		return nil, err
+	}
+
+	// alerts are only relevant when they happen, so the latest event is not replayed
+	messages, unsubscribe := s.eventRepository.Subscribe(ctx, stockLevelTopic(req.WarehouseID))
+
+	events := make(chan model.StockLevelEvent)
+	go func() {
+		defer close(events)
+		defer unsubscribe()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case payload, ok := <-messages:
+				if !ok {
+					return
+				}
+				var event model.StockLevelEvent
+				if err := json.Unmarshal(payload, &event); err != nil {
+					logger.Context(ctx).Error(err)
+					continue
+				}
+				select {
+				case events <- event:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+
+	return events, nil
+}
+
+// publishStockLevelEvents notifies the warehouse of every house whose on-hand stock crossed its minimum in either direction,
+// the changes are read in the transaction of the movements so concurrent movements never emit an event twice or miss it
+func (s *stock) publishStockLevelEvents(ctx context.Context, houses []model.MedicineHouse, changes []model.StockOnHandChange) {
+	for _, change := range changes {
+		index := slices.IndexFunc(houses, func(house model.MedicineHouse) bool { return house.ID == change.HouseID })
+		if index < 0 {
+			continue
+		}
+		s.publishStockLevelEvent(ctx, houses[index], change.Before, change.After)
+	}
+}
+
+func (s *stock) publishStockLevelEvent(ctx context.Context, house model.MedicineHouse, before, after int64) {
+	levels, err := s.stockRepository.ListStockLevels(ctx, house.WarehouseID)
+	if err != nil {
+		return
+	}
+	level := resolveStockLevel(levels, house)
+	if level == nil {
+		return
+	}
+
+	minQuantity := int64(level.MinQuantity)
+	var eventType model.StockLevelEventType
+	switch {
+	case before >= minQuantity && after < minQuantity:
+		eventType = model.StockLevelEventTypeBelowMinimum
+	case before < minQuantity && after >= minQuantity:
+		eventType = model.StockLevelEventTypeRestocked
+	default:
+		return
+	}
+
+	_ = s.eventRepository.Publish(ctx, stockLevelTopic(house.WarehouseID), model.StockLevelEvent{
+		LowStock:   newLowStock(house, *level, after),
+		Type:       eventType,
+		OccurredAt: time.Now(),
+	})
+}
+
+func (s *stock) getMedicineHouse(ctx context.Context, id uuid.UUID) (model.MedicineHouse, error) {
+	houses, err := s.medicineRepository.GetMedicineHouses(ctx, model.FilterMedicineHouse{ID: id})
+	if err != nil {
+		logger.Context(ctx).Error(err)
+		return model.MedicineHouse{}, echo.NewHTTPError(http.StatusInternalServerError, echo.Map{"error": err.Error()})
+	}
+	if len(houses) == 0 {
+		logger.Context(ctx).Errorf("houseID %s is not found", id.String())
+		return model.MedicineHouse{}, echo.NewHTTPError(http.StatusNotFound, echo.Map{"error": "houseID is not found"})
+	}
+	return houses[0], nil
+}
+
+func (s *stock) checkMedicineBrand(ctx context.Context, house model.MedicineHouse, brandID *uuid.UUID) error {
+	if brandID == nil {
+		return nil
+	}
+
+	brands, err := s.medicineRepository.GetMedicineBrands(ctx, model.FilterMedicineBrand{BrandID: *brandID})
+	if err != nil {
+		logger.Context(ctx).Error(err)
+		return echo.NewHTTPError(http.StatusInternalServerError, echo.Map{"error": err.Error()})
+	}
+	if len(brands) == 0 {
+		return echo.NewHTTPError(http.StatusNotFound, echo.Map{"error": "brandID is not found"})
+	}
+	if brands[0].MedicationID != house.MedicationID {
+		return echo.NewHTTPError(http.StatusBadRequest, echo.Map{"error": "brandID does not belong to the medicine of the house"})
+	}
+	return nil
+}
+
+// checkMedicineLot makes sure the lot of a movement is stored in the house with the same brand
+func (s *stock) checkMedicineLot(ctx context.Context, house model.MedicineHouse, brandID, lotID *uuid.UUID) error {
+	if lotID == nil {
+		return nil
+	}
+
+	medicineLot, err := s.lotRepository.GetMedicineLot(ctx, *lotID)
+	if err != nil {
+		logger.Context(ctx).Error(err)
+		if errors.Is(err, sql.ErrNoRows) {
+			return echo.NewHTTPError(http.StatusNotFound, echo.Map{"error": "lotID is not found"})
+		}
+		return echo.NewHTTPError(http.StatusInternalServerError, echo.Map{"error": err.Error()})
+	}
+	if medicineLot.HouseID != house.ID {
+		return echo.NewHTTPError(http.StatusBadRequest, echo.Map{"error": "lotID does not belong to the house"})
+	}
+	if (medicineLot.BrandID == nil) != (brandID == nil) || (brandID != nil && *medicineLot.BrandID != *brandID) {
+		return echo.NewHTTPError(http.StatusBadRequest, echo.Map{"error": "lotID does not belong to the brand"})
+	}
+	return nil
+}
+
+func (s *stock) checkWarehouseManagementRole(ctx context.Context, warehouseID string, roles ...genmodel.PharmaSheetRole) (err error) {
+	userProfile, err := profile.UseProfile(ctx)
+	if err != nil {
+		return
+	}
+
+	role, err := s.warehouseRepository.GetWarehouseRole(ctx, warehouseID, userProfile.UserID)
+	if err != nil {
+		logger.Context(ctx).Error(err)
+		return echo.NewHTTPError(http.StatusInternalServerError, echo.Map{"error": err.Error()})
+	}
+
+	if !slices.Contains(roles, role) {
+		return echo.NewHTTPError(http.StatusForbidden, echo.Map{"error": "permission denied"})
+	}
+
+	return nil
+}
+
+// checkHouseAddress makes sure the address of a house fits the locker layout of its warehouse
+func (s *stock) checkHouseAddress(ctx context.Context, warehouseID, locker string, floor, no int32) error {
+	lockers, err := s.warehouseRepository.ListWarehouseLockers(ctx, warehouseID)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, echo.Map{"error": err.Error()})
+	}
+	if err = model.CheckHouseAddress(lockers, locker, floor, no); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, echo.Map{"error": err.Error()})
+	}
+	return nil
+}
+
+// resolveStockLevel picks the level of the house itself before the level of its medicine in the warehouse
+func resolveStockLevel(levels []model.StockLevel, house model.MedicineHouse) *model.StockLevel {
+	var warehouseLevel *model.StockLevel
+	for index, level := range levels {
+		if level.WarehouseID != house.WarehouseID || level.MedicationID != house.MedicationID {
+			continue
+		}
+		if level.HouseID == nil {
+			warehouseLevel = &levels[index]
+		} else if *level.HouseID == house.ID {
+			return &levels[index]
+		}
+	}
+	return warehouseLevel
+}
+
+func newLowStock(house model.MedicineHouse, level model.StockLevel, onHand int64) model.LowStock {
+	// refill up to the maximum when there is one, otherwise just back to the minimum
+	target := int64(level.MinQuantity)
+	if level.MaxQuantity != nil {
+		target = int64(*level.MaxQuantity)
+	}
+	return model.LowStock{
+		HouseID:         house.ID,
+		WarehouseID:     house.WarehouseID,
+		MedicationID:    house.MedicationID,
+		Locker:          house.Locker,
+		Floor:           house.Floor,
+		No:              house.No,
+		OnHand:          onHand,
+		MinQuantity:     level.MinQuantity,
+		MaxQuantity:     level.MaxQuantity,
+		ReorderQuantity: max(target-onHand, 0),
+		Scope:           level.Scope(),
+	}
+}
+
+func stockLevelTopic(warehouseID string) string {
+	return "STOCK_LEVEL:" + warehouseID
+}
+
+// confirmMovement resolves the second user confirming a movement of a flagged medicine
+func (s *stock) confirmMovement(ctx context.Context, house model.MedicineHouse, action model.ConfirmationAction, code string) (*uuid.UUID, error) {
+	flag, err := s.medicineRepository.GetMedicineFlag(ctx, house.MedicationID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, echo.NewHTTPError(http.StatusNotFound, echo.Map{"error": "medicationID is not found"})
+		}
+		return nil, echo.NewHTTPError(http.StatusInternalServerError, echo.Map{"error": err.Error()})
+	}
+
+	confirmedBy, err := confirmFlaggedMedicine(ctx, s.cacheRepository, s.warehouseRepository, model.ConfirmationScope{WarehouseID: house.WarehouseID, MedicationID: house.MedicationID, Action: action}, flag, code)
+	if err != nil {
+		logger.Context(ctx).Error(err)
+		return nil, err
+	}
+	return confirmedBy, nil
+}
@@ -0,0 +1,303 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"net/http"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+	genmodel "github.com/kinkando/pharma-sheet-service/.gen/pharma_sheet/public/model"
+	"github.com/kinkando/pharma-sheet-service/model"
+	"github.com/kinkando/pharma-sheet-service/pkg/google"
+	"github.com/kinkando/pharma-sheet-service/pkg/logger"
+	"github.com/kinkando/pharma-sheet-service/pkg/profile"
+	"github.com/kinkando/pharma-sheet-service/repository"
+	"github.com/labstack/echo/v4"
+)
+
+type Trash interface {
+	ListDeletedWarehouses(ctx context.Context) ([]model.DeletedWarehouse, error)
+	ListWarehouseTrash(ctx context.Context, warehouseID string) (model.WarehouseTrash, error)
+
+	RestoreWarehouse(ctx context.Context, warehouseID string) error
+	RestoreMedicine(ctx context.Context, medicationID string) error
+	RestoreMedicineBrand(ctx context.Context, id uuid.UUID) error
+	RestoreMedicineHouse(ctx context.Context, id uuid.UUID) error
+
+	PurgeTrash(ctx context.Context) (model.PurgedTrash, error)
+	SchedulePurge(ctx context.Context, interval time.Duration)
+}
+
+type trash struct {
+	trashRepository     repository.Trash
+	medicineRepository  repository.Medicine
+	warehouseRepository repository.Warehouse
+	storage             google.Drive
+	retentionDays       int32
+}
+
+func NewTrashService(
+	trashRepository repository.Trash,
+	medicineRepository repository.Medicine,
+	warehouseRepository repository.Warehouse,
+	storage google.Drive,
+	retentionDays int32,
+) Trash {
+	return &trash{
+		trashRepository:     trashRepository,
+		medicineRepository:  medicineRepository,
+		warehouseRepository: warehouseRepository,
+		storage:             storage,
+		retentionDays:       retentionDays,
+	}
+}
+
+func (s *trash) ListDeletedWarehouses(ctx context.Context) ([]model.DeletedWarehouse, error) {
+	userProfile, err := profile.UseProfile(ctx)
+	if err != nil {
+		return nil, echo.NewHTTPError(http.StatusUnauthorized, echo.Map{"error": err.Error()})
+	}
+
+	warehouses, err := s.trashRepository.ListDeletedWarehouses(ctx, userProfile.UserID)
+	if err != nil {
+		return nil, echo.NewHTTPError(http.StatusInternalServerError, echo.Map{"error": err.Error()})
+	}
+	for i := range warehouses {
+		warehouses[i].PurgeAt = s.purgeAt(warehouses[i].DeletedAt)
+	}
+	return warehouses, nil
+}
+
+func (s *trash) ListWarehouseTrash(ctx context.Context, warehouseID string) (model.WarehouseTrash, error) {
+	err := s.checkWarehouseManagementRole(ctx, warehouseID, genmodel.PharmaSheetRole_Admin, genmodel.PharmaSheetRole_Editor)
+	if err != nil {
+		logger.Context(ctx).Error(err)
+		return model.WarehouseTrash{}, err
+	}
+
+	data, err := s.trashRepository.ListWarehouseTrash(ctx, warehouseID)
+	if err != nil {
+		return model.WarehouseTrash{}, echo.NewHTTPError(http.StatusInternalServerError, echo.Map{"error": err.Error()})
+	}
+	for i := range data.Medicines {
+		data.Medicines[i].PurgeAt = s.purgeAt(data.Medicines[i].DeletedAt)
+	}
+	for i := range data.Brands {
+		data.Brands[i].PurgeAt = s.purgeAt(data.Brands[i].DeletedAt)
+	}
+	for i := range data.Houses {
+		data.Houses[i].PurgeAt = s.purgeAt(data.Houses[i].DeletedAt)
+	}
+	return data, nil
+}
+
+// RestoreWarehouse brings a warehouse back from the trash, only an admin of the warehouse may do it
+func (s *trash) RestoreWarehouse(ctx context.Context, warehouseID string) error {
+	warehouses, err := s.ListDeletedWarehouses(ctx)
+	if err != nil {
+		return err
+	}
+	index := slices.IndexFunc(warehouses, func(warehouse model.DeletedWarehouse) bool { return warehouse.WarehouseID == warehouseID })
+	if index < 0 {
+		return echo.NewHTTPError(http.StatusNotFound, echo.Map{"error": "warehouseID is not found in the trash"})
+	}
+	if warehouses[index].Role != string(genmodel.PharmaSheetRole_Admin) {
+		return echo.NewHTTPError(http.StatusForbidden, echo.Map{"error": "permission denied"})
+	}
+
+	rowsAffected, err := s.trashRepository.RestoreWarehouse(ctx, warehouseID)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, echo.Map{"error": err.Error()})
+	}
+	if rowsAffected == 0 {
+		return echo.NewHTTPError(http.StatusNotFound, echo.Map{"error": "warehouseID is not found in the trash"})
+	}
+	return nil
+}
+
+func (s *trash) RestoreMedicine(ctx context.Context, medicationID string) error {
+	err := s.checkMedicineManagementRole(ctx, medicationID, genmodel.PharmaSheetRole_Admin, genmodel.PharmaSheetRole_Editor)
+	if err != nil {
+		logger.Context(ctx).Error(err)
+		return err
+	}
+
+	rowsAffected, err := s.trashRepository.RestoreMedicine(ctx, medicationID)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, echo.Map{"error": err.Error()})
+	}
+	if rowsAffected == 0 {
+		return echo.NewHTTPError(http.StatusNotFound, echo.Map{"error": "medicationID is not found in the trash"})
+	}
+	return nil
+}
+
+// RestoreMedicineBrand brings a brand back from the trash, its medicine has to be restored first
+func (s *trash) RestoreMedicineBrand(ctx context.Context, id uuid.UUID) error {
+	brand, err := s.trashRepository.GetDeletedMedicineBrand(ctx, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return echo.NewHTTPError(http.StatusNotFound, echo.Map{"error": "brandID is not found in the trash"})
+		}
+		return echo.NewHTTPError(http.StatusInternalServerError, echo.Map{"error": err.Error()})
+	}
+
+	if err = s.checkMedicineActive(ctx, brand.MedicationID); err != nil {
+		return err
+	}
+	err = s.checkMedicineManagementRole(ctx, brand.MedicationID, genmodel.PharmaSheetRole_Admin, genmodel.PharmaSheetRole_Editor)
+	if err != nil {
+		logger.Context(ctx).Error(err)
+		return err
+	}
+
+	rowsAffected, err := s.trashRepository.RestoreMedicineBrand(ctx, id)
+	if err != nil {
+		if model.IsConflictError(err) {
+			return echo.NewHTTPError(http.StatusConflict, echo.Map{"error": "another brand already has the tradeID or barcode of the brand"})
+		}
+		return echo.NewHTTPError(http.StatusInternalServerError, echo.Map{"error": err.Error()})
+	}
+	if rowsAffected == 0 {
+		return echo.NewHTTPError(http.StatusNotFound, echo.Map{"error": "brandID is not found in the trash"})
+	}
+	return nil
+}
+
+// RestoreMedicineHouse brings a house back from the trash, its medicine and warehouse have to be restored first
+func (s *trash) RestoreMedicineHouse(ctx context.Context, id uuid.UUID) error {
+	house, err := s.trashRepository.GetDeletedMedicineHouse(ctx, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return echo.NewHTTPError(http.StatusNotFound, echo.Map{"error": "houseID is not found in the trash"})
+		}
+		return echo.NewHTTPError(http.StatusInternalServerError, echo.Map{"error": err.Error()})
+	}
+
+	if err = s.checkMedicineActive(ctx, house.MedicationID); err != nil {
+		return err
+	}
+	roles, err := houseManagementRoles(ctx, s.medicineRepository, house.MedicationID)
+	if err != nil {
+		return err
+	}
+	err = s.checkWarehouseManagementRole(ctx, house.WarehouseID, roles...)
+	if err != nil {
+		logger.Context(ctx).Error(err)
+		return err
+	}
+
+	rowsAffected, err := s.trashRepository.RestoreMedicineHouse(ctx, id)
+	if err != nil {
+		if model.IsConflictError(err) {
+			return echo.NewHTTPError(http.StatusConflict, echo.Map{"error": "another house of the medicine already takes the address"})
+		}
+		return echo.NewHTTPError(http.StatusInternalServerError, echo.Map{"error": err.Error()})
+	}
+	if rowsAffected == 0 {
+		return echo.NewHTTPError(http.StatusNotFound, echo.Map{"error": "houseID is not found in the trash"})
+	}
+	return nil
+}
+
+// PurgeTrash permanently deletes what has been in the trash longer than the retention period, along with the brand images
+func (s *trash) PurgeTrash(ctx context.Context) (model.PurgedTrash, error) {
+	before := time.Now().AddDate(0, 0, -int(s.retentionDays))
+	purged, err := s.trashRepository.PurgeTrash(ctx, before)
+	if err != nil {
+		return model.PurgedTrash{}, err
+	}
+
+	for _, url := range purged.ImageURLs {
+		if err = s.storage.Delete(ctx, url); err != nil {
+			logger.Context(ctx).Warn(err)
+		}
+	}
+	return purged, nil
+}
+
+// SchedulePurge purges the trash every interval until the context is done
+func (s *trash) SchedulePurge(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			purged, err := s.PurgeTrash(ctx)
+			if err != nil {
+				logger.Context(ctx).Error(err)
+				continue
+			}
+			if purged.Warehouses+purged.Medicines+purged.Brands+purged.Houses > 0 {
+				logger.Context(ctx).Infof("trash: purged %d warehouses, %d medicines, %d brands and %d houses",
+					purged.Warehouses, purged.Medicines, purged.Brands, purged.Houses)
+			}
+		}
+	}
+}
+
+func (s *trash) purgeAt(deletedAt time.Time) time.Time {
+	return deletedAt.AddDate(0, 0, int(s.retentionDays))
+}
+
+// checkMedicineActive rejects the restore of what belongs to a medicine which is still in the trash
+func (s *trash) checkMedicineActive(ctx context.Context, medicationID string) error {
+	if _, err := s.medicineRepository.GetMedicineFlag(ctx, medicationID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return echo.NewHTTPError(http.StatusConflict, echo.Map{"error": "medicine is in the trash, restore it first"})
+		}
+		return echo.NewHTTPError(http.StatusInternalServerError, echo.Map{"error": err.Error()})
+	}
+	return nil
+}
+
+func (s *trash) checkMedicineManagementRole(ctx context.Context, medicationID string, roles ...genmodel.PharmaSheetRole) (err error) {
+	userProfile, err := profile.UseProfile(ctx)
+	if err != nil {
+		return
+	}
+
+	role, err := s.medicineRepository.GetMedicineRole(ctx, medicationID, userProfile.UserID)
+	if err != nil {
+		logger.Context(ctx).Error(err)
+		if errors.Is(err, model.ErrResourceNotAllowed) {
+			return echo.NewHTTPError(http.StatusLocked, echo.Map{"error": err.Error()})
+		}
+		return echo.NewHTTPError(http.StatusInternalServerError, echo.Map{"error": err.Error()})
+	}
+
+	if !slices.Contains(roles, role) {
+		return echo.NewHTTPError(http.StatusForbidden, echo.Map{"error": "permission denied"})
+	}
+
+	return nil
+}
+
+func (s *trash) checkWarehouseManagementRole(ctx context.Context, warehouseID string, roles ...genmodel.PharmaSheetRole) (err error) {
+	userProfile, err := profile.UseProfile(ctx)
+	if err != nil {
+		return
+	}
+
+	role, err := s.warehouseRepository.GetWarehouseRole(ctx, warehouseID, userProfile.UserID)
+	if err != nil {
+		logger.Context(ctx).Error(err)
+		if errors.Is(err, sql.ErrNoRows) {
+			// a warehouse in the trash has no roles until it is restored
+			return echo.NewHTTPError(http.StatusForbidden, echo.Map{"error": "permission denied"})
+		}
+		return echo.NewHTTPError(http.StatusInternalServerError, echo.Map{"error": err.Error()})
+	}
+
+	if !slices.Contains(roles, role) {
+		return echo.NewHTTPError(http.StatusForbidden, echo.Map{"error": "permission denied"})
+	}
+
+	return nil
+}
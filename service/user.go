@@ -2,6 +2,7 @@ package service
 
 import (
 	"context"
+	"net/http"
 
 	"firebase.google.com/go/auth"
 	"github.com/google/uuid"
@@ -11,6 +12,7 @@ import (
 	"github.com/kinkando/pharma-sheet-service/pkg/logger"
 	"github.com/kinkando/pharma-sheet-service/pkg/profile"
 	"github.com/kinkando/pharma-sheet-service/repository"
+	"github.com/labstack/echo/v4"
 )
 
 type User interface {
@@ -110,3 +112,22 @@ func (s *user) UpdateUserInfo(ctx context.Context, req model.UpdateUserRequest)
 
 	return nil
 }
+
+// checkSystemAdminRole guards the master data shared by every warehouse, a warehouse admin role is not enough
+// since any user becomes the admin of a warehouse they create
+func checkSystemAdminRole(ctx context.Context, userRepository repository.User) error {
+	userProfile, err := profile.UseProfile(ctx)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusUnauthorized, echo.Map{"error": err.Error()})
+	}
+
+	ok, err := userRepository.IsSystemAdmin(ctx, userProfile.UserID)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, echo.Map{"error": err.Error()})
+	}
+	if !ok {
+		return echo.NewHTTPError(http.StatusForbidden, echo.Map{"error": "system admin role is required"})
+	}
+
+	return nil
+}
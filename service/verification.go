@@ -0,0 +1,135 @@
+package service
+
+import (
+	"context"
+	"net/http"
+	"slices"
+	"strings"
+
+	genmodel "github.com/kinkando/pharma-sheet-service/.gen/pharma_sheet/public/model"
+	"github.com/kinkando/pharma-sheet-service/model"
+	"github.com/kinkando/pharma-sheet-service/pkg/logger"
+	"github.com/kinkando/pharma-sheet-service/pkg/profile"
+	"github.com/kinkando/pharma-sheet-service/repository"
+	"github.com/labstack/echo/v4"
+)
+
+type Verification interface {
+	VerifyPick(ctx context.Context, req model.VerifyPickRequest) (model.PickVerification, error)
+	ListPickVerifications(ctx context.Context, filter model.FilterPickVerification) (model.PagingWithMetadata[model.PickVerification], error)
+}
+
+type verification struct {
+	verificationRepository repository.Verification
+	medicineRepository     repository.Medicine
+	warehouseRepository    repository.Warehouse
+}
+
+func NewVerificationService(
+	verificationRepository repository.Verification,
+	medicineRepository repository.Medicine,
+	warehouseRepository repository.Warehouse,
+) Verification {
+	return &verification{
+		verificationRepository: verificationRepository,
+		medicineRepository:     medicineRepository,
+		warehouseRepository:    warehouseRepository,
+	}
+}
+
+// VerifyPick checks the product scanned after a house is a brand of the medicine kept in that house,
+// every verification is recorded whatever the result is.
+func (s *verification) VerifyPick(ctx context.Context, req model.VerifyPickRequest) (res model.PickVerification, err error) {
+	houses, err := s.medicineRepository.GetMedicineHouses(ctx, model.FilterMedicineHouse{ID: req.HouseID})
+	if err != nil {
+		logger.Context(ctx).Error(err)
+		return res, echo.NewHTTPError(http.StatusInternalServerError, echo.Map{"error": err.Error()})
+	}
+	if len(houses) == 0 {
+		logger.Context(ctx).Errorf("houseID %s is not found", req.HouseID.String())
+		return res, echo.NewHTTPError(http.StatusNotFound, echo.Map{"error": "houseID is not found"})
+	}
+	house := houses[0]
+
+	err = s.checkWarehouseManagementRole(ctx, house.WarehouseID, genmodel.PharmaSheetRole_Admin, genmodel.PharmaSheetRole_Editor, genmodel.PharmaSheetRole_Viewer)
+	if err != nil {
+		logger.Context(ctx).Error(err)
+		return res, err
+	}
+
+	productCode := strings.TrimSpace(req.ProductCode)
+	brands, _, err := findMedicineBrands(ctx, s.medicineRepository, productCode)
+	if err != nil {
+		return res, err
+	}
+
+	res = model.PickVerification{
+		WarehouseID:  house.WarehouseID,
+		HouseID:      &house.ID,
+		MedicationID: house.MedicationID,
+		Address:      house.Address(),
+		ProductCode:  productCode,
+		Result:       genmodel.PharmaSheetPickVerificationResult_Unknown,
+	}
+	if len(brands) > 0 {
+		brand := brands[0]
+		res.Result = genmodel.PharmaSheetPickVerificationResult_Mismatch
+		if index := slices.IndexFunc(brands, func(brand model.MedicineBrand) bool { return brand.MedicationID == house.MedicationID }); index >= 0 {
+			brand = brands[index]
+			res.Result = genmodel.PharmaSheetPickVerificationResult_Match
+		}
+		res.BrandID = &brand.ID
+		res.ScannedMedicationID = &brand.MedicationID
+		res.TradeID = &brand.TradeID
+		res.TradeName = brand.TradeName
+	}
+
+	verification, err := s.verificationRepository.CreatePickVerification(ctx, res)
+	if err != nil {
+		return res, echo.NewHTTPError(http.StatusInternalServerError, echo.Map{"error": err.Error()})
+	}
+	res.ID = verification.ID
+	res.CreatedBy = verification.CreatedBy
+	res.CreatedAt = verification.CreatedAt
+
+	if res.Result != genmodel.PharmaSheetPickVerificationResult_Match {
+		logger.Context(ctx).Warnf("pick verification %s of house %s is %s", res.ID.String(), house.ID.String(), res.Result)
+	}
+
+	return res, nil
+}
+
+func (s *verification) ListPickVerifications(ctx context.Context, filter model.FilterPickVerification) (res model.PagingWithMetadata[model.PickVerification], err error) {
+	err = s.checkWarehouseManagementRole(ctx, filter.WarehouseID, genmodel.PharmaSheetRole_Admin, genmodel.PharmaSheetRole_Editor)
+	if err != nil {
+		logger.Context(ctx).Error(err)
+		return res, err
+	}
+
+	data, total, err := s.verificationRepository.ListPickVerifications(ctx, filter)
+	if err != nil {
+		return res, echo.NewHTTPError(http.StatusInternalServerError, echo.Map{"error": err.Error()})
+	}
+
+	res = model.PaginationResponse(data, filter.Pagination, total)
+	return res, nil
+}
+
+func (s *verification) checkWarehouseManagementRole(ctx context.Context, warehouseID string, roles ...genmodel.PharmaSheetRole) (err error) {
+	userProfile, err := profile.UseProfile(ctx)
+	if err != nil {
+		return
+	}
+
+	role, err := s.warehouseRepository.GetWarehouseRole(ctx, warehouseID, userProfile.UserID)
+	if err != nil {
+		logger.Context(ctx).Error(err)
+		return echo.NewHTTPError(http.StatusInternalServerError, echo.Map{"error": err.Error()})
+	}
+
+	if !slices.Contains(roles, role) {
+		return echo.NewHTTPError(http.StatusForbidden, echo.Map{"error": "permission denied"})
+	}
+
+	return nil
+}
@@ -1,11 +1,14 @@
 package service
 
 import (
+	"cmp"
 	"context"
 	"database/sql"
 	"errors"
+	"fmt"
 	"net/http"
 	"slices"
+	"strings"
 
 	"github.com/google/uuid"
 	genmodel "github.com/kinkando/pharma-sheet-service/.gen/pharma_sheet/public/model"
@@ -13,6 +16,7 @@ import (
 	"github.com/kinkando/pharma-sheet-service/pkg/google"
 	"github.com/kinkando/pharma-sheet-service/pkg/logger"
 	"github.com/kinkando/pharma-sheet-service/pkg/profile"
+	"github.com/kinkando/pharma-sheet-service/pkg/util"
 	"github.com/kinkando/pharma-sheet-service/repository"
 	"github.com/labstack/echo/v4"
 	"github.com/sourcegraph/conc/pool"
@@ -36,12 +40,20 @@ type Warehouse interface {
 	LeaveWarehouse(ctx context.Context, warehouseID, userID string) error
 	ApproveUser(ctx context.Context, req model.ApprovalWarehouseUserRequest) error
 	RejectUser(ctx context.Context, req model.ApprovalWarehouseUserRequest) error
+
+	GetWarehouseLayout(ctx context.Context, warehouseID string) (model.WarehouseLayout, error)
+	UpsertWarehouseLocker(ctx context.Context, req model.UpsertWarehouseLockerRequest) (string, error)
+	DeleteWarehouseLocker(ctx context.Context, req model.DeleteWarehouseLockerRequest) error
+	PreviewRelocation(ctx context.Context, req model.RelocateMedicineHouseRequest) (model.RelocationPreview, error)
+	RelocateMedicineHouses(ctx context.Context, req model.RelocateMedicineHouseRequest) (model.RelocationPreview, error)
 }
 
 type warehouse struct {
 	warehouseRepository repository.Warehouse
 	userRepository      repository.User
 	medicineRepository  repository.Medicine
+	categoryRepository  repository.Category
+	auditRepository     repository.Audit
 	storage             google.Storage
 }
 
@@ -49,12 +61,16 @@ func NewWarehouseService(
 	warehouseRepository repository.Warehouse,
 	userRepository repository.User,
 	medicineRepository repository.Medicine,
+	categoryRepository repository.Category,
+	auditRepository repository.Audit,
 	storage google.Storage,
 ) Warehouse {
 	return &warehouse{
 		warehouseRepository: warehouseRepository,
 		userRepository:      userRepository,
 		medicineRepository:  medicineRepository,
+		categoryRepository:  categoryRepository,
+		auditRepository:     auditRepository,
 		storage:             storage,
 	}
 }
@@ -74,6 +90,11 @@ func (s *warehouse) GetWarehouseDetails(ctx context.Context, filter model.Filter
 		return res, err
 	}
 
+	categories, err := s.categoryRepository.ListCategories(ctx)
+	if err != nil {
+		return res, err
+	}
+
 	conc := pool.New().WithContext(ctx).WithMaxGoroutines(5).WithCancelOnError().WithFirstError()
 	for index, warehouse := range data {
 		index, warehouse := index, warehouse
@@ -84,6 +105,12 @@ func (s *warehouse) GetWarehouseDetails(ctx context.Context, filter model.Filter
 			}
 			data[index].TotalMedicine = uint64(len(medicineHouses))
 
+			medicineCategories, err := s.categoryRepository.ListMedicineCategories(ctx, model.FilterCategoryMedicine{WarehouseID: warehouse.WarehouseID})
+			if err != nil {
+				return err
+			}
+			data[index].Categories = model.CountCategoryMedicines(categories, medicineCategories)
+
 			if filter.Group != model.MyWarehouse {
 				result, err := s.GetWarehouseUsers(ctx, warehouse.WarehouseID, model.FilterWarehouseUser{
 					Pagination: model.Pagination{Page: 1, Limit: 9999},
@@ -108,10 +135,33 @@ func (s *warehouse) GetWarehouseDetails(ctx context.Context, filter model.Filter
 }
 
 func (s *warehouse) CreateWarehouse(ctx context.Context, req model.CreateWarehouseRequest) (string, error) {
-	return s.warehouseRepository.CreateWarehouse(ctx, model.Warehouse{
+	warehouseID, err := s.warehouseRepository.CreateWarehouse(ctx, model.Warehouse{
 		Name:        req.WarehouseName,
 		WarehouseID: req.WarehouseID,
 	})
+	if err != nil {
+		if !model.IsConflictError(err) {
+			return "", echo.NewHTTPError(http.StatusInternalServerError, echo.Map{"error": err.Error()})
+		}
+		// a warehouse in the trash keeps its id until it is purged
+		isDeleted, err := s.warehouseRepository.IsWarehouseDeleted(ctx, req.WarehouseID)
+		if err != nil {
+			return "", echo.NewHTTPError(http.StatusInternalServerError, echo.Map{"error": err.Error()})
+		}
+		if isDeleted {
+			return "", echo.NewHTTPError(http.StatusConflict, echo.Map{"error": "warehouseID is in the trash, restore it from the trash instead"})
+		}
+		return "", echo.NewHTTPError(http.StatusConflict, echo.Map{"error": "warehouseID already exists"})
+	}
+
+	recordAudit(ctx, s.auditRepository, model.CreateAuditLog{
+		Action:      genmodel.PharmaSheetAuditAction_Create,
+		EntityType:  model.AuditEntityWarehouse,
+		EntityID:    warehouseID,
+		WarehouseID: &warehouseID,
+		After:       warehouseSnapshot(ctx, s.warehouseRepository, warehouseID),
+	})
+	return warehouseID, nil
 }
 
 func (s *warehouse) UpdateWarehouse(ctx context.Context, req model.UpdateWarehouseRequest) error {
@@ -120,6 +170,8 @@ func (s *warehouse) UpdateWarehouse(ctx context.Context, req model.UpdateWarehou
 		logger.Context(ctx).Error(err)
 		return err
 	}
+
+	before := warehouseSnapshot(ctx, s.warehouseRepository, req.WarehouseID)
 	err = s.warehouseRepository.UpdateWarehouse(ctx, model.Warehouse{
 		WarehouseID: req.WarehouseID,
 		Name:        req.WarehouseName,
@@ -127,6 +179,15 @@ func (s *warehouse) UpdateWarehouse(ctx context.Context, req model.UpdateWarehou
 	if err != nil {
 		return echo.NewHTTPError(http.StatusInternalServerError, echo.Map{"error": err.Error()})
 	}
+
+	recordAudit(ctx, s.auditRepository, model.CreateAuditLog{
+		Action:      genmodel.PharmaSheetAuditAction_Update,
+		EntityType:  model.AuditEntityWarehouse,
+		EntityID:    req.WarehouseID,
+		WarehouseID: &req.WarehouseID,
+		Before:      before,
+		After:       warehouseSnapshot(ctx, s.warehouseRepository, req.WarehouseID),
+	})
 	return nil
 }
 
@@ -139,6 +200,7 @@ func (s *warehouse) DeleteWarehouse(ctx context.Context, warehouseID string, byp
 		}
 	}
 
+	before := warehouseSnapshot(ctx, s.warehouseRepository, warehouseID)
 	err := s.warehouseRepository.DeleteWarehouse(ctx, warehouseID)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
@@ -147,6 +209,13 @@ func (s *warehouse) DeleteWarehouse(ctx context.Context, warehouseID string, byp
 		return echo.NewHTTPError(http.StatusInternalServerError, echo.Map{"error": err.Error()})
 	}
 
+	recordAudit(ctx, s.auditRepository, model.CreateAuditLog{
+		Action:      genmodel.PharmaSheetAuditAction_Delete,
+		EntityType:  model.AuditEntityWarehouse,
+		EntityID:    warehouseID,
+		WarehouseID: &warehouseID,
+		Before:      before,
+	})
 	return nil
 }
 
@@ -233,6 +302,14 @@ func (s *warehouse) CreateWarehouseUser(ctx context.Context, req model.CreateWar
 		logger.Context(ctx).Error(err)
 		return echo.NewHTTPError(http.StatusInternalServerError, echo.Map{"error": err.Error()})
 	}
+
+	recordAudit(ctx, s.auditRepository, model.CreateAuditLog{
+		Action:      genmodel.PharmaSheetAuditAction_Create,
+		EntityType:  model.AuditEntityWarehouseUser,
+		EntityID:    user.UserID.String(),
+		WarehouseID: &req.WarehouseID,
+		After:       warehouseUserSnapshot(ctx, s.warehouseRepository, req.WarehouseID, user.UserID.String()),
+	})
 	return nil
 }
 
@@ -252,6 +329,7 @@ func (s *warehouse) UpdateWarehouseUser(ctx context.Context, req model.UpdateWar
 		return echo.NewHTTPError(http.StatusBadRequest, echo.Map{"error": "grant yourself is not allowed"})
 	}
 
+	before := warehouseUserSnapshot(ctx, s.warehouseRepository, req.WarehouseID, req.UserID)
 	err = s.warehouseRepository.UpdateWarehouseUser(ctx, genmodel.PharmaSheetWarehouseUsers{
 		WarehouseID: req.WarehouseID,
 		UserID:      uuid.MustParse(req.UserID),
@@ -261,6 +339,15 @@ func (s *warehouse) UpdateWarehouseUser(ctx context.Context, req model.UpdateWar
 		logger.Context(ctx).Error(err)
 		return echo.NewHTTPError(http.StatusInternalServerError, echo.Map{"error": err.Error()})
 	}
+
+	recordAudit(ctx, s.auditRepository, model.CreateAuditLog{
+		Action:      genmodel.PharmaSheetAuditAction_Update,
+		EntityType:  model.AuditEntityWarehouseUser,
+		EntityID:    req.UserID,
+		WarehouseID: &req.WarehouseID,
+		Before:      before,
+		After:       warehouseUserSnapshot(ctx, s.warehouseRepository, req.WarehouseID, req.UserID),
+	})
 	return nil
 }
 
@@ -280,28 +367,70 @@ func (s *warehouse) DeleteWarehouseUser(ctx context.Context, req model.DeleteWar
 		return echo.NewHTTPError(http.StatusBadRequest, echo.Map{"error": "delete yourself is not allowed"})
 	}
 
+	before := warehouseUserSnapshot(ctx, s.warehouseRepository, req.WarehouseID, req.UserID)
 	err = s.warehouseRepository.DeleteWarehouseUser(ctx, req.WarehouseID, &req.UserID)
 	if err != nil {
 		logger.Context(ctx).Error(err)
 		return echo.NewHTTPError(http.StatusInternalServerError, echo.Map{"error": err.Error()})
 	}
+
+	recordAudit(ctx, s.auditRepository, model.CreateAuditLog{
+		Action:      genmodel.PharmaSheetAuditAction_Delete,
+		EntityType:  model.AuditEntityWarehouseUser,
+		EntityID:    req.UserID,
+		WarehouseID: &req.WarehouseID,
+		Before:      before,
+	})
 	return nil
 }
 
 func (s *warehouse) JoinWarehouse(ctx context.Context, warehouseID, userID string) error {
-	return s.warehouseRepository.CreateWarehouseUser(ctx, warehouseID, userID, genmodel.PharmaSheetRole_Viewer, genmodel.PharmaSheetApprovalStatus_Pending)
+	err := s.warehouseRepository.CreateWarehouseUser(ctx, warehouseID, userID, genmodel.PharmaSheetRole_Viewer, genmodel.PharmaSheetApprovalStatus_Pending)
+	if err != nil {
+		return err
+	}
+
+	recordAudit(ctx, s.auditRepository, model.CreateAuditLog{
+		Action:      genmodel.PharmaSheetAuditAction_Create,
+		EntityType:  model.AuditEntityWarehouseUser,
+		EntityID:    userID,
+		WarehouseID: &warehouseID,
+		After:       warehouseUserSnapshot(ctx, s.warehouseRepository, warehouseID, userID),
+	})
+	return nil
 }
 
 func (s *warehouse) CancelJoinWarehouse(ctx context.Context, warehouseID, userID string) error {
-	return s.warehouseRepository.DeleteWarehouseUser(ctx, warehouseID, &userID)
+	before := warehouseUserSnapshot(ctx, s.warehouseRepository, warehouseID, userID)
+	err := s.warehouseRepository.DeleteWarehouseUser(ctx, warehouseID, &userID)
+	if err != nil {
+		return err
+	}
+
+	recordAudit(ctx, s.auditRepository, model.CreateAuditLog{
+		Action:      genmodel.PharmaSheetAuditAction_Delete,
+		EntityType:  model.AuditEntityWarehouseUser,
+		EntityID:    userID,
+		WarehouseID: &warehouseID,
+		Before:      before,
+	})
+	return nil
 }
 
 func (s *warehouse) LeaveWarehouse(ctx context.Context, warehouseID, userID string) error {
+	before := warehouseUserSnapshot(ctx, s.warehouseRepository, warehouseID, userID)
 	err := s.warehouseRepository.DeleteWarehouseUser(ctx, warehouseID, &userID)
 	if err != nil {
 		logger.Context(ctx).Error(err)
 		return err
 	}
+	recordAudit(ctx, s.auditRepository, model.CreateAuditLog{
+		Action:      genmodel.PharmaSheetAuditAction_Delete,
+		EntityType:  model.AuditEntityWarehouseUser,
+		EntityID:    userID,
+		WarehouseID: &warehouseID,
+		Before:      before,
+	})
 
 	_, total, err := s.warehouseRepository.GetWarehouseUsers(ctx, warehouseID, model.FilterWarehouseUser{
 		Pagination: model.Pagination{Page: 1, Limit: 1},
@@ -348,6 +477,7 @@ func (s *warehouse) ApproveUser(ctx context.Context, req model.ApprovalWarehouse
 		return echo.NewHTTPError(http.StatusBadRequest, echo.Map{"error": "status is not pending"})
 	}
 
+	before := warehouseUserSnapshot(ctx, s.warehouseRepository, req.WarehouseID, req.UserID)
 	err = s.warehouseRepository.UpdateWarehouseUser(ctx, genmodel.PharmaSheetWarehouseUsers{
 		WarehouseID: req.WarehouseID,
 		UserID:      uuid.MustParse(req.UserID),
@@ -357,6 +487,14 @@ func (s *warehouse) ApproveUser(ctx context.Context, req model.ApprovalWarehouse
 		return echo.NewHTTPError(http.StatusInternalServerError, echo.Map{"error": err.Error()})
 	}
 
+	recordAudit(ctx, s.auditRepository, model.CreateAuditLog{
+		Action:      genmodel.PharmaSheetAuditAction_Update,
+		EntityType:  model.AuditEntityWarehouseUser,
+		EntityID:    req.UserID,
+		WarehouseID: &req.WarehouseID,
+		Before:      before,
+		After:       warehouseUserSnapshot(ctx, s.warehouseRepository, req.WarehouseID, req.UserID),
+	})
 	return nil
 }
 
@@ -388,10 +526,369 @@ func (s *warehouse) RejectUser(ctx context.Context, req model.ApprovalWarehouseU
 		return echo.NewHTTPError(http.StatusBadRequest, echo.Map{"error": "status is not pending"})
 	}
 
+	before := warehouseUserSnapshot(ctx, s.warehouseRepository, req.WarehouseID, req.UserID)
 	err = s.warehouseRepository.DeleteWarehouseUser(ctx, req.WarehouseID, &req.UserID)
 	if err != nil {
 		return echo.NewHTTPError(http.StatusInternalServerError, echo.Map{"error": err.Error()})
 	}
 
+	recordAudit(ctx, s.auditRepository, model.CreateAuditLog{
+		Action:      genmodel.PharmaSheetAuditAction_Delete,
+		EntityType:  model.AuditEntityWarehouseUser,
+		EntityID:    req.UserID,
+		WarehouseID: &req.WarehouseID,
+		Before:      before,
+	})
+	return nil
+}
+
+func (s *warehouse) GetWarehouseLayout(ctx context.Context, warehouseID string) (layout model.WarehouseLayout, err error) {
+	err = s.checkWarehouseManagementRole(ctx, warehouseID, genmodel.PharmaSheetRole_Admin, genmodel.PharmaSheetRole_Editor, genmodel.PharmaSheetRole_Viewer)
+	if err != nil {
+		logger.Context(ctx).Error(err)
+		return layout, err
+	}
+
+	lockers, err := s.warehouseRepository.ListWarehouseLockers(ctx, warehouseID)
+	if err != nil {
+		return layout, echo.NewHTTPError(http.StatusInternalServerError, echo.Map{"error": err.Error()})
+	}
+	houses, err := s.medicineRepository.GetMedicineHouses(ctx, model.FilterMedicineHouse{WarehouseID: warehouseID})
+	if err != nil {
+		return layout, echo.NewHTTPError(http.StatusInternalServerError, echo.Map{"error": err.Error()})
+	}
+
+	housesByAddress := make(map[string][]model.LayoutHouse)
+	for _, house := range houses {
+		housesByAddress[house.Address()] = append(housesByAddress[house.Address()], model.LayoutHouse{
+			ID:           house.ID,
+			MedicationID: house.MedicationID,
+			Locker:       house.Locker,
+			Floor:        house.Floor,
+			No:           house.No,
+			Label:        house.Label,
+		})
+	}
+
+	layout = model.WarehouseLayout{
+		WarehouseID:    warehouseID,
+		Lockers:        make([]model.LockerOccupancy, 0, len(lockers)),
+		UnplacedHouses: make([]model.LayoutHouse, 0),
+	}
+	for _, locker := range lockers {
+		occupancy := model.LockerOccupancy{
+			WarehouseLocker: locker,
+			Floors:          make([]model.FloorOccupancy, 0, locker.Floors),
+		}
+		for floor := int32(1); floor <= locker.Floors; floor++ {
+			floorOccupancy := model.FloorOccupancy{
+				Floor: floor,
+				Slots: make([]model.SlotOccupancy, 0, locker.SlotsPerFloor),
+			}
+			for no := int32(1); no <= locker.SlotsPerFloor; no++ {
+				address := model.MedicineHouse{Locker: locker.Name, Floor: floor, No: no}.Address()
+				slot := model.SlotOccupancy{
+					No:     no,
+					Houses: housesByAddress[address],
+				}
+				delete(housesByAddress, address)
+				if slot.Houses == nil {
+					slot.Houses = []model.LayoutHouse{}
+					slot.IsFree = true
+					occupancy.FreeSlots++
+				}
+				floorOccupancy.Slots = append(floorOccupancy.Slots, slot)
+			}
+			occupancy.Floors = append(occupancy.Floors, floorOccupancy)
+		}
+		layout.TotalSlots += locker.TotalSlots()
+		layout.FreeSlots += occupancy.FreeSlots
+		layout.Lockers = append(layout.Lockers, occupancy)
+	}
+
+	// houses left over do not fit in any locker, they were placed before the layout or by a typo
+	for _, houses := range housesByAddress {
+		layout.UnplacedHouses = append(layout.UnplacedHouses, houses...)
+	}
+	slices.SortFunc(layout.UnplacedHouses, func(a, b model.LayoutHouse) int {
+		return cmp.Or(
+			cmp.Compare(a.Locker, b.Locker),
+			cmp.Compare(a.Floor, b.Floor),
+			cmp.Compare(a.No, b.No),
+			cmp.Compare(a.MedicationID, b.MedicationID),
+		)
+	})
+
+	return layout, nil
+}
+
+func (s *warehouse) UpsertWarehouseLocker(ctx context.Context, req model.UpsertWarehouseLockerRequest) (string, error) {
+	err := s.checkWarehouseManagementRole(ctx, req.WarehouseID, genmodel.PharmaSheetRole_Admin, genmodel.PharmaSheetRole_Editor)
+	if err != nil {
+		logger.Context(ctx).Error(err)
+		return "", err
+	}
+
+	locker := model.WarehouseLocker{
+		WarehouseID:   req.WarehouseID,
+		Name:          strings.TrimSpace(req.Name),
+		Floors:        req.Floors,
+		SlotsPerFloor: req.SlotsPerFloor,
+	}
+	if locker.Name == "" {
+		return "", echo.NewHTTPError(http.StatusBadRequest, echo.Map{"error": "name is required"})
+	}
+
+	// shrinking a locker must not leave any of its houses outside
+	houses, err := s.medicineRepository.GetMedicineHouses(ctx, model.FilterMedicineHouse{WarehouseID: req.WarehouseID})
+	if err != nil {
+		return "", echo.NewHTTPError(http.StatusInternalServerError, echo.Map{"error": err.Error()})
+	}
+	var medicationIDs []string
+	for _, house := range houses {
+		if house.Locker != locker.Name {
+			continue
+		}
+		if !locker.Contains(house.Floor, house.No) {
+			return "", echo.NewHTTPError(http.StatusConflict, echo.Map{"error": fmt.Sprintf("house %s of medicine %s would be outside the locker", house.Address(), house.MedicationID)})
+		}
+		medicationIDs = append(medicationIDs, house.MedicationID)
+	}
+
+	// resizing a locker holding a flagged medicine changes its houses, so it needs an admin
+	roles, err := houseManagementRoles(ctx, s.medicineRepository, medicationIDs...)
+	if err != nil {
+		return "", err
+	}
+	if err = s.checkWarehouseManagementRole(ctx, req.WarehouseID, roles...); err != nil {
+		logger.Context(ctx).Error(err)
+		return "", err
+	}
+
+	lockers, err := s.warehouseRepository.ListWarehouseLockers(ctx, req.WarehouseID)
+	if err != nil {
+		return "", echo.NewHTTPError(http.StatusInternalServerError, echo.Map{"error": err.Error()})
+	}
+	auditLog := model.CreateAuditLog{Action: genmodel.PharmaSheetAuditAction_Create, EntityType: model.AuditEntityWarehouseLocker, WarehouseID: &req.WarehouseID}
+	if index := slices.IndexFunc(lockers, func(existing model.WarehouseLocker) bool { return existing.Name == locker.Name }); index >= 0 {
+		auditLog.Action, auditLog.Before = genmodel.PharmaSheetAuditAction_Update, lockers[index]
+	}
+
+	id, err := s.warehouseRepository.UpsertWarehouseLocker(ctx, locker)
+	if err != nil {
+		return "", echo.NewHTTPError(http.StatusInternalServerError, echo.Map{"error": err.Error()})
+	}
+
+	locker.ID = uuid.MustParse(id)
+	auditLog.EntityID, auditLog.After = id, locker
+	recordAudit(ctx, s.auditRepository, auditLog)
+	return id, nil
+}
+
+func (s *warehouse) DeleteWarehouseLocker(ctx context.Context, req model.DeleteWarehouseLockerRequest) error {
+	err := s.checkWarehouseManagementRole(ctx, req.WarehouseID, genmodel.PharmaSheetRole_Admin)
+	if err != nil {
+		logger.Context(ctx).Error(err)
+		return err
+	}
+
+	lockers, err := s.warehouseRepository.ListWarehouseLockers(ctx, req.WarehouseID)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, echo.Map{"error": err.Error()})
+	}
+	index := slices.IndexFunc(lockers, func(locker model.WarehouseLocker) bool { return locker.ID == req.LockerID })
+	if index < 0 {
+		return echo.NewHTTPError(http.StatusNotFound, echo.Map{"error": "lockerID is not found"})
+	}
+
+	houses, err := s.medicineRepository.GetMedicineHouses(ctx, model.FilterMedicineHouse{WarehouseID: req.WarehouseID})
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, echo.Map{"error": err.Error()})
+	}
+	for _, house := range houses {
+		if house.Locker == lockers[index].Name {
+			return echo.NewHTTPError(http.StatusConflict, echo.Map{"error": "locker still has medicine houses"})
+		}
+	}
+
+	_, err = s.warehouseRepository.DeleteWarehouseLocker(ctx, req.WarehouseID, req.LockerID)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, echo.Map{"error": err.Error()})
+	}
+
+	recordAudit(ctx, s.auditRepository, model.CreateAuditLog{
+		Action:      genmodel.PharmaSheetAuditAction_Delete,
+		EntityType:  model.AuditEntityWarehouseLocker,
+		EntityID:    req.LockerID.String(),
+		WarehouseID: &req.WarehouseID,
+		Before:      lockers[index],
+	})
 	return nil
 }
+
+func (s *warehouse) PreviewRelocation(ctx context.Context, req model.RelocateMedicineHouseRequest) (model.RelocationPreview, error) {
+	err := s.checkWarehouseManagementRole(ctx, req.WarehouseID, genmodel.PharmaSheetRole_Admin, genmodel.PharmaSheetRole_Editor)
+	if err != nil {
+		logger.Context(ctx).Error(err)
+		return model.RelocationPreview{}, err
+	}
+
+	return s.planRelocation(ctx, req)
+}
+
+func (s *warehouse) RelocateMedicineHouses(ctx context.Context, req model.RelocateMedicineHouseRequest) (model.RelocationPreview, error) {
+	err := s.checkWarehouseManagementRole(ctx, req.WarehouseID, genmodel.PharmaSheetRole_Admin, genmodel.PharmaSheetRole_Editor)
+	if err != nil {
+		logger.Context(ctx).Error(err)
+		return model.RelocationPreview{}, err
+	}
+
+	preview, err := s.planRelocation(ctx, req)
+	if err != nil {
+		return preview, err
+	}
+	if preview.TotalConflicts > 0 {
+		return preview, echo.NewHTTPError(http.StatusConflict, echo.Map{"error": "relocation has conflicts", "preview": preview})
+	}
+	if preview.TotalHouses == 0 {
+		return preview, nil
+	}
+
+	medicationIDs := make([]string, 0, len(preview.Relocations))
+	for _, relocation := range preview.Relocations {
+		medicationIDs = append(medicationIDs, relocation.MedicationID)
+	}
+	roles, err := houseManagementRoles(ctx, s.medicineRepository, medicationIDs...)
+	if err != nil {
+		return preview, err
+	}
+	if err = s.checkWarehouseManagementRole(ctx, req.WarehouseID, roles...); err != nil {
+		logger.Context(ctx).Error(err)
+		return preview, err
+	}
+
+	houses, err := s.medicineRepository.GetMedicineHouses(ctx, model.FilterMedicineHouse{WarehouseID: req.WarehouseID})
+	if err != nil {
+		return preview, echo.NewHTTPError(http.StatusInternalServerError, echo.Map{"error": err.Error()})
+	}
+
+	err = s.medicineRepository.RelocateMedicineHouses(ctx, preview.Relocations)
+	if err != nil {
+		if model.IsConflictError(err) {
+			return preview, echo.NewHTTPError(http.StatusConflict, echo.Map{"error": err.Error()})
+		}
+		return preview, echo.NewHTTPError(http.StatusInternalServerError, echo.Map{"error": err.Error()})
+	}
+
+	auditLogs := make([]model.CreateAuditLog, 0, len(preview.Relocations))
+	for _, relocation := range preview.Relocations {
+		auditLog := model.CreateAuditLog{
+			Action:      genmodel.PharmaSheetAuditAction_Update,
+			EntityType:  model.AuditEntityMedicineHouse,
+			EntityID:    relocation.HouseID.String(),
+			WarehouseID: &req.WarehouseID,
+			After:       medicineHouseSnapshot(ctx, s.medicineRepository, relocation.HouseID),
+		}
+		if index := slices.IndexFunc(houses, func(house model.MedicineHouse) bool { return house.ID == relocation.HouseID }); index >= 0 {
+			auditLog.Before = houses[index]
+		}
+		auditLogs = append(auditLogs, auditLog)
+	}
+	recordAudit(ctx, s.auditRepository, auditLogs...)
+	return preview, nil
+}
+
+// planRelocation applies the first matching rule to every house of the warehouse and flags each relocation that
+// would fall outside the layout or end up on the same address as another house of the same medicine.
+func (s *warehouse) planRelocation(ctx context.Context, req model.RelocateMedicineHouseRequest) (preview model.RelocationPreview, err error) {
+	for index, rule := range req.Rules {
+		if rule.ToLocker != nil {
+			toLocker := strings.TrimSpace(*rule.ToLocker)
+			if toLocker == "" {
+				return preview, echo.NewHTTPError(http.StatusBadRequest, echo.Map{"error": fmt.Sprintf("rules[%d]: toLocker must not be empty", index)})
+			}
+			req.Rules[index].ToLocker = &toLocker
+		}
+		if (rule.ToLocker == nil || *req.Rules[index].ToLocker == rule.Locker) && rule.FloorShift == 0 && rule.NoShift == 0 {
+			return preview, echo.NewHTTPError(http.StatusBadRequest, echo.Map{"error": fmt.Sprintf("rules[%d]: rule does not change any address", index)})
+		}
+	}
+
+	houses, err := s.medicineRepository.GetMedicineHouses(ctx, model.FilterMedicineHouse{WarehouseID: req.WarehouseID})
+	if err != nil {
+		return preview, echo.NewHTTPError(http.StatusInternalServerError, echo.Map{"error": err.Error()})
+	}
+	lockers, err := s.warehouseRepository.ListWarehouseLockers(ctx, req.WarehouseID)
+	if err != nil {
+		return preview, echo.NewHTTPError(http.StatusInternalServerError, echo.Map{"error": err.Error()})
+	}
+
+	preview = model.RelocationPreview{
+		WarehouseID: req.WarehouseID,
+		Relocations: make([]model.HouseRelocation, 0),
+	}
+
+	// the final address of every house, moved or not, keyed the same way as unique_house
+	occupants := make(map[string][]uuid.UUID)
+	for _, house := range houses {
+		target := house
+		if index := slices.IndexFunc(req.Rules, func(rule model.RelocationRule) bool { return rule.Match(house) }); index >= 0 {
+			target = req.Rules[index].Apply(house)
+		}
+		occupants[target.ExternalID()] = append(occupants[target.ExternalID()], house.ID)
+
+		if target.Address() == house.Address() {
+			continue
+		}
+		relocation := model.HouseRelocation{
+			HouseID:      house.ID,
+			MedicationID: house.MedicationID,
+			FromLocker:   house.Locker,
+			FromFloor:    house.Floor,
+			FromNo:       house.No,
+			ToLocker:     target.Locker,
+			ToFloor:      target.Floor,
+			ToNo:         target.No,
+		}
+		if target.Floor < 1 || target.No < 1 {
+			relocation.Conflict = util.Pointer(fmt.Sprintf("address %s is out of range", target.Address()))
+		} else if err := model.CheckHouseAddress(lockers, target.Locker, target.Floor, target.No); err != nil {
+			relocation.Conflict = util.Pointer(err.Error())
+		}
+		preview.Relocations = append(preview.Relocations, relocation)
+	}
+
+	for index, relocation := range preview.Relocations {
+		if relocation.Conflict != nil {
+			continue
+		}
+		target := model.MedicineHouse{
+			WarehouseID:  req.WarehouseID,
+			MedicationID: relocation.MedicationID,
+			Locker:       relocation.ToLocker,
+			Floor:        relocation.ToFloor,
+			No:           relocation.ToNo,
+		}
+		if len(occupants[target.ExternalID()]) > 1 {
+			preview.Relocations[index].Conflict = util.Pointer(fmt.Sprintf("address %s is already taken by medicine %s", target.Address(), relocation.MedicationID))
+		}
+	}
+
+	slices.SortFunc(preview.Relocations, func(a, b model.HouseRelocation) int {
+		return cmp.Or(
+			cmp.Compare(a.FromLocker, b.FromLocker),
+			cmp.Compare(a.FromFloor, b.FromFloor),
+			cmp.Compare(a.FromNo, b.FromNo),
+			cmp.Compare(a.MedicationID, b.MedicationID),
+		)
+	})
+
+	preview.TotalHouses = len(preview.Relocations)
+	for _, relocation := range preview.Relocations {
+		if relocation.Conflict != nil {
+			preview.TotalConflicts++
+		}
+	}
+
+	return preview, nil
+}